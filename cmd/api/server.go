@@ -45,20 +45,49 @@ func main() {
 	logger.Println("Database connection pool established")
 
 	logger.Println("Running database migrations...")
+	if err := repository.MigrateBeforeSchema(db); err != nil {
+		logger.Fatalf("could not run data migrations: %v", err)
+	}
 	err = db.AutoMigrate(
 		&model.Poli{},
 		&model.Petugas{},
 		&model.Pasien{},
 		&model.Jadwal{},
+		&model.IcdKelompok{},
 		&model.Icd{},
+		&model.Prosedur{},
 		&model.Antrian{},
+		&model.AntrianSequence{},
 		&model.Pemeriksaan{},
 		&model.JenisPemeriksaanLab{},
+		&model.RentangRujukanLab{},
+		&model.PanelLab{},
+		&model.PanelLabItem{},
 		&model.PemeriksaanLab{},
+		&model.RefreshToken{},
+		&model.RevokedToken{},
+		&model.AuditLog{},
+		&model.PemeriksaanRevisi{},
+		&model.PemeriksaanAddendum{},
+		&model.PemeriksaanDiagnosis{},
+		&model.PemeriksaanTindakan{},
+		&model.Obat{},
+		&model.Resep{},
+		&model.ResepItem{},
+		&model.ResepPeringatan{},
+		&model.AlergiPasien{},
+		&model.InteraksiObat{},
+		&model.StokBatch{},
+		&model.StokMutasi{},
+		&model.OrderLab{},
+		&model.OrderLabItem{},
 	)
 	if err != nil {
 		logger.Fatalf("could not run migrations: %v", err)
 	}
+	if err := repository.Migrate(db); err != nil {
+		logger.Fatalf("could not run data migrations: %v", err)
+	}
 
 	logger.Println("Seeding database...")
 	if err := repository.Seed(db); err != nil {
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/franklindh/simedis-api/internal/config"
+	"github.com/franklindh/simedis-api/internal/icdimport"
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/service"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const usage = `Usage: go run cmd/cli/main.go <command> [flags]
+
+Commands:
+  import-icd        import katalog ICD-10 dari CSV atau ClaML XML
+  import-prosedur   import katalog prosedur ICD-9-CM dari CSV atau ClaML XML
+  import-interaksi  import tabel interaksi obat dari CSV (zat_a, zat_b, tingkat, deskripsi)
+`
+
+func main() {
+	logger := log.New(os.Stderr, "", log.Ldate|log.Ltime)
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "import-icd":
+		err = runImportIcd(os.Args[2:])
+	case "import-prosedur":
+		err = runImportProsedur(os.Args[2:])
+	case "import-interaksi":
+		err = runImportInteraksi(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		logger.Fatal(err)
+	}
+}
+
+func openDB() (*gorm.DB, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("could not load config: %w", err)
+	}
+	db, err := gorm.Open(postgres.Open(cfg.DSN), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("could not open gorm connection: %w", err)
+	}
+	return db, nil
+}
+
+type importFunc func(ctx context.Context, db *gorm.DB, source io.Reader, format string, dryRun bool) (model.ImportReport, error)
+
+func runImportIcd(args []string) error {
+	return runImport("import-icd", args, func(ctx context.Context, db *gorm.DB, source io.Reader, format string, dryRun bool) (model.ImportReport, error) {
+		if err := db.AutoMigrate(&model.IcdKelompok{}, &model.Icd{}); err != nil {
+			return model.ImportReport{}, fmt.Errorf("could not run migrations: %w", err)
+		}
+		return service.NewIcdService(repository.NewIcdRepository(db)).ImportIcd(ctx, source, format, dryRun)
+	})
+}
+
+func runImportProsedur(args []string) error {
+	return runImport("import-prosedur", args, func(ctx context.Context, db *gorm.DB, source io.Reader, format string, dryRun bool) (model.ImportReport, error) {
+		if err := db.AutoMigrate(&model.Prosedur{}); err != nil {
+			return model.ImportReport{}, fmt.Errorf("could not run migrations: %w", err)
+		}
+		return service.NewProsedurService(repository.NewProsedurRepository(db)).ImportProsedur(ctx, source, format, dryRun)
+	})
+}
+
+func runImportInteraksi(args []string) error {
+	return runImport("import-interaksi", args, func(ctx context.Context, db *gorm.DB, source io.Reader, format string, dryRun bool) (model.ImportReport, error) {
+		if err := db.AutoMigrate(&model.InteraksiObat{}); err != nil {
+			return model.ImportReport{}, fmt.Errorf("could not run migrations: %w", err)
+		}
+		return service.NewInteraksiObatService(repository.NewInteraksiObatRepository(db)).ImportInteraksi(ctx, source, format, dryRun)
+	})
+}
+
+func runImport(name string, args []string, importer importFunc) error {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	file := flags.String("file", "", "path file CSV atau ClaML XML")
+	format := flags.String("format", "", "csv atau claml (default: ditebak dari ekstensi file)")
+	dryRun := flags.Bool("dry-run", false, "hanya tampilkan laporan tanpa menyimpan perubahan")
+	flags.Parse(args)
+
+	if *file == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if *format == "" {
+		*format = icdimport.DetectFormat(*file)
+	}
+
+	source, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+
+	report, err := importer(context.Background(), db, source, *format, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	printImportReport(report)
+	return nil
+}
+
+func printImportReport(report model.ImportReport) {
+	if report.DryRun {
+		fmt.Println("dry run: tidak ada perubahan yang disimpan")
+	}
+	fmt.Printf("total: %d, inserted: %d, updated: %d, unchanged: %d, skipped: %d\n",
+		report.Total, report.Inserted, report.Updated, report.Unchanged, report.Skipped)
+	for _, e := range report.Errors {
+		if e.Baris > 0 {
+			fmt.Printf("  baris %d (%s): %s\n", e.Baris, e.Kode, e.Pesan)
+			continue
+		}
+		fmt.Printf("  %s: %s\n", e.Kode, e.Pesan)
+	}
+	if report.Skipped > len(report.Errors) {
+		fmt.Printf("  ... dan %d baris lain\n", report.Skipped-len(report.Errors))
+	}
+}
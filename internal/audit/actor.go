@@ -0,0 +1,22 @@
+package audit
+
+import "context"
+
+type actorKey struct{}
+
+// identitas pengguna yang sedang login, dibawa lewat context request sampai ke service
+type Actor struct {
+	ID       int
+	Username string
+	Role     string
+	IP       string
+}
+
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+type Change struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// membandingkan dua nilai lewat representasi JSON-nya, hanya field yang berubah yang dikembalikan
+func Diff(before, after any) (map[string]Change, error) {
+	beforeMap, err := toMap(before)
+	if err != nil {
+		return nil, err
+	}
+	afterMap, err := toMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]Change)
+	for key, oldValue := range beforeMap {
+		newValue, ok := afterMap[key]
+		if !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes[key] = Change{Old: oldValue, New: newValue}
+		}
+	}
+	for key, newValue := range afterMap {
+		if _, ok := beforeMap[key]; !ok {
+			changes[key] = Change{Old: nil, New: newValue}
+		}
+	}
+	return changes, nil
+}
+
+func toMap(value any) (map[string]any, error) {
+	if value == nil {
+		return map[string]any{}, nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]any)
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
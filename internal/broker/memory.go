@@ -0,0 +1,64 @@
+package broker
+
+import (
+	"sync"
+
+	"github.com/franklindh/simedis-api/internal/model"
+)
+
+const defaultBufferSize = 32
+
+// broker in-process untuk event antrian. Hanya menjangkau subscriber di instance yang sama,
+// untuk beberapa instance bisa diganti implementasi berbasis Postgres LISTEN/NOTIFY
+type MemoryBroker struct {
+	mu          sync.RWMutex
+	nextID      int
+	subscribers map[int]subscriber
+	bufferSize  int
+}
+
+type subscriber struct {
+	filter model.AntrianEventFilter
+	ch     chan model.AntrianEvent
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subscribers: make(map[int]subscriber), bufferSize: defaultBufferSize}
+}
+
+// subscriber yang lambat tidak menahan publisher, event untuknya dibuang saat buffer penuh
+func (b *MemoryBroker) Publish(event model.AntrianEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subscribers {
+		if !sub.filter.Match(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+}
+
+func (b *MemoryBroker) Subscribe(filter model.AntrianEventFilter) (<-chan model.AntrianEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	ch := make(chan model.AntrianEvent, b.bufferSize)
+	b.subscribers[id] = subscriber{filter: filter, ch: ch}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, id)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"gorm.io/gorm"
@@ -15,6 +16,15 @@ type Config struct {
 	DSN                    string
 	DefaultPetugasPassword string
 	JWTSecret              string
+	AccessTokenTTL         time.Duration
+	RefreshTokenTTL        time.Duration
+	AuthCacheTTL           time.Duration
+	RecordSignatureSecret  string
+
+	// identitas klinik untuk kop dokumen cetak
+	KlinikNama    string
+	KlinikAlamat  string
+	KlinikTelepon string
 }
 
 type Application struct {
@@ -35,5 +45,56 @@ func Load() (*Config, error) {
 
 	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s", os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"), os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_NAME"), os.Getenv("DB_SSLMODE"))
 
-	return &Config{Port: os.Getenv("API_PORT"), DSN: dsn, DefaultPetugasPassword: os.Getenv("DEFAULT_PETUGAS_PASSWORD"), JWTSecret: os.Getenv("JWT_SECRET")}, nil
+	accessTokenTTL, err := durationFromEnv("ACCESS_TOKEN_TTL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	refreshTokenTTL, err := durationFromEnv("REFRESH_TOKEN_TTL", 7*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	authCacheTTL, err := durationFromEnv("AUTH_CACHE_TTL", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	// kunci tanda tangan rekam medis sebaiknya terpisah dari JWT_SECRET agar rotasi JWT
+	// tidak membuat tanda tangan lama dianggap tidak valid
+	recordSignatureSecret := os.Getenv("RECORD_SIGNATURE_SECRET")
+	if recordSignatureSecret == "" {
+		recordSignatureSecret = os.Getenv("JWT_SECRET")
+	}
+
+	klinikNama := os.Getenv("KLINIK_NAMA")
+	if klinikNama == "" {
+		klinikNama = "Klinik"
+	}
+
+	return &Config{
+		Port:                   os.Getenv("API_PORT"),
+		DSN:                    dsn,
+		DefaultPetugasPassword: os.Getenv("DEFAULT_PETUGAS_PASSWORD"),
+		JWTSecret:              os.Getenv("JWT_SECRET"),
+		AccessTokenTTL:         accessTokenTTL,
+		RefreshTokenTTL:        refreshTokenTTL,
+		AuthCacheTTL:           authCacheTTL,
+		RecordSignatureSecret:  recordSignatureSecret,
+		KlinikNama:             klinikNama,
+		KlinikAlamat:           os.Getenv("KLINIK_ALAMAT"),
+		KlinikTelepon:          os.Getenv("KLINIK_TELEPON"),
+	}, nil
+}
+
+func durationFromEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return duration, nil
 }
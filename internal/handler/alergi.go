@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/pkg/utils"
+	"github.com/franklindh/simedis-api/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AlergiHandler struct {
+	Service *service.AlergiService
+}
+
+func NewAlergiHandler(svc *service.AlergiService) *AlergiHandler {
+	return &AlergiHandler{Service: svc}
+}
+
+func (h *AlergiHandler) Create(c *gin.Context) {
+	pasienID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	var req model.AlergiPasienRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	actorID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	created, err := h.Service.CreateAlergi(c.Request.Context(), pasienID, req, actorID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "pasien not found", nil)
+			return
+		}
+		if errors.Is(err, service.ErrAlergiDuplikat) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to create data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, created, "data created successfully")
+}
+
+func (h *AlergiHandler) GetAll(c *gin.Context) {
+	pasienID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	list, err := h.Service.GetAllByPasienID(c.Request.Context(), pasienID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, list, "success")
+}
+
+func (h *AlergiHandler) Update(c *gin.Context) {
+	pasienID, alergiID, ok := alergiParams(c)
+	if !ok {
+		return
+	}
+
+	var req model.AlergiPasienRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	updated, err := h.Service.UpdateAlergi(c.Request.Context(), pasienID, alergiID, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		if errors.Is(err, service.ErrAlergiDuplikat) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to update data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, updated, "data updated successfully")
+}
+
+func (h *AlergiHandler) Delete(c *gin.Context) {
+	pasienID, alergiID, ok := alergiParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteAlergi(c.Request.Context(), pasienID, alergiID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to delete data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "data deleted successfully")
+}
+
+func alergiParams(c *gin.Context) (int, int, bool) {
+	pasienID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return 0, 0, false
+	}
+	alergiID, err := strconv.Atoi(c.Param("alergi_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid alergi_id format", err)
+		return 0, 0, false
+	}
+	return pasienID, alergiID, true
+}
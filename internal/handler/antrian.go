@@ -37,6 +37,10 @@ func (h *AntrianHandler) Create(c *gin.Context) {
 			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
 			return
 		}
+		if errors.Is(err, service.ErrNomorAntrianConflict) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
 		if errors.Is(err, service.ErrForeignKey) {
 			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
 			return
@@ -125,6 +129,88 @@ func (h *AntrianHandler) Update(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, result, "data updated successfully")
 }
 
+func (h *AntrianHandler) Panggil(c *gin.Context) {
+	h.transition(c, model.StatusAntrianDipanggil)
+}
+
+func (h *AntrianHandler) Periksa(c *gin.Context) {
+	h.transition(c, model.StatusAntrianDiperiksa)
+}
+
+func (h *AntrianHandler) MenungguLab(c *gin.Context) {
+	h.transition(c, model.StatusAntrianMenungguLab)
+}
+
+func (h *AntrianHandler) Selesai(c *gin.Context) {
+	h.transition(c, model.StatusAntrianSelesai)
+}
+
+func (h *AntrianHandler) Batal(c *gin.Context) {
+	h.transition(c, model.StatusAntrianBatal)
+}
+
+func (h *AntrianHandler) TidakHadir(c *gin.Context) {
+	h.transition(c, model.StatusAntrianTidakHadir)
+}
+
+func (h *AntrianHandler) CallNext(c *gin.Context) {
+	jadwalID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	actorID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.Service.CallNextAntrian(c.Request.Context(), jadwalID, actorID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "jadwal not found", nil)
+			return
+		}
+		if errors.Is(err, service.ErrAntrianKosong) {
+			utils.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to call next antrian", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result, "antrian called successfully")
+}
+
+func (h *AntrianHandler) transition(c *gin.Context, toStatus string) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	actorID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.Service.TransitionAntrian(c.Request.Context(), id, toStatus, actorID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidTransition) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to update status", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result, "status updated successfully")
+}
+
 func (h *AntrianHandler) Delete(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const sseHeartbeatInterval = 15 * time.Second
+
+type paramsStreamAntrian struct {
+	JadwalID int `form:"jadwal_id" binding:"omitempty,gt=0"`
+	PoliID   int `form:"poli_id" binding:"omitempty,gt=0"`
+}
+
+// stream event antrian lengkap untuk layar petugas, bisa difilter per jadwal atau poli
+func (h *AntrianHandler) Stream(c *gin.Context) {
+	var params paramsStreamAntrian
+	if err := c.ShouldBindQuery(&params); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	events, unsubscribe := h.Service.SubscribeAntrian(model.AntrianEventFilter{JadwalID: params.JadwalID, PoliID: params.PoliID})
+	defer unsubscribe()
+
+	streamSSE(c, events, func(event model.AntrianEvent) any { return event })
+}
+
+// snapshot antrian hari ini untuk layar ruang tunggu, hanya nomor dan status
+func (h *AntrianHandler) Display(c *gin.Context) {
+	poliID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	antrian, err := h.Service.GetDisplayAntrianPoli(c.Request.Context(), poliID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, antrian, "success")
+}
+
+// stream publik untuk layar ruang tunggu, identitas pasien tidak pernah dikirim
+func (h *AntrianHandler) DisplayStream(c *gin.Context) {
+	poliID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	events, unsubscribe := h.Service.SubscribeAntrian(model.AntrianEventFilter{PoliID: poliID})
+	defer unsubscribe()
+
+	streamSSE(c, events, func(event model.AntrianEvent) any { return event.ToDisplay() })
+}
+
+func streamSSE(c *gin.Context, events <-chan model.AntrianEvent, encode func(model.AntrianEvent) any) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	ctx := c.Request.Context()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, encode(event))
+			return true
+		case t := <-heartbeat.C:
+			c.SSEvent("ping", t.Unix())
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/pkg/utils"
+	"github.com/franklindh/simedis-api/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	Service *service.AuditService
+}
+
+func NewAuditHandler(svc *service.AuditService) *AuditHandler {
+	return &AuditHandler{Service: svc}
+}
+
+func (h *AuditHandler) GetAll(c *gin.Context) {
+	var params repository.ParamsGetAllAudit
+
+	if err := c.ShouldBindQuery(&params); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	logs, metadata, err := h.Service.GetAllAudit(c.Request.Context(), params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"metadata": metadata,
+		"data":     logs,
+	})
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/franklindh/simedis-api/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func getUserID(c *gin.Context) (int, bool) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User ID not found in token", nil)
+		return 0, false
+	}
+
+	id, ok := userID.(int)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID format in token", nil)
+		return 0, false
+	}
+
+	return id, true
+}
+
+func getPasienID(c *gin.Context) (int, bool) {
+	pasienID, ok := c.Get("pasienID")
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Pasien ID not found in token", nil)
+		return 0, false
+	}
+
+	id, ok := pasienID.(int)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid pasien ID format in token", nil)
+		return 0, false
+	}
+
+	return id, true
+}
+
+func getTokenInfo(c *gin.Context) (string, time.Time) {
+	jti := c.GetString("jti")
+	expiresAt := c.GetTime("tokenExpiresAt")
+	return jti, expiresAt
+}
@@ -71,6 +71,38 @@ func (h *IcdHandler) GetAll(c *gin.Context) {
 	})
 }
 
+func (h *IcdHandler) Search(c *gin.Context) {
+	var params model.ParamsSearchKatalog
+	if err := c.ShouldBindQuery(&params); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	result, err := h.Service.SearchIcd(c.Request.Context(), params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result, "success")
+}
+
+func (h *IcdHandler) GetAllKelompok(c *gin.Context) {
+	var params model.ParamsGetAllIcdKelompok
+	if err := c.ShouldBindQuery(&params); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	result, err := h.Service.GetAllIcdKelompok(c.Request.Context(), params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result, "success")
+}
+
 func (h *IcdHandler) GetByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -140,3 +172,15 @@ func (h *IcdHandler) Delete(c *gin.Context) {
 
 	utils.SuccessResponse(c, http.StatusOK, nil, "data deleted successfully")
 }
+
+// file katalog dikirim sebagai multipart field "file", format ditebak dari ekstensi bila tidak diisi
+func (h *IcdHandler) Import(c *gin.Context) {
+	file, params, ok := openImportFile(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	report, err := h.Service.ImportIcd(c.Request.Context(), file, params.Format, params.DryRun)
+	respondImport(c, report, params, err)
+}
@@ -33,6 +33,10 @@ func (h *JenisPemeriksaanLabHandler) Create(c *gin.Context) {
 			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
 			return
 		}
+		if errors.Is(err, service.ErrRujukanLabInvalid) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
 		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to create data", err)
 		return
 	}
@@ -80,6 +84,10 @@ func (h *JenisPemeriksaanLabHandler) Update(c *gin.Context) {
 			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
 			return
 		}
+		if errors.Is(err, service.ErrRujukanLabInvalid) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
 		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to update data", err)
 		return
 	}
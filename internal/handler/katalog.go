@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/franklindh/simedis-api/internal/icdimport"
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/pkg/utils"
+	"github.com/franklindh/simedis-api/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ClaML ICD-10 lengkap berukuran sekitar 30 MB
+const maxKatalogImportSize = 64 << 20
+
+// membaca parameter impor dan file katalog dari request. Jika gagal, respons error sudah dikirim
+func openImportFile(c *gin.Context) (multipart.File, model.ParamsImportKatalog, bool) {
+	var params model.ParamsImportKatalog
+	if err := c.ShouldBindQuery(&params); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return nil, params, false
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxKatalogImportSize)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "file is required", err)
+		return nil, params, false
+	}
+	if params.Format == "" {
+		params.Format = icdimport.DetectFormat(fileHeader.Filename)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to read file", err)
+		return nil, params, false
+	}
+	return file, params, true
+}
+
+func respondImport(c *gin.Context, report model.ImportReport, params model.ParamsImportKatalog, err error) {
+	if err != nil {
+		if errors.Is(err, service.ErrImportFormat) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to import data", err)
+		return
+	}
+
+	message := "data imported successfully"
+	if params.DryRun {
+		message = "dry run completed, no data was changed"
+	}
+	utils.SuccessResponse(c, http.StatusOK, report, message)
+}
@@ -44,8 +44,9 @@ func (h *LaporanHandler) GetPenyakitTeratas(c *gin.Context) {
 	startDate := c.DefaultQuery("startDate", firstDayOfMonth.Format("2006-01-02"))
 	endDate := c.DefaultQuery("endDate", today.Format("2006-01-02"))
 	limit := limitConv
+	includeSekunder, _ := strconv.ParseBool(c.DefaultQuery("include_sekunder", "false"))
 
-	laporan, err := h.Service.GetLaporanPenyakitTeratas(c.Request.Context(), startDate, endDate, limit)
+	laporan, err := h.Service.GetLaporanPenyakitTeratas(c.Request.Context(), startDate, endDate, limit, includeSekunder)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), err)
 		return
@@ -53,3 +54,20 @@ func (h *LaporanHandler) GetPenyakitTeratas(c *gin.Context) {
 
 	utils.SuccessResponse(c, http.StatusOK, laporan, "Laporan penyakit teratas berhasil diambil")
 }
+
+func (h *LaporanHandler) GetTindakanTeratas(c *gin.Context) {
+	today := time.Now()
+	firstDayOfMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	startDate := c.DefaultQuery("startDate", firstDayOfMonth.Format("2006-01-02"))
+	endDate := c.DefaultQuery("endDate", today.Format("2006-01-02"))
+
+	laporan, err := h.Service.GetLaporanTindakanTeratas(c.Request.Context(), startDate, endDate, limit)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, laporan, "Laporan tindakan teratas berhasil diambil")
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/pkg/utils"
+	"github.com/franklindh/simedis-api/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ObatHandler struct {
+	Service *service.ObatService
+}
+
+func NewObatHandler(svc *service.ObatService) *ObatHandler {
+	return &ObatHandler{Service: svc}
+}
+
+func (h *ObatHandler) Create(c *gin.Context) {
+	var req model.CreateObatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	created, err := h.Service.CreateObat(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrObatConflict) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to create data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, created, "data created successfully")
+}
+
+func (h *ObatHandler) GetAll(c *gin.Context) {
+	var params repository.ParamsGetAllObat
+
+	if err := c.ShouldBindQuery(&params); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	if params.Page == 0 {
+		params.Page = 1
+	}
+	if params.PageSize == 0 {
+		params.PageSize = 5
+	}
+	if params.SortBy == "" {
+		params.SortBy = "nama_asc"
+	}
+
+	allObat, metadata, err := h.Service.GetAllObat(c.Request.Context(), params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"metadata": metadata,
+		"data":     allObat,
+	})
+}
+
+func (h *ObatHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	obat, err := h.Service.GetObatByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, obat, "success")
+}
+
+func (h *ObatHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	var req model.UpdateObatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	result, err := h.Service.UpdateObat(c.Request.Context(), id, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		if errors.Is(err, service.ErrObatConflict) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to update data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result, "data updated successfully")
+}
+
+func (h *ObatHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	err = h.Service.DeleteObat(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to delete data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "data deleted successfully")
+}
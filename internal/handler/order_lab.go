@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/pkg/utils"
+	"github.com/franklindh/simedis-api/service"
+	"github.com/gin-gonic/gin"
+)
+
+type OrderLabHandler struct {
+	Service *service.OrderLabService
+}
+
+func NewOrderLabHandler(svc *service.OrderLabService) *OrderLabHandler {
+	return &OrderLabHandler{Service: svc}
+}
+
+func (h *OrderLabHandler) Create(c *gin.Context) {
+	pemeriksaanID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	var req model.CreateOrderLabRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	dokterID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	created, err := h.Service.CreateOrder(c.Request.Context(), pemeriksaanID, req, dokterID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "pemeriksaan not found", nil)
+			return
+		}
+		if errors.Is(err, service.ErrPemeriksaanVoided) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, service.ErrJenisPemeriksaanInvalid) || errors.Is(err, service.ErrOrderLabJenisDuplikat) || errors.Is(err, service.ErrPanelLabInvalid) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to create data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, created, "data created successfully")
+}
+
+func (h *OrderLabHandler) GetAllByPemeriksaan(c *gin.Context) {
+	pemeriksaanID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	list, err := h.Service.GetAllByPemeriksaanID(c.Request.Context(), pemeriksaanID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, list, "success")
+}
+
+// worklist laboratorium, tanpa ?status= hanya order yang belum divalidasi atau dibatalkan
+func (h *OrderLabHandler) GetAll(c *gin.Context) {
+	var params repository.ParamsGetAllOrderLab
+	if err := c.ShouldBindQuery(&params); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	if params.Page == 0 {
+		params.Page = 1
+	}
+	if params.PageSize == 0 {
+		params.PageSize = 10
+	}
+
+	list, metadata, err := h.Service.GetAllOrder(c.Request.Context(), params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"metadata": metadata,
+		"data":     list,
+	})
+}
+
+func (h *OrderLabHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	order, err := h.Service.GetOrderByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, order, "success")
+}
+
+func (h *OrderLabHandler) AmbilSpesimen(c *gin.Context) {
+	h.transisi(c, h.Service.AmbilSpesimen, "specimen collected")
+}
+
+func (h *OrderLabHandler) TerimaSpesimen(c *gin.Context) {
+	h.transisi(c, h.Service.TerimaSpesimen, "specimen received")
+}
+
+func (h *OrderLabHandler) Validasi(c *gin.Context) {
+	h.transisi(c, h.Service.Validasi, "lab results validated")
+}
+
+func (h *OrderLabHandler) SimpanHasil(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	var reqs []model.HasilOrderLabRequest
+	if err := c.ShouldBindJSON(&reqs); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+	if len(reqs) == 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "at least one result is required", nil)
+		return
+	}
+
+	actorID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.Service.SimpanHasil(c.Request.Context(), id, reqs, actorID)
+	if err != nil {
+		respondOrderLabError(c, err, "failed to update data")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, order, "lab results saved")
+}
+
+func (h *OrderLabHandler) Batalkan(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	var req model.BatalOrderLabRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	order, err := h.Service.Batalkan(c.Request.Context(), id, req)
+	if err != nil {
+		respondOrderLabError(c, err, "failed to update data")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, order, "lab order cancelled")
+}
+
+func (h *OrderLabHandler) transisi(c *gin.Context, update func(ctx context.Context, id int, actorID int) (model.OrderLabResponse, error), message string) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	actorID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	order, err := update(c.Request.Context(), id, actorID)
+	if err != nil {
+		respondOrderLabError(c, err, "failed to update data")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, order, message)
+}
+
+func respondOrderLabError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+	case errors.Is(err, service.ErrOrderLabStatus), errors.Is(err, service.ErrValidatorPengisiHasil):
+		utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, service.ErrOrderLabItemInvalid), errors.Is(err, service.ErrHasilLabTidakValid):
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+	default:
+		utils.ErrorResponse(c, http.StatusInternalServerError, message, err)
+	}
+}
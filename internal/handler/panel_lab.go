@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/pkg/utils"
+	"github.com/franklindh/simedis-api/service"
+	"github.com/gin-gonic/gin"
+)
+
+type PanelLabHandler struct {
+	Service *service.PanelLabService
+}
+
+func NewPanelLabHandler(svc *service.PanelLabService) *PanelLabHandler {
+	return &PanelLabHandler{Service: svc}
+}
+
+func (h *PanelLabHandler) Create(c *gin.Context) {
+	var req model.PanelLabRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	created, err := h.Service.Create(c.Request.Context(), req)
+	if err != nil {
+		respondPanelLabError(c, err, "failed to create data")
+		return
+	}
+	utils.SuccessResponse(c, http.StatusCreated, created, "data created successfully")
+}
+
+func (h *PanelLabHandler) GetAll(c *gin.Context) {
+	list, err := h.Service.GetAll(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, list, "success")
+}
+
+func (h *PanelLabHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	panel, err := h.Service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, panel, "success")
+}
+
+func (h *PanelLabHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	var req model.PanelLabRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	updated, err := h.Service.Update(c.Request.Context(), id, req)
+	if err != nil {
+		respondPanelLabError(c, err, "failed to update data")
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, updated, "data updated successfully")
+}
+
+func (h *PanelLabHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	if err := h.Service.Delete(c.Request.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to delete data", err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, nil, "data deleted successfully")
+}
+
+func respondPanelLabError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+	case errors.Is(err, service.ErrPanelLabConflict):
+		utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, service.ErrJenisPemeriksaanInvalid), errors.Is(err, service.ErrPanelLabJenisDuplikat):
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+	default:
+		utils.ErrorResponse(c, http.StatusInternalServerError, message, err)
+	}
+}
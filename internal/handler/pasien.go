@@ -140,3 +140,29 @@ func (h *PasienHandler) Delete(c *gin.Context) {
 
 	utils.SuccessResponse(c, http.StatusOK, nil, "data deleted successfully")
 }
+
+func (h *PasienHandler) Login(c *gin.Context) {
+	var req model.LoginPasienRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "username and password are required", err)
+		return
+	}
+
+	result, err := h.Service.Login(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			utils.ErrorResponse(c, http.StatusUnauthorized, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "an internal error occurred", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":           "success",
+		"message":          "login successful",
+		"token":            result.Token,
+		"password_changed": result.PasswordChanged,
+	})
+}
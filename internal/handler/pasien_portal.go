@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/pkg/utils"
+	"github.com/franklindh/simedis-api/service"
+	"github.com/gin-gonic/gin"
+)
+
+type PasienPortalHandler struct {
+	PasienService      *service.PasienService
+	AntrianService     *service.AntrianService
+	PemeriksaanService *service.PemeriksaanService
+}
+
+func NewPasienPortalHandler(pasienSvc *service.PasienService, antrianSvc *service.AntrianService, pemeriksaanSvc *service.PemeriksaanService) *PasienPortalHandler {
+	return &PasienPortalHandler{
+		PasienService:      pasienSvc,
+		AntrianService:     antrianSvc,
+		PemeriksaanService: pemeriksaanSvc,
+	}
+}
+
+func (h *PasienPortalHandler) GetProfile(c *gin.Context) {
+	pasienID, ok := getPasienID(c)
+	if !ok {
+		return
+	}
+
+	pasien, err := h.PasienService.GetPasienByID(c.Request.Context(), pasienID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, pasien, "success")
+}
+
+func (h *PasienPortalHandler) GetAntrian(c *gin.Context) {
+	pasienID, ok := getPasienID(c)
+	if !ok {
+		return
+	}
+
+	antrian, err := h.AntrianService.GetUpcomingAntrianPasien(c.Request.Context(), pasienID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, antrian, "success")
+}
+
+func (h *PasienPortalHandler) GetRiwayatPemeriksaan(c *gin.Context) {
+	pasienID, ok := getPasienID(c)
+	if !ok {
+		return
+	}
+
+	riwayat, err := h.PemeriksaanService.GetRiwayatPemeriksaanPasien(c.Request.Context(), pasienID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, riwayat, "success")
+}
+
+func (h *PasienPortalHandler) ChangePassword(c *gin.Context) {
+	pasienID, ok := getPasienID(c)
+	if !ok {
+		return
+	}
+
+	var req model.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	result, err := h.PasienService.ChangePassword(c.Request.Context(), pasienID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+		case errors.Is(err, service.ErrOldPasswordMismatch), errors.Is(err, service.ErrPasswordSameAsDefault):
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "failed to change password", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":           "success",
+		"message":          "Password changed successfully",
+		"token":            result.Token,
+		"password_changed": result.PasswordChanged,
+	})
+}
+
+func (h *PasienPortalHandler) Logout(c *gin.Context) {
+	jti, expiresAt := getTokenInfo(c)
+	if err := h.PasienService.Logout(c.Request.Context(), jti, expiresAt); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to logout", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "logout successful")
+}
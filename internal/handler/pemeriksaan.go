@@ -27,12 +27,30 @@ func (h *PemeriksaanHandler) Create(c *gin.Context) {
 		return
 	}
 
-	created, err := h.Service.CreatePemeriksaan(c.Request.Context(), req)
+	authorID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	created, err := h.Service.CreatePemeriksaan(c.Request.Context(), req, authorID)
 	if err != nil {
-		if errors.Is(err, service.ErrPemeriksaanExists) {
+		if errors.Is(err, service.ErrPemeriksaanExists) || errors.Is(err, service.ErrInvalidTransition) {
 			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
 			return
 		}
+		if errors.Is(err, service.ErrDokterInvalid) ||
+			errors.Is(err, service.ErrDiagnosisPrimer) ||
+			errors.Is(err, service.ErrDiagnosisDuplikat) ||
+			errors.Is(err, service.ErrIcdNotFound) ||
+			errors.Is(err, service.ErrProsedurNotFound) ||
+			errors.Is(err, service.ErrPelaksanaInvalid) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "antrian not found", nil)
+			return
+		}
 		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to create data", err)
 		return
 	}
@@ -78,6 +96,31 @@ func (h *PemeriksaanHandler) GetRiwayatByPasienID(c *gin.Context) {
 	})
 }
 
+func (h *PemeriksaanHandler) GetVitalTrend(c *gin.Context) {
+	pasienID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	var params model.ParamsVitalTrend
+	if err := c.ShouldBindQuery(&params); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	trend, err := h.Service.GetVitalTrend(c.Request.Context(), pasienID, params)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDateRange) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, trend, "success")
+}
+
 func (h *PemeriksaanHandler) Update(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -91,8 +134,26 @@ func (h *PemeriksaanHandler) Update(c *gin.Context) {
 		return
 	}
 
-	updated, err := h.Service.UpdatePemeriksaan(c.Request.Context(), id, req)
+	authorID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	updated, err := h.Service.UpdatePemeriksaan(c.Request.Context(), id, req, authorID)
 	if err != nil {
+		if errors.Is(err, service.ErrDokterInvalid) ||
+			errors.Is(err, service.ErrDiagnosisPrimer) ||
+			errors.Is(err, service.ErrDiagnosisDuplikat) ||
+			errors.Is(err, service.ErrIcdNotFound) ||
+			errors.Is(err, service.ErrProsedurNotFound) ||
+			errors.Is(err, service.ErrPelaksanaInvalid) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, service.ErrPemeriksaanVoided) || errors.Is(err, service.ErrPemeriksaanLocked) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
 		if errors.Is(err, repository.ErrNotFound) {
 			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
 			return
@@ -102,3 +163,151 @@ func (h *PemeriksaanHandler) Update(c *gin.Context) {
 	}
 	utils.SuccessResponse(c, http.StatusOK, updated, "data updated successfully")
 }
+
+func (h *PemeriksaanHandler) Void(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	var req model.VoidPemeriksaanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	actorID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	voided, err := h.Service.VoidPemeriksaan(c.Request.Context(), id, req, actorID)
+	if err != nil {
+		if errors.Is(err, service.ErrPemeriksaanVoided) || errors.Is(err, service.ErrPemeriksaanLocked) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to void data", err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, voided, "data voided successfully")
+}
+
+func (h *PemeriksaanHandler) GetRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	revisions, err := h.Service.GetRevisions(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, revisions, "success")
+}
+
+func (h *PemeriksaanHandler) DiffRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	var params model.ParamsDiffRevisi
+	if err := c.ShouldBindQuery(&params); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	diff, err := h.Service.DiffRevisions(c.Request.Context(), id, params)
+	if err != nil {
+		if errors.Is(err, service.ErrRevisiRange) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) || errors.Is(err, service.ErrRevisiNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, diff, "success")
+}
+
+func (h *PemeriksaanHandler) Finalize(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	signerID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	signed, err := h.Service.FinalizePemeriksaan(c.Request.Context(), id, signerID)
+	if err != nil {
+		if errors.Is(err, service.ErrSignerNotDokter) {
+			utils.ErrorResponse(c, http.StatusForbidden, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, service.ErrPemeriksaanVoided) || errors.Is(err, service.ErrPemeriksaanLocked) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to sign data", err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, signed, "data signed successfully")
+}
+
+func (h *PemeriksaanHandler) AddAddendum(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	var req model.CreateAddendumRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	authorID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	addendum, err := h.Service.AddAddendum(c.Request.Context(), id, req, authorID)
+	if err != nil {
+		if errors.Is(err, service.ErrPemeriksaanVoided) || errors.Is(err, service.ErrPemeriksaanDraft) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to create data", err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusCreated, addendum, "data created successfully")
+}
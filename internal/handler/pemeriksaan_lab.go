@@ -33,10 +33,44 @@ func (h *PemeriksaanLabHandler) Create(c *gin.Context) {
 		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
 		return
 	}
+	if len(reqs) == 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "at least one result is required", nil)
+		return
+	}
 
 	created, err := h.Service.CreateBatch(c.Request.Context(), pemeriksaanID, reqs)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), err)
+		respondHasilLabBatchError(c, err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusCreated, created, "Lab results created successfully")
+}
+
+func (h *PemeriksaanLabHandler) CreatePanel(c *gin.Context) {
+	pemeriksaanID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid id format", err)
+		return
+	}
+	panelID, err := strconv.Atoi(c.Param("panel_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid panel_id format", err)
+		return
+	}
+
+	var reqs []model.CreateHasilLabRequest
+	if err := c.ShouldBindJSON(&reqs); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+	if len(reqs) == 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "at least one result is required", nil)
+		return
+	}
+
+	created, err := h.Service.CreatePanel(c.Request.Context(), pemeriksaanID, panelID, reqs)
+	if err != nil {
+		respondHasilLabBatchError(c, err)
 		return
 	}
 	utils.SuccessResponse(c, http.StatusCreated, created, "Lab results created successfully")
@@ -58,6 +92,32 @@ func (h *PemeriksaanLabHandler) GetAll(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, results, "Success")
 }
 
+// laporan hasil lab siap cetak untuk diserahkan ke pasien
+func (h *PemeriksaanLabHandler) CetakLaporan(c *gin.Context) {
+	pemeriksaanID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid id format", err)
+		return
+	}
+
+	laporan, err := h.Service.CetakLaporan(c.Request.Context(), pemeriksaanID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "pemeriksaan not found", nil)
+			return
+		}
+		if errors.Is(err, service.ErrHasilLabKosong) {
+			utils.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate report", err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="hasil-lab-%d.pdf"`, pemeriksaanID))
+	c.Data(http.StatusOK, "application/pdf", laporan)
+}
+
 func (h *PemeriksaanLabHandler) Update(c *gin.Context) {
 	hasilID, err := strconv.Atoi(c.Param("hasil_id"))
 	if err != nil {
@@ -77,6 +137,14 @@ func (h *PemeriksaanLabHandler) Update(c *gin.Context) {
 			utils.ErrorResponse(c, http.StatusNotFound, "Data not found", nil)
 			return
 		}
+		if errors.Is(err, service.ErrHasilLabOrder) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, service.ErrHasilLabTidakValid) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update data", err)
 		return
 	}
@@ -96,8 +164,35 @@ func (h *PemeriksaanLabHandler) Delete(c *gin.Context) {
 			utils.ErrorResponse(c, http.StatusNotFound, "Data not found", nil)
 			return
 		}
+		if errors.Is(err, service.ErrHasilLabOrder) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete data", err)
 		return
 	}
 	utils.SuccessResponse(c, http.StatusOK, nil, "Data deleted successfully")
 }
+
+// kesalahan per item dikirim di data supaya client bisa menandai baris yang salah sesuai index
+func respondHasilLabBatchError(c *gin.Context, err error) {
+	var batchErr *service.HasilLabBatchError
+	switch {
+	case errors.As(err, &batchErr):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": service.ErrHasilLabBatchInvalid.Error(),
+			"data":    batchErr.Errors,
+		})
+	case errors.Is(err, repository.ErrNotFound):
+		utils.ErrorResponse(c, http.StatusNotFound, "pemeriksaan not found", nil)
+	case errors.Is(err, service.ErrPanelLabInvalid):
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, service.ErrPemeriksaanVoided):
+		utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, service.ErrJenisPemeriksaanInvalid):
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+	default:
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create data", err)
+	}
+}
@@ -153,23 +153,78 @@ func (h *PetugasHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.Service.Login(c.Request.Context(), req)
+	tokens, err := h.Service.Login(c.Request.Context(), req)
 	if err != nil {
 		if errors.Is(err, service.ErrInvalidCredentials) {
 			utils.ErrorResponse(c, http.StatusUnauthorized, err.Error(), nil)
 			return
 		}
+		if errors.Is(err, service.ErrPetugasInactive) {
+			utils.ErrorResponse(c, http.StatusForbidden, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "an internal error occurred", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":        "success",
+		"message":       "login successful",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+func (h *PetugasHandler) RefreshToken(c *gin.Context) {
+	var req model.RefreshTokenRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "refresh_token is required", err)
+		return
+	}
+
+	tokens, err := h.Service.RefreshToken(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) {
+			utils.ErrorResponse(c, http.StatusUnauthorized, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, service.ErrPetugasInactive) {
+			utils.ErrorResponse(c, http.StatusForbidden, err.Error(), nil)
+			return
+		}
 		utils.ErrorResponse(c, http.StatusInternalServerError, "an internal error occurred", err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"status":  "success",
-		"message": "login successful",
-		"token":   token,
+		"status":        "success",
+		"message":       "token refreshed",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 	})
 }
 
+func (h *PetugasHandler) Logout(c *gin.Context) {
+	var req model.LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+			return
+		}
+	}
+
+	jti, expiresAt := getTokenInfo(c)
+	if err := h.Service.Logout(c.Request.Context(), jti, expiresAt, req); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to logout", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "logout successful")
+}
+
 func (h *PetugasHandler) ChangePassword(c *gin.Context) {
 
 	userID, exists := c.Get("userID")
@@ -30,7 +30,7 @@ func (h *PoliHandler) Create(c *gin.Context) {
 	createdPoli, err := h.Service.CreatePoli(c.Request.Context(), req)
 	if err != nil {
 
-		if errors.Is(err, service.ErrPoliConflict) {
+		if errors.Is(err, service.ErrPoliConflict) || errors.Is(err, service.ErrPoliKodeConflict) {
 			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
 			return
 		}
@@ -93,7 +93,7 @@ func (h *PoliHandler) Update(c *gin.Context) {
 		switch {
 		case errors.Is(err, repository.ErrNotFound):
 			utils.ErrorResponse(c, http.StatusNotFound, "Data not found", nil)
-		case errors.Is(err, service.ErrPoliConflict):
+		case errors.Is(err, service.ErrPoliConflict), errors.Is(err, service.ErrPoliKodeConflict):
 			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
 		default:
 			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update data", err)
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/pkg/utils"
+	"github.com/franklindh/simedis-api/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ProsedurHandler struct {
+	Service *service.ProsedurService
+}
+
+func NewProsedurHandler(svc *service.ProsedurService) *ProsedurHandler {
+	return &ProsedurHandler{Service: svc}
+}
+
+func (h *ProsedurHandler) Create(c *gin.Context) {
+	var req model.CreateProsedurRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	created, err := h.Service.CreateProsedur(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrProsedurConflict) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to create data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, created, "data created successfully")
+}
+
+func (h *ProsedurHandler) GetAll(c *gin.Context) {
+	var params repository.ParamsGetAllProsedur
+
+	if err := c.ShouldBindQuery(&params); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	if params.Page == 0 {
+		params.Page = 1
+	}
+	if params.PageSize == 0 {
+		params.PageSize = 5
+	}
+	if params.SortBy == "" {
+		params.SortBy = "created_at_desc"
+	}
+
+	allProsedur, metadata, err := h.Service.GetAllProsedur(c.Request.Context(), params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"metadata": metadata,
+		"data":     allProsedur,
+	})
+}
+
+func (h *ProsedurHandler) Search(c *gin.Context) {
+	var params model.ParamsSearchKatalog
+	if err := c.ShouldBindQuery(&params); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	result, err := h.Service.SearchProsedur(c.Request.Context(), params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result, "success")
+}
+
+func (h *ProsedurHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	prosedur, err := h.Service.GetProsedurByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, prosedur, "success")
+}
+
+func (h *ProsedurHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	var req model.UpdateProsedurRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	result, err := h.Service.UpdateProsedur(c.Request.Context(), id, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		if errors.Is(err, service.ErrProsedurConflict) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to update data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result, "data updated successfully")
+}
+
+func (h *ProsedurHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	err = h.Service.DeleteProsedur(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to delete data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "data deleted successfully")
+}
+
+// file katalog dikirim sebagai multipart field "file", format ditebak dari ekstensi bila tidak diisi
+func (h *ProsedurHandler) Import(c *gin.Context) {
+	file, params, ok := openImportFile(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	report, err := h.Service.ImportProsedur(c.Request.Context(), file, params.Format, params.DryRun)
+	respondImport(c, report, params, err)
+}
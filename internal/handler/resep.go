@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/pkg/utils"
+	"github.com/franklindh/simedis-api/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ResepHandler struct {
+	Service *service.ResepService
+}
+
+func NewResepHandler(svc *service.ResepService) *ResepHandler {
+	return &ResepHandler{Service: svc}
+}
+
+func (h *ResepHandler) Create(c *gin.Context) {
+	pemeriksaanID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	var req model.CreateResepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	dokterID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	created, err := h.Service.CreateResep(c.Request.Context(), pemeriksaanID, req, dokterID)
+	if err != nil {
+		var peringatanErr *service.PeringatanResepError
+		if errors.As(err, &peringatanErr) {
+			// client menampilkan peringatan lalu mengirim ulang resep dengan kodenya di konfirmasi_peringatan
+			c.JSON(http.StatusConflict, gin.H{
+				"status":  "error",
+				"message": service.ErrPeringatanBelumDikonfirmasi.Error(),
+				"data":    peringatanErr.Peringatan,
+			})
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "pemeriksaan not found", nil)
+			return
+		}
+		if errors.Is(err, service.ErrPemeriksaanVoided) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, service.ErrObatInvalid) || errors.Is(err, service.ErrResepObatDuplikat) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to create data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, created, "data created successfully")
+}
+
+func (h *ResepHandler) GetAllByPemeriksaan(c *gin.Context) {
+	pemeriksaanID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	list, err := h.Service.GetAllByPemeriksaanID(c.Request.Context(), pemeriksaanID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, list, "success")
+}
+
+// daftar kerja apotek, contoh ?status=menunggu untuk resep yang belum diserahkan
+func (h *ResepHandler) GetAll(c *gin.Context) {
+	var params repository.ParamsGetAllResep
+	if err := c.ShouldBindQuery(&params); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	if params.Page == 0 {
+		params.Page = 1
+	}
+	if params.PageSize == 0 {
+		params.PageSize = 10
+	}
+
+	list, metadata, err := h.Service.GetAllResep(c.Request.Context(), params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"metadata": metadata,
+		"data":     list,
+	})
+}
+
+func (h *ResepHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	resep, err := h.Service.GetResepByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, resep, "success")
+}
+
+func (h *ResepHandler) Serahkan(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid id format", err)
+		return
+	}
+
+	actorID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	resep, err := h.Service.SerahkanResep(c.Request.Context(), id, actorID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "data not found", nil)
+			return
+		}
+		if errors.Is(err, service.ErrResepDiserahkan) || errors.Is(err, service.ErrStokTidakCukup) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to update data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, resep, "resep dispensed successfully")
+}
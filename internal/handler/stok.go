@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/pkg/utils"
+	"github.com/franklindh/simedis-api/service"
+	"github.com/gin-gonic/gin"
+)
+
+type StokHandler struct {
+	Service *service.StokService
+}
+
+func NewStokHandler(svc *service.StokService) *StokHandler {
+	return &StokHandler{Service: svc}
+}
+
+func (h *StokHandler) StokMasuk(c *gin.Context) {
+	var req model.StokMasukRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	actorID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	batch, err := h.Service.StokMasuk(c.Request.Context(), req, actorID)
+	if err != nil {
+		if errors.Is(err, service.ErrObatInvalid) || errors.Is(err, service.ErrBatchKedaluwarsa) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, service.ErrBatchKedaluwarsaBeda) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to create data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, batch, "stock recorded successfully")
+}
+
+func (h *StokHandler) Sesuaikan(c *gin.Context) {
+	var req model.PenyesuaianStokRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	actorID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	batch, err := h.Service.Sesuaikan(c.Request.Context(), req, actorID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "batch not found", nil)
+			return
+		}
+		if errors.Is(err, service.ErrStokTidakCukup) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to update data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, batch, "stock adjusted successfully")
+}
+
+// contoh ?obat_id=3&include_habis=true untuk melihat batch yang sisanya sudah 0
+func (h *StokHandler) GetAllBatch(c *gin.Context) {
+	var params repository.ParamsGetAllStokBatch
+	if err := c.ShouldBindQuery(&params); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	list, err := h.Service.GetAllBatch(c.Request.Context(), params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, list, "success")
+}
+
+func (h *StokHandler) GetAllMutasi(c *gin.Context) {
+	var params repository.ParamsGetAllStokMutasi
+	if err := c.ShouldBindQuery(&params); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	if params.Page == 0 {
+		params.Page = 1
+	}
+	if params.PageSize == 0 {
+		params.PageSize = 10
+	}
+
+	list, metadata, err := h.Service.GetAllMutasi(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDateRange) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"metadata": metadata,
+		"data":     list,
+	})
+}
+
+// contoh ?hari=30 untuk batch yang kedaluwarsa dalam 30 hari ke depan
+func (h *StokHandler) GetPeringatan(c *gin.Context) {
+	var params model.ParamsPeringatanStok
+	if err := c.ShouldBindQuery(&params); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, utils.FormatValidationError(err), err)
+		return
+	}
+
+	peringatan, err := h.Service.GetPeringatan(c.Request.Context(), params)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to retrieve data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, peringatan, "success")
+}
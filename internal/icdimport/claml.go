@@ -0,0 +1,153 @@
+package icdimport
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	clamlKindChapter  = "chapter"
+	clamlKindBlock    = "block"
+	clamlKindCategory = "category"
+)
+
+type clamlClass struct {
+	Code       string `xml:"code,attr"`
+	Kind       string `xml:"kind,attr"`
+	SuperClass []struct {
+		Code string `xml:"code,attr"`
+	} `xml:"SuperClass"`
+	Rubric []struct {
+		Kind  string `xml:"kind,attr"`
+		Label []struct {
+			Inner string `xml:",innerxml"`
+		} `xml:"Label"`
+	} `xml:"Rubric"`
+}
+
+func (c clamlClass) superCode() string {
+	if len(c.SuperClass) == 0 {
+		return ""
+	}
+	return c.SuperClass[0].Code
+}
+
+// teks label pertama dari rubric dengan kind tertentu, elemen di dalam label (Reference, Fragment)
+// diambil isinya saja
+func (c clamlClass) label(kind string) string {
+	for _, rubric := range c.Rubric {
+		if rubric.Kind != kind || len(rubric.Label) == 0 {
+			continue
+		}
+		return cleanText(html.UnescapeString(xmlTagPattern.ReplaceAllString(rubric.Label[0].Inner, " ")))
+	}
+	return ""
+}
+
+var xmlTagPattern = regexp.MustCompile(`<[^>]+>`)
+
+// file ClaML WHO berisi Class untuk bab, blok, dan kategori. Hanya kategori yang disimpan
+// sebagai kode ICD, bab dan blok disimpan sebagai kelompok beserta namanya
+func parseClaML(r io.Reader) (Result, error) {
+	decoder := xml.NewDecoder(r)
+	classes := make(map[string]clamlClass)
+	var order []string
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to read claml: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "Class" {
+			continue
+		}
+		var class clamlClass
+		if err := decoder.DecodeElement(&class, &start); err != nil {
+			return Result{}, fmt.Errorf("failed to read claml class: %w", err)
+		}
+		if _, exists := classes[class.Code]; !exists {
+			order = append(order, class.Code)
+		}
+		classes[class.Code] = class
+	}
+	if len(classes) == 0 {
+		return Result{}, errors.New("claml file contains no classes")
+	}
+
+	var (
+		entries   []Entry
+		rowErrors []RowError
+		kelompok  = newKelompokSet()
+	)
+	for _, code := range order {
+		class := classes[code]
+		switch class.Kind {
+		case clamlKindChapter:
+			kelompok.add(Kelompok{Kode: class.Code, Nama: class.label("preferred"), Jenis: KelompokBab})
+			continue
+		case clamlKindBlock:
+			blokInduk, bab := ancestors(classes, class)
+			induk := bab
+			if blokInduk != "" {
+				induk = blokInduk
+			}
+			kelompok.add(Kelompok{Kode: class.Code, Nama: class.label("preferred"), Jenis: KelompokBlok, KodeInduk: induk})
+			continue
+		}
+		if class.Kind != clamlKindCategory {
+			continue
+		}
+
+		entry := Entry{
+			Kode:      strings.ToUpper(class.Code),
+			Nama:      class.label("preferred"),
+			Deskripsi: class.label("preferredLong"),
+		}
+		if entry.Deskripsi == "" {
+			entry.Deskripsi = class.label("definition")
+		}
+		if parent, ok := classes[class.superCode()]; ok && parent.Kind == clamlKindCategory {
+			entry.KodeInduk = strings.ToUpper(parent.Code)
+		}
+		entry.Blok, entry.Bab = ancestors(classes, class)
+
+		if msg := validateEntry(entry); msg != "" {
+			rowErrors = append(rowErrors, RowError{Kode: entry.Kode, Pesan: msg})
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return Result{Entries: entries, Kelompok: kelompok.list, Errors: rowErrors}, nil
+}
+
+// menelusuri SuperClass ke atas sampai bab, blok yang diambil adalah blok terdekat
+func ancestors(classes map[string]clamlClass, class clamlClass) (blok, bab string) {
+	visited := make(map[string]bool)
+	current := class
+	for !visited[current.Code] {
+		visited[current.Code] = true
+		parent, ok := classes[current.superCode()]
+		if !ok {
+			break
+		}
+		switch parent.Kind {
+		case clamlKindBlock:
+			if blok == "" {
+				blok = parent.Code
+			}
+		case clamlKindChapter:
+			return blok, parent.Code
+		}
+		current = parent
+	}
+	return blok, bab
+}
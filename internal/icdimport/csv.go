@@ -0,0 +1,136 @@
+package icdimport
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// nama kolom yang dikenali, header dibandingkan tanpa memperhatikan huruf besar kecil
+var csvColumns = map[string][]string{
+	"kode":       {"kode", "kode_icd", "kode_prosedur", "code"},
+	"nama":       {"nama", "nama_penyakit", "nama_prosedur", "name", "title"},
+	"deskripsi":  {"deskripsi", "deskripsi_penyakit", "deskripsi_prosedur", "description"},
+	"bab":        {"bab", "chapter"},
+	"nama_bab":   {"nama_bab", "chapter_name"},
+	"blok":       {"blok", "block"},
+	"nama_blok":  {"nama_blok", "block_name"},
+	"kode_induk": {"kode_induk", "induk", "parent"},
+	"status":     {"status", "status_icd"},
+}
+
+// CSV wajib punya baris header dengan minimal kolom kode dan nama. Pemisah koma atau
+// titik koma (ekspor Excel lokal) dideteksi dari baris header
+func parseCSV(r io.Reader) (Result, error) {
+	br := bufio.NewReader(r)
+	header, err := br.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Result{}, err
+	}
+	header = strings.TrimPrefix(header, "\ufeff")
+
+	reader := csv.NewReader(io.MultiReader(strings.NewReader(header), br))
+	if strings.Count(header, ";") > strings.Count(header, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	columns, err := reader.Read()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	position := csvColumnPositions(columns)
+	if _, ok := position["kode"]; !ok {
+		return Result{}, errors.New("csv header must contain a kode column")
+	}
+	if _, ok := position["nama"]; !ok {
+		return Result{}, errors.New("csv header must contain a nama column")
+	}
+
+	var (
+		entries   []Entry
+		rowErrors []RowError
+		kelompok  = newKelompokSet()
+	)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to read csv: %w", err)
+		}
+		baris, _ := reader.FieldPos(0)
+
+		value := func(column string) string {
+			i, ok := position[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return cleanText(record[i])
+		}
+
+		entry := Entry{
+			Baris:     baris,
+			Kode:      strings.ToUpper(value("kode")),
+			Nama:      value("nama"),
+			Deskripsi: value("deskripsi"),
+			Bab:       value("bab"),
+			Blok:      strings.ToUpper(value("blok")),
+			KodeInduk: strings.ToUpper(value("kode_induk")),
+			Status:    strings.ToLower(value("status")),
+		}
+		if entry.Kode == "" && entry.Nama == "" {
+			continue
+		}
+		if msg := validateEntry(entry); msg != "" {
+			rowErrors = append(rowErrors, RowError{Baris: baris, Kode: entry.Kode, Pesan: msg})
+			continue
+		}
+		entries = append(entries, entry)
+
+		if entry.Bab != "" {
+			kelompok.add(Kelompok{Kode: entry.Bab, Nama: value("nama_bab"), Jenis: KelompokBab})
+		}
+		if entry.Blok != "" {
+			kelompok.add(Kelompok{Kode: entry.Blok, Nama: value("nama_blok"), Jenis: KelompokBlok, KodeInduk: entry.Bab})
+		}
+	}
+
+	resolveHierarchy(entries)
+	return Result{Entries: entries, Kelompok: kelompok.list, Errors: rowErrors}, nil
+}
+
+func csvColumnPositions(header []string) map[string]int {
+	position := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for column, aliases := range csvColumns {
+			for _, alias := range aliases {
+				if name == alias {
+					if _, exists := position[column]; !exists {
+						position[column] = i
+					}
+				}
+			}
+		}
+	}
+	return position
+}
+
+func validateEntry(e Entry) string {
+	switch {
+	case e.Kode == "":
+		return "kode is required"
+	case e.Nama == "":
+		return "nama is required"
+	case e.Status != "" && e.Status != "aktif" && e.Status != "nonaktif":
+		return "status must be aktif or nonaktif"
+	}
+	return ""
+}
@@ -0,0 +1,171 @@
+package icdimport
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatClaML = "claml"
+)
+
+const (
+	KelompokBab  = "bab"
+	KelompokBlok = "blok"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported import format")
+
+// satu kode hasil baca file katalog. Bab dan Blok berisi kode bab ("X") dan rentang blok ("J00-J06"),
+// KodeInduk berisi kode kategori di atasnya untuk subkategori seperti "J01.0"
+type Entry struct {
+	Baris     int
+	Kode      string
+	Nama      string
+	Deskripsi string
+	Bab       string
+	Blok      string
+	KodeInduk string
+	Status    string
+}
+
+// bab atau blok katalog. KodeInduk blok berisi kode babnya. Nama bisa kosong bila file
+// hanya menyebut kodenya
+type Kelompok struct {
+	Kode      string
+	Nama      string
+	Jenis     string
+	KodeInduk string
+}
+
+type Result struct {
+	Entries  []Entry
+	Kelompok []Kelompok
+	Errors   []RowError
+}
+
+// baris yang tidak bisa diimpor beserta alasannya, tidak menghentikan proses impor
+type RowError struct {
+	Baris int
+	Kode  string
+	Pesan string
+}
+
+// format ditebak dari ekstensi file, file .xml dianggap ClaML
+func DetectFormat(filename string) string {
+	if strings.EqualFold(filepath.Ext(filename), ".xml") {
+		return FormatClaML
+	}
+	return FormatCSV
+}
+
+func Parse(r io.Reader, format string) (Result, error) {
+	var (
+		result Result
+		err    error
+	)
+	switch format {
+	case FormatCSV:
+		result, err = parseCSV(r)
+	case FormatClaML:
+		result, err = parseClaML(r)
+	default:
+		return Result{}, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	entries, duplikat := dedupe(result.Entries)
+	result.Entries = entries
+	result.Errors = append(result.Errors, duplikat...)
+	return result, nil
+}
+
+// kode yang muncul lebih dari sekali hanya diambil kemunculan pertamanya
+func dedupe(entries []Entry) ([]Entry, []RowError) {
+	var rowErrors []RowError
+	seen := make(map[string]bool, len(entries))
+	unique := entries[:0]
+	for _, e := range entries {
+		key := strings.ToUpper(e.Kode)
+		if seen[key] {
+			rowErrors = append(rowErrors, RowError{Baris: e.Baris, Kode: e.Kode, Pesan: "duplicate code in file"})
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, e)
+	}
+	return unique, rowErrors
+}
+
+// kode induk diturunkan dari kodenya: "J01.0" ke "J01" (ICD-10), "47.01" ke "47.0" (ICD-9-CM).
+// Bab dan blok yang kosong diwarisi dari induknya bila induk ada di file yang sama
+func resolveHierarchy(entries []Entry) {
+	index := make(map[string]int, len(entries))
+	for i, e := range entries {
+		index[strings.ToUpper(e.Kode)] = i
+	}
+	for i := range entries {
+		e := &entries[i]
+		if e.KodeInduk == "" {
+			e.KodeInduk = parentCode(e.Kode)
+		}
+		if e.KodeInduk == "" {
+			continue
+		}
+		if parent, ok := index[strings.ToUpper(e.KodeInduk)]; ok {
+			if e.Bab == "" {
+				e.Bab = entries[parent].Bab
+			}
+			if e.Blok == "" {
+				e.Blok = entries[parent].Blok
+			}
+		}
+	}
+}
+
+func parentCode(kode string) string {
+	dot := strings.Index(kode, ".")
+	if dot <= 0 {
+		return ""
+	}
+	if len(kode)-dot > 2 {
+		return kode[:len(kode)-1]
+	}
+	return kode[:dot]
+}
+
+func cleanText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// kumpulan bab/blok unik sesuai urutan kemunculan, nama dan induk yang kosong dilengkapi
+// dari kemunculan berikutnya
+type kelompokSet struct {
+	list  []Kelompok
+	index map[string]int
+}
+
+func newKelompokSet() *kelompokSet {
+	return &kelompokSet{index: make(map[string]int)}
+}
+
+func (s *kelompokSet) add(k Kelompok) {
+	i, ok := s.index[k.Kode]
+	if !ok {
+		s.index[k.Kode] = len(s.list)
+		s.list = append(s.list, k)
+		return
+	}
+	if s.list[i].Nama == "" {
+		s.list[i].Nama = k.Nama
+	}
+	if s.list[i].KodeInduk == "" {
+		s.list[i].KodeInduk = k.KodeInduk
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditRecorder interface {
+	Record(ctx context.Context, entry model.AuditLog) error
+}
+
+// mencatat akses baca data medis yang berhasil. Perubahan data dicatat di service beserta diff-nya
+func AuditRead(recorder AuditRecorder, entity string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Request.Method != http.MethodGet || c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		entityID := c.Param("id")
+		if entityID == "" {
+			if pasienID, ok := c.Get("pasienID"); ok {
+				entityID = strconv.Itoa(pasienID.(int))
+			}
+		}
+
+		entry := model.AuditLog{
+			Action:   model.AuditActionRead,
+			Entity:   entity,
+			EntityID: entityID,
+			Path:     c.Request.URL.RequestURI(),
+		}
+		if err := recorder.Record(c.Request.Context(), entry); err != nil {
+			c.Error(err)
+		}
+	}
+}
@@ -1,59 +1,184 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/franklindh/simedis-api/internal/audit"
 	"github.com/franklindh/simedis-api/internal/config"
+	"github.com/franklindh/simedis-api/internal/model"
 	"github.com/franklindh/simedis-api/pkg/utils"
+	"github.com/franklindh/simedis-api/service"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+type TokenValidator interface {
+	ValidatePetugasToken(ctx context.Context, petugasID int, jti string, tokenVersion int) (model.Petugas, error)
+	ValidatePasienToken(ctx context.Context, pasienID int, jti string, tokenVersion int) error
+}
+
+func AuthMiddleware(cfg *config.Config, validator TokenValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Authorization header is required", nil)
+		claims, ok := parseBearerToken(c, cfg, utils.TokenTypePetugas)
+		if !ok {
 			c.Abort()
 			return
 		}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Authorization header format must be Bearer {token}", nil)
+		tokenVersion, _ := claims.raw["ver"].(float64)
+		petugas, err := validator.ValidatePetugasToken(c.Request.Context(), claims.userID, claims.jti, int(tokenVersion))
+		if err != nil {
+			respondTokenError(c, err)
 			c.Abort()
 			return
 		}
-		tokenString := parts[1]
 
-		jwtSecret := []byte(cfg.JWTSecret)
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
+		// role dan username diambil dari data petugas terkini, bukan dari claim token
+		c.Set("userID", petugas.ID)
+		c.Set("jti", claims.jti)
+		c.Set("tokenExpiresAt", claims.expiresAt)
+		c.Set("role", petugas.Role)
+		c.Set("username", petugas.Username)
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), audit.Actor{
+			ID:       petugas.ID,
+			Username: petugas.Username,
+			Role:     petugas.Role,
+			IP:       c.ClientIP(),
+		}))
+
+		c.Next()
+	}
+}
 
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return jwtSecret, nil
-		})
+func PasienAuthMiddleware(cfg *config.Config, validator TokenValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
 
-		if err != nil {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired token", err)
+		claims, ok := parseBearerToken(c, cfg, utils.TokenTypePasien)
+		if !ok {
 			c.Abort()
 			return
 		}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			c.Set("userID", claims["sub"])
-			c.Set("role", claims["role"])
-			c.Set("username", claims["username"])
-		} else {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid token claims", nil)
+		tokenVersion, _ := claims.raw["ver"].(float64)
+		if err := validator.ValidatePasienToken(c.Request.Context(), claims.userID, claims.jti, int(tokenVersion)); err != nil {
+			respondTokenError(c, err)
 			c.Abort()
 			return
 		}
 
+		passwordChanged, _ := claims.raw["password_changed"].(bool)
+
+		c.Set("pasienID", claims.userID)
+		c.Set("jti", claims.jti)
+		c.Set("tokenExpiresAt", claims.expiresAt)
+		c.Set("role", claims.raw["role"])
+		c.Set("username", claims.raw["username"])
+		c.Set("passwordChanged", passwordChanged)
+
+		username, _ := claims.raw["username"].(string)
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), audit.Actor{
+			ID:       claims.userID,
+			Username: username,
+			Role:     "Pasien",
+			IP:       c.ClientIP(),
+		}))
+
 		c.Next()
 	}
 }
+
+// blok akses pasien yang masih memakai password default (NIK)
+func RequirePasswordChanged() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if changed, _ := c.Get("passwordChanged"); changed != true {
+			utils.ErrorResponse(c, http.StatusForbidden, "Password must be changed before accessing this resource", nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+type tokenClaims struct {
+	userID    int
+	jti       string
+	expiresAt time.Time
+	raw       jwt.MapClaims
+}
+
+func parseBearerToken(c *gin.Context, cfg *config.Config, tokenType string) (tokenClaims, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Authorization header is required", nil)
+		return tokenClaims{}, false
+	}
+
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Authorization header format must be Bearer {token}", nil)
+		return tokenClaims{}, false
+	}
+	tokenString := parts[1]
+
+	jwtSecret := []byte(cfg.JWTSecret)
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
+
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return jwtSecret, nil
+	})
+
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired token", err)
+		return tokenClaims{}, false
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid token claims", nil)
+		return tokenClaims{}, false
+	}
+
+	if typ, _ := claims["typ"].(string); typ != tokenType {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Token is not valid for this resource", nil)
+		return tokenClaims{}, false
+	}
+
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.Atoi(sub)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid token claims", err)
+		return tokenClaims{}, false
+	}
+
+	jti, _ := claims["jti"].(string)
+
+	var expiresAt time.Time
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		expiresAt = exp.Time
+	}
+
+	return tokenClaims{userID: userID, jti: jti, expiresAt: expiresAt, raw: claims}, true
+}
+
+// hanya token yang memang ditolak yang dijawab 401. Kegagalan database dijawab 500 supaya
+// client tidak menganggapnya logout dan membuang sesi pengguna
+func respondTokenError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrTokenRevoked):
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired token", err)
+	case errors.Is(err, service.ErrPetugasInactive):
+		utils.ErrorResponse(c, http.StatusForbidden, err.Error(), nil)
+	default:
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to validate token", err)
+	}
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	KeparahanAlergiRingan = "ringan"
+	KeparahanAlergiSedang = "sedang"
+	KeparahanAlergiBerat  = "berat"
+)
+
+// alergi yang pernah dilaporkan pasien. Zat berisi nama zat aktif atau golongan obat
+// ("amoksisilin", "penisilin") yang dicocokkan dengan zat aktif obat saat resep ditulis
+type AlergiPasien struct {
+	ID               int            `gorm:"primaryKey;column:id_alergi"`
+	PasienID         int            `gorm:"column:id_pasien;index"`
+	Zat              string         `gorm:"column:zat"`
+	Reaksi           sql.NullString `gorm:"column:reaksi"`
+	TingkatKeparahan string         `gorm:"column:tingkat_keparahan"`
+	DicatatOleh      sql.NullInt64  `gorm:"column:dicatat_oleh"`
+	DeletedAt        gorm.DeletedAt `gorm:"index;column:deleted_at"`
+	CreatedAt        time.Time      `gorm:"column:created_at"`
+	UpdatedAt        time.Time      `gorm:"column:updated_at"`
+}
+
+func (AlergiPasien) TableName() string { return "alergi_pasien" }
+
+type AlergiPasienRequest struct {
+	Zat              string `json:"zat" binding:"required,min=3,max=100,sanitize"`
+	Reaksi           string `json:"reaksi,omitempty" binding:"omitempty,max=255,sanitize"`
+	TingkatKeparahan string `json:"tingkat_keparahan" binding:"required,oneof=ringan sedang berat"`
+}
+
+func (req *AlergiPasienRequest) ToModel(pasienID int) AlergiPasien {
+	return AlergiPasien{
+		PasienID:         pasienID,
+		Zat:              req.Zat,
+		Reaksi:           sql.NullString{String: req.Reaksi, Valid: req.Reaksi != ""},
+		TingkatKeparahan: req.TingkatKeparahan,
+	}
+}
+
+type AlergiPasienResponse struct {
+	ID               int       `json:"id"`
+	Zat              string    `json:"zat"`
+	Reaksi           string    `json:"reaksi,omitempty"`
+	TingkatKeparahan string    `json:"tingkat_keparahan"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+func ToAlergiPasienResponse(a AlergiPasien) AlergiPasienResponse {
+	return AlergiPasienResponse{
+		ID:               a.ID,
+		Zat:              a.Zat,
+		Reaksi:           a.Reaksi.String,
+		TingkatKeparahan: a.TingkatKeparahan,
+		CreatedAt:        a.CreatedAt,
+	}
+}
+
+func ToAlergiPasienResponseList(list []AlergiPasien) []AlergiPasienResponse {
+	responses := make([]AlergiPasienResponse, 0, len(list))
+	for _, a := range list {
+		responses = append(responses, ToAlergiPasienResponse(a))
+	}
+	return responses
+}
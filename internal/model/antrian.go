@@ -1,21 +1,37 @@
 package model
 
 import (
+	"database/sql"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type Antrian struct {
-	ID           int            `json:"id,omitempty" gorm:"primaryKey;column:id_antrian"`
-	JadwalID     int            `json:"jadwal_id" gorm:"column:id_jadwal"`
-	PasienID     int            `json:"pasien_id" gorm:"column:id_pasien"`
-	NomorAntrian string         `json:"nomor_antrian" gorm:"column:nomor_antrian"`
-	Prioritas    string         `json:"prioritas" gorm:"column:prioritas"`
-	Status       string         `json:"status" gorm:"column:status"`
-	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index;column:deleted_at"`
-	CreatedAt    time.Time      `json:"created_at" gorm:"column:created_at"`
-	UpdatedAt    time.Time      `json:"updated_at" gorm:"column:updated_at"`
+	ID           int    `json:"id,omitempty" gorm:"primaryKey;column:id_antrian"`
+	JadwalID     int    `json:"jadwal_id" gorm:"column:id_jadwal;uniqueIndex:idx_antrian_jadwal_nomor"`
+	PasienID     int    `json:"pasien_id" gorm:"column:id_pasien"`
+	NomorAntrian string `json:"nomor_antrian" gorm:"column:nomor_antrian;uniqueIndex:idx_antrian_jadwal_nomor"`
+	Prioritas    string `json:"prioritas" gorm:"column:prioritas"`
+	Status       string `json:"status" gorm:"column:status"`
+
+	// waktu dan petugas untuk setiap transisi status
+	DipanggilAt     sql.NullTime   `json:"dipanggil_at" gorm:"column:dipanggil_at"`
+	DipanggilOleh   sql.NullInt64  `json:"dipanggil_oleh" gorm:"column:dipanggil_oleh"`
+	DiperiksaAt     sql.NullTime   `json:"diperiksa_at" gorm:"column:diperiksa_at"`
+	DiperiksaOleh   sql.NullInt64  `json:"diperiksa_oleh" gorm:"column:diperiksa_oleh"`
+	MenungguLabAt   sql.NullTime   `json:"menunggu_lab_at" gorm:"column:menunggu_lab_at"`
+	MenungguLabOleh sql.NullInt64  `json:"menunggu_lab_oleh" gorm:"column:menunggu_lab_oleh"`
+	SelesaiAt       sql.NullTime   `json:"selesai_at" gorm:"column:selesai_at"`
+	SelesaiOleh     sql.NullInt64  `json:"selesai_oleh" gorm:"column:selesai_oleh"`
+	BatalAt         sql.NullTime   `json:"batal_at" gorm:"column:batal_at"`
+	BatalOleh       sql.NullInt64  `json:"batal_oleh" gorm:"column:batal_oleh"`
+	TidakHadirAt    sql.NullTime   `json:"tidak_hadir_at" gorm:"column:tidak_hadir_at"`
+	TidakHadirOleh  sql.NullInt64  `json:"tidak_hadir_oleh" gorm:"column:tidak_hadir_oleh"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index;column:deleted_at"`
+	CreatedAt       time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt       time.Time      `json:"updated_at" gorm:"column:updated_at"`
 
 	Jadwal Jadwal `json:"jadwal" gorm:"foreignKey:JadwalID"`
 	Pasien Pasien `json:"pasien" gorm:"foreignKey:PasienID"`
@@ -23,39 +39,105 @@ type Antrian struct {
 
 func (Antrian) TableName() string { return "antrian" }
 
+const (
+	StatusAntrianMenunggu    = "Menunggu"
+	StatusAntrianDipanggil   = "Dipanggil"
+	StatusAntrianDiperiksa   = "Diperiksa"
+	StatusAntrianMenungguLab = "Menunggu Lab"
+	StatusAntrianSelesai     = "Selesai"
+	StatusAntrianBatal       = "Batal"
+	StatusAntrianTidakHadir  = "Tidak Hadir"
+)
+
+// status yang masih dianggap aktif untuk cek duplikasi dan jadwal bentrok
+var ActiveAntrianStatuses = []string{
+	StatusAntrianMenunggu,
+	StatusAntrianDipanggil,
+	StatusAntrianDiperiksa,
+	StatusAntrianMenungguLab,
+}
+
+// transisi status yang diizinkan, status tanpa entri adalah status akhir
+var antrianTransitions = map[string][]string{
+	StatusAntrianMenunggu:    {StatusAntrianDipanggil, StatusAntrianBatal, StatusAntrianTidakHadir},
+	StatusAntrianDipanggil:   {StatusAntrianDiperiksa, StatusAntrianBatal, StatusAntrianTidakHadir},
+	StatusAntrianDiperiksa:   {StatusAntrianMenungguLab, StatusAntrianSelesai},
+	StatusAntrianMenungguLab: {StatusAntrianSelesai},
+}
+
+func CanTransitionAntrian(from, to string) bool {
+	for _, next := range antrianTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// kolom waktu dan petugas yang diisi saat antrian masuk ke status tersebut
+func AntrianTransitionColumns(status string) (atColumn, byColumn string, ok bool) {
+	switch status {
+	case StatusAntrianDipanggil:
+		return "dipanggil_at", "dipanggil_oleh", true
+	case StatusAntrianDiperiksa:
+		return "diperiksa_at", "diperiksa_oleh", true
+	case StatusAntrianMenungguLab:
+		return "menunggu_lab_at", "menunggu_lab_oleh", true
+	case StatusAntrianSelesai:
+		return "selesai_at", "selesai_oleh", true
+	case StatusAntrianBatal:
+		return "batal_at", "batal_oleh", true
+	case StatusAntrianTidakHadir:
+		return "tidak_hadir_at", "tidak_hadir_oleh", true
+	}
+	return "", "", false
+}
+
+// nomor terakhir yang sudah dibagikan per jadwal, baris ini dikunci saat alokasi nomor baru
+type AntrianSequence struct {
+	JadwalID   int       `gorm:"primaryKey;autoIncrement:false;column:id_jadwal"`
+	LastNumber int       `gorm:"column:last_number;not null"`
+	UpdatedAt  time.Time `gorm:"column:updated_at"`
+}
+
+func (AntrianSequence) TableName() string { return "antrian_sequence" }
+
+func FormatNomorAntrian(prefix string, number int) string {
+	return fmt.Sprintf("%s-%03d", prefix, number)
+}
+
 type CreateAntrianRequest struct {
 	JadwalID  int    `json:"jadwal_id" binding:"required,gt=0"`
 	PasienID  int    `json:"pasien_id" binding:"required,gt=0"`
 	Prioritas string `json:"prioritas" binding:"required,oneof=Gawat 'Non Gawat'"`
 }
 
-func (req *CreateAntrianRequest) ToModel(nomorAntrian string) Antrian {
+func (req *CreateAntrianRequest) ToModel() Antrian {
 	return Antrian{
-		JadwalID:     req.JadwalID,
-		PasienID:     req.PasienID,
-		Prioritas:    req.Prioritas,
-		Status:       "Menunggu",
-		NomorAntrian: nomorAntrian,
+		JadwalID:  req.JadwalID,
+		PasienID:  req.PasienID,
+		Prioritas: req.Prioritas,
+		Status:    StatusAntrianMenunggu,
 	}
 }
 
+// status tidak lagi bisa diubah di sini, gunakan endpoint transisi
 type UpdateAntrianRequest struct {
 	Prioritas string `json:"prioritas" binding:"required,oneof=Gawat 'Non Gawat'"`
-	Status    string `json:"status" binding:"required,oneof=Menunggu 'Menunggu Diagnosis' Selesai"`
 }
 
 func (req *UpdateAntrianRequest) ToModel() Antrian {
 	return Antrian{
 		Prioritas: req.Prioritas,
-		Status:    req.Status,
 	}
 }
 
 type AntrianResponse struct {
-	ID           int    `json:"id"`
-	NomorAntrian string `json:"nomor_antrian"`
-	Prioritas    string `json:"prioritas"`
-	Status       string `json:"status"`
+	ID           int                         `json:"id"`
+	NomorAntrian string                      `json:"nomor_antrian"`
+	Prioritas    string                      `json:"prioritas"`
+	Status       string                      `json:"status"`
+	Riwayat      []AntrianTransitionResponse `json:"riwayat_status"`
 	Jadwal       struct {
 		ID      int    `json:"id"`
 		Tanggal string `json:"tanggal"`
@@ -72,12 +154,41 @@ type AntrianResponse struct {
 	} `json:"pasien"`
 }
 
+type AntrianTransitionResponse struct {
+	Status    string    `json:"status"`
+	Waktu     time.Time `json:"waktu"`
+	PetugasID *int64    `json:"petugas_id,omitempty"`
+}
+
+func toAntrianRiwayat(a Antrian) []AntrianTransitionResponse {
+	riwayat := []AntrianTransitionResponse{}
+	add := func(status string, at sql.NullTime, by sql.NullInt64) {
+		if !at.Valid {
+			return
+		}
+		var petugasID *int64
+		if by.Valid {
+			petugasID = &by.Int64
+		}
+		riwayat = append(riwayat, AntrianTransitionResponse{Status: status, Waktu: at.Time, PetugasID: petugasID})
+	}
+
+	add(StatusAntrianDipanggil, a.DipanggilAt, a.DipanggilOleh)
+	add(StatusAntrianDiperiksa, a.DiperiksaAt, a.DiperiksaOleh)
+	add(StatusAntrianMenungguLab, a.MenungguLabAt, a.MenungguLabOleh)
+	add(StatusAntrianSelesai, a.SelesaiAt, a.SelesaiOleh)
+	add(StatusAntrianBatal, a.BatalAt, a.BatalOleh)
+	add(StatusAntrianTidakHadir, a.TidakHadirAt, a.TidakHadirOleh)
+	return riwayat
+}
+
 func ToAntrianResponse(a Antrian) AntrianResponse {
 	return AntrianResponse{
 		ID:           a.ID,
 		NomorAntrian: a.NomorAntrian,
 		Prioritas:    a.Prioritas,
 		Status:       a.Status,
+		Riwayat:      toAntrianRiwayat(a),
 		Jadwal: struct {
 			ID      int    `json:"id"`
 			Tanggal string `json:"tanggal"`
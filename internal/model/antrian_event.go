@@ -0,0 +1,80 @@
+package model
+
+import "time"
+
+const (
+	AntrianEventCreated       = "created"
+	AntrianEventCalled        = "called"
+	AntrianEventStatusChanged = "status_changed"
+	AntrianEventUpdated       = "updated"
+	AntrianEventDeleted       = "deleted"
+)
+
+type AntrianEvent struct {
+	Type         string    `json:"type"`
+	AntrianID    int       `json:"antrian_id"`
+	JadwalID     int       `json:"jadwal_id"`
+	PoliID       int       `json:"poli_id"`
+	NomorAntrian string    `json:"nomor_antrian"`
+	Prioritas    string    `json:"prioritas"`
+	Status       string    `json:"status"`
+	PasienID     int       `json:"pasien_id"`
+	NamaPasien   string    `json:"nama_pasien"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// filter subscriber, nilai 0 berarti tidak difilter
+type AntrianEventFilter struct {
+	JadwalID int
+	PoliID   int
+}
+
+func (f AntrianEventFilter) Match(event AntrianEvent) bool {
+	if f.JadwalID > 0 && f.JadwalID != event.JadwalID {
+		return false
+	}
+	if f.PoliID > 0 && f.PoliID != event.PoliID {
+		return false
+	}
+	return true
+}
+
+// event untuk layar ruang tunggu, tanpa identitas pasien
+type AntrianDisplayEvent struct {
+	Type         string    `json:"type"`
+	NomorAntrian string    `json:"nomor_antrian"`
+	Status       string    `json:"status"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+func NewAntrianEvent(eventType string, a Antrian) AntrianEvent {
+	return AntrianEvent{
+		Type:         eventType,
+		AntrianID:    a.ID,
+		JadwalID:     a.JadwalID,
+		PoliID:       a.Jadwal.PoliID,
+		NomorAntrian: a.NomorAntrian,
+		Prioritas:    a.Prioritas,
+		Status:       a.Status,
+		PasienID:     a.PasienID,
+		NamaPasien:   a.Pasien.NamaPasien,
+		Timestamp:    time.Now(),
+	}
+}
+
+func (e AntrianEvent) ToDisplay() AntrianDisplayEvent {
+	return AntrianDisplayEvent{
+		Type:         e.Type,
+		NomorAntrian: e.NomorAntrian,
+		Status:       e.Status,
+		Timestamp:    e.Timestamp,
+	}
+}
+
+func ToAntrianDisplayList(antrians []Antrian) []AntrianDisplayEvent {
+	list := []AntrianDisplayEvent{}
+	for _, a := range antrians {
+		list = append(list, AntrianDisplayEvent{NomorAntrian: a.NomorAntrian, Status: a.Status, Timestamp: a.UpdatedAt})
+	}
+	return list
+}
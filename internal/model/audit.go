@@ -0,0 +1,88 @@
+package model
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditActionRead   = "read"
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	AuditActionVoid   = "void"
+)
+
+const (
+	AuditEntityPasien         = "pasien"
+	AuditEntityPemeriksaan    = "pemeriksaan"
+	AuditEntityPemeriksaanLab = "pemeriksaan_lab"
+	AuditEntityResep          = "resep"
+	AuditEntityAlergiPasien   = "alergi_pasien"
+	AuditEntityOrderLab       = "order_lab"
+)
+
+type AuditLog struct {
+	ID            int            `gorm:"primaryKey;column:id_audit_log"`
+	ActorID       sql.NullInt64  `gorm:"column:actor_id;index"`
+	ActorUsername string         `gorm:"column:actor_username"`
+	ActorRole     string         `gorm:"column:actor_role"`
+	Action        string         `gorm:"column:action;index"`
+	Entity        string         `gorm:"column:entity;index:idx_audit_entity"`
+	EntityID      string         `gorm:"column:entity_id;index:idx_audit_entity"`
+	Changes       sql.NullString `gorm:"column:changes;type:jsonb"`
+	Path          string         `gorm:"column:path"`
+	IPAddress     string         `gorm:"column:ip_address"`
+	CreatedAt     time.Time      `gorm:"column:created_at;index"`
+}
+
+func (AuditLog) TableName() string { return "audit_log" }
+
+type AuditLogResponse struct {
+	ID            int       `json:"id"`
+	ActorID       *int64    `json:"actor_id"`
+	ActorUsername string    `json:"actor_username"`
+	ActorRole     string    `json:"actor_role"`
+	Action        string    `json:"action"`
+	Entity        string    `json:"entity"`
+	EntityID      string    `json:"entity_id"`
+	Changes       any       `json:"changes,omitempty"`
+	Path          string    `json:"path,omitempty"`
+	IPAddress     string    `json:"ip_address"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func ToAuditLogResponse(a AuditLog) AuditLogResponse {
+	var actorID *int64
+	if a.ActorID.Valid {
+		actorID = &a.ActorID.Int64
+	}
+
+	var changes any
+	if a.Changes.Valid {
+		changes = json.RawMessage(a.Changes.String)
+	}
+
+	return AuditLogResponse{
+		ID:            a.ID,
+		ActorID:       actorID,
+		ActorUsername: a.ActorUsername,
+		ActorRole:     a.ActorRole,
+		Action:        a.Action,
+		Entity:        a.Entity,
+		EntityID:      a.EntityID,
+		Changes:       changes,
+		Path:          a.Path,
+		IPAddress:     a.IPAddress,
+		CreatedAt:     a.CreatedAt,
+	}
+}
+
+func ToAuditLogResponseList(logs []AuditLog) []AuditLogResponse {
+	responses := []AuditLogResponse{}
+	for _, l := range logs {
+		responses = append(responses, ToAuditLogResponse(l))
+	}
+	return responses
+}
@@ -13,9 +13,18 @@ type Icd struct {
 	NamaPenyakit string         `json:"nama_penyakit" gorm:"column:nama_penyakit"`
 	Deskripsi    sql.NullString `json:"deskripsi,omitempty" gorm:"column:deskripsi_penyakit"`
 	Status       string         `json:"status" gorm:"column:status_icd"`
+	Bab          sql.NullString `json:"bab,omitempty" gorm:"column:bab;index"`
+	Blok         sql.NullString `json:"blok,omitempty" gorm:"column:blok;index"`
+	KodeInduk    sql.NullString `json:"kode_induk,omitempty" gorm:"column:kode_induk;index"`
 	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index;column:deleted_at"`
 	CreatedAt    time.Time      `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt    time.Time      `json:"updated_at" gorm:"column:updated_at"`
+
+	// relasi lewat kode, tanpa foreign key constraint karena urutan impor katalog tidak dijamin
+	KelompokBab  *IcdKelompok `json:"-" gorm:"foreignKey:Bab;references:Kode;constraint:-"`
+	KelompokBlok *IcdKelompok `json:"-" gorm:"foreignKey:Blok;references:Kode;constraint:-"`
+	Induk        *Icd         `json:"-" gorm:"foreignKey:KodeInduk;references:KodeIcd;constraint:-"`
+	Turunan      []Icd        `json:"-" gorm:"foreignKey:KodeInduk;references:KodeIcd;constraint:-"`
 }
 
 func (Icd) TableName() string {
@@ -27,6 +36,9 @@ type CreateIcdRequest struct {
 	NamaPenyakit string `json:"nama_penyakit" binding:"required,min=3,sanitize"`
 	Deskripsi    string `json:"deskripsi,omitempty" binding:"sanitize"`
 	Status       string `json:"status" binding:"required,oneof=aktif nonaktif"`
+	Bab          string `json:"bab,omitempty" binding:"omitempty,max=10,sanitize"`
+	Blok         string `json:"blok,omitempty" binding:"omitempty,max=20,sanitize"`
+	KodeInduk    string `json:"kode_induk,omitempty" binding:"omitempty,sanitize"`
 }
 
 func (req *CreateIcdRequest) ToModel() Icd {
@@ -35,6 +47,9 @@ func (req *CreateIcdRequest) ToModel() Icd {
 		NamaPenyakit: req.NamaPenyakit,
 		Deskripsi:    sql.NullString{String: req.Deskripsi, Valid: req.Deskripsi != ""},
 		Status:       req.Status,
+		Bab:          sql.NullString{String: req.Bab, Valid: req.Bab != ""},
+		Blok:         sql.NullString{String: req.Blok, Valid: req.Blok != ""},
+		KodeInduk:    sql.NullString{String: req.KodeInduk, Valid: req.KodeInduk != ""},
 	}
 }
 
@@ -43,6 +58,9 @@ type UpdateIcdRequest struct {
 	NamaPenyakit string `json:"nama_penyakit" binding:"required,min=3,sanitize"`
 	Deskripsi    string `json:"deskripsi,omitempty" binding:"sanitize"`
 	Status       string `json:"status" binding:"required,oneof=aktif nonaktif"`
+	Bab          string `json:"bab,omitempty" binding:"omitempty,max=10,sanitize"`
+	Blok         string `json:"blok,omitempty" binding:"omitempty,max=20,sanitize"`
+	KodeInduk    string `json:"kode_induk,omitempty" binding:"omitempty,sanitize"`
 }
 
 func (req *UpdateIcdRequest) ToModel() Icd {
@@ -51,6 +69,9 @@ func (req *UpdateIcdRequest) ToModel() Icd {
 		NamaPenyakit: req.NamaPenyakit,
 		Deskripsi:    sql.NullString{String: req.Deskripsi, Valid: req.Deskripsi != ""},
 		Status:       req.Status,
+		Bab:          sql.NullString{String: req.Bab, Valid: req.Bab != ""},
+		Blok:         sql.NullString{String: req.Blok, Valid: req.Blok != ""},
+		KodeInduk:    sql.NullString{String: req.KodeInduk, Valid: req.KodeInduk != ""},
 	}
 }
 
@@ -60,6 +81,51 @@ type IcdResponse struct {
 	NamaPenyakit string `json:"nama_penyakit"`
 	Deskripsi    string `json:"deskripsi,omitempty"`
 	Status       string `json:"status"`
+	Bab          string `json:"bab,omitempty"`
+	Blok         string `json:"blok,omitempty"`
+	KodeInduk    string `json:"kode_induk,omitempty"`
+
+	Hierarki *IcdHierarkiResponse `json:"hierarki,omitempty"`
+}
+
+type IcdRingkasResponse struct {
+	ID           int    `json:"id"`
+	KodeIcd      string `json:"kode_icd"`
+	NamaPenyakit string `json:"nama_penyakit"`
+}
+
+// hanya diisi pada detail ICD, relasinya dimuat lewat preload
+type IcdHierarkiResponse struct {
+	Bab     *IcdKelompokResponse `json:"bab,omitempty"`
+	Blok    *IcdKelompokResponse `json:"blok,omitempty"`
+	Induk   *IcdRingkasResponse  `json:"induk,omitempty"`
+	Turunan []IcdRingkasResponse `json:"turunan"`
+}
+
+func ToIcdRingkasResponse(icd Icd) IcdRingkasResponse {
+	return IcdRingkasResponse{ID: icd.ID, KodeIcd: icd.KodeIcd, NamaPenyakit: icd.NamaPenyakit}
+}
+
+func ToIcdDetailResponse(icd Icd) IcdResponse {
+	resp := ToIcdResponse(icd)
+	hierarki := &IcdHierarkiResponse{Turunan: make([]IcdRingkasResponse, 0, len(icd.Turunan))}
+	if icd.KelompokBab != nil {
+		bab := ToIcdKelompokResponse(*icd.KelompokBab)
+		hierarki.Bab = &bab
+	}
+	if icd.KelompokBlok != nil {
+		blok := ToIcdKelompokResponse(*icd.KelompokBlok)
+		hierarki.Blok = &blok
+	}
+	if icd.Induk != nil {
+		induk := ToIcdRingkasResponse(*icd.Induk)
+		hierarki.Induk = &induk
+	}
+	for _, turunan := range icd.Turunan {
+		hierarki.Turunan = append(hierarki.Turunan, ToIcdRingkasResponse(turunan))
+	}
+	resp.Hierarki = hierarki
+	return resp
 }
 
 func ToIcdResponse(icd Icd) IcdResponse {
@@ -69,6 +135,9 @@ func ToIcdResponse(icd Icd) IcdResponse {
 		NamaPenyakit: icd.NamaPenyakit,
 		Deskripsi:    icd.Deskripsi.String,
 		Status:       icd.Status,
+		Bab:          icd.Bab.String,
+		Blok:         icd.Blok.String,
+		KodeInduk:    icd.KodeInduk.String,
 	}
 }
 
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	JenisKelompokBab  = "bab"
+	JenisKelompokBlok = "blok"
+)
+
+// bab dan blok katalog ICD (misal bab "X" dan blok "J00-J06"). Kode ICD merujuk ke sini
+// lewat kolom bab dan blok, blok merujuk ke babnya lewat kode_induk
+type IcdKelompok struct {
+	ID        int            `gorm:"primaryKey;column:id_icd_kelompok"`
+	Kode      string         `gorm:"column:kode;unique"`
+	Nama      string         `gorm:"column:nama"`
+	Jenis     string         `gorm:"column:jenis;index"`
+	KodeInduk sql.NullString `gorm:"column:kode_induk;index"`
+	CreatedAt time.Time      `gorm:"column:created_at"`
+	UpdatedAt time.Time      `gorm:"column:updated_at"`
+}
+
+func (IcdKelompok) TableName() string { return "icd_kelompok" }
+
+type IcdKelompokResponse struct {
+	Kode      string `json:"kode"`
+	Nama      string `json:"nama"`
+	Jenis     string `json:"jenis"`
+	KodeInduk string `json:"kode_induk,omitempty"`
+}
+
+func ToIcdKelompokResponse(k IcdKelompok) IcdKelompokResponse {
+	return IcdKelompokResponse{Kode: k.Kode, Nama: k.Nama, Jenis: k.Jenis, KodeInduk: k.KodeInduk.String}
+}
+
+func ToIcdKelompokResponseList(list []IcdKelompok) []IcdKelompokResponse {
+	responses := make([]IcdKelompokResponse, 0, len(list))
+	for _, k := range list {
+		responses = append(responses, ToIcdKelompokResponse(k))
+	}
+	return responses
+}
+
+type ParamsGetAllIcdKelompok struct {
+	Jenis     string `form:"jenis" binding:"omitempty,oneof=bab blok"`
+	KodeInduk string `form:"induk" binding:"omitempty,sanitize"`
+}
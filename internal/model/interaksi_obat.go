@@ -0,0 +1,41 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	TingkatInteraksiMinor   = "minor"
+	TingkatInteraksiModerat = "moderat"
+	TingkatInteraksiMayor   = "mayor"
+)
+
+// pasangan zat aktif yang diketahui berinteraksi, diisi dari tabel interaksi lokal lewat
+// perintah import-interaksi. ZatA dan ZatB disimpan huruf kecil dengan ZatA < ZatB supaya
+// satu pasangan hanya punya satu baris
+type InteraksiObat struct {
+	ID        int       `gorm:"primaryKey;column:id_interaksi"`
+	ZatA      string    `gorm:"column:zat_a;uniqueIndex:idx_interaksi_obat_zat"`
+	ZatB      string    `gorm:"column:zat_b;uniqueIndex:idx_interaksi_obat_zat"`
+	Tingkat   string    `gorm:"column:tingkat"`
+	Deskripsi string    `gorm:"column:deskripsi"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+func (InteraksiObat) TableName() string { return "interaksi_obat" }
+
+// nama zat dinormalisasi ke huruf kecil tanpa spasi berlebih, pasangan diurutkan
+func PasanganZat(a, b string) (string, string) {
+	a = NormalisasiZat(a)
+	b = NormalisasiZat(b)
+	if b < a {
+		return b, a
+	}
+	return a, b
+}
+
+func NormalisasiZat(zat string) string {
+	return strings.ToLower(strings.Join(strings.Fields(zat), " "))
+}
@@ -7,15 +7,22 @@ import (
 	"gorm.io/gorm"
 )
 
+// nilai_rujukan dan kriteria tetap teks bebas untuk ditampilkan. Penandaan hasil abnormal
+// memakai tipe_hasil, rentang rujukan numerik, dan pilihan/nilai normal untuk hasil kategori
 type JenisPemeriksaanLab struct {
 	ID              int            `json:"id,omitempty" gorm:"primaryKey;column:id_jenis_pemeriksaan"`
 	NamaPemeriksaan string         `json:"nama_pemeriksaan" gorm:"column:nama_pemeriksaan;unique"`
 	Satuan          sql.NullString `json:"satuan" gorm:"column:satuan"`
 	NilaiRujukan    sql.NullString `json:"nilai_rujukan" gorm:"column:nilai_rujukan"`
 	Kriteria        sql.NullString `json:"kriteria" gorm:"column:kriteria"`
+	TipeHasil       string         `json:"tipe_hasil" gorm:"column:tipe_hasil;default:teks"`
+	PilihanHasil    sql.NullString `json:"pilihan_hasil" gorm:"column:pilihan_hasil"`
+	NilaiNormal     sql.NullString `json:"nilai_normal" gorm:"column:nilai_normal"`
 	CreatedAt       time.Time      `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt       time.Time      `json:"updated_at" gorm:"column:updated_at"`
 	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index;column:deleted_at"`
+
+	Rujukan []RentangRujukanLab `json:"rujukan" gorm:"foreignKey:JenisPemeriksaanID"`
 }
 
 func (JenisPemeriksaanLab) TableName() string {
@@ -23,10 +30,14 @@ func (JenisPemeriksaanLab) TableName() string {
 }
 
 type CreateJenisPemeriksaanLabRequest struct {
-	NamaPemeriksaan string `json:"nama_pemeriksaan" binding:"required,min=3,sanitize"`
-	Satuan          string `json:"satuan,omitempty" binding:"sanitize"`
-	NilaiRujukan    string `json:"nilai_rujukan,omitempty" binding:"sanitize"`
-	Kriteria        string `json:"kriteria,omitempty" binding:"sanitize"`
+	NamaPemeriksaan string                     `json:"nama_pemeriksaan" binding:"required,min=3,sanitize"`
+	Satuan          string                     `json:"satuan,omitempty" binding:"sanitize"`
+	NilaiRujukan    string                     `json:"nilai_rujukan,omitempty" binding:"sanitize"`
+	Kriteria        string                     `json:"kriteria,omitempty" binding:"sanitize"`
+	TipeHasil       string                     `json:"tipe_hasil,omitempty" binding:"omitempty,oneof=numerik kategori teks"`
+	PilihanHasil    []string                   `json:"pilihan_hasil,omitempty" binding:"omitempty,max=30,dive,required,max=50,excludesall=0x2C,sanitize"`
+	NilaiNormal     []string                   `json:"nilai_normal,omitempty" binding:"omitempty,max=30,dive,required,max=50,excludesall=0x2C,sanitize"`
+	Rujukan         []RentangRujukanLabRequest `json:"rujukan,omitempty" binding:"omitempty,max=20,dive"`
 }
 
 func (req *CreateJenisPemeriksaanLabRequest) ToModel() JenisPemeriksaanLab {
@@ -35,41 +46,86 @@ func (req *CreateJenisPemeriksaanLabRequest) ToModel() JenisPemeriksaanLab {
 		Satuan:          sql.NullString{String: req.Satuan, Valid: req.Satuan != ""},
 		NilaiRujukan:    sql.NullString{String: req.NilaiRujukan, Valid: req.NilaiRujukan != ""},
 		Kriteria:        sql.NullString{String: req.Kriteria, Valid: req.Kriteria != ""},
+		TipeHasil:       tipeHasilOrDefault(req.TipeHasil),
+		PilihanHasil:    joinDaftar(req.PilihanHasil),
+		NilaiNormal:     joinDaftar(req.NilaiNormal),
+		Rujukan:         toRentangRujukanList(req.Rujukan),
 	}
 }
 
 type UpdateJenisPemeriksaanLabRequest struct {
-	NamaPemeriksaan string `json:"nama_pemeriksaan" binding:"required,min=3,sanitize"`
-	Satuan          string `json:"satuan,omitempty" binding:"sanitize"`
-	NilaiRujukan    string `json:"nilai_rujukan,omitempty" binding:"sanitize"`
-	Kriteria        string `json:"kriteria,omitempty" binding:"sanitize"`
+	NamaPemeriksaan string                     `json:"nama_pemeriksaan" binding:"required,min=3,sanitize"`
+	Satuan          string                     `json:"satuan,omitempty" binding:"sanitize"`
+	NilaiRujukan    string                     `json:"nilai_rujukan,omitempty" binding:"sanitize"`
+	Kriteria        string                     `json:"kriteria,omitempty" binding:"sanitize"`
+	TipeHasil       string                     `json:"tipe_hasil,omitempty" binding:"omitempty,oneof=numerik kategori teks"`
+	PilihanHasil    []string                   `json:"pilihan_hasil,omitempty" binding:"omitempty,max=30,dive,required,max=50,excludesall=0x2C,sanitize"`
+	NilaiNormal     []string                   `json:"nilai_normal,omitempty" binding:"omitempty,max=30,dive,required,max=50,excludesall=0x2C,sanitize"`
+	Rujukan         []RentangRujukanLabRequest `json:"rujukan,omitempty" binding:"omitempty,max=20,dive"`
 }
 
-func (req *UpdateJenisPemeriksaanLabRequest) ToModel() JenisPemeriksaanLab {
-	return JenisPemeriksaanLab{
+// tipe_hasil, pilihan_hasil, nilai_normal, dan rujukan yang tidak dikirim tetap memakai nilai
+// current, supaya client lama yang hanya mengirim nama dan satuan tidak menghapus rentang rujukan.
+// Rujukan nil berarti rentang yang tersimpan tidak diganti, array kosong menghapus semuanya
+func (req *UpdateJenisPemeriksaanLabRequest) ToModel(current JenisPemeriksaanLab) JenisPemeriksaanLab {
+	jenis := JenisPemeriksaanLab{
 		NamaPemeriksaan: req.NamaPemeriksaan,
 		Satuan:          sql.NullString{String: req.Satuan, Valid: req.Satuan != ""},
 		NilaiRujukan:    sql.NullString{String: req.NilaiRujukan, Valid: req.NilaiRujukan != ""},
 		Kriteria:        sql.NullString{String: req.Kriteria, Valid: req.Kriteria != ""},
+		TipeHasil:       req.TipeHasil,
+		PilihanHasil:    current.PilihanHasil,
+		NilaiNormal:     current.NilaiNormal,
+	}
+	if jenis.TipeHasil == "" {
+		jenis.TipeHasil = tipeHasilOrDefault(current.TipeHasil)
+	}
+	if req.PilihanHasil != nil {
+		jenis.PilihanHasil = joinDaftar(req.PilihanHasil)
+	}
+	if req.NilaiNormal != nil {
+		jenis.NilaiNormal = joinDaftar(req.NilaiNormal)
 	}
+	if req.Rujukan != nil {
+		jenis.Rujukan = toRentangRujukanList(req.Rujukan)
+	}
+	return jenis
+}
+
+func tipeHasilOrDefault(tipe string) string {
+	if tipe == "" {
+		return TipeHasilTeks
+	}
+	return tipe
 }
 
 type JenisPemeriksaanLabResponse struct {
-	ID              int    `json:"id"`
-	NamaPemeriksaan string `json:"nama_pemeriksaan"`
-	Satuan          string `json:"satuan,omitempty"`
-	NilaiRujukan    string `json:"nilai_rujukan,omitempty"`
-	Kriteria        string `json:"kriteria,omitempty"`
+	ID              int                         `json:"id"`
+	NamaPemeriksaan string                      `json:"nama_pemeriksaan"`
+	Satuan          string                      `json:"satuan,omitempty"`
+	NilaiRujukan    string                      `json:"nilai_rujukan,omitempty"`
+	Kriteria        string                      `json:"kriteria,omitempty"`
+	TipeHasil       string                      `json:"tipe_hasil"`
+	PilihanHasil    []string                    `json:"pilihan_hasil,omitempty"`
+	NilaiNormal     []string                    `json:"nilai_normal,omitempty"`
+	Rujukan         []RentangRujukanLabResponse `json:"rujukan,omitempty"`
 }
 
 func ToJenisPemeriksaanLabResponse(jpl JenisPemeriksaanLab) JenisPemeriksaanLabResponse {
-	return JenisPemeriksaanLabResponse{
+	resp := JenisPemeriksaanLabResponse{
 		ID:              jpl.ID,
 		NamaPemeriksaan: jpl.NamaPemeriksaan,
 		Satuan:          jpl.Satuan.String,
 		NilaiRujukan:    jpl.NilaiRujukan.String,
 		Kriteria:        jpl.Kriteria.String,
+		TipeHasil:       tipeHasilOrDefault(jpl.TipeHasil),
+		PilihanHasil:    jpl.DaftarPilihan(),
+		NilaiNormal:     jpl.DaftarNilaiNormal(),
+	}
+	for _, r := range jpl.Rujukan {
+		resp.Rujukan = append(resp.Rujukan, ToRentangRujukanLabResponse(r))
 	}
+	return resp
 }
 
 func ToJenisPemeriksaanLabResponseList(list []JenisPemeriksaanLab) []JenisPemeriksaanLabResponse {
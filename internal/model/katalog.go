@@ -0,0 +1,32 @@
+package model
+
+type ParamsImportKatalog struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv claml"`
+	DryRun bool   `form:"dry_run"`
+}
+
+type ImportError struct {
+	Baris int    `json:"baris,omitempty"`
+	Kode  string `json:"kode,omitempty"`
+	Pesan string `json:"pesan"`
+}
+
+// ringkasan impor katalog ICD-10 atau ICD-9-CM. Unchanged berarti kode sudah ada dengan isi yang sama,
+// Skipped berarti baris tidak valid dan alasannya ada di Errors. Kelompok adalah jumlah
+// bab dan blok yang ikut disimpan
+type ImportReport struct {
+	DryRun    bool          `json:"dry_run"`
+	Total     int           `json:"total"`
+	Inserted  int           `json:"inserted"`
+	Updated   int           `json:"updated"`
+	Kelompok  int           `json:"kelompok"`
+	Unchanged int           `json:"unchanged"`
+	Skipped   int           `json:"skipped"`
+	Errors    []ImportError `json:"errors"`
+}
+
+// q dicocokkan ke awalan kode lalu ke nama, untuk typeahead pemilih diagnosis dan prosedur
+type ParamsSearchKatalog struct {
+	Query string `form:"q" binding:"required,min=1,max=100"`
+	Limit int    `form:"limit" binding:"omitempty,gt=0,max=50"`
+}
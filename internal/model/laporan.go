@@ -10,3 +10,10 @@ type LaporanPenyakitTeratas struct {
 	NamaPenyakit string `json:"nama_penyakit"`
 	JumlahKasus  int    `json:"jumlah_kasus"`
 }
+
+type LaporanTindakanTeratas struct {
+	KodeProsedur      string `json:"kode_prosedur"`
+	NamaProsedur      string `json:"nama_prosedur"`
+	JumlahTindakan    int    `json:"jumlah_tindakan"`
+	JumlahPemeriksaan int    `json:"jumlah_pemeriksaan"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// isi laporan hasil lab yang dicetak untuk pasien. Hasil sudah dikelompokkan per panel
+type LaporanHasilLab struct {
+	PemeriksaanID      int
+	TanggalPemeriksaan time.Time
+	Pasien             Pasien
+	DokterPengirim     []string
+	Kelompok           []HasilLabPanelResponse
+	Validasi           []ValidasiHasilLab
+	DicetakAt          time.Time
+}
+
+// petugas lab yang memvalidasi order asal hasil yang dicetak
+type ValidasiHasilLab struct {
+	Nama  string
+	Waktu time.Time
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// master obat yang bisa diresepkan. Kekuatan berisi kadar per satuan ("500 mg", "125 mg/5 ml"),
+// satuan dipakai untuk jumlah yang diserahkan apotek dan jumlah stok. Stok yang turun sampai StokMinimum
+// muncul di peringatan stok menipis, 0 berarti tanpa batas minimum. ZatAktif berisi nama generik
+// dipisah koma untuk obat kombinasi, dipakai untuk cek alergi dan interaksi obat
+type Obat struct {
+	ID            int            `json:"id,omitempty" gorm:"primaryKey;column:id_obat"`
+	KodeObat      string         `json:"kode_obat" gorm:"column:kode_obat;unique"`
+	NamaObat      string         `json:"nama_obat" gorm:"column:nama_obat"`
+	BentukSediaan string         `json:"bentuk_sediaan" gorm:"column:bentuk_sediaan"`
+	Kekuatan      sql.NullString `json:"kekuatan,omitempty" gorm:"column:kekuatan"`
+	ZatAktif      sql.NullString `json:"zat_aktif,omitempty" gorm:"column:zat_aktif"`
+	Satuan        string         `json:"satuan" gorm:"column:satuan"`
+	StokMinimum   int            `json:"stok_minimum" gorm:"column:stok_minimum;default:0"`
+	Status        string         `json:"status" gorm:"column:status_obat"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index;column:deleted_at"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt     time.Time      `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (Obat) TableName() string {
+	return "obat"
+}
+
+// zat aktif obat dalam huruf kecil. Obat tanpa zat aktif memakai nama obatnya
+func (o Obat) DaftarZat() []string {
+	if !o.ZatAktif.Valid || strings.TrimSpace(o.ZatAktif.String) == "" {
+		return []string{NormalisasiZat(o.NamaObat)}
+	}
+	var list []string
+	for _, zat := range strings.Split(o.ZatAktif.String, ",") {
+		if zat = NormalisasiZat(zat); zat != "" {
+			list = append(list, zat)
+		}
+	}
+	return list
+}
+
+type CreateObatRequest struct {
+	KodeObat      string `json:"kode_obat" binding:"required,max=20,sanitize"`
+	NamaObat      string `json:"nama_obat" binding:"required,min=3,max=100,sanitize"`
+	BentukSediaan string `json:"bentuk_sediaan" binding:"required,oneof=tablet kapsul sirup injeksi salep tetes inhaler supositoria puyer lainnya"`
+	Kekuatan      string `json:"kekuatan,omitempty" binding:"omitempty,max=50,sanitize"`
+	ZatAktif      string `json:"zat_aktif,omitempty" binding:"omitempty,max=255,sanitize"`
+	Satuan        string `json:"satuan" binding:"required,max=20,sanitize"`
+	StokMinimum   int    `json:"stok_minimum,omitempty" binding:"omitempty,min=0,max=100000"`
+	Status        string `json:"status" binding:"required,oneof=aktif nonaktif"`
+}
+
+func (req *CreateObatRequest) ToModel() Obat {
+	return Obat{
+		KodeObat:      req.KodeObat,
+		NamaObat:      req.NamaObat,
+		BentukSediaan: req.BentukSediaan,
+		Kekuatan:      sql.NullString{String: req.Kekuatan, Valid: req.Kekuatan != ""},
+		ZatAktif:      sql.NullString{String: req.ZatAktif, Valid: req.ZatAktif != ""},
+		Satuan:        req.Satuan,
+		StokMinimum:   req.StokMinimum,
+		Status:        req.Status,
+	}
+}
+
+type UpdateObatRequest struct {
+	KodeObat      string `json:"kode_obat" binding:"required,max=20,sanitize"`
+	NamaObat      string `json:"nama_obat" binding:"required,min=3,max=100,sanitize"`
+	BentukSediaan string `json:"bentuk_sediaan" binding:"required,oneof=tablet kapsul sirup injeksi salep tetes inhaler supositoria puyer lainnya"`
+	Kekuatan      string `json:"kekuatan,omitempty" binding:"omitempty,max=50,sanitize"`
+	ZatAktif      string `json:"zat_aktif,omitempty" binding:"omitempty,max=255,sanitize"`
+	Satuan        string `json:"satuan" binding:"required,max=20,sanitize"`
+	StokMinimum   int    `json:"stok_minimum,omitempty" binding:"omitempty,min=0,max=100000"`
+	Status        string `json:"status" binding:"required,oneof=aktif nonaktif"`
+}
+
+func (req *UpdateObatRequest) ToModel() Obat {
+	return Obat{
+		KodeObat:      req.KodeObat,
+		NamaObat:      req.NamaObat,
+		BentukSediaan: req.BentukSediaan,
+		Kekuatan:      sql.NullString{String: req.Kekuatan, Valid: req.Kekuatan != ""},
+		ZatAktif:      sql.NullString{String: req.ZatAktif, Valid: req.ZatAktif != ""},
+		Satuan:        req.Satuan,
+		StokMinimum:   req.StokMinimum,
+		Status:        req.Status,
+	}
+}
+
+type ObatResponse struct {
+	ID            int       `json:"id"`
+	KodeObat      string    `json:"kode_obat"`
+	NamaObat      string    `json:"nama_obat"`
+	BentukSediaan string    `json:"bentuk_sediaan"`
+	Kekuatan      string    `json:"kekuatan,omitempty"`
+	ZatAktif      string    `json:"zat_aktif,omitempty"`
+	Satuan        string    `json:"satuan"`
+	StokMinimum   int       `json:"stok_minimum"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func ToObatResponse(o Obat) ObatResponse {
+	return ObatResponse{
+		ID:            o.ID,
+		KodeObat:      o.KodeObat,
+		NamaObat:      o.NamaObat,
+		BentukSediaan: o.BentukSediaan,
+		Kekuatan:      o.Kekuatan.String,
+		ZatAktif:      o.ZatAktif.String,
+		Satuan:        o.Satuan,
+		StokMinimum:   o.StokMinimum,
+		Status:        o.Status,
+		CreatedAt:     o.CreatedAt,
+		UpdatedAt:     o.UpdatedAt,
+	}
+}
+
+func ToObatResponseList(list []Obat) []ObatResponse {
+	responses := make([]ObatResponse, 0, len(list))
+	for _, o := range list {
+		responses = append(responses, ToObatResponse(o))
+	}
+	return responses
+}
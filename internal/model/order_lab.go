@@ -0,0 +1,198 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// alur order lab: dokter memesan, spesimen diambil lalu diterima laboratorium, hasil diisi
+// petugas lab, kemudian divalidasi petugas lab lain sebelum bisa dilihat dokter
+const (
+	StatusOrderLabDipesan          = "dipesan"
+	StatusOrderLabSpesimenDiambil  = "spesimen_diambil"
+	StatusOrderLabSpesimenDiterima = "spesimen_diterima"
+	StatusOrderLabMenungguValidasi = "menunggu_validasi"
+	StatusOrderLabDivalidasi       = "divalidasi"
+	StatusOrderLabDibatalkan       = "dibatalkan"
+)
+
+const (
+	PrioritasOrderLabRutin = "rutin"
+	PrioritasOrderLabCito  = "cito"
+)
+
+// permintaan pemeriksaan laboratorium dari satu pemeriksaan. id_pasien disalin dari antrian
+// supaya worklist lab tidak perlu join ke pemeriksaan
+type OrderLab struct {
+	ID                   int            `gorm:"primaryKey;column:id_order_lab"`
+	PemeriksaanID        int            `gorm:"column:id_pemeriksaan;index"`
+	PasienID             int            `gorm:"column:id_pasien;index"`
+	DokterID             int            `gorm:"column:id_dokter"`
+	Status               string         `gorm:"column:status;index;default:dipesan"`
+	Prioritas            string         `gorm:"column:prioritas;default:rutin"`
+	CatatanKlinis        sql.NullString `gorm:"column:catatan_klinis"`
+	SpesimenDiambilAt    sql.NullTime   `gorm:"column:spesimen_diambil_at"`
+	SpesimenDiambilOleh  sql.NullInt64  `gorm:"column:spesimen_diambil_oleh"`
+	SpesimenDiterimaAt   sql.NullTime   `gorm:"column:spesimen_diterima_at"`
+	SpesimenDiterimaOleh sql.NullInt64  `gorm:"column:spesimen_diterima_oleh"`
+	DivalidasiAt         sql.NullTime   `gorm:"column:divalidasi_at"`
+	DivalidasiOleh       sql.NullInt64  `gorm:"column:divalidasi_oleh"`
+	DibatalkanAt         sql.NullTime   `gorm:"column:dibatalkan_at"`
+	AlasanBatal          sql.NullString `gorm:"column:alasan_batal"`
+	CreatedAt            time.Time      `gorm:"column:created_at"`
+	UpdatedAt            time.Time      `gorm:"column:updated_at"`
+
+	Pasien    Pasien         `gorm:"foreignKey:PasienID"`
+	Dokter    Petugas        `gorm:"foreignKey:DokterID"`
+	Pengambil Petugas        `gorm:"foreignKey:SpesimenDiambilOleh"`
+	Penerima  Petugas        `gorm:"foreignKey:SpesimenDiterimaOleh"`
+	Validator Petugas        `gorm:"foreignKey:DivalidasiOleh"`
+	Items     []OrderLabItem `gorm:"foreignKey:OrderLabID"`
+}
+
+func (OrderLab) TableName() string { return "order_lab" }
+
+// status yang masih menunggu dikerjakan laboratorium
+func (o OrderLab) IsAktif() bool {
+	return o.Status != StatusOrderLabDivalidasi && o.Status != StatusOrderLabDibatalkan
+}
+
+// satu jenis pemeriksaan yang diminta, langsung atau sebagai bagian dari panel. Hasilnya
+// disimpan sebagai PemeriksaanLab yang menunjuk ke item ini
+type OrderLabItem struct {
+	ID                 int           `gorm:"primaryKey;column:id_order_lab_item"`
+	OrderLabID         int           `gorm:"column:id_order_lab;uniqueIndex:idx_order_lab_item_jenis"`
+	JenisPemeriksaanID int           `gorm:"column:id_jenis_pemeriksaan;uniqueIndex:idx_order_lab_item_jenis"`
+	PanelLabID         sql.NullInt64 `gorm:"column:id_panel_lab"`
+	Urutan             int           `gorm:"column:urutan"`
+
+	JenisPemeriksaanLab JenisPemeriksaanLab `gorm:"foreignKey:JenisPemeriksaanID"`
+	Panel               *PanelLab           `gorm:"foreignKey:PanelLabID"`
+	Hasil               *PemeriksaanLab     `gorm:"foreignKey:OrderLabItemID"`
+}
+
+func (OrderLabItem) TableName() string { return "order_lab_item" }
+
+// order bisa berisi panel, jenis pemeriksaan satuan, atau keduanya. Item panel diurutkan lebih
+// dulu sesuai urutan panel
+type CreateOrderLabRequest struct {
+	PanelIDs            []int  `json:"panel_ids,omitempty" binding:"required_without=JenisPemeriksaanIDs,omitempty,max=10,dive,gt=0"`
+	JenisPemeriksaanIDs []int  `json:"jenis_pemeriksaan_ids,omitempty" binding:"required_without=PanelIDs,omitempty,max=50,dive,gt=0"`
+	Prioritas           string `json:"prioritas,omitempty" binding:"omitempty,oneof=rutin cito"`
+	CatatanKlinis       string `json:"catatan_klinis,omitempty" binding:"omitempty,max=500,sanitize"`
+}
+
+// item order disusun service setelah panel dibaca
+func (req *CreateOrderLabRequest) ToModel(pemeriksaanID int) OrderLab {
+	order := OrderLab{
+		PemeriksaanID: pemeriksaanID,
+		Status:        StatusOrderLabDipesan,
+		Prioritas:     req.Prioritas,
+		CatatanKlinis: sql.NullString{String: req.CatatanKlinis, Valid: req.CatatanKlinis != ""},
+	}
+	if order.Prioritas == "" {
+		order.Prioritas = PrioritasOrderLabRutin
+	}
+	return order
+}
+
+// hasil untuk satu item order. Item yang sudah punya hasil diperbarui selama order belum divalidasi
+type HasilOrderLabRequest struct {
+	OrderLabItemID int    `json:"order_lab_item_id" binding:"required,gt=0"`
+	Hasil          string `json:"hasil" binding:"required,sanitize"`
+}
+
+type BatalOrderLabRequest struct {
+	Alasan string `json:"alasan" binding:"required,min=5,max=255,sanitize"`
+}
+
+type OrderLabResponse struct {
+	ID                   int                    `json:"id"`
+	PemeriksaanID        int                    `json:"pemeriksaan_id"`
+	Pasien               PasienInfo             `json:"pasien"`
+	Dokter               PetugasInfo            `json:"dokter"`
+	Status               string                 `json:"status"`
+	Prioritas            string                 `json:"prioritas"`
+	CatatanKlinis        string                 `json:"catatan_klinis,omitempty"`
+	Items                []OrderLabItemResponse `json:"items"`
+	SpesimenDiambilAt    *time.Time             `json:"spesimen_diambil_at,omitempty"`
+	SpesimenDiambilOleh  *PetugasInfo           `json:"spesimen_diambil_oleh,omitempty"`
+	SpesimenDiterimaAt   *time.Time             `json:"spesimen_diterima_at,omitempty"`
+	SpesimenDiterimaOleh *PetugasInfo           `json:"spesimen_diterima_oleh,omitempty"`
+	DivalidasiAt         *time.Time             `json:"divalidasi_at,omitempty"`
+	DivalidasiOleh       *PetugasInfo           `json:"divalidasi_oleh,omitempty"`
+	DibatalkanAt         *time.Time             `json:"dibatalkan_at,omitempty"`
+	AlasanBatal          string                 `json:"alasan_batal,omitempty"`
+	CreatedAt            time.Time              `json:"created_at"`
+}
+
+type OrderLabItemResponse struct {
+	ID               int                     `json:"id"`
+	JenisPemeriksaan JenisPemeriksaanInfo    `json:"jenis_pemeriksaan"`
+	Panel            *PanelLabInfo           `json:"panel,omitempty"`
+	Urutan           int                     `json:"urutan"`
+	Hasil            *PemeriksaanLabResponse `json:"hasil,omitempty"`
+}
+
+type JenisPemeriksaanInfo struct {
+	ID   int    `json:"id"`
+	Nama string `json:"nama"`
+}
+
+// tampilkanHasil false dipakai untuk dokter selama order belum divalidasi, item tetap tampil
+// tanpa hasilnya
+func ToOrderLabResponse(o OrderLab, tampilkanHasil bool) OrderLabResponse {
+	resp := OrderLabResponse{
+		ID:            o.ID,
+		PemeriksaanID: o.PemeriksaanID,
+		Pasien: PasienInfo{
+			ID:           o.Pasien.ID,
+			Nama:         o.Pasien.NamaPasien,
+			NoRekamMedis: o.Pasien.NoRekamMedis.String,
+		},
+		Dokter:        PetugasInfo{ID: o.Dokter.ID, Nama: o.Dokter.Nama},
+		Status:        o.Status,
+		Prioritas:     o.Prioritas,
+		CatatanKlinis: o.CatatanKlinis.String,
+		Items:         make([]OrderLabItemResponse, 0, len(o.Items)),
+		AlasanBatal:   o.AlasanBatal.String,
+		CreatedAt:     o.CreatedAt,
+	}
+	for _, item := range o.Items {
+		itemResp := OrderLabItemResponse{
+			ID:               item.ID,
+			JenisPemeriksaan: JenisPemeriksaanInfo{ID: item.JenisPemeriksaanLab.ID, Nama: item.JenisPemeriksaanLab.NamaPemeriksaan},
+			Panel:            toPanelLabInfo(item.Panel),
+			Urutan:           item.Urutan,
+		}
+		if tampilkanHasil && item.Hasil != nil {
+			hasil := ToPemeriksaanLabResponse(*item.Hasil)
+			itemResp.Hasil = &hasil
+		}
+		resp.Items = append(resp.Items, itemResp)
+	}
+	if o.SpesimenDiambilAt.Valid {
+		resp.SpesimenDiambilAt = &o.SpesimenDiambilAt.Time
+		resp.SpesimenDiambilOleh = &PetugasInfo{ID: o.Pengambil.ID, Nama: o.Pengambil.Nama, Role: o.Pengambil.Role}
+	}
+	if o.SpesimenDiterimaAt.Valid {
+		resp.SpesimenDiterimaAt = &o.SpesimenDiterimaAt.Time
+		resp.SpesimenDiterimaOleh = &PetugasInfo{ID: o.Penerima.ID, Nama: o.Penerima.Nama, Role: o.Penerima.Role}
+	}
+	if o.DivalidasiAt.Valid {
+		resp.DivalidasiAt = &o.DivalidasiAt.Time
+		resp.DivalidasiOleh = &PetugasInfo{ID: o.Validator.ID, Nama: o.Validator.Nama, Role: o.Validator.Role}
+	}
+	if o.DibatalkanAt.Valid {
+		resp.DibatalkanAt = &o.DibatalkanAt.Time
+	}
+	return resp
+}
+
+func ToOrderLabResponseList(list []OrderLab, tampilkanHasil func(OrderLab) bool) []OrderLabResponse {
+	responses := make([]OrderLabResponse, 0, len(list))
+	for _, o := range list {
+		responses = append(responses, ToOrderLabResponse(o, tampilkanHasil(o)))
+	}
+	return responses
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// panel/profil lab berisi beberapa jenis pemeriksaan yang biasa dipesan bersama, misalnya
+// "Darah Lengkap". Urutan item dipakai untuk urutan tampilan hasil
+type PanelLab struct {
+	ID        int            `json:"id" gorm:"primaryKey;column:id_panel_lab"`
+	NamaPanel string         `json:"nama_panel" gorm:"column:nama_panel;unique"`
+	Deskripsi sql.NullString `json:"deskripsi" gorm:"column:deskripsi"`
+	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index;column:deleted_at"`
+
+	Items []PanelLabItem `json:"items" gorm:"foreignKey:PanelLabID"`
+}
+
+func (PanelLab) TableName() string {
+	return "panel_lab"
+}
+
+type PanelLabItem struct {
+	ID                 int `json:"id" gorm:"primaryKey;column:id_panel_lab_item"`
+	PanelLabID         int `json:"-" gorm:"column:id_panel_lab;uniqueIndex:idx_panel_lab_item_jenis"`
+	JenisPemeriksaanID int `json:"jenis_pemeriksaan_id" gorm:"column:id_jenis_pemeriksaan;uniqueIndex:idx_panel_lab_item_jenis"`
+	Urutan             int `json:"urutan" gorm:"column:urutan"`
+
+	JenisPemeriksaanLab JenisPemeriksaanLab `json:"jenis_pemeriksaan" gorm:"foreignKey:JenisPemeriksaanID"`
+}
+
+func (PanelLabItem) TableName() string {
+	return "panel_lab_item"
+}
+
+// urutan item di panel, 0 bila jenis pemeriksaan bukan bagian dari panel
+func (p PanelLab) UrutanJenis(jenisID int) int {
+	for _, item := range p.Items {
+		if item.JenisPemeriksaanID == jenisID {
+			return item.Urutan
+		}
+	}
+	return 0
+}
+
+func (p PanelLab) JenisIDs() []int {
+	ids := make([]int, 0, len(p.Items))
+	for _, item := range p.Items {
+		ids = append(ids, item.JenisPemeriksaanID)
+	}
+	return ids
+}
+
+// urutan jenis_pemeriksaan_ids menjadi urutan tampilan item panel
+type PanelLabRequest struct {
+	NamaPanel           string `json:"nama_panel" binding:"required,min=3,max=100,sanitize"`
+	Deskripsi           string `json:"deskripsi,omitempty" binding:"omitempty,max=255,sanitize"`
+	JenisPemeriksaanIDs []int  `json:"jenis_pemeriksaan_ids" binding:"required,min=1,max=50,dive,gt=0"`
+}
+
+func (req *PanelLabRequest) ToModel() PanelLab {
+	panel := PanelLab{
+		NamaPanel: req.NamaPanel,
+		Deskripsi: sql.NullString{String: req.Deskripsi, Valid: req.Deskripsi != ""},
+		Items:     make([]PanelLabItem, 0, len(req.JenisPemeriksaanIDs)),
+	}
+	for i, jenisID := range req.JenisPemeriksaanIDs {
+		panel.Items = append(panel.Items, PanelLabItem{JenisPemeriksaanID: jenisID, Urutan: i + 1})
+	}
+	return panel
+}
+
+type PanelLabInfo struct {
+	ID   int    `json:"id"`
+	Nama string `json:"nama"`
+}
+
+// nil untuk hasil atau item yang tidak berasal dari panel
+func toPanelLabInfo(p *PanelLab) *PanelLabInfo {
+	if p == nil {
+		return nil
+	}
+	return &PanelLabInfo{ID: p.ID, Nama: p.NamaPanel}
+}
+
+type PanelLabResponse struct {
+	ID        int                    `json:"id"`
+	NamaPanel string                 `json:"nama_panel"`
+	Deskripsi string                 `json:"deskripsi,omitempty"`
+	Items     []PanelLabItemResponse `json:"items"`
+}
+
+type PanelLabItemResponse struct {
+	JenisPemeriksaan JenisPemeriksaanInfo `json:"jenis_pemeriksaan"`
+	Urutan           int                  `json:"urutan"`
+}
+
+func ToPanelLabResponse(p PanelLab) PanelLabResponse {
+	resp := PanelLabResponse{
+		ID:        p.ID,
+		NamaPanel: p.NamaPanel,
+		Deskripsi: p.Deskripsi.String,
+		Items:     make([]PanelLabItemResponse, 0, len(p.Items)),
+	}
+	for _, item := range p.Items {
+		resp.Items = append(resp.Items, PanelLabItemResponse{
+			JenisPemeriksaan: JenisPemeriksaanInfo{ID: item.JenisPemeriksaanID, Nama: item.JenisPemeriksaanLab.NamaPemeriksaan},
+			Urutan:           item.Urutan,
+		})
+	}
+	return resp
+}
+
+func ToPanelLabResponseList(list []PanelLab) []PanelLabResponse {
+	responses := make([]PanelLabResponse, 0, len(list))
+	for _, p := range list {
+		responses = append(responses, ToPanelLabResponse(p))
+	}
+	return responses
+}
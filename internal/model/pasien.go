@@ -21,8 +21,13 @@ type Pasien struct {
 	NamaKeluargaTerdekat      sql.NullString `json:"nama_keluarga_terdekat" gorm:"column:nama_keluarga_terdekat"`
 	NoTeleponKeluargaTerdekat sql.NullString `json:"no_telepon_keluarga_terdekat" gorm:"column:no_telepon_keluarga_terdekat"`
 	Password                  string         `json:"-" gorm:"column:password"`
-	CreatedAt                 time.Time      `json:"created_at" gorm:"column:created_at"`
-	UpdatedAt                 time.Time      `json:"updated_at" gorm:"column:updated_at"`
+	PasswordChanged           bool           `json:"-" gorm:"column:password_changed;default:false"`
+	// dinaikkan setiap password berubah untuk membatalkan token lama
+	TokenVersion int       `json:"-" gorm:"column:token_version;default:0"`
+	CreatedAt    time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"column:updated_at"`
+
+	Alergi []AlergiPasien `json:"-" gorm:"foreignKey:PasienID"`
 }
 
 func (Pasien) TableName() string {
@@ -60,6 +65,16 @@ type UpdatePasienRequest struct {
 	NoTeleponKeluargaTerdekat string `json:"no_telepon_keluarga_terdekat,omitempty"`
 }
 
+type LoginPasienRequest struct {
+	Username string `json:"username" binding:"required,sanitize"`
+	Password string `json:"password" binding:"required"`
+}
+
+type LoginPasienResponse struct {
+	Token           string `json:"token"`
+	PasswordChanged bool   `json:"password_changed"`
+}
+
 func (req *CreatePasienRequest) ToModel(username, hashedPassword, noRekamMedis string) Pasien {
 	parsedDate, _ := time.Parse("2006-01-02", req.TanggalLahirPasien)
 	return Pasien{
@@ -113,7 +128,9 @@ type PasienResponse struct {
 	StatusPernikahan          string    `json:"status_pernikahan"`
 	NamaKeluargaTerdekat      string    `json:"nama_keluarga_terdekat,omitempty"`
 	NoTeleponKeluargaTerdekat string    `json:"no_telepon_keluarga_terdekat,omitempty"`
-	CreatedAt                 time.Time `json:"created_at"`
+	// hanya terisi di detail pasien
+	Alergi    []AlergiPasienResponse `json:"alergi,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
 }
 
 func ToPasienResponse(p Pasien) PasienResponse {
@@ -132,6 +149,7 @@ func ToPasienResponse(p Pasien) PasienResponse {
 		StatusPernikahan:          p.StatusPernikahan,
 		NamaKeluargaTerdekat:      p.NamaKeluargaTerdekat.String,
 		NoTeleponKeluargaTerdekat: p.NoTeleponKeluargaTerdekat.String,
+		Alergi:                    ToAlergiPasienResponseList(p.Alergi),
 		CreatedAt:                 p.CreatedAt,
 	}
 }
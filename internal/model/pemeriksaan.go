@@ -9,55 +9,89 @@ type Pemeriksaan struct {
 	ID              int            `json:"id,omitempty" gorm:"primaryKey;column:id_pemeriksaan"`
 	AntrianID       int            `json:"antrian_id" gorm:"column:id_antrian;unique"`
 	IcdID           sql.NullInt64  `json:"icd_id" gorm:"column:id_icd"`
-	Nadi            sql.NullString `json:"nadi" gorm:"column:nadi"`
-	TekananDarah    sql.NullString `json:"tekanan_darah" gorm:"column:tekanan_darah"`
-	Suhu            sql.NullString `json:"suhu" gorm:"column:suhu"`
-	BeratBadan      sql.NullString `json:"berat_badan" gorm:"column:berat_badan"`
+	TandaVital      TandaVital     `json:"tanda_vital" gorm:"embedded"`
 	KeadaanUmum     sql.NullString `json:"keadaan_umum" gorm:"column:keadaan_umum"`
 	Keluhan         sql.NullString `json:"keluhan" gorm:"column:keluhan"`
 	RiwayatPenyakit sql.NullString `json:"riwayat_penyakit" gorm:"column:riwayat_penyakit"`
 	Keterangan      sql.NullString `json:"keterangan" gorm:"column:keterangan"`
 	Tindakan        sql.NullString `json:"tindakan" gorm:"column:tindakan"`
 
+	// dokter penanggung jawab, bisa berbeda dengan petugas yang mengetik rekam medis
+	DokterID   sql.NullInt64 `json:"dokter_id" gorm:"column:id_dokter;index"`
+	DibuatOleh sql.NullInt64 `json:"dibuat_oleh" gorm:"column:dibuat_oleh"`
+	DiubahOleh sql.NullInt64 `json:"diubah_oleh" gorm:"column:diubah_oleh"`
+
+	// nomor revisi terbaru, riwayatnya di pemeriksaan_revisi
+	Revisi int `json:"revisi" gorm:"column:revisi;default:1"`
+
+	// rekam medis tidak dihapus, hanya dibatalkan dengan alasan
+	DibatalkanAt   sql.NullTime   `json:"dibatalkan_at" gorm:"column:dibatalkan_at"`
+	DibatalkanOleh sql.NullInt64  `json:"dibatalkan_oleh" gorm:"column:dibatalkan_oleh"`
+	AlasanBatal    sql.NullString `json:"alasan_batal" gorm:"column:alasan_batal"`
+
+	// setelah ditandatangani rekam medis terkunci, tambahan hanya lewat addendum
+	DitandatanganiAt   sql.NullTime   `json:"ditandatangani_at" gorm:"column:ditandatangani_at"`
+	DitandatanganiOleh sql.NullInt64  `json:"ditandatangani_oleh" gorm:"column:ditandatangani_oleh"`
+	TandaTangan        sql.NullString `json:"-" gorm:"column:tanda_tangan"`
+
 	TanggalPemeriksaan time.Time `json:"tanggal_pemeriksaan" gorm:"column:tanggal_pemeriksaan"`
 	CreatedAt          time.Time `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt          time.Time `json:"updated_at" gorm:"column:updated_at"`
 
-	Antrian Antrian `json:"antrian" gorm:"foreignKey:AntrianID"`
-	Icd     Icd     `json:"icd" gorm:"foreignKey:IcdID"`
+	Antrian         Antrian `json:"antrian" gorm:"foreignKey:AntrianID"`
+	Icd             Icd     `json:"icd" gorm:"foreignKey:IcdID"`
+	Dokter          Petugas `json:"dokter" gorm:"foreignKey:DokterID"`
+	PetugasPembuat  Petugas `json:"petugas_pembuat" gorm:"foreignKey:DibuatOleh"`
+	PetugasPengubah Petugas `json:"petugas_pengubah" gorm:"foreignKey:DiubahOleh"`
+	Penandatangan   Petugas `json:"penandatangan" gorm:"foreignKey:DitandatanganiOleh"`
+
+	Addendum       []PemeriksaanAddendum  `json:"addendum" gorm:"foreignKey:PemeriksaanID"`
+	Diagnosis      []PemeriksaanDiagnosis `json:"diagnosis" gorm:"foreignKey:PemeriksaanID"`
+	DaftarTindakan []PemeriksaanTindakan  `json:"daftar_tindakan" gorm:"foreignKey:PemeriksaanID"`
 }
 
 func (Pemeriksaan) TableName() string {
 	return "pemeriksaan"
 }
 
+func (p Pemeriksaan) IsVoid() bool {
+	return p.DibatalkanAt.Valid
+}
+
+func (p Pemeriksaan) IsSigned() bool {
+	return p.DitandatanganiAt.Valid
+}
+
 type CreatePemeriksaanRequest struct {
-	AntrianID          int    `json:"antrian_id" binding:"required,gt=0"`
-	IcdID              *int   `json:"icd_id,omitempty"`
-	Nadi               string `json:"nadi,omitempty" binding:"sanitize"`
-	TekananDarah       string `json:"tekanan_darah,omitempty" binding:"sanitize"`
-	Suhu               string `json:"suhu,omitempty" binding:"sanitize"`
-	BeratBadan         string `json:"berat_badan,omitempty" binding:"sanitize"`
-	KeadaanUmum        string `json:"keadaan_umum,omitempty" binding:"sanitize"`
-	Keluhan            string `json:"keluhan,omitempty" binding:"sanitize"`
-	RiwayatPenyakit    string `json:"riwayat_penyakit,omitempty" binding:"sanitize"`
-	Keterangan         string `json:"keterangan,omitempty" binding:"sanitize"`
-	Tindakan           string `json:"tindakan,omitempty" binding:"sanitize"`
-	TanggalPemeriksaan string `json:"tanggal_pemeriksaan" binding:"required,datetime=2006-01-02"`
+	AntrianID int  `json:"antrian_id" binding:"required,gt=0"`
+	DokterID  *int `json:"dokter_id,omitempty" binding:"omitempty,gt=0"`
+	// icd_id tetap diterima untuk klien lama dan dianggap satu diagnosis primer kasus baru
+	IcdID              *int               `json:"icd_id,omitempty"`
+	Diagnosis          []DiagnosisRequest `json:"diagnosis,omitempty" binding:"omitempty,dive"`
+	TandaVital         TandaVitalRequest  `json:"tanda_vital"`
+	KeadaanUmum        string             `json:"keadaan_umum,omitempty" binding:"sanitize"`
+	Keluhan            string             `json:"keluhan,omitempty" binding:"sanitize"`
+	RiwayatPenyakit    string             `json:"riwayat_penyakit,omitempty" binding:"sanitize"`
+	Keterangan         string             `json:"keterangan,omitempty" binding:"sanitize"`
+	Tindakan           string             `json:"tindakan,omitempty" binding:"sanitize"`
+	DaftarTindakan     []TindakanRequest  `json:"daftar_tindakan,omitempty" binding:"omitempty,dive"`
+	TanggalPemeriksaan string             `json:"tanggal_pemeriksaan" binding:"required,datetime=2006-01-02"`
 }
 
 type UpdatePemeriksaanRequest struct {
-	IcdID              *int   `json:"icd_id,omitempty"`
-	Nadi               string `json:"nadi,omitempty" binding:"sanitize"`
-	TekananDarah       string `json:"tekanan_darah,omitempty" binding:"sanitize"`
-	Suhu               string `json:"suhu,omitempty" binding:"sanitize"`
-	BeratBadan         string `json:"berat_badan,omitempty" binding:"sanitize"`
-	KeadaanUmum        string `json:"keadaan_umum,omitempty" binding:"sanitize"`
-	Keluhan            string `json:"keluhan,omitempty" binding:"sanitize"`
-	RiwayatPenyakit    string `json:"riwayat_penyakit,omitempty" binding:"sanitize"`
-	Keterangan         string `json:"keterangan,omitempty" binding:"sanitize"`
-	Tindakan           string `json:"tindakan,omitempty" binding:"sanitize"`
-	TanggalPemeriksaan string `json:"tanggal_pemeriksaan" binding:"required,datetime=2006-01-02"`
+	DokterID *int `json:"dokter_id,omitempty" binding:"omitempty,gt=0"`
+	// icd_id tetap diterima untuk klien lama dan dianggap satu diagnosis primer kasus baru
+	IcdID              *int               `json:"icd_id,omitempty"`
+	Diagnosis          []DiagnosisRequest `json:"diagnosis,omitempty" binding:"omitempty,dive"`
+	TandaVital         TandaVitalRequest  `json:"tanda_vital"`
+	KeadaanUmum        string             `json:"keadaan_umum,omitempty" binding:"sanitize"`
+	Keluhan            string             `json:"keluhan,omitempty" binding:"sanitize"`
+	RiwayatPenyakit    string             `json:"riwayat_penyakit,omitempty" binding:"sanitize"`
+	Keterangan         string             `json:"keterangan,omitempty" binding:"sanitize"`
+	Tindakan           string             `json:"tindakan,omitempty" binding:"sanitize"`
+	DaftarTindakan     []TindakanRequest  `json:"daftar_tindakan,omitempty" binding:"omitempty,dive"`
+	TanggalPemeriksaan string             `json:"tanggal_pemeriksaan" binding:"required,datetime=2006-01-02"`
+	Alasan             string             `json:"alasan" binding:"required,min=5,max=255,sanitize"`
 }
 
 func (req *CreatePemeriksaanRequest) ToModel() Pemeriksaan {
@@ -66,10 +100,7 @@ func (req *CreatePemeriksaanRequest) ToModel() Pemeriksaan {
 
 	pemeriksaan := Pemeriksaan{
 		AntrianID:          req.AntrianID,
-		Nadi:               sql.NullString{String: req.Nadi, Valid: req.Nadi != ""},
-		TekananDarah:       sql.NullString{String: req.TekananDarah, Valid: req.TekananDarah != ""},
-		Suhu:               sql.NullString{String: req.Suhu, Valid: req.Suhu != ""},
-		BeratBadan:         sql.NullString{String: req.BeratBadan, Valid: req.BeratBadan != ""},
+		TandaVital:         req.TandaVital.ToModel(),
 		KeadaanUmum:        sql.NullString{String: req.KeadaanUmum, Valid: req.KeadaanUmum != ""},
 		Keluhan:            sql.NullString{String: req.Keluhan, Valid: req.Keluhan != ""},
 		RiwayatPenyakit:    sql.NullString{String: req.RiwayatPenyakit, Valid: req.RiwayatPenyakit != ""},
@@ -77,8 +108,13 @@ func (req *CreatePemeriksaanRequest) ToModel() Pemeriksaan {
 		Tindakan:           sql.NullString{String: req.Tindakan, Valid: req.Tindakan != ""},
 		TanggalPemeriksaan: parsedDate,
 	}
-	if req.IcdID != nil {
-		pemeriksaan.IcdID = sql.NullInt64{Int64: int64(*req.IcdID), Valid: true}
+	pemeriksaan.Diagnosis = diagnosisFromRequest(req.Diagnosis, req.IcdID)
+	if pemeriksaan.Diagnosis != nil {
+		pemeriksaan.IcdID = PrimaryIcdID(pemeriksaan.Diagnosis)
+	}
+	pemeriksaan.DaftarTindakan = ToPemeriksaanTindakanList(req.DaftarTindakan)
+	if req.DokterID != nil {
+		pemeriksaan.DokterID = sql.NullInt64{Int64: int64(*req.DokterID), Valid: true}
 	}
 	return pemeriksaan
 }
@@ -88,10 +124,7 @@ func (req *UpdatePemeriksaanRequest) ToModel() Pemeriksaan {
 	parsedDate, _ := time.Parse("2006-01-02", req.TanggalPemeriksaan)
 
 	pemeriksaan := Pemeriksaan{
-		Nadi:               sql.NullString{String: req.Nadi, Valid: req.Nadi != ""},
-		TekananDarah:       sql.NullString{String: req.TekananDarah, Valid: req.TekananDarah != ""},
-		Suhu:               sql.NullString{String: req.Suhu, Valid: req.Suhu != ""},
-		BeratBadan:         sql.NullString{String: req.BeratBadan, Valid: req.BeratBadan != ""},
+		TandaVital:         req.TandaVital.ToModel(),
 		KeadaanUmum:        sql.NullString{String: req.KeadaanUmum, Valid: req.KeadaanUmum != ""},
 		Keluhan:            sql.NullString{String: req.Keluhan, Valid: req.Keluhan != ""},
 		RiwayatPenyakit:    sql.NullString{String: req.RiwayatPenyakit, Valid: req.RiwayatPenyakit != ""},
@@ -99,25 +132,55 @@ func (req *UpdatePemeriksaanRequest) ToModel() Pemeriksaan {
 		Tindakan:           sql.NullString{String: req.Tindakan, Valid: req.Tindakan != ""},
 		TanggalPemeriksaan: parsedDate,
 	}
-	if req.IcdID != nil {
-		pemeriksaan.IcdID = sql.NullInt64{Int64: int64(*req.IcdID), Valid: true}
+	pemeriksaan.Diagnosis = diagnosisFromRequest(req.Diagnosis, req.IcdID)
+	if pemeriksaan.Diagnosis != nil {
+		pemeriksaan.IcdID = PrimaryIcdID(pemeriksaan.Diagnosis)
+	}
+	pemeriksaan.DaftarTindakan = ToPemeriksaanTindakanList(req.DaftarTindakan)
+	if req.DokterID != nil {
+		pemeriksaan.DokterID = sql.NullInt64{Int64: int64(*req.DokterID), Valid: true}
 	}
 	return pemeriksaan
 }
 
+// nil berarti diagnosis tidak diubah, slice kosong berarti semua diagnosis dihapus
+func diagnosisFromRequest(reqs []DiagnosisRequest, legacyIcdID *int) []PemeriksaanDiagnosis {
+	if reqs != nil {
+		return ToPemeriksaanDiagnosisList(reqs)
+	}
+	if legacyIcdID != nil {
+		return ToPemeriksaanDiagnosisList([]DiagnosisRequest{{IcdID: *legacyIcdID, Jenis: JenisDiagnosisPrimer, Kasus: KasusBaru}})
+	}
+	return nil
+}
+
 type PemeriksaanResponse struct {
-	ID                 int           `json:"id"`
-	TanggalPemeriksaan time.Time     `json:"tanggal_pemeriksaan"`
-	Nadi               string        `json:"nadi,omitempty"`
-	TekananDarah       string        `json:"tekanan_darah,omitempty"`
-	Suhu               string        `json:"suhu,omitempty"`
-	BeratBadan         string        `json:"berat_badan,omitempty"`
-	Keluhan            string        `json:"keluhan,omitempty"`
-	Tindakan           string        `json:"tindakan,omitempty"`
-	Pasien             PasienInfo    `json:"pasien"`
-	Dokter             PetugasInfo   `json:"dokter"`
-	Poli               PoliInfo      `json:"poli"`
-	Diagnosis          DiagnosisInfo `json:"diagnosis"`
+	ID                 int                 `json:"id"`
+	TanggalPemeriksaan time.Time           `json:"tanggal_pemeriksaan"`
+	TandaVital         TandaVitalResponse  `json:"tanda_vital"`
+	Keluhan            string              `json:"keluhan,omitempty"`
+	Tindakan           string              `json:"tindakan,omitempty"`
+	Pasien             PasienInfo          `json:"pasien"`
+	Dokter             PetugasInfo         `json:"dokter"`
+	DibuatOleh         *PetugasInfo        `json:"dibuat_oleh,omitempty"`
+	DiubahOleh         *PetugasInfo        `json:"diubah_oleh,omitempty"`
+	Revisi             int                 `json:"revisi"`
+	Dibatalkan         bool                `json:"dibatalkan"`
+	DibatalkanAt       *time.Time          `json:"dibatalkan_at,omitempty"`
+	AlasanBatal        string              `json:"alasan_batal,omitempty"`
+	TandaTangan        *TandaTanganInfo    `json:"tanda_tangan,omitempty"`
+	Addendum           []AddendumResponse  `json:"addendum,omitempty"`
+	Poli               PoliInfo            `json:"poli"`
+	Diagnosis          DiagnosisInfo       `json:"diagnosis"`
+	DaftarDiagnosis    []DiagnosisResponse `json:"daftar_diagnosis"`
+	DaftarTindakan     []TindakanResponse  `json:"daftar_tindakan"`
+}
+
+// Valid diisi service setelah menghitung ulang tanda tangan dari isi yang tersimpan
+type TandaTanganInfo struct {
+	Oleh  PetugasInfo `json:"oleh"`
+	Waktu time.Time   `json:"waktu"`
+	Valid bool        `json:"valid"`
 }
 
 type PasienInfo struct {
@@ -129,6 +192,7 @@ type PasienInfo struct {
 type PetugasInfo struct {
 	ID   int    `json:"id"`
 	Nama string `json:"nama"`
+	Role string `json:"role,omitempty"`
 }
 
 type PoliInfo struct {
@@ -146,27 +210,51 @@ func ToPemeriksaanResponse(p Pemeriksaan) PemeriksaanResponse {
 	resp := PemeriksaanResponse{
 		ID:                 p.ID,
 		TanggalPemeriksaan: p.TanggalPemeriksaan,
-		Nadi:               p.Nadi.String,
-		TekananDarah:       p.TekananDarah.String,
-		Suhu:               p.Suhu.String,
-		BeratBadan:         p.BeratBadan.String,
+		TandaVital:         ToTandaVitalResponse(p.TandaVital),
 		Keluhan:            p.Keluhan.String,
 		Tindakan:           p.Tindakan.String,
+		Revisi:             p.Revisi,
+		Dibatalkan:         p.DibatalkanAt.Valid,
+		AlasanBatal:        p.AlasanBatal.String,
 		Pasien: PasienInfo{
 			ID:           p.Antrian.Pasien.ID,
 			Nama:         p.Antrian.Pasien.NamaPasien,
 			NoRekamMedis: p.Antrian.Pasien.NoRekamMedis.String,
 		},
-		Dokter: PetugasInfo{
-			ID:   p.Antrian.Jadwal.Petugas.ID,
-			Nama: p.Antrian.Jadwal.Petugas.Nama,
-		},
 		Poli: PoliInfo{
 			ID:   p.Antrian.Jadwal.Poli.ID,
 			Nama: p.Antrian.Jadwal.Poli.Nama,
 		},
 	}
 
+	// data lama tanpa id_dokter memakai dokter dari jadwal antrian
+	if p.DokterID.Valid {
+		resp.Dokter = PetugasInfo{ID: p.Dokter.ID, Nama: p.Dokter.Nama}
+	} else {
+		resp.Dokter = PetugasInfo{ID: p.Antrian.Jadwal.Petugas.ID, Nama: p.Antrian.Jadwal.Petugas.Nama}
+	}
+	if p.DibatalkanAt.Valid {
+		resp.DibatalkanAt = &p.DibatalkanAt.Time
+	}
+	if p.DitandatanganiAt.Valid {
+		resp.TandaTangan = &TandaTanganInfo{
+			Oleh:  PetugasInfo{ID: p.Penandatangan.ID, Nama: p.Penandatangan.Nama, Role: p.Penandatangan.Role},
+			Waktu: p.DitandatanganiAt.Time,
+		}
+	}
+	if len(p.Addendum) > 0 {
+		resp.Addendum = ToAddendumResponseList(p.Addendum)
+	}
+	if p.DibuatOleh.Valid {
+		resp.DibuatOleh = &PetugasInfo{ID: p.PetugasPembuat.ID, Nama: p.PetugasPembuat.Nama, Role: p.PetugasPembuat.Role}
+	}
+	if p.DiubahOleh.Valid {
+		resp.DiubahOleh = &PetugasInfo{ID: p.PetugasPengubah.ID, Nama: p.PetugasPengubah.Nama, Role: p.PetugasPengubah.Role}
+	}
+
+	resp.DaftarDiagnosis = ToDiagnosisResponseList(p.Diagnosis)
+	resp.DaftarTindakan = ToTindakanResponseList(p.DaftarTindakan)
+
 	if p.IcdID.Valid {
 
 		id := p.IcdID.Int64
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// catatan tambahan untuk rekam medis yang sudah ditandatangani, isi aslinya tidak berubah
+type PemeriksaanAddendum struct {
+	ID            int           `gorm:"primaryKey;column:id_addendum"`
+	PemeriksaanID int           `gorm:"column:id_pemeriksaan;index"`
+	Isi           string        `gorm:"column:isi"`
+	DibuatOleh    sql.NullInt64 `gorm:"column:dibuat_oleh"`
+	CreatedAt     time.Time     `gorm:"column:created_at"`
+
+	Petugas Petugas `gorm:"foreignKey:DibuatOleh"`
+}
+
+func (PemeriksaanAddendum) TableName() string { return "pemeriksaan_addendum" }
+
+type CreateAddendumRequest struct {
+	Isi string `json:"isi" binding:"required,min=5,sanitize"`
+}
+
+type AddendumResponse struct {
+	ID         int          `json:"id"`
+	Isi        string       `json:"isi"`
+	DibuatOleh *PetugasInfo `json:"dibuat_oleh,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+func ToAddendumResponse(a PemeriksaanAddendum) AddendumResponse {
+	resp := AddendumResponse{
+		ID:        a.ID,
+		Isi:       a.Isi,
+		CreatedAt: a.CreatedAt,
+	}
+	if a.DibuatOleh.Valid {
+		resp.DibuatOleh = &PetugasInfo{ID: a.Petugas.ID, Nama: a.Petugas.Nama, Role: a.Petugas.Role}
+	}
+	return resp
+}
+
+func ToAddendumResponseList(addendum []PemeriksaanAddendum) []AddendumResponse {
+	responses := make([]AddendumResponse, 0, len(addendum))
+	for _, a := range addendum {
+		responses = append(responses, ToAddendumResponse(a))
+	}
+	return responses
+}
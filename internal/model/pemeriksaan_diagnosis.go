@@ -0,0 +1,102 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	JenisDiagnosisPrimer   = "primer"
+	JenisDiagnosisSekunder = "sekunder"
+)
+
+const (
+	KasusBaru = "baru"
+	KasusLama = "lama"
+)
+
+type PemeriksaanDiagnosis struct {
+	ID            int       `gorm:"primaryKey;column:id_pemeriksaan_diagnosis"`
+	PemeriksaanID int       `gorm:"column:id_pemeriksaan;uniqueIndex:idx_pemeriksaan_diagnosis_icd"`
+	IcdID         int       `gorm:"column:id_icd;uniqueIndex:idx_pemeriksaan_diagnosis_icd"`
+	Jenis         string    `gorm:"column:jenis;index"`
+	Kasus         string    `gorm:"column:kasus"`
+	Urutan        int       `gorm:"column:urutan"`
+	CreatedAt     time.Time `gorm:"column:created_at"`
+
+	Icd Icd `gorm:"foreignKey:IcdID"`
+}
+
+func (PemeriksaanDiagnosis) TableName() string { return "pemeriksaan_diagnosis" }
+
+type DiagnosisRequest struct {
+	IcdID int    `json:"icd_id" binding:"required,gt=0"`
+	Jenis string `json:"jenis" binding:"required,oneof=primer sekunder"`
+	Kasus string `json:"kasus" binding:"required,oneof=baru lama"`
+}
+
+// urutan mengikuti posisi di request, diagnosis primer tidak harus di urutan pertama
+func ToPemeriksaanDiagnosisList(reqs []DiagnosisRequest) []PemeriksaanDiagnosis {
+	if reqs == nil {
+		return nil
+	}
+	diagnosis := make([]PemeriksaanDiagnosis, 0, len(reqs))
+	for i, req := range reqs {
+		diagnosis = append(diagnosis, PemeriksaanDiagnosis{
+			IcdID:  req.IcdID,
+			Jenis:  req.Jenis,
+			Kasus:  req.Kasus,
+			Urutan: i + 1,
+		})
+	}
+	return diagnosis
+}
+
+// id_icd pada pemeriksaan tetap diisi diagnosis primer agar klien lama tetap berjalan
+func PrimaryIcdID(diagnosis []PemeriksaanDiagnosis) sql.NullInt64 {
+	for _, d := range diagnosis {
+		if d.Jenis == JenisDiagnosisPrimer {
+			return sql.NullInt64{Int64: int64(d.IcdID), Valid: true}
+		}
+	}
+	return sql.NullInt64{}
+}
+
+type DiagnosisResponse struct {
+	IcdID    int    `json:"icd_id"`
+	Kode     string `json:"kode"`
+	Penyakit string `json:"penyakit"`
+	Jenis    string `json:"jenis"`
+	Kasus    string `json:"kasus"`
+	Urutan   int    `json:"urutan"`
+}
+
+func ToDiagnosisResponseList(diagnosis []PemeriksaanDiagnosis) []DiagnosisResponse {
+	responses := make([]DiagnosisResponse, 0, len(diagnosis))
+	for _, d := range diagnosis {
+		responses = append(responses, DiagnosisResponse{
+			IcdID:    d.IcdID,
+			Kode:     d.Icd.KodeIcd,
+			Penyakit: d.Icd.NamaPenyakit,
+			Jenis:    d.Jenis,
+			Kasus:    d.Kasus,
+			Urutan:   d.Urutan,
+		})
+	}
+	return responses
+}
+
+type DiagnosisSnapshot struct {
+	IcdID  int    `json:"icd_id"`
+	Jenis  string `json:"jenis"`
+	Kasus  string `json:"kasus"`
+	Urutan int    `json:"urutan"`
+}
+
+func toDiagnosisSnapshotList(diagnosis []PemeriksaanDiagnosis) []DiagnosisSnapshot {
+	snapshots := make([]DiagnosisSnapshot, 0, len(diagnosis))
+	for _, d := range diagnosis {
+		snapshots = append(snapshots, DiagnosisSnapshot{IcdID: d.IcdID, Jenis: d.Jenis, Kasus: d.Kasus, Urutan: d.Urutan})
+	}
+	return snapshots
+}
@@ -1,15 +1,29 @@
 package model
 
-import "time"
+import (
+	"database/sql"
+	"sort"
+	"time"
+)
 
+// hasil lab bisa dicatat langsung atau melalui order lab. Hasil dari order menunjuk ke item
+// ordernya dan baru terlihat di pemeriksaan setelah order divalidasi. Flag dan teks nilai
+// rujukan dihitung saat hasil disimpan, sehingga perubahan rujukan tidak mengubah hasil lama
 type PemeriksaanLab struct {
 	ID                  int                 `json:"id,omitempty" gorm:"primaryKey;column:id_pemeriksaan_lab"`
 	PemeriksaanID       int                 `json:"pemeriksaan_id" gorm:"column:id_pemeriksaan"`
 	JenisPemeriksaanID  int                 `json:"jenis_pemeriksaan_id" gorm:"column:id_jenis_pemeriksaan"`
 	Hasil               string              `json:"hasil" gorm:"column:hasil"`
+	NilaiNumerik        sql.NullFloat64     `json:"-" gorm:"column:nilai_numerik"`
+	Flag                sql.NullString      `json:"-" gorm:"column:flag"`
+	NilaiRujukan        sql.NullString      `json:"-" gorm:"column:nilai_rujukan"`
+	OrderLabItemID      sql.NullInt64       `json:"-" gorm:"column:id_order_lab_item;uniqueIndex"`
+	PanelLabID          sql.NullInt64       `json:"-" gorm:"column:id_panel_lab;index"`
+	DiisiOleh           sql.NullInt64       `json:"-" gorm:"column:diisi_oleh"`
 	CreatedAt           time.Time           `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt           time.Time           `json:"updated_at" gorm:"column:updated_at"`
 	JenisPemeriksaanLab JenisPemeriksaanLab `json:"jenis_pemeriksaan" gorm:"foreignKey:JenisPemeriksaanID"`
+	Panel               *PanelLab           `json:"-" gorm:"foreignKey:PanelLabID"`
 }
 
 func (PemeriksaanLab) TableName() string {
@@ -29,6 +43,13 @@ func (req *CreateHasilLabRequest) ToModel(pemeriksaanID int) PemeriksaanLab {
 	}
 }
 
+// kesalahan validasi satu item batch hasil lab, index mengikuti urutan item pada request
+type HasilLabItemError struct {
+	Index              int    `json:"index"`
+	JenisPemeriksaanID int    `json:"jenis_pemeriksaan_id"`
+	Pesan              string `json:"pesan"`
+}
+
 type UpdateHasilLabRequest struct {
 	Hasil string `json:"hasil" binding:"required,sanitize"`
 }
@@ -46,6 +67,15 @@ type PemeriksaanLabResponse struct {
 		ID   int    `json:"id"`
 		Nama string `json:"nama"`
 	} `json:"jenis_pemeriksaan"`
+	Satuan       string        `json:"satuan,omitempty"`
+	NilaiRujukan string        `json:"nilai_rujukan,omitempty"`
+	Flag         string        `json:"flag,omitempty"`
+	Kritis       bool          `json:"kritis"`
+	Panel        *PanelLabInfo `json:"panel,omitempty"`
+}
+
+func (p PemeriksaanLab) IsKritis() bool {
+	return p.Flag.String == FlagLabKritisTinggi || p.Flag.String == FlagLabKritisRendah
 }
 
 func ToPemeriksaanLabResponse(p PemeriksaanLab) PemeriksaanLabResponse {
@@ -59,13 +89,51 @@ func ToPemeriksaanLabResponse(p PemeriksaanLab) PemeriksaanLabResponse {
 			ID:   p.JenisPemeriksaanLab.ID,
 			Nama: p.JenisPemeriksaanLab.NamaPemeriksaan,
 		},
+		Satuan:       p.JenisPemeriksaanLab.Satuan.String,
+		NilaiRujukan: p.NilaiRujukan.String,
+		Flag:         p.Flag.String,
+		Kritis:       p.IsKritis(),
+		Panel:        toPanelLabInfo(p.Panel),
 	}
 }
 
 func ToPemeriksaanLabResponseList(pemeriksaanLabs []PemeriksaanLab) []PemeriksaanLabResponse {
-	var responses []PemeriksaanLabResponse
+	responses := make([]PemeriksaanLabResponse, 0, len(pemeriksaanLabs))
 	for _, p := range pemeriksaanLabs {
 		responses = append(responses, ToPemeriksaanLabResponse(p))
 	}
 	return responses
 }
+
+// hasil lab dikelompokkan per panel, hasil tanpa panel dikumpulkan dalam kelompok dengan panel null
+type HasilLabPanelResponse struct {
+	Panel *PanelLabInfo            `json:"panel"`
+	Hasil []PemeriksaanLabResponse `json:"hasil"`
+}
+
+// kelompok diurutkan sesuai kemunculan pertamanya, hasil di dalam panel mengikuti urutan item panel
+func KelompokkanHasilLab(list []PemeriksaanLab) []HasilLabPanelResponse {
+	groups := make([]HasilLabPanelResponse, 0)
+	indexByPanel := make(map[int64]int)
+	urutan := make(map[int]int, len(list))
+	for _, p := range list {
+		key := int64(0)
+		if p.PanelLabID.Valid {
+			key = p.PanelLabID.Int64
+		}
+		if p.Panel != nil {
+			urutan[p.ID] = p.Panel.UrutanJenis(p.JenisPemeriksaanID)
+		}
+		i, ok := indexByPanel[key]
+		if !ok {
+			i = len(groups)
+			indexByPanel[key] = i
+			groups = append(groups, HasilLabPanelResponse{Panel: toPanelLabInfo(p.Panel)})
+		}
+		groups[i].Hasil = append(groups[i].Hasil, ToPemeriksaanLabResponse(p))
+	}
+	for _, g := range groups {
+		sort.SliceStable(g.Hasil, func(a, b int) bool { return urutan[g.Hasil[a].ID] < urutan[g.Hasil[b].ID] })
+	}
+	return groups
+}
@@ -0,0 +1,153 @@
+package model
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/franklindh/simedis-api/internal/audit"
+)
+
+// satu versi rekam medis yang tidak pernah diubah atau dihapus. Baris pemeriksaan selalu
+// berisi revisi terbaru, riwayat lengkapnya ada di tabel ini
+type PemeriksaanRevisi struct {
+	ID            int           `gorm:"primaryKey;column:id_revisi"`
+	PemeriksaanID int           `gorm:"column:id_pemeriksaan;uniqueIndex:idx_pemeriksaan_revisi"`
+	Revisi        int           `gorm:"column:revisi;uniqueIndex:idx_pemeriksaan_revisi"`
+	Data          string        `gorm:"column:data;type:jsonb"`
+	Alasan        string        `gorm:"column:alasan"`
+	DibuatOleh    sql.NullInt64 `gorm:"column:dibuat_oleh"`
+	CreatedAt     time.Time     `gorm:"column:created_at"`
+
+	Petugas Petugas `gorm:"foreignKey:DibuatOleh"`
+}
+
+func (PemeriksaanRevisi) TableName() string { return "pemeriksaan_revisi" }
+
+const AlasanRevisiAwal = "rekam medis dibuat"
+
+// isi klinis yang disimpan pada setiap revisi
+type PemeriksaanSnapshot struct {
+	DokterID           *int64              `json:"dokter_id"`
+	IcdID              *int64              `json:"icd_id"`
+	Diagnosis          []DiagnosisSnapshot `json:"diagnosis"`
+	DaftarTindakan     []TindakanSnapshot  `json:"daftar_tindakan"`
+	TandaVital         TandaVitalResponse  `json:"tanda_vital"`
+	KeadaanUmum        string              `json:"keadaan_umum"`
+	Keluhan            string              `json:"keluhan"`
+	RiwayatPenyakit    string              `json:"riwayat_penyakit"`
+	Keterangan         string              `json:"keterangan"`
+	Tindakan           string              `json:"tindakan"`
+	TanggalPemeriksaan string              `json:"tanggal_pemeriksaan"`
+}
+
+func NewPemeriksaanSnapshot(p Pemeriksaan) PemeriksaanSnapshot {
+	snapshot := PemeriksaanSnapshot{
+		TandaVital:         ToTandaVitalResponse(p.TandaVital),
+		Diagnosis:          toDiagnosisSnapshotList(p.Diagnosis),
+		DaftarTindakan:     toTindakanSnapshotList(p.DaftarTindakan),
+		KeadaanUmum:        p.KeadaanUmum.String,
+		Keluhan:            p.Keluhan.String,
+		RiwayatPenyakit:    p.RiwayatPenyakit.String,
+		Keterangan:         p.Keterangan.String,
+		Tindakan:           p.Tindakan.String,
+		TanggalPemeriksaan: p.TanggalPemeriksaan.Format("2006-01-02"),
+	}
+	if p.DokterID.Valid {
+		id := p.DokterID.Int64
+		snapshot.DokterID = &id
+	}
+	if p.IcdID.Valid {
+		id := p.IcdID.Int64
+		snapshot.IcdID = &id
+	}
+	return snapshot
+}
+
+// membuat revisi berikutnya dari kondisi pemeriksaan setelah perubahan
+func NewPemeriksaanRevisi(p Pemeriksaan, revisi int, alasan string, petugasID sql.NullInt64) (PemeriksaanRevisi, error) {
+	data, err := json.Marshal(NewPemeriksaanSnapshot(p))
+	if err != nil {
+		return PemeriksaanRevisi{}, err
+	}
+	return PemeriksaanRevisi{
+		PemeriksaanID: p.ID,
+		Revisi:        revisi,
+		Data:          string(data),
+		Alasan:        alasan,
+		DibuatOleh:    petugasID,
+	}, nil
+}
+
+func (r PemeriksaanRevisi) Snapshot() (PemeriksaanSnapshot, error) {
+	var snapshot PemeriksaanSnapshot
+	err := json.Unmarshal([]byte(r.Data), &snapshot)
+	return snapshot, err
+}
+
+type PemeriksaanRevisiResponse struct {
+	Revisi     int                 `json:"revisi"`
+	Alasan     string              `json:"alasan"`
+	DibuatOleh *PetugasInfo        `json:"dibuat_oleh,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+	Data       PemeriksaanSnapshot `json:"data"`
+}
+
+func ToPemeriksaanRevisiResponse(r PemeriksaanRevisi) PemeriksaanRevisiResponse {
+	snapshot, _ := r.Snapshot()
+	resp := PemeriksaanRevisiResponse{
+		Revisi:    r.Revisi,
+		Alasan:    r.Alasan,
+		CreatedAt: r.CreatedAt,
+		Data:      snapshot,
+	}
+	if r.DibuatOleh.Valid {
+		resp.DibuatOleh = &PetugasInfo{ID: r.Petugas.ID, Nama: r.Petugas.Nama, Role: r.Petugas.Role}
+	}
+	return resp
+}
+
+func ToPemeriksaanRevisiResponseList(revisions []PemeriksaanRevisi) []PemeriksaanRevisiResponse {
+	responses := make([]PemeriksaanRevisiResponse, 0, len(revisions))
+	for _, r := range revisions {
+		responses = append(responses, ToPemeriksaanRevisiResponse(r))
+	}
+	return responses
+}
+
+type PemeriksaanRevisiDiffResponse struct {
+	Dari    int                     `json:"dari"`
+	Ke      int                     `json:"ke"`
+	Changes map[string]audit.Change `json:"changes"`
+}
+
+type ParamsDiffRevisi struct {
+	Dari int `form:"from" binding:"omitempty,gt=0"`
+	Ke   int `form:"to" binding:"omitempty,gt=0"`
+}
+
+type VoidPemeriksaanRequest struct {
+	Alasan string `json:"alasan" binding:"required,min=5,max=255,sanitize"`
+}
+
+// isi yang ditandatangani: snapshot klinis beserta identitas record, penandatangan, dan waktunya.
+// Urutan field struct membuat hasil json.Marshal selalu sama untuk isi yang sama
+type PemeriksaanSignaturePayload struct {
+	PemeriksaanID      int                 `json:"id_pemeriksaan"`
+	AntrianID          int                 `json:"id_antrian"`
+	Revisi             int                 `json:"revisi"`
+	Data               PemeriksaanSnapshot `json:"data"`
+	DitandatanganiOleh int64               `json:"ditandatangani_oleh"`
+	DitandatanganiAt   string              `json:"ditandatangani_at"`
+}
+
+func NewPemeriksaanSignaturePayload(p Pemeriksaan, signerID int64, at time.Time) PemeriksaanSignaturePayload {
+	return PemeriksaanSignaturePayload{
+		PemeriksaanID:      p.ID,
+		AntrianID:          p.AntrianID,
+		Revisi:             p.Revisi,
+		Data:               NewPemeriksaanSnapshot(p),
+		DitandatanganiOleh: signerID,
+		DitandatanganiAt:   at.UTC().Format(time.RFC3339Nano),
+	}
+}
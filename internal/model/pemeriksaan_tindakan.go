@@ -0,0 +1,105 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// prosedur ICD-9-CM yang dilakukan pada satu pemeriksaan, pelaksana bisa dokter atau perawat
+type PemeriksaanTindakan struct {
+	ID            int            `gorm:"primaryKey;column:id_pemeriksaan_tindakan"`
+	PemeriksaanID int            `gorm:"column:id_pemeriksaan;index"`
+	ProsedurID    int            `gorm:"column:id_prosedur;index"`
+	Jumlah        int            `gorm:"column:jumlah;default:1"`
+	PelaksanaID   sql.NullInt64  `gorm:"column:id_pelaksana;index"`
+	Catatan       sql.NullString `gorm:"column:catatan"`
+	Urutan        int            `gorm:"column:urutan"`
+	CreatedAt     time.Time      `gorm:"column:created_at"`
+
+	Prosedur  Prosedur `gorm:"foreignKey:ProsedurID"`
+	Pelaksana Petugas  `gorm:"foreignKey:PelaksanaID"`
+}
+
+func (PemeriksaanTindakan) TableName() string { return "pemeriksaan_tindakan" }
+
+type TindakanRequest struct {
+	ProsedurID  int    `json:"prosedur_id" binding:"required,gt=0"`
+	Jumlah      int    `json:"jumlah,omitempty" binding:"omitempty,min=1,max=100"`
+	PelaksanaID *int   `json:"pelaksana_id,omitempty" binding:"omitempty,gt=0"`
+	Catatan     string `json:"catatan,omitempty" binding:"omitempty,max=255,sanitize"`
+}
+
+// nil berarti daftar tindakan tidak diubah, slice kosong berarti semua tindakan dihapus
+func ToPemeriksaanTindakanList(reqs []TindakanRequest) []PemeriksaanTindakan {
+	if reqs == nil {
+		return nil
+	}
+	tindakan := make([]PemeriksaanTindakan, 0, len(reqs))
+	for i, req := range reqs {
+		jumlah := req.Jumlah
+		if jumlah == 0 {
+			jumlah = 1
+		}
+		t := PemeriksaanTindakan{
+			ProsedurID: req.ProsedurID,
+			Jumlah:     jumlah,
+			Catatan:    sql.NullString{String: req.Catatan, Valid: req.Catatan != ""},
+			Urutan:     i + 1,
+		}
+		if req.PelaksanaID != nil {
+			t.PelaksanaID = sql.NullInt64{Int64: int64(*req.PelaksanaID), Valid: true}
+		}
+		tindakan = append(tindakan, t)
+	}
+	return tindakan
+}
+
+type TindakanResponse struct {
+	ProsedurID int          `json:"prosedur_id"`
+	Kode       string       `json:"kode"`
+	Nama       string       `json:"nama"`
+	Jumlah     int          `json:"jumlah"`
+	Pelaksana  *PetugasInfo `json:"pelaksana,omitempty"`
+	Catatan    string       `json:"catatan,omitempty"`
+	Urutan     int          `json:"urutan"`
+}
+
+func ToTindakanResponseList(tindakan []PemeriksaanTindakan) []TindakanResponse {
+	responses := make([]TindakanResponse, 0, len(tindakan))
+	for _, t := range tindakan {
+		resp := TindakanResponse{
+			ProsedurID: t.ProsedurID,
+			Kode:       t.Prosedur.KodeProsedur,
+			Nama:       t.Prosedur.NamaProsedur,
+			Jumlah:     t.Jumlah,
+			Catatan:    t.Catatan.String,
+			Urutan:     t.Urutan,
+		}
+		if t.PelaksanaID.Valid {
+			resp.Pelaksana = &PetugasInfo{ID: t.Pelaksana.ID, Nama: t.Pelaksana.Nama, Role: t.Pelaksana.Role}
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+type TindakanSnapshot struct {
+	ProsedurID  int    `json:"prosedur_id"`
+	Jumlah      int    `json:"jumlah"`
+	PelaksanaID *int64 `json:"pelaksana_id"`
+	Catatan     string `json:"catatan"`
+	Urutan      int    `json:"urutan"`
+}
+
+func toTindakanSnapshotList(tindakan []PemeriksaanTindakan) []TindakanSnapshot {
+	snapshots := make([]TindakanSnapshot, 0, len(tindakan))
+	for _, t := range tindakan {
+		snapshot := TindakanSnapshot{ProsedurID: t.ProsedurID, Jumlah: t.Jumlah, Catatan: t.Catatan.String, Urutan: t.Urutan}
+		if t.PelaksanaID.Valid {
+			id := t.PelaksanaID.Int64
+			snapshot.PelaksanaID = &id
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots
+}
@@ -8,16 +8,18 @@ import (
 )
 
 type Petugas struct {
-	ID        int            `json:"id,omitempty" gorm:"primaryKey;column:id_petugas"`
-	PoliID    sql.NullInt64  `json:"poli_id" gorm:"column:id_poli"`
-	Username  string         `json:"username" gorm:"column:username_petugas;unique"`
-	Nama      string         `json:"nama" gorm:"column:nama_petugas"`
-	Status    string         `json:"status" gorm:"column:status"`
-	Role      string         `json:"role" gorm:"column:role"`
-	Password  string         `json:"-" gorm:"column:password"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index;column:deleted_at"`
-	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
-	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at"`
+	ID       int           `json:"id,omitempty" gorm:"primaryKey;column:id_petugas"`
+	PoliID   sql.NullInt64 `json:"poli_id" gorm:"column:id_poli"`
+	Username string        `json:"username" gorm:"column:username_petugas;unique"`
+	Nama     string        `json:"nama" gorm:"column:nama_petugas"`
+	Status   string        `json:"status" gorm:"column:status"`
+	Role     string        `json:"role" gorm:"column:role"`
+	Password string        `json:"-" gorm:"column:password"`
+	// dinaikkan setiap password, role, atau status berubah untuk membatalkan token lama
+	TokenVersion int            `json:"-" gorm:"column:token_version;default:0"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index;column:deleted_at"`
+	CreatedAt    time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt    time.Time      `json:"updated_at" gorm:"column:updated_at"`
 }
 
 type PetugasResponse struct {
@@ -35,14 +37,14 @@ type CreatePetugasRequest struct {
 	Username string `json:"username" binding:"required,min=5,max=20,alphanum,sanitize"`
 	Nama     string `json:"nama" binding:"required,min=3,max=50,sanitize"`
 	Status   string `json:"status" binding:"required,oneof=aktif nonaktif"`
-	Role     string `json:"role" binding:"required,oneof=Administrasi Poliklinik Dokter Lab"`
+	Role     string `json:"role" binding:"required,oneof=Administrasi Poliklinik Dokter Lab Apotek"`
 	PoliID   *int64 `json:"poli_id,omitempty"`
 }
 
 type UpdatePetugasRequest struct {
 	Nama   string `json:"nama" binding:"required,min=3,max=50,sanitize"`
 	Status string `json:"status" binding:"required,oneof=aktif nonaktif"`
-	Role   string `json:"role" binding:"required,oneof=Administrasi Poliklinik Dokter Lab"`
+	Role   string `json:"role" binding:"required,oneof=Administrasi Poliklinik Dokter Lab Apotek"`
 	PoliID *int64 `json:"poli_id,omitempty"`
 }
 
@@ -1,49 +1,65 @@
 package model
 
 import (
+	"database/sql"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type Poli struct {
-	ID        int            `json:"id,omitempty" gorm:"primaryKey;column:id_poli"`
-	Nama      string         `json:"nama" gorm:"column:nama_poli"`
-	Status    string         `json:"status" gorm:"column:status_poli"`
-	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
-	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index;column:deleted_at"`
+	ID     int    `json:"id,omitempty" gorm:"primaryKey;column:id_poli"`
+	Nama   string `json:"nama" gorm:"column:nama_poli"`
+	Status string `json:"status" gorm:"column:status_poli"`
+	// prefix nomor antrian, contoh "UM" menghasilkan UM-001
+	KodeAntrian sql.NullString `json:"kode_antrian" gorm:"column:kode_antrian;size:5;uniqueIndex:idx_poli_kode_antrian"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt   time.Time      `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index;column:deleted_at"`
 }
 
 func (Poli) TableName() string {
 	return "poli"
 }
 
+// poli tanpa kode memakai P + id supaya prefix tetap unik antar poli
+func (p Poli) PrefixAntrian() string {
+	if p.KodeAntrian.Valid && p.KodeAntrian.String != "" {
+		return p.KodeAntrian.String
+	}
+	return fmt.Sprintf("P%d", p.ID)
+}
+
 type CreatePoliRequest struct {
-	Name   string `json:"name" binding:"required,min=3,max=50,sanitize"`
-	Status string `json:"status" binding:"required,oneof=aktif nonaktif"`
+	Name        string `json:"name" binding:"required,min=3,max=50,sanitize"`
+	Status      string `json:"status" binding:"required,oneof=aktif nonaktif"`
+	KodeAntrian string `json:"kode_antrian" binding:"omitempty,min=1,max=5,alphanum,uppercase"`
 }
 
 type UpdatePoliRequest struct {
-	Name   string `json:"name" binding:"required,min=3,max=50,sanitize"`
-	Status string `json:"status" binding:"required,oneof=aktif nonaktif"`
+	Name        string `json:"name" binding:"required,min=3,max=50,sanitize"`
+	Status      string `json:"status" binding:"required,oneof=aktif nonaktif"`
+	KodeAntrian string `json:"kode_antrian" binding:"omitempty,min=1,max=5,alphanum,uppercase"`
 }
 
 type PoliResponse struct {
-	ID        int       `json:"id"`
-	Nama      string    `json:"nama"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          int       `json:"id"`
+	Nama        string    `json:"nama"`
+	Status      string    `json:"status"`
+	KodeAntrian string    `json:"kode_antrian,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 func ToPoliResponse(p Poli) PoliResponse {
 	return PoliResponse{
-		ID:        p.ID,
-		Nama:      p.Nama,
-		Status:    p.Status,
-		CreatedAt: p.CreatedAt,
-		UpdatedAt: p.UpdatedAt,
+		ID:          p.ID,
+		Nama:        p.Nama,
+		Status:      p.Status,
+		KodeAntrian: p.KodeAntrian.String,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
 	}
 }
 
@@ -57,14 +73,16 @@ func ToPoliResponseList(polis []Poli) []PoliResponse {
 
 func (req *CreatePoliRequest) ToModel() Poli {
 	return Poli{
-		Nama:   req.Name,
-		Status: req.Status,
+		Nama:        req.Name,
+		Status:      req.Status,
+		KodeAntrian: sql.NullString{String: req.KodeAntrian, Valid: req.KodeAntrian != ""},
 	}
 }
 
 func (req *UpdatePoliRequest) ToModel() Poli {
 	return Poli{
-		Nama:   req.Name,
-		Status: req.Status,
+		Nama:        req.Name,
+		Status:      req.Status,
+		KodeAntrian: sql.NullString{String: req.KodeAntrian, Valid: req.KodeAntrian != ""},
 	}
 }
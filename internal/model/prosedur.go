@@ -0,0 +1,119 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// master prosedur/tindakan berkode ICD-9-CM, strukturnya mengikuti Icd
+type Prosedur struct {
+	ID           int            `json:"id,omitempty" gorm:"primaryKey;column:id_prosedur"`
+	KodeProsedur string         `json:"kode_prosedur" gorm:"column:kode_prosedur;unique"`
+	NamaProsedur string         `json:"nama_prosedur" gorm:"column:nama_prosedur"`
+	Deskripsi    sql.NullString `json:"deskripsi,omitempty" gorm:"column:deskripsi_prosedur"`
+	Status       string         `json:"status" gorm:"column:status_prosedur"`
+	Bab          sql.NullString `json:"bab,omitempty" gorm:"column:bab;index"`
+	Blok         sql.NullString `json:"blok,omitempty" gorm:"column:blok;index"`
+	KodeInduk    sql.NullString `json:"kode_induk,omitempty" gorm:"column:kode_induk;index"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index;column:deleted_at"`
+	CreatedAt    time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt    time.Time      `json:"updated_at" gorm:"column:updated_at"`
+
+	Induk   *Prosedur  `json:"-" gorm:"foreignKey:KodeInduk;references:KodeProsedur;constraint:-"`
+	Turunan []Prosedur `json:"-" gorm:"foreignKey:KodeInduk;references:KodeProsedur;constraint:-"`
+}
+
+func (Prosedur) TableName() string {
+	return "prosedur"
+}
+
+type CreateProsedurRequest struct {
+	KodeProsedur string `json:"kode_prosedur" binding:"required"`
+	NamaProsedur string `json:"nama_prosedur" binding:"required,min=3,sanitize"`
+	Deskripsi    string `json:"deskripsi,omitempty" binding:"sanitize"`
+	Status       string `json:"status" binding:"required,oneof=aktif nonaktif"`
+	KodeInduk    string `json:"kode_induk,omitempty" binding:"omitempty,sanitize"`
+}
+
+func (req *CreateProsedurRequest) ToModel() Prosedur {
+	return Prosedur{
+		KodeProsedur: req.KodeProsedur,
+		NamaProsedur: req.NamaProsedur,
+		Deskripsi:    sql.NullString{String: req.Deskripsi, Valid: req.Deskripsi != ""},
+		Status:       req.Status,
+		KodeInduk:    sql.NullString{String: req.KodeInduk, Valid: req.KodeInduk != ""},
+	}
+}
+
+type UpdateProsedurRequest struct {
+	KodeProsedur string `json:"kode_prosedur" binding:"required"`
+	NamaProsedur string `json:"nama_prosedur" binding:"required,min=3,sanitize"`
+	Deskripsi    string `json:"deskripsi,omitempty" binding:"sanitize"`
+	Status       string `json:"status" binding:"required,oneof=aktif nonaktif"`
+	KodeInduk    string `json:"kode_induk,omitempty" binding:"omitempty,sanitize"`
+}
+
+func (req *UpdateProsedurRequest) ToModel() Prosedur {
+	return Prosedur{
+		KodeProsedur: req.KodeProsedur,
+		NamaProsedur: req.NamaProsedur,
+		Deskripsi:    sql.NullString{String: req.Deskripsi, Valid: req.Deskripsi != ""},
+		Status:       req.Status,
+		KodeInduk:    sql.NullString{String: req.KodeInduk, Valid: req.KodeInduk != ""},
+	}
+}
+
+type ProsedurResponse struct {
+	ID           int    `json:"id"`
+	KodeProsedur string `json:"kode_prosedur"`
+	NamaProsedur string `json:"nama_prosedur"`
+	Deskripsi    string `json:"deskripsi,omitempty"`
+	Status       string `json:"status"`
+	Bab          string `json:"bab,omitempty"`
+	Blok         string `json:"blok,omitempty"`
+	KodeInduk    string `json:"kode_induk,omitempty"`
+
+	// hanya diisi pada detail prosedur
+	Induk   *ProsedurRingkasResponse  `json:"induk,omitempty"`
+	Turunan []ProsedurRingkasResponse `json:"turunan,omitempty"`
+}
+
+type ProsedurRingkasResponse struct {
+	ID           int    `json:"id"`
+	KodeProsedur string `json:"kode_prosedur"`
+	NamaProsedur string `json:"nama_prosedur"`
+}
+
+func ToProsedurResponse(p Prosedur) ProsedurResponse {
+	return ProsedurResponse{
+		ID:           p.ID,
+		KodeProsedur: p.KodeProsedur,
+		NamaProsedur: p.NamaProsedur,
+		Deskripsi:    p.Deskripsi.String,
+		Status:       p.Status,
+		Bab:          p.Bab.String,
+		Blok:         p.Blok.String,
+		KodeInduk:    p.KodeInduk.String,
+	}
+}
+
+func ToProsedurDetailResponse(p Prosedur) ProsedurResponse {
+	resp := ToProsedurResponse(p)
+	if p.Induk != nil {
+		resp.Induk = &ProsedurRingkasResponse{ID: p.Induk.ID, KodeProsedur: p.Induk.KodeProsedur, NamaProsedur: p.Induk.NamaProsedur}
+	}
+	for _, turunan := range p.Turunan {
+		resp.Turunan = append(resp.Turunan, ProsedurRingkasResponse{ID: turunan.ID, KodeProsedur: turunan.KodeProsedur, NamaProsedur: turunan.NamaProsedur})
+	}
+	return resp
+}
+
+func ToProsedurResponseList(list []Prosedur) []ProsedurResponse {
+	responses := make([]ProsedurResponse, 0, len(list))
+	for _, p := range list {
+		responses = append(responses, ToProsedurResponse(p))
+	}
+	return responses
+}
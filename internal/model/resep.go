@@ -0,0 +1,216 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	StatusResepMenunggu   = "menunggu"
+	StatusResepDiserahkan = "diserahkan"
+)
+
+const (
+	JenisPeringatanAlergi    = "alergi"
+	JenisPeringatanInteraksi = "interaksi"
+)
+
+// resep yang ditulis dokter dari satu pemeriksaan. id_pasien disalin dari antrian supaya
+// daftar resep per pasien tidak perlu join ke pemeriksaan
+type Resep struct {
+	ID             int            `gorm:"primaryKey;column:id_resep"`
+	PemeriksaanID  int            `gorm:"column:id_pemeriksaan;index"`
+	PasienID       int            `gorm:"column:id_pasien;index"`
+	DokterID       int            `gorm:"column:id_dokter;index"`
+	Status         string         `gorm:"column:status;index;default:menunggu"`
+	Catatan        sql.NullString `gorm:"column:catatan"`
+	DiserahkanAt   sql.NullTime   `gorm:"column:diserahkan_at"`
+	DiserahkanOleh sql.NullInt64  `gorm:"column:diserahkan_oleh"`
+	CreatedAt      time.Time      `gorm:"column:created_at"`
+	UpdatedAt      time.Time      `gorm:"column:updated_at"`
+
+	Pasien   Pasien      `gorm:"foreignKey:PasienID"`
+	Dokter   Petugas     `gorm:"foreignKey:DokterID"`
+	Penyerah Petugas     `gorm:"foreignKey:DiserahkanOleh"`
+	Items    []ResepItem `gorm:"foreignKey:ResepID"`
+
+	Peringatan []ResepPeringatan `gorm:"foreignKey:ResepID"`
+}
+
+func (Resep) TableName() string { return "resep" }
+
+// satu obat dalam resep. Dosis dan frekuensi berupa teks bebas ("1 tablet", "3x sehari"),
+// jumlah dihitung dalam satuan obat
+type ResepItem struct {
+	ID          int            `gorm:"primaryKey;column:id_resep_item"`
+	ResepID     int            `gorm:"column:id_resep;index"`
+	ObatID      int            `gorm:"column:id_obat;index"`
+	Dosis       string         `gorm:"column:dosis"`
+	Frekuensi   string         `gorm:"column:frekuensi"`
+	DurasiHari  int            `gorm:"column:durasi_hari"`
+	Rute        string         `gorm:"column:rute"`
+	Jumlah      int            `gorm:"column:jumlah"`
+	AturanPakai sql.NullString `gorm:"column:aturan_pakai"`
+	Urutan      int            `gorm:"column:urutan"`
+
+	Obat Obat `gorm:"foreignKey:ObatID"`
+}
+
+func (ResepItem) TableName() string { return "resep_item" }
+
+// peringatan alergi atau interaksi yang sudah dikonfirmasi dokter saat resep ditulis,
+// disimpan apa adanya supaya tetap terbaca walaupun data alergi atau tabel interaksi berubah
+type ResepPeringatan struct {
+	ID      int    `gorm:"primaryKey;column:id_resep_peringatan"`
+	ResepID int    `gorm:"column:id_resep;index"`
+	Kode    string `gorm:"column:kode"`
+	Jenis   string `gorm:"column:jenis"`
+	Tingkat string `gorm:"column:tingkat"`
+	Pesan   string `gorm:"column:pesan"`
+}
+
+func (ResepPeringatan) TableName() string { return "resep_peringatan" }
+
+// hasil cek alergi dan interaksi. Kode tetap sama selama obat dan data alergi atau interaksinya
+// sama, client mengirim ulang kode ini di konfirmasi_peringatan untuk menyimpan resep
+type PeringatanResep struct {
+	Kode    string `json:"kode"`
+	Jenis   string `json:"jenis"`
+	Tingkat string `json:"tingkat"`
+	Pesan   string `json:"pesan"`
+	ObatIDs []int  `json:"obat_ids,omitempty"`
+}
+
+func (p PeringatanResep) ToModel() ResepPeringatan {
+	return ResepPeringatan{Kode: p.Kode, Jenis: p.Jenis, Tingkat: p.Tingkat, Pesan: p.Pesan}
+}
+
+type CreateResepRequest struct {
+	Catatan string             `json:"catatan,omitempty" binding:"omitempty,max=255,sanitize"`
+	Items   []ResepItemRequest `json:"items" binding:"required,min=1,dive"`
+	// kode peringatan yang sudah dibaca dokter, resep ditolak bila masih ada peringatan
+	// yang kodenya tidak dikirim
+	KonfirmasiPeringatan []string `json:"konfirmasi_peringatan,omitempty" binding:"omitempty,max=50,dive,max=100"`
+}
+
+type ResepItemRequest struct {
+	ObatID      int    `json:"obat_id" binding:"required,gt=0"`
+	Dosis       string `json:"dosis" binding:"required,max=50,sanitize"`
+	Frekuensi   string `json:"frekuensi" binding:"required,max=50,sanitize"`
+	DurasiHari  int    `json:"durasi_hari" binding:"required,min=1,max=365"`
+	Rute        string `json:"rute" binding:"required,oneof=oral sublingual bukal topikal inhalasi nasal mata telinga rektal vaginal iv im sc"`
+	Jumlah      int    `json:"jumlah" binding:"required,min=1,max=1000"`
+	AturanPakai string `json:"aturan_pakai,omitempty" binding:"omitempty,max=100,sanitize"`
+}
+
+func (req *CreateResepRequest) ToModel(pemeriksaanID int) Resep {
+	resep := Resep{
+		PemeriksaanID: pemeriksaanID,
+		Status:        StatusResepMenunggu,
+		Catatan:       sql.NullString{String: req.Catatan, Valid: req.Catatan != ""},
+		Items:         make([]ResepItem, 0, len(req.Items)),
+	}
+	for i, item := range req.Items {
+		resep.Items = append(resep.Items, ResepItem{
+			ObatID:      item.ObatID,
+			Dosis:       item.Dosis,
+			Frekuensi:   item.Frekuensi,
+			DurasiHari:  item.DurasiHari,
+			Rute:        item.Rute,
+			Jumlah:      item.Jumlah,
+			AturanPakai: sql.NullString{String: item.AturanPakai, Valid: item.AturanPakai != ""},
+			Urutan:      i + 1,
+		})
+	}
+	return resep
+}
+
+type ResepResponse struct {
+	ID             int                 `json:"id"`
+	PemeriksaanID  int                 `json:"pemeriksaan_id"`
+	Pasien         PasienInfo          `json:"pasien"`
+	Dokter         PetugasInfo         `json:"dokter"`
+	Status         string              `json:"status"`
+	Catatan        string              `json:"catatan,omitempty"`
+	Items          []ResepItemResponse `json:"items"`
+	Peringatan     []PeringatanResep   `json:"peringatan,omitempty"`
+	DiserahkanAt   *time.Time          `json:"diserahkan_at,omitempty"`
+	DiserahkanOleh *PetugasInfo        `json:"diserahkan_oleh,omitempty"`
+	CreatedAt      time.Time           `json:"created_at"`
+}
+
+type ResepItemResponse struct {
+	Obat        ObatInfo `json:"obat"`
+	Dosis       string   `json:"dosis"`
+	Frekuensi   string   `json:"frekuensi"`
+	DurasiHari  int      `json:"durasi_hari"`
+	Rute        string   `json:"rute"`
+	Jumlah      int      `json:"jumlah"`
+	AturanPakai string   `json:"aturan_pakai,omitempty"`
+	Urutan      int      `json:"urutan"`
+}
+
+type ObatInfo struct {
+	ID       int    `json:"id"`
+	Kode     string `json:"kode"`
+	Nama     string `json:"nama"`
+	Kekuatan string `json:"kekuatan,omitempty"`
+	Satuan   string `json:"satuan"`
+}
+
+func ToObatInfo(o Obat) ObatInfo {
+	return ObatInfo{
+		ID:       o.ID,
+		Kode:     o.KodeObat,
+		Nama:     o.NamaObat,
+		Kekuatan: o.Kekuatan.String,
+		Satuan:   o.Satuan,
+	}
+}
+
+func ToResepResponse(r Resep) ResepResponse {
+	resp := ResepResponse{
+		ID:            r.ID,
+		PemeriksaanID: r.PemeriksaanID,
+		Pasien: PasienInfo{
+			ID:           r.Pasien.ID,
+			Nama:         r.Pasien.NamaPasien,
+			NoRekamMedis: r.Pasien.NoRekamMedis.String,
+		},
+		Dokter:    PetugasInfo{ID: r.Dokter.ID, Nama: r.Dokter.Nama},
+		Status:    r.Status,
+		Catatan:   r.Catatan.String,
+		Items:     make([]ResepItemResponse, 0, len(r.Items)),
+		CreatedAt: r.CreatedAt,
+	}
+	for _, item := range r.Items {
+		resp.Items = append(resp.Items, ResepItemResponse{
+			Obat:        ToObatInfo(item.Obat),
+			Dosis:       item.Dosis,
+			Frekuensi:   item.Frekuensi,
+			DurasiHari:  item.DurasiHari,
+			Rute:        item.Rute,
+			Jumlah:      item.Jumlah,
+			AturanPakai: item.AturanPakai.String,
+			Urutan:      item.Urutan,
+		})
+	}
+	for _, p := range r.Peringatan {
+		resp.Peringatan = append(resp.Peringatan, PeringatanResep{Kode: p.Kode, Jenis: p.Jenis, Tingkat: p.Tingkat, Pesan: p.Pesan})
+	}
+	if r.DiserahkanAt.Valid {
+		resp.DiserahkanAt = &r.DiserahkanAt.Time
+	}
+	if r.DiserahkanOleh.Valid {
+		resp.DiserahkanOleh = &PetugasInfo{ID: r.Penyerah.ID, Nama: r.Penyerah.Nama, Role: r.Penyerah.Role}
+	}
+	return resp
+}
+
+func ToResepResponseList(list []Resep) []ResepResponse {
+	responses := make([]ResepResponse, 0, len(list))
+	for _, r := range list {
+		responses = append(responses, ToResepResponse(r))
+	}
+	return responses
+}
@@ -0,0 +1,235 @@
+package model
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tipe hasil pemeriksaan lab. Hanya hasil numerik yang dibandingkan dengan rentang rujukan,
+// hasil kategori dibandingkan dengan daftar nilai normal
+const (
+	TipeHasilNumerik  = "numerik"
+	TipeHasilKategori = "kategori"
+	TipeHasilTeks     = "teks"
+)
+
+// flag hasil lab. HH dan LL berarti hasil melewati batas kritis
+const (
+	FlagLabTinggi       = "H"
+	FlagLabRendah       = "L"
+	FlagLabKritisTinggi = "HH"
+	FlagLabKritisRendah = "LL"
+	FlagLabAbnormal     = "A"
+)
+
+// rentang rujukan numerik untuk satu jenis pemeriksaan. Jenis kelamin dan batas umur yang
+// kosong berlaku untuk semua pasien, umur dihitung dalam hari supaya rentang neonatus bisa
+// dibedakan. Batas umur maksimum tidak termasuk
+type RentangRujukanLab struct {
+	ID                 int             `json:"id" gorm:"primaryKey;column:id_rentang_rujukan"`
+	JenisPemeriksaanID int             `json:"-" gorm:"column:id_jenis_pemeriksaan;index"`
+	JenisKelamin       sql.NullString  `json:"jenis_kelamin" gorm:"column:jenis_kelamin"`
+	UmurMinHari        sql.NullInt64   `json:"umur_min_hari" gorm:"column:umur_min_hari"`
+	UmurMaksHari       sql.NullInt64   `json:"umur_maks_hari" gorm:"column:umur_maks_hari"`
+	BatasBawah         sql.NullFloat64 `json:"batas_bawah" gorm:"column:batas_bawah"`
+	BatasAtas          sql.NullFloat64 `json:"batas_atas" gorm:"column:batas_atas"`
+	KritisBawah        sql.NullFloat64 `json:"kritis_bawah" gorm:"column:kritis_bawah"`
+	KritisAtas         sql.NullFloat64 `json:"kritis_atas" gorm:"column:kritis_atas"`
+}
+
+func (RentangRujukanLab) TableName() string {
+	return "rentang_rujukan_lab"
+}
+
+// umurHari negatif berarti tanggal lahir tidak diketahui, hanya rentang tanpa batas umur yang cocok
+func (r RentangRujukanLab) Cocok(jenisKelamin string, umurHari int) bool {
+	if r.JenisKelamin.Valid && r.JenisKelamin.String != jenisKelamin {
+		return false
+	}
+	if (r.UmurMinHari.Valid || r.UmurMaksHari.Valid) && umurHari < 0 {
+		return false
+	}
+	if r.UmurMinHari.Valid && int64(umurHari) < r.UmurMinHari.Int64 {
+		return false
+	}
+	if r.UmurMaksHari.Valid && int64(umurHari) >= r.UmurMaksHari.Int64 {
+		return false
+	}
+	return true
+}
+
+func (r RentangRujukanLab) Flag(nilai float64) string {
+	switch {
+	case r.KritisBawah.Valid && nilai < r.KritisBawah.Float64:
+		return FlagLabKritisRendah
+	case r.KritisAtas.Valid && nilai > r.KritisAtas.Float64:
+		return FlagLabKritisTinggi
+	case r.BatasBawah.Valid && nilai < r.BatasBawah.Float64:
+		return FlagLabRendah
+	case r.BatasAtas.Valid && nilai > r.BatasAtas.Float64:
+		return FlagLabTinggi
+	}
+	return ""
+}
+
+// teks rentang yang disalin ke hasil, misalnya "12 - 16", "< 200" atau "> 40"
+func (r RentangRujukanLab) Teks() string {
+	switch {
+	case r.BatasBawah.Valid && r.BatasAtas.Valid:
+		return fmt.Sprintf("%s - %s", formatAngka(r.BatasBawah.Float64), formatAngka(r.BatasAtas.Float64))
+	case r.BatasAtas.Valid:
+		return "< " + formatAngka(r.BatasAtas.Float64)
+	case r.BatasBawah.Valid:
+		return "> " + formatAngka(r.BatasBawah.Float64)
+	}
+	return ""
+}
+
+// rentang yang paling spesifik menang: yang khusus jenis kelamin lebih dulu, lalu rentang
+// umur yang paling sempit
+func (j JenisPemeriksaanLab) PilihRujukan(jenisKelamin string, umurHari int) (RentangRujukanLab, bool) {
+	var terpilih RentangRujukanLab
+	found := false
+	for _, r := range j.Rujukan {
+		if !r.Cocok(jenisKelamin, umurHari) {
+			continue
+		}
+		if !found || lebihSpesifik(r, terpilih) {
+			terpilih = r
+			found = true
+		}
+	}
+	return terpilih, found
+}
+
+func lebihSpesifik(a, b RentangRujukanLab) bool {
+	if a.JenisKelamin.Valid != b.JenisKelamin.Valid {
+		return a.JenisKelamin.Valid
+	}
+	return lebarUmur(a) < lebarUmur(b)
+}
+
+func lebarUmur(r RentangRujukanLab) int64 {
+	awal, akhir := int64(0), int64(1<<62)
+	if r.UmurMinHari.Valid {
+		awal = r.UmurMinHari.Int64
+	}
+	if r.UmurMaksHari.Valid {
+		akhir = r.UmurMaksHari.Int64
+	}
+	return akhir - awal
+}
+
+func (j JenisPemeriksaanLab) DaftarPilihan() []string {
+	return splitDaftar(j.PilihanHasil)
+}
+
+func (j JenisPemeriksaanLab) DaftarNilaiNormal() []string {
+	return splitDaftar(j.NilaiNormal)
+}
+
+// hasil numerik ditulis sebagai angka biasa dengan titik atau koma sebagai pemisah desimal,
+// tanpa pemisah ribuan
+func ParseNilaiNumerik(text string) (float64, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" || strings.Count(text, ",")+strings.Count(text, ".") > 1 {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(strings.Replace(text, ",", ".", 1), 64)
+	return value, err == nil
+}
+
+// umur dalam hari pada tanggal at, -1 bila tanggal lahir tidak diisi
+func UmurHari(tanggalLahir time.Time, at time.Time) int {
+	if tanggalLahir.IsZero() || at.Before(tanggalLahir) {
+		return -1
+	}
+	lahir := time.Date(tanggalLahir.Year(), tanggalLahir.Month(), tanggalLahir.Day(), 0, 0, 0, 0, time.UTC)
+	hari := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	return int(hari.Sub(lahir).Hours() / 24)
+}
+
+func formatAngka(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func splitDaftar(value sql.NullString) []string {
+	if !value.Valid {
+		return nil
+	}
+	var list []string
+	for _, item := range strings.Split(value.String, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func joinDaftar(list []string) sql.NullString {
+	var cleaned []string
+	for _, item := range list {
+		if item = strings.TrimSpace(item); item != "" {
+			cleaned = append(cleaned, item)
+		}
+	}
+	return sql.NullString{String: strings.Join(cleaned, ","), Valid: len(cleaned) > 0}
+}
+
+// batas umur dan nilai yang kosong dikirim sebagai null
+type RentangRujukanLabRequest struct {
+	JenisKelamin string   `json:"jenis_kelamin,omitempty" binding:"omitempty,oneof=L P"`
+	UmurMinHari  *int     `json:"umur_min_hari,omitempty" binding:"omitempty,gte=0"`
+	UmurMaksHari *int     `json:"umur_maks_hari,omitempty" binding:"omitempty,gt=0"`
+	BatasBawah   *float64 `json:"batas_bawah,omitempty"`
+	BatasAtas    *float64 `json:"batas_atas,omitempty"`
+	KritisBawah  *float64 `json:"kritis_bawah,omitempty"`
+	KritisAtas   *float64 `json:"kritis_atas,omitempty"`
+}
+
+func (req RentangRujukanLabRequest) ToModel() RentangRujukanLab {
+	return RentangRujukanLab{
+		JenisKelamin: sql.NullString{String: req.JenisKelamin, Valid: req.JenisKelamin != ""},
+		UmurMinHari:  nullInt64(req.UmurMinHari),
+		UmurMaksHari: nullInt64(req.UmurMaksHari),
+		BatasBawah:   nullFloat64(req.BatasBawah),
+		BatasAtas:    nullFloat64(req.BatasAtas),
+		KritisBawah:  nullFloat64(req.KritisBawah),
+		KritisAtas:   nullFloat64(req.KritisAtas),
+	}
+}
+
+func toRentangRujukanList(reqs []RentangRujukanLabRequest) []RentangRujukanLab {
+	list := make([]RentangRujukanLab, 0, len(reqs))
+	for _, req := range reqs {
+		list = append(list, req.ToModel())
+	}
+	return list
+}
+
+type RentangRujukanLabResponse struct {
+	ID           int      `json:"id"`
+	JenisKelamin string   `json:"jenis_kelamin,omitempty"`
+	UmurMinHari  *int64   `json:"umur_min_hari,omitempty"`
+	UmurMaksHari *int64   `json:"umur_maks_hari,omitempty"`
+	BatasBawah   *float64 `json:"batas_bawah,omitempty"`
+	BatasAtas    *float64 `json:"batas_atas,omitempty"`
+	KritisBawah  *float64 `json:"kritis_bawah,omitempty"`
+	KritisAtas   *float64 `json:"kritis_atas,omitempty"`
+}
+
+func ToRentangRujukanLabResponse(r RentangRujukanLab) RentangRujukanLabResponse {
+	return RentangRujukanLabResponse{
+		ID:           r.ID,
+		JenisKelamin: r.JenisKelamin.String,
+		UmurMinHari:  int64Ptr(r.UmurMinHari),
+		UmurMaksHari: int64Ptr(r.UmurMaksHari),
+		BatasBawah:   float64Ptr(r.BatasBawah),
+		BatasAtas:    float64Ptr(r.BatasAtas),
+		KritisBawah:  float64Ptr(r.KritisBawah),
+		KritisAtas:   float64Ptr(r.KritisAtas),
+	}
+}
@@ -0,0 +1,177 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	JenisMutasiMasuk       = "masuk"
+	JenisMutasiKeluar      = "keluar"
+	JenisMutasiPenyesuaian = "penyesuaian"
+)
+
+// stok satu obat per nomor batch dari pemasok. Sisa berkurang saat resep diserahkan,
+// batch yang sudah lewat tanggal kedaluwarsa tidak ikut dipakai
+type StokBatch struct {
+	ID                 int       `gorm:"primaryKey;column:id_stok_batch"`
+	ObatID             int       `gorm:"column:id_obat;uniqueIndex:idx_stok_batch_obat_nomor"`
+	NomorBatch         string    `gorm:"column:nomor_batch;uniqueIndex:idx_stok_batch_obat_nomor"`
+	TanggalKedaluwarsa time.Time `gorm:"column:tanggal_kedaluwarsa;type:date;index"`
+	Sisa               int       `gorm:"column:sisa"`
+	CreatedAt          time.Time `gorm:"column:created_at"`
+	UpdatedAt          time.Time `gorm:"column:updated_at"`
+
+	Obat Obat `gorm:"foreignKey:ObatID"`
+}
+
+func (StokBatch) TableName() string { return "stok_batch" }
+
+// pergerakan stok yang tidak pernah diubah. Jumlah bertanda: positif untuk stok masuk,
+// negatif untuk stok keluar, sehingga sisa batch sama dengan total mutasinya
+type StokMutasi struct {
+	ID          int            `gorm:"primaryKey;column:id_stok_mutasi"`
+	StokBatchID int            `gorm:"column:id_stok_batch;index"`
+	ObatID      int            `gorm:"column:id_obat;index"`
+	Jenis       string         `gorm:"column:jenis"`
+	Jumlah      int            `gorm:"column:jumlah"`
+	ResepID     sql.NullInt64  `gorm:"column:id_resep;index"`
+	ResepItemID sql.NullInt64  `gorm:"column:id_resep_item"`
+	Keterangan  sql.NullString `gorm:"column:keterangan"`
+	DibuatOleh  sql.NullInt64  `gorm:"column:dibuat_oleh"`
+	CreatedAt   time.Time      `gorm:"column:created_at;index"`
+
+	StokBatch StokBatch `gorm:"foreignKey:StokBatchID"`
+	Obat      Obat      `gorm:"foreignKey:ObatID"`
+	Petugas   Petugas   `gorm:"foreignKey:DibuatOleh"`
+}
+
+func (StokMutasi) TableName() string { return "stok_mutasi" }
+
+// penerimaan obat dari pemasok. Nomor batch yang sudah ada untuk obat yang sama
+// menambah sisa batch tersebut
+type StokMasukRequest struct {
+	ObatID             int    `json:"obat_id" binding:"required,gt=0"`
+	NomorBatch         string `json:"nomor_batch" binding:"required,max=50,sanitize"`
+	TanggalKedaluwarsa string `json:"tanggal_kedaluwarsa" binding:"required,datetime=2006-01-02"`
+	Jumlah             int    `json:"jumlah" binding:"required,min=1,max=1000000"`
+	Keterangan         string `json:"keterangan,omitempty" binding:"omitempty,max=255,sanitize"`
+}
+
+func (req *StokMasukRequest) ToModel() (StokBatch, StokMutasi) {
+	kedaluwarsa, _ := time.Parse("2006-01-02", req.TanggalKedaluwarsa)
+	batch := StokBatch{
+		ObatID:             req.ObatID,
+		NomorBatch:         req.NomorBatch,
+		TanggalKedaluwarsa: kedaluwarsa,
+		Sisa:               req.Jumlah,
+	}
+	mutasi := StokMutasi{
+		ObatID:     req.ObatID,
+		Jenis:      JenisMutasiMasuk,
+		Jumlah:     req.Jumlah,
+		Keterangan: sql.NullString{String: req.Keterangan, Valid: req.Keterangan != ""},
+	}
+	return batch, mutasi
+}
+
+// koreksi stok hasil stock opname, obat rusak, atau pemusnahan obat kedaluwarsa.
+// Jumlah negatif mengurangi sisa batch
+type PenyesuaianStokRequest struct {
+	StokBatchID int    `json:"stok_batch_id" binding:"required,gt=0"`
+	Jumlah      int    `json:"jumlah" binding:"required,ne=0,min=-1000000,max=1000000"`
+	Keterangan  string `json:"keterangan" binding:"required,min=5,max=255,sanitize"`
+}
+
+type ParamsPeringatanStok struct {
+	// batas hari sebelum kedaluwarsa, default 90 hari
+	Hari int `form:"hari" binding:"omitempty,min=1,max=730"`
+}
+
+type StokBatchResponse struct {
+	ID                 int       `json:"id"`
+	Obat               ObatInfo  `json:"obat"`
+	NomorBatch         string    `json:"nomor_batch"`
+	TanggalKedaluwarsa string    `json:"tanggal_kedaluwarsa"`
+	Sisa               int       `json:"sisa"`
+	Kedaluwarsa        bool      `json:"kedaluwarsa"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+func ToStokBatchResponse(b StokBatch, today time.Time) StokBatchResponse {
+	return StokBatchResponse{
+		ID:                 b.ID,
+		Obat:               ToObatInfo(b.Obat),
+		NomorBatch:         b.NomorBatch,
+		TanggalKedaluwarsa: b.TanggalKedaluwarsa.Format("2006-01-02"),
+		Sisa:               b.Sisa,
+		Kedaluwarsa:        b.IsKedaluwarsa(today),
+		UpdatedAt:          b.UpdatedAt,
+	}
+}
+
+func ToStokBatchResponseList(list []StokBatch, today time.Time) []StokBatchResponse {
+	responses := make([]StokBatchResponse, 0, len(list))
+	for _, b := range list {
+		responses = append(responses, ToStokBatchResponse(b, today))
+	}
+	return responses
+}
+
+// batch masih boleh dipakai sampai hari tanggal kedaluwarsanya
+func (b StokBatch) IsKedaluwarsa(today time.Time) bool {
+	return b.TanggalKedaluwarsa.Format("2006-01-02") < today.Format("2006-01-02")
+}
+
+type StokMutasiResponse struct {
+	ID         int          `json:"id"`
+	Obat       ObatInfo     `json:"obat"`
+	NomorBatch string       `json:"nomor_batch"`
+	Jenis      string       `json:"jenis"`
+	Jumlah     int          `json:"jumlah"`
+	ResepID    *int64       `json:"resep_id,omitempty"`
+	Keterangan string       `json:"keterangan,omitempty"`
+	DibuatOleh *PetugasInfo `json:"dibuat_oleh,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+func ToStokMutasiResponseList(list []StokMutasi) []StokMutasiResponse {
+	responses := make([]StokMutasiResponse, 0, len(list))
+	for _, m := range list {
+		resp := StokMutasiResponse{
+			ID:         m.ID,
+			Obat:       ToObatInfo(m.Obat),
+			NomorBatch: m.StokBatch.NomorBatch,
+			Jenis:      m.Jenis,
+			Jumlah:     m.Jumlah,
+			Keterangan: m.Keterangan.String,
+			CreatedAt:  m.CreatedAt,
+		}
+		if m.ResepID.Valid {
+			id := m.ResepID.Int64
+			resp.ResepID = &id
+		}
+		if m.DibuatOleh.Valid {
+			resp.DibuatOleh = &PetugasInfo{ID: m.Petugas.ID, Nama: m.Petugas.Nama, Role: m.Petugas.Role}
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+// total sisa batch yang belum kedaluwarsa per obat
+type StokObat struct {
+	ObatID      int    `json:"obat_id"`
+	KodeObat    string `json:"kode_obat"`
+	NamaObat    string `json:"nama_obat"`
+	Satuan      string `json:"satuan"`
+	TotalStok   int    `json:"total_stok"`
+	StokMinimum int    `json:"stok_minimum"`
+}
+
+type PeringatanStokResponse struct {
+	StokMenipis          []StokObat          `json:"stok_menipis"`
+	MendekatiKedaluwarsa []StokBatchResponse `json:"mendekati_kedaluwarsa"`
+	SudahKedaluwarsa     []StokBatchResponse `json:"sudah_kedaluwarsa"`
+	BatasHariKedaluwarsa int                 `json:"batas_hari_kedaluwarsa"`
+}
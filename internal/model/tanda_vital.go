@@ -0,0 +1,163 @@
+package model
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// tanda vital terstruktur, disimpan sebagai kolom biasa pada tabel pemeriksaan
+type TandaVital struct {
+	Sistolik    sql.NullInt64   `json:"tekanan_sistolik" gorm:"column:tekanan_sistolik"`
+	Diastolik   sql.NullInt64   `json:"tekanan_diastolik" gorm:"column:tekanan_diastolik"`
+	Nadi        sql.NullInt64   `json:"nadi" gorm:"column:nadi_bpm"`
+	Suhu        sql.NullFloat64 `json:"suhu" gorm:"column:suhu_celsius"`
+	BeratBadan  sql.NullFloat64 `json:"berat_badan" gorm:"column:berat_badan_kg"`
+	TinggiBadan sql.NullFloat64 `json:"tinggi_badan" gorm:"column:tinggi_badan_cm"`
+	SpO2        sql.NullInt64   `json:"spo2" gorm:"column:spo2"`
+	LajuNapas   sql.NullInt64   `json:"laju_napas" gorm:"column:laju_napas"`
+}
+
+// BMI dihitung dari berat (kg) dan tinggi (cm), dibulatkan satu angka desimal
+func (v TandaVital) BMI() (float64, bool) {
+	if !v.BeratBadan.Valid || !v.TinggiBadan.Valid || v.TinggiBadan.Float64 <= 0 {
+		return 0, false
+	}
+	tinggiMeter := v.TinggiBadan.Float64 / 100
+	bmi := v.BeratBadan.Float64 / (tinggiMeter * tinggiMeter)
+	return math.Round(bmi*10) / 10, true
+}
+
+// batas fisiologis yang masih masuk akal, di luar ini hampir pasti salah input
+type TandaVitalRequest struct {
+	Sistolik    *int     `json:"tekanan_sistolik,omitempty" binding:"required_with=Diastolik,omitempty,min=50,max=300,gtfield=Diastolik"`
+	Diastolik   *int     `json:"tekanan_diastolik,omitempty" binding:"required_with=Sistolik,omitempty,min=20,max=200"`
+	Nadi        *int     `json:"nadi,omitempty" binding:"omitempty,min=20,max=250"`
+	Suhu        *float64 `json:"suhu,omitempty" binding:"omitempty,min=30,max=45"`
+	BeratBadan  *float64 `json:"berat_badan,omitempty" binding:"omitempty,min=0.5,max=500"`
+	TinggiBadan *float64 `json:"tinggi_badan,omitempty" binding:"omitempty,min=30,max=250"`
+	SpO2        *int     `json:"spo2,omitempty" binding:"omitempty,min=50,max=100"`
+	LajuNapas   *int     `json:"laju_napas,omitempty" binding:"omitempty,min=4,max=80"`
+}
+
+func (req TandaVitalRequest) ToModel() TandaVital {
+	return TandaVital{
+		Sistolik:    nullInt64(req.Sistolik),
+		Diastolik:   nullInt64(req.Diastolik),
+		Nadi:        nullInt64(req.Nadi),
+		Suhu:        nullFloat64(req.Suhu),
+		BeratBadan:  nullFloat64(req.BeratBadan),
+		TinggiBadan: nullFloat64(req.TinggiBadan),
+		SpO2:        nullInt64(req.SpO2),
+		LajuNapas:   nullInt64(req.LajuNapas),
+	}
+}
+
+type TandaVitalResponse struct {
+	TekananDarah string   `json:"tekanan_darah,omitempty"`
+	Sistolik     *int64   `json:"tekanan_sistolik,omitempty"`
+	Diastolik    *int64   `json:"tekanan_diastolik,omitempty"`
+	Nadi         *int64   `json:"nadi,omitempty"`
+	Suhu         *float64 `json:"suhu,omitempty"`
+	BeratBadan   *float64 `json:"berat_badan,omitempty"`
+	TinggiBadan  *float64 `json:"tinggi_badan,omitempty"`
+	SpO2         *int64   `json:"spo2,omitempty"`
+	LajuNapas    *int64   `json:"laju_napas,omitempty"`
+	BMI          *float64 `json:"bmi,omitempty"`
+}
+
+func ToTandaVitalResponse(v TandaVital) TandaVitalResponse {
+	resp := TandaVitalResponse{
+		Sistolik:    int64Ptr(v.Sistolik),
+		Diastolik:   int64Ptr(v.Diastolik),
+		Nadi:        int64Ptr(v.Nadi),
+		Suhu:        float64Ptr(v.Suhu),
+		BeratBadan:  float64Ptr(v.BeratBadan),
+		TinggiBadan: float64Ptr(v.TinggiBadan),
+		SpO2:        int64Ptr(v.SpO2),
+		LajuNapas:   int64Ptr(v.LajuNapas),
+	}
+	if v.Sistolik.Valid && v.Diastolik.Valid {
+		resp.TekananDarah = fmt.Sprintf("%d/%d", v.Sistolik.Int64, v.Diastolik.Int64)
+	}
+	if bmi, ok := v.BMI(); ok {
+		resp.BMI = &bmi
+	}
+	return resp
+}
+
+var (
+	tekananDarahPattern = regexp.MustCompile(`(\d{2,3})\s*/\s*(\d{2,3})`)
+	angkaPattern        = regexp.MustCompile(`\d+(?:[.,]\d+)?`)
+)
+
+// membaca tanda vital lama yang berupa teks bebas ("120 / 80 mmHg", "36,5 C", "60kg").
+// Nilai yang tidak bisa dibaca atau di luar batas fisiologis dibiarkan kosong
+func ParseTandaVitalLama(nadi, tekananDarah, suhu, beratBadan string) TandaVital {
+	var v TandaVital
+
+	if match := tekananDarahPattern.FindStringSubmatch(tekananDarah); match != nil {
+		sistolik, _ := strconv.ParseInt(match[1], 10, 64)
+		diastolik, _ := strconv.ParseInt(match[2], 10, 64)
+		if inRange(float64(sistolik), 50, 300) && inRange(float64(diastolik), 20, 200) && sistolik > diastolik {
+			v.Sistolik = sql.NullInt64{Int64: sistolik, Valid: true}
+			v.Diastolik = sql.NullInt64{Int64: diastolik, Valid: true}
+		}
+	}
+	if value, ok := parseAngka(nadi); ok && value == math.Trunc(value) && inRange(value, 20, 250) {
+		v.Nadi = sql.NullInt64{Int64: int64(value), Valid: true}
+	}
+	if value, ok := parseAngka(suhu); ok && inRange(value, 30, 45) {
+		v.Suhu = sql.NullFloat64{Float64: value, Valid: true}
+	}
+	if value, ok := parseAngka(beratBadan); ok && inRange(value, 0.5, 500) {
+		v.BeratBadan = sql.NullFloat64{Float64: value, Valid: true}
+	}
+	return v
+}
+
+func parseAngka(text string) (float64, bool) {
+	match := angkaPattern.FindString(text)
+	if match == "" {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(strings.Replace(match, ",", ".", 1), 64)
+	return value, err == nil
+}
+
+func inRange(value, min, max float64) bool {
+	return value >= min && value <= max
+}
+
+func nullInt64(value *int) sql.NullInt64 {
+	if value == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*value), Valid: true}
+}
+
+func nullFloat64(value *float64) sql.NullFloat64 {
+	if value == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *value, Valid: true}
+}
+
+func int64Ptr(value sql.NullInt64) *int64 {
+	if !value.Valid {
+		return nil
+	}
+	v := value.Int64
+	return &v
+}
+
+func float64Ptr(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	v := value.Float64
+	return &v
+}
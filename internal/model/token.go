@@ -0,0 +1,46 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+type RefreshToken struct {
+	ID           int           `json:"id,omitempty" gorm:"primaryKey;column:id_refresh_token"`
+	PetugasID    int           `json:"petugas_id" gorm:"column:id_petugas;index"`
+	TokenHash    string        `json:"-" gorm:"column:token_hash;unique"`
+	TokenVersion int           `json:"token_version" gorm:"column:token_version"`
+	ExpiresAt    time.Time     `json:"expires_at" gorm:"column:expires_at"`
+	RevokedAt    sql.NullTime  `json:"revoked_at" gorm:"column:revoked_at"`
+	ReplacedByID sql.NullInt64 `json:"replaced_by_id" gorm:"column:replaced_by_id"`
+	CreatedAt    time.Time     `json:"created_at" gorm:"column:created_at"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_token"
+}
+
+// jti access token yang dicabut sebelum kedaluwarsa (logout)
+type RevokedToken struct {
+	JTI       string    `json:"jti" gorm:"primaryKey;column:jti"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"column:expires_at;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+func (RevokedToken) TableName() string {
+	return "revoked_token"
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
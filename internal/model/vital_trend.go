@@ -0,0 +1,148 @@
+package model
+
+import "time"
+
+const (
+	VitalMetricTekananDarah = "tekanan_darah"
+	VitalMetricNadi         = "nadi"
+	VitalMetricSuhu         = "suhu"
+	VitalMetricBeratBadan   = "berat_badan"
+)
+
+const (
+	VitalFlagHigh = "H"
+	VitalFlagLow  = "L"
+)
+
+type ParamsVitalTrend struct {
+	From   string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To     string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+	Metric string `form:"metric" binding:"omitempty,oneof=tekanan_darah nadi suhu berat_badan"`
+}
+
+func (p ParamsVitalTrend) Includes(metric string) bool {
+	return p.Metric == "" || p.Metric == metric
+}
+
+type TekananDarahPoint struct {
+	PemeriksaanID int       `json:"pemeriksaan_id"`
+	Tanggal       time.Time `json:"tanggal"`
+	Sistolik      int64     `json:"sistolik"`
+	Diastolik     int64     `json:"diastolik"`
+	Flag          string    `json:"flag,omitempty"`
+}
+
+type VitalPoint struct {
+	PemeriksaanID int       `json:"pemeriksaan_id"`
+	Tanggal       time.Time `json:"tanggal"`
+	Nilai         float64   `json:"nilai"`
+	Flag          string    `json:"flag,omitempty"`
+}
+
+// seri yang tidak diminta lewat parameter metric bernilai null
+type VitalTrendResponse struct {
+	PasienID     int                 `json:"pasien_id"`
+	TekananDarah []TekananDarahPoint `json:"tekanan_darah"`
+	Nadi         []VitalPoint        `json:"nadi"`
+	Suhu         []VitalPoint        `json:"suhu"`
+	BeratBadan   []VitalPoint        `json:"berat_badan"`
+}
+
+// batas normal dewasa; tekanan darah tinggi mengikuti ambang hipertensi 140/90
+func FlagTekananDarah(sistolik, diastolik int64) string {
+	switch {
+	case sistolik >= 140 || diastolik >= 90:
+		return VitalFlagHigh
+	case sistolik < 90 || diastolik < 60:
+		return VitalFlagLow
+	}
+	return ""
+}
+
+func FlagNadi(nadi int64) string {
+	return flagRange(float64(nadi), 60, 100)
+}
+
+func FlagSuhu(suhu float64) string {
+	return flagRange(suhu, 36, 37.5)
+}
+
+// berat badan saja tidak punya batas normal, flag diambil dari BMI jika tinggi tersedia
+func FlagBeratBadan(v TandaVital) string {
+	bmi, ok := v.BMI()
+	if !ok {
+		return ""
+	}
+	switch {
+	case bmi >= 25:
+		return VitalFlagHigh
+	case bmi < 18.5:
+		return VitalFlagLow
+	}
+	return ""
+}
+
+func flagRange(value, low, high float64) string {
+	switch {
+	case value > high:
+		return VitalFlagHigh
+	case value < low:
+		return VitalFlagLow
+	}
+	return ""
+}
+
+// menyusun seri per metrik dari daftar pemeriksaan yang sudah terurut kronologis
+func ToVitalTrendResponse(pasienID int, pemeriksaanList []Pemeriksaan, params ParamsVitalTrend) VitalTrendResponse {
+	resp := VitalTrendResponse{PasienID: pasienID}
+	if params.Includes(VitalMetricTekananDarah) {
+		resp.TekananDarah = []TekananDarahPoint{}
+	}
+	if params.Includes(VitalMetricNadi) {
+		resp.Nadi = []VitalPoint{}
+	}
+	if params.Includes(VitalMetricSuhu) {
+		resp.Suhu = []VitalPoint{}
+	}
+	if params.Includes(VitalMetricBeratBadan) {
+		resp.BeratBadan = []VitalPoint{}
+	}
+
+	for _, p := range pemeriksaanList {
+		v := p.TandaVital
+		if resp.TekananDarah != nil && v.Sistolik.Valid && v.Diastolik.Valid {
+			resp.TekananDarah = append(resp.TekananDarah, TekananDarahPoint{
+				PemeriksaanID: p.ID,
+				Tanggal:       p.TanggalPemeriksaan,
+				Sistolik:      v.Sistolik.Int64,
+				Diastolik:     v.Diastolik.Int64,
+				Flag:          FlagTekananDarah(v.Sistolik.Int64, v.Diastolik.Int64),
+			})
+		}
+		if resp.Nadi != nil && v.Nadi.Valid {
+			resp.Nadi = append(resp.Nadi, VitalPoint{
+				PemeriksaanID: p.ID,
+				Tanggal:       p.TanggalPemeriksaan,
+				Nilai:         float64(v.Nadi.Int64),
+				Flag:          FlagNadi(v.Nadi.Int64),
+			})
+		}
+		if resp.Suhu != nil && v.Suhu.Valid {
+			resp.Suhu = append(resp.Suhu, VitalPoint{
+				PemeriksaanID: p.ID,
+				Tanggal:       p.TanggalPemeriksaan,
+				Nilai:         v.Suhu.Float64,
+				Flag:          FlagSuhu(v.Suhu.Float64),
+			})
+		}
+		if resp.BeratBadan != nil && v.BeratBadan.Valid {
+			resp.BeratBadan = append(resp.BeratBadan, VitalPoint{
+				PemeriksaanID: p.ID,
+				Tanggal:       p.TanggalPemeriksaan,
+				Nilai:         v.BeratBadan.Float64,
+				Flag:          FlagBeratBadan(v),
+			})
+		}
+	}
+	return resp
+}
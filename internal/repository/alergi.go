@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"gorm.io/gorm"
+)
+
+type AlergiRepository struct {
+	DB *gorm.DB
+}
+
+func NewAlergiRepository(db *gorm.DB) *AlergiRepository {
+	return &AlergiRepository{DB: db}
+}
+
+func (r *AlergiRepository) Create(alergi model.AlergiPasien) (model.AlergiPasien, error) {
+	result := r.DB.Create(&alergi)
+	return alergi, result.Error
+}
+
+func (r *AlergiRepository) GetAllByPasienID(pasienID int) ([]model.AlergiPasien, error) {
+	var list []model.AlergiPasien
+	err := r.DB.Where("id_pasien = ?", pasienID).Order("created_at ASC").Find(&list).Error
+	return list, err
+}
+
+// alergi dicari bersama id pasien supaya id alergi milik pasien lain dianggap tidak ada
+func (r *AlergiRepository) GetByID(pasienID int, id int) (model.AlergiPasien, error) {
+	var alergi model.AlergiPasien
+	result := r.DB.Where("id_pasien = ?", pasienID).First(&alergi, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return model.AlergiPasien{}, ErrNotFound
+		}
+		return model.AlergiPasien{}, result.Error
+	}
+	return alergi, nil
+}
+
+func (r *AlergiRepository) Update(pasienID int, id int, alergi model.AlergiPasien) (model.AlergiPasien, error) {
+	result := r.DB.Model(&model.AlergiPasien{}).
+		Where("id_alergi = ? AND id_pasien = ?", id, pasienID).
+		Updates(map[string]any{
+			"zat":               alergi.Zat,
+			"reaksi":            alergi.Reaksi,
+			"tingkat_keparahan": alergi.TingkatKeparahan,
+		})
+	if result.Error != nil {
+		return model.AlergiPasien{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.AlergiPasien{}, ErrNotFound
+	}
+	return r.GetByID(pasienID, id)
+}
+
+func (r *AlergiRepository) Delete(pasienID int, id int) error {
+	result := r.DB.Where("id_pasien = ?", pasienID).Delete(&model.AlergiPasien{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/franklindh/simedis-api/internal/model"
@@ -35,6 +37,31 @@ func (r *AntrianRepository) Create(antrian model.Antrian) (model.Antrian, error)
 	return r.GetByID(antrian.ID)
 }
 
+// nomor diambil dari antrian_sequence di transaksi yang sama dengan insert,
+// upsert mengunci baris sequence sehingga pendaftaran bersamaan tidak mendapat nomor yang sama
+func (r *AntrianRepository) CreateWithSequence(antrian model.Antrian, prefix string) (model.Antrian, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var nextNumber int
+		err := tx.Raw(`
+			INSERT INTO antrian_sequence (id_jadwal, last_number, updated_at)
+			VALUES (?, 1, NOW())
+			ON CONFLICT (id_jadwal) DO UPDATE
+			SET last_number = antrian_sequence.last_number + 1, updated_at = NOW()
+			RETURNING last_number`, antrian.JadwalID).Scan(&nextNumber).Error
+		if err != nil {
+			return err
+		}
+
+		antrian.NomorAntrian = model.FormatNomorAntrian(prefix, nextNumber)
+		return tx.Create(&antrian).Error
+	})
+	if err != nil {
+		return model.Antrian{}, err
+	}
+
+	return r.GetByID(antrian.ID)
+}
+
 func (r *AntrianRepository) GetAll(params ParamsGetAllAntrian) ([]model.Antrian, pagination.Metadata, error) {
 	var antrian []model.Antrian
 	var totalRecords int64
@@ -94,6 +121,68 @@ func (r *AntrianRepository) Update(id int, antrian model.Antrian) (model.Antrian
 	return r.GetByID(id)
 }
 
+// update hanya berhasil jika status masih sama dengan fromStatus, mencegah dua transisi bersamaan
+func (r *AntrianRepository) UpdateStatus(id int, fromStatus, toStatus string, actorID int, at time.Time) (model.Antrian, error) {
+	atColumn, byColumn, ok := model.AntrianTransitionColumns(toStatus)
+	if !ok {
+		return model.Antrian{}, fmt.Errorf("unknown antrian status %q", toStatus)
+	}
+
+	result := r.DB.Model(&model.Antrian{}).
+		Where("id_antrian = ?", id).
+		Where("status = ?", fromStatus).
+		Updates(map[string]any{
+			"status":     toStatus,
+			atColumn:     at,
+			byColumn:     sql.NullInt64{Int64: int64(actorID), Valid: actorID > 0},
+			"updated_at": at,
+		})
+	if result.Error != nil {
+		return model.Antrian{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.Antrian{}, ErrNotFound
+	}
+	return r.GetByID(id)
+}
+
+// memanggil antrian berikutnya pada jadwal: Gawat lebih dulu, lalu urut waktu daftar.
+// SKIP LOCKED membuat dua petugas yang memanggil bersamaan mendapat pasien berbeda
+func (r *AntrianRepository) CallNext(jadwalID int, actorID int, at time.Time) (model.Antrian, error) {
+	var antrianID int
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var antrian model.Antrian
+		result := tx.Raw(`
+			SELECT id_antrian FROM antrian
+			WHERE id_jadwal = ? AND status = ? AND deleted_at IS NULL
+			ORDER BY CASE WHEN prioritas = 'Gawat' THEN 0 ELSE 1 END, created_at ASC, id_antrian ASC
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED`, jadwalID, model.StatusAntrianMenunggu).Scan(&antrian)
+		if result.Error != nil {
+			return result.Error
+		}
+		if antrian.ID == 0 {
+			return ErrNotFound
+		}
+
+		antrianID = antrian.ID
+		return tx.Model(&model.Antrian{}).
+			Where("id_antrian = ?", antrian.ID).
+			Updates(map[string]any{
+				"status":         model.StatusAntrianDipanggil,
+				"dipanggil_at":   at,
+				"dipanggil_oleh": sql.NullInt64{Int64: int64(actorID), Valid: actorID > 0},
+				"updated_at":     at,
+			}).Error
+	})
+	if err != nil {
+		return model.Antrian{}, err
+	}
+
+	return r.GetByID(antrianID)
+}
+
 func (r *AntrianRepository) Delete(id int) error {
 	result := r.DB.Delete(&model.Antrian{}, id)
 	if result.RowsAffected == 0 {
@@ -108,7 +197,7 @@ func (r *AntrianRepository) CheckAntrian(pasienID, jadwalID int) (bool, error) {
 	result := r.DB.Model(&model.Antrian{}).
 		Where("id_pasien = ?", pasienID).
 		Where("id_jadwal = ?", jadwalID).
-		Where("status IN ?", []string{"Menunggu", "Menunggu Diagnosis"}).
+		Where("status IN ?", model.ActiveAntrianStatuses).
 		Count(&count)
 
 	if result.Error != nil {
@@ -131,7 +220,7 @@ func (r *AntrianRepository) CheckForOverlappingAntrian(pasienID int, tanggal tim
 		Where("jadwal.tanggal_praktik = ?", tanggal).
 		Where("jadwal.waktu_selesai > ?", waktuMulai).
 		Where("jadwal.waktu_mulai < ?", waktuSelesai).
-		Where("antrian.status IN ?", []string{"Menunggu", "Menunggu Diagnosis"}).
+		Where("antrian.status IN ?", model.ActiveAntrianStatuses).
 		Count(&count)
 
 	if result.Error != nil {
@@ -145,17 +234,17 @@ func (r *AntrianRepository) CheckForOverlappingAntrian(pasienID int, tanggal tim
 	return false, nil
 }
 
-func (r *AntrianRepository) CountTodayByJadwal(jadwalID int) (int64, error) {
-	var count int64
+func (r *AntrianRepository) GetUpcomingByPasienID(pasienID int) ([]model.Antrian, error) {
+	var antrian []model.Antrian
 
 	result := r.DB.Model(&model.Antrian{}).
-		Where("id_jadwal = ?", jadwalID).
-		Where("CAST(created_at AS DATE) = CURRENT_DATE").
-		Count(&count)
-
-	if result.Error != nil {
-		return 0, result.Error
-	}
+		Preload("Pasien").Preload("Jadwal.Poli").Preload("Jadwal.Petugas").
+		Joins("JOIN jadwal ON antrian.id_jadwal = jadwal.id_jadwal").
+		Where("antrian.id_pasien = ?", pasienID).
+		Where("jadwal.tanggal_praktik >= CURRENT_DATE").
+		Where("antrian.status IN ?", model.ActiveAntrianStatuses).
+		Order("jadwal.tanggal_praktik ASC, jadwal.waktu_mulai ASC").
+		Find(&antrian)
 
-	return count, nil
+	return antrian, result.Error
 }
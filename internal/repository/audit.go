@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/pkg/utils/pagination"
+	"gorm.io/gorm"
+)
+
+type ParamsGetAllAudit struct {
+	ActorID  int    `form:"actor_id" binding:"omitempty,gt=0"`
+	Action   string `form:"action" binding:"omitempty,oneof=read create update delete void"`
+	Entity   string `form:"entity" binding:"omitempty,sanitize"`
+	EntityID string `form:"entity_id" binding:"omitempty,sanitize"`
+	From     string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To       string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+	Page     int    `form:"page" binding:"omitempty,gt=0"`
+	PageSize int    `form:"pageSize" binding:"omitempty,gt=0"`
+}
+
+type AuditRepository struct {
+	DB *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{DB: db}
+}
+
+func (r *AuditRepository) Create(entry model.AuditLog) error {
+	return r.DB.Create(&entry).Error
+}
+
+func (r *AuditRepository) GetAll(params ParamsGetAllAudit) ([]model.AuditLog, pagination.Metadata, error) {
+	var logs []model.AuditLog
+	var totalRecords int64
+
+	db := r.DB.Model(&model.AuditLog{})
+
+	if params.ActorID > 0 {
+		db = db.Where("actor_id = ?", params.ActorID)
+	}
+	if params.Action != "" {
+		db = db.Where("action = ?", params.Action)
+	}
+	if params.Entity != "" {
+		db = db.Where("entity = ?", params.Entity)
+	}
+	if params.EntityID != "" {
+		db = db.Where("entity_id = ?", params.EntityID)
+	}
+	if params.From != "" {
+		db = db.Where("created_at >= ?", params.From)
+	}
+	if params.To != "" {
+		db = db.Where("created_at < CAST(? AS DATE) + INTERVAL '1 day'", params.To)
+	}
+
+	if err := db.Count(&totalRecords).Error; err != nil {
+		return nil, pagination.Metadata{}, err
+	}
+
+	metadata := pagination.CalculateMetadata(int(totalRecords), params.Page, params.PageSize)
+
+	db = db.Order("created_at DESC, id_audit_log DESC")
+	db = db.Limit(metadata.PageSize).Offset((metadata.CurrentPage - 1) * metadata.PageSize)
+
+	if err := db.Find(&logs).Error; err != nil {
+		return nil, pagination.Metadata{}, err
+	}
+	return logs, metadata, nil
+}
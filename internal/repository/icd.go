@@ -2,10 +2,12 @@ package repository
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/franklindh/simedis-api/internal/model"
 	"github.com/franklindh/simedis-api/pkg/utils/pagination"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ParamsGetAllIcd struct {
@@ -17,6 +19,8 @@ type ParamsGetAllIcd struct {
 	PageSize     int    `form:"pageSize" binding:"omitempty,gt=0"`
 }
 
+const importBatchSize = 500
+
 type IcdRepository struct {
 	DB *gorm.DB
 }
@@ -75,7 +79,12 @@ func (r *IcdRepository) GetAll(params ParamsGetAllIcd) ([]model.Icd, pagination.
 
 func (r *IcdRepository) GetByID(id int) (model.Icd, error) {
 	var icd model.Icd
-	result := r.DB.First(&icd, id)
+	result := r.DB.
+		Preload("KelompokBab").
+		Preload("KelompokBlok").
+		Preload("Induk").
+		Preload("Turunan", func(db *gorm.DB) *gorm.DB { return db.Order("kode_icd ASC") }).
+		First(&icd, id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return model.Icd{}, ErrNotFound
@@ -107,3 +116,93 @@ func (r *IcdRepository) Delete(id int) error {
 	}
 	return nil
 }
+
+// termasuk kode yang sudah dihapus, karena kode_icd tetap unik walau soft delete. Kode dicocokkan
+// tanpa membedakan huruf besar kecil agar baris lama seperti "a09" tetap ditemukan
+func (r *IcdRepository) GetByKodes(kodes []string) ([]model.Icd, error) {
+	var icds []model.Icd
+	upper := make([]string, 0, len(kodes))
+	for _, kode := range kodes {
+		upper = append(upper, strings.ToUpper(kode))
+	}
+	kodes = upper
+	for start := 0; start < len(kodes); start += importBatchSize {
+		end := min(start+importBatchSize, len(kodes))
+		var batch []model.Icd
+		if err := r.DB.Unscoped().Where("upper(kode_icd) IN ?", kodes[start:end]).Find(&batch).Error; err != nil {
+			return nil, err
+		}
+		icds = append(icds, batch...)
+	}
+	return icds, nil
+}
+
+// upsert bab/blok lalu kode ICD dalam satu transaksi, kode yang sebelumnya dihapus ikut dipulihkan.
+// Nama kelompok yang kosong di file tidak menimpa nama yang sudah tersimpan
+func (r *IcdRepository) Upsert(kelompok []model.IcdKelompok, icds []model.Icd) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if len(kelompok) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "kode"}},
+				DoUpdates: clause.Set{
+					{Column: clause.Column{Name: "nama"}, Value: gorm.Expr("COALESCE(NULLIF(EXCLUDED.nama, ''), icd_kelompok.nama)")},
+					{Column: clause.Column{Name: "jenis"}, Value: gorm.Expr("EXCLUDED.jenis")},
+					{Column: clause.Column{Name: "kode_induk"}, Value: gorm.Expr("COALESCE(EXCLUDED.kode_induk, icd_kelompok.kode_induk)")},
+					{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("EXCLUDED.updated_at")},
+				},
+			}).CreateInBatches(&kelompok, importBatchSize).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(icds) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "kode_icd"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"nama_penyakit", "deskripsi_penyakit", "status_icd", "bab", "blok", "kode_induk", "deleted_at", "updated_at",
+			}),
+		}).CreateInBatches(&icds, importBatchSize).Error
+	})
+}
+
+// urutan hasil: kode persis, awalan kode, awalan nama, lalu kemiripan nama (pg_trgm).
+// Indeks trigram dibuat oleh migrateKatalogSearch
+func (r *IcdRepository) Search(params model.ParamsSearchKatalog) ([]model.Icd, error) {
+	var icds []model.Icd
+	pattern := escapeLike(params.Query)
+	err := r.DB.
+		Where("status_icd = ?", "aktif").
+		Where("upper(kode_icd) LIKE upper(?) OR nama_penyakit ILIKE ? OR nama_penyakit % ?", pattern+"%", "%"+pattern+"%", params.Query).
+		Order(clause.OrderBy{Expression: gorm.Expr(`
+			CASE
+				WHEN upper(kode_icd) = upper(?) THEN 0
+				WHEN upper(kode_icd) LIKE upper(?) THEN 1
+				WHEN nama_penyakit ILIKE ? THEN 2
+				ELSE 3
+			END, similarity(nama_penyakit, ?) DESC, kode_icd ASC`,
+			params.Query, pattern+"%", pattern+"%", params.Query)}).
+		Limit(params.Limit).
+		Find(&icds).Error
+	return icds, err
+}
+
+func (r *IcdRepository) GetAllKelompok(params model.ParamsGetAllIcdKelompok) ([]model.IcdKelompok, error) {
+	var kelompok []model.IcdKelompok
+	db := r.DB.Model(&model.IcdKelompok{})
+	if params.Jenis != "" {
+		db = db.Where("jenis = ?", params.Jenis)
+	}
+	if params.KodeInduk != "" {
+		db = db.Where("kode_induk = ?", params.KodeInduk)
+	}
+	// urutan simpan mengikuti urutan file katalog, bab dengan angka romawi tidak bisa diurutkan per kode
+	err := db.Order("id_icd_kelompok ASC").Find(&kelompok).Error
+	return kelompok, err
+}
+
+// karakter wildcard LIKE dari input pengguna dicocokkan apa adanya
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"github.com/franklindh/simedis-api/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InteraksiObatRepository struct {
+	DB *gorm.DB
+}
+
+func NewInteraksiObatRepository(db *gorm.DB) *InteraksiObatRepository {
+	return &InteraksiObatRepository{DB: db}
+}
+
+// interaksi yang kedua zatnya ada di daftar, zat harus sudah dinormalisasi
+func (r *InteraksiObatRepository) GetByZat(zat []string) ([]model.InteraksiObat, error) {
+	var list []model.InteraksiObat
+	if len(zat) < 2 {
+		return list, nil
+	}
+	err := r.DB.Where("zat_a IN ? AND zat_b IN ?", zat, zat).Find(&list).Error
+	return list, err
+}
+
+func (r *InteraksiObatRepository) GetAll() ([]model.InteraksiObat, error) {
+	var list []model.InteraksiObat
+	err := r.DB.Find(&list).Error
+	return list, err
+}
+
+func (r *InteraksiObatRepository) Upsert(list []model.InteraksiObat) error {
+	if len(list) == 0 {
+		return nil
+	}
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "zat_a"}, {Name: "zat_b"}},
+			DoUpdates: clause.AssignmentColumns([]string{"tingkat", "deskripsi", "updated_at"}),
+		}).CreateInBatches(&list, importBatchSize).Error
+	})
+}
@@ -15,6 +15,10 @@ func NewJenisPemeriksaanLabRepository(db *gorm.DB) *JenisPemeriksaanLabRepositor
 	return &JenisPemeriksaanLabRepository{DB: db}
 }
 
+func preloadRujukan(db *gorm.DB) *gorm.DB {
+	return db.Order("jenis_kelamin NULLS LAST, umur_min_hari NULLS FIRST")
+}
+
 func (r *JenisPemeriksaanLabRepository) Create(jenis model.JenisPemeriksaanLab) (model.JenisPemeriksaanLab, error) {
 	result := r.DB.Create(&jenis)
 	return jenis, result.Error
@@ -22,13 +26,22 @@ func (r *JenisPemeriksaanLabRepository) Create(jenis model.JenisPemeriksaanLab)
 
 func (r *JenisPemeriksaanLabRepository) GetAll() ([]model.JenisPemeriksaanLab, error) {
 	var allJenis []model.JenisPemeriksaanLab
-	result := r.DB.Find(&allJenis)
+	result := r.DB.Preload("Rujukan", preloadRujukan).Find(&allJenis)
 	return allJenis, result.Error
 }
 
+func (r *JenisPemeriksaanLabRepository) GetByIDs(ids []int) ([]model.JenisPemeriksaanLab, error) {
+	var list []model.JenisPemeriksaanLab
+	if len(ids) == 0 {
+		return list, nil
+	}
+	err := r.DB.Preload("Rujukan", preloadRujukan).Where("id_jenis_pemeriksaan IN ?", ids).Find(&list).Error
+	return list, err
+}
+
 func (r *JenisPemeriksaanLabRepository) GetById(id int) (model.JenisPemeriksaanLab, error) {
 	var jenis model.JenisPemeriksaanLab
-	result := r.DB.First(&jenis, id)
+	result := r.DB.Preload("Rujukan", preloadRujukan).First(&jenis, id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return model.JenisPemeriksaanLab{}, ErrNotFound
@@ -38,13 +51,38 @@ func (r *JenisPemeriksaanLabRepository) GetById(id int) (model.JenisPemeriksaanL
 	return jenis, nil
 }
 
+// rentang rujukan lama diganti seluruhnya dengan yang dikirim, Rujukan nil berarti rentang yang
+// tersimpan tidak diubah
 func (r *JenisPemeriksaanLabRepository) Update(id int, jenis model.JenisPemeriksaanLab) (model.JenisPemeriksaanLab, error) {
-	result := r.DB.Model(&model.JenisPemeriksaanLab{}).Where("id_jenis_pemeriksaan = ?", id).Updates(&jenis)
-	if result.Error != nil {
-		return model.JenisPemeriksaanLab{}, result.Error
-	}
-	if result.RowsAffected == 0 {
-		return model.JenisPemeriksaanLab{}, ErrNotFound
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.JenisPemeriksaanLab{}).
+			Where("id_jenis_pemeriksaan = ?", id).
+			Select("nama_pemeriksaan", "satuan", "nilai_rujukan", "kriteria", "tipe_hasil", "pilihan_hasil", "nilai_normal", "updated_at").
+			Updates(&jenis)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		if jenis.Rujukan == nil {
+			return nil
+		}
+		if err := tx.Where("id_jenis_pemeriksaan = ?", id).Delete(&model.RentangRujukanLab{}).Error; err != nil {
+			return err
+		}
+		if len(jenis.Rujukan) == 0 {
+			return nil
+		}
+		for i := range jenis.Rujukan {
+			jenis.Rujukan[i].ID = 0
+			jenis.Rujukan[i].JenisPemeriksaanID = id
+		}
+		return tx.Create(&jenis.Rujukan).Error
+	})
+	if err != nil {
+		return model.JenisPemeriksaanLab{}, err
 	}
 	return r.GetById(id)
 }
@@ -27,14 +27,22 @@ func (r *LaporanRepository) GetLaporanKunjunganPerPoli(startDate, endDate string
 	return results, err
 }
 
-func (r *LaporanRepository) GetLaporanPenyakitTeratas(startDate, endDate string, limit int) ([]model.LaporanPenyakitTeratas, error) {
+// secara default hanya diagnosis primer yang dihitung, includeSekunder menambahkan komorbid
+func (r *LaporanRepository) GetLaporanPenyakitTeratas(startDate, endDate string, limit int, includeSekunder bool) ([]model.LaporanPenyakitTeratas, error) {
 	var results []model.LaporanPenyakitTeratas
 
-	err := r.DB.Table("pemeriksaan").
-		Select("icd.kode_icd, icd.nama_penyakit, count(pemeriksaan.id_pemeriksaan) as jumlah_kasus").
-		Joins("join icd on pemeriksaan.id_icd = icd.id_icd").
+	db := r.DB.Table("pemeriksaan_diagnosis").
+		Select("icd.kode_icd, icd.nama_penyakit, count(DISTINCT pemeriksaan.id_pemeriksaan) as jumlah_kasus").
+		Joins("join pemeriksaan on pemeriksaan.id_pemeriksaan = pemeriksaan_diagnosis.id_pemeriksaan").
+		Joins("join icd on pemeriksaan_diagnosis.id_icd = icd.id_icd").
 		Where("pemeriksaan.tanggal_pemeriksaan BETWEEN ? AND ?", startDate, endDate).
-		Where("pemeriksaan.id_icd IS NOT NULL").
+		Where("pemeriksaan.dibatalkan_at IS NULL")
+
+	if !includeSekunder {
+		db = db.Where("pemeriksaan_diagnosis.jenis = ?", model.JenisDiagnosisPrimer)
+	}
+
+	err := db.
 		Group("icd.kode_icd, icd.nama_penyakit").
 		Order("jumlah_kasus DESC").
 		Limit(limit).
@@ -42,3 +50,21 @@ func (r *LaporanRepository) GetLaporanPenyakitTeratas(startDate, endDate string,
 
 	return results, err
 }
+
+// jumlah_tindakan menjumlahkan kuantitas, jumlah_pemeriksaan menghitung kunjungan yang memakai prosedur tersebut
+func (r *LaporanRepository) GetLaporanTindakanTeratas(startDate, endDate string, limit int) ([]model.LaporanTindakanTeratas, error) {
+	var results []model.LaporanTindakanTeratas
+
+	err := r.DB.Table("pemeriksaan_tindakan").
+		Select("prosedur.kode_prosedur, prosedur.nama_prosedur, sum(pemeriksaan_tindakan.jumlah) as jumlah_tindakan, count(DISTINCT pemeriksaan.id_pemeriksaan) as jumlah_pemeriksaan").
+		Joins("join pemeriksaan on pemeriksaan.id_pemeriksaan = pemeriksaan_tindakan.id_pemeriksaan").
+		Joins("join prosedur on pemeriksaan_tindakan.id_prosedur = prosedur.id_prosedur").
+		Where("pemeriksaan.tanggal_pemeriksaan BETWEEN ? AND ?", startDate, endDate).
+		Where("pemeriksaan.dibatalkan_at IS NULL").
+		Group("prosedur.kode_prosedur, prosedur.nama_prosedur").
+		Order("jumlah_tindakan DESC").
+		Limit(limit).
+		Scan(&results).Error
+
+	return results, err
+}
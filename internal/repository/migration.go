@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"gorm.io/gorm"
+)
+
+// dijalankan sebelum AutoMigrate untuk data lama yang akan melanggar constraint baru, setiap
+// langkah harus aman dijalankan berulang
+func MigrateBeforeSchema(db *gorm.DB) error {
+	if err := migrateNomorAntrianGanda(db); err != nil {
+		return err
+	}
+	return nil
+}
+
+// migrasi data yang tidak bisa ditangani AutoMigrate, setiap langkah harus aman dijalankan berulang
+func Migrate(db *gorm.DB) error {
+	if err := migrateAntrianStatus(db); err != nil {
+		return err
+	}
+	if err := migrateAntrianSequence(db); err != nil {
+		return err
+	}
+	if err := migratePemeriksaanDokter(db); err != nil {
+		return err
+	}
+	// tanda vital dan diagnosis lama diisi lebih dulu agar ikut tersimpan di revisi pertama
+	if err := migrateTandaVital(db); err != nil {
+		return err
+	}
+	if err := migratePemeriksaanDiagnosis(db); err != nil {
+		return err
+	}
+	if err := migratePemeriksaanRevisi(db); err != nil {
+		return err
+	}
+	if err := migrateKatalogSearch(db); err != nil {
+		return err
+	}
+	if err := migrateHasilLabLangsung(db); err != nil {
+		return err
+	}
+	return nil
+}
+
+// status lama "Menunggu Diagnosis" setara dengan Diperiksa pada state machine antrian
+func migrateAntrianStatus(db *gorm.DB) error {
+	result := db.Model(&model.Antrian{}).
+		Where("status = ?", "Menunggu Diagnosis").
+		Update("status", model.StatusAntrianDiperiksa)
+	if result.Error != nil {
+		return fmt.Errorf("failed to migrate antrian status: %w", result.Error)
+	}
+	return nil
+}
+
+// alokasi lama count+1 bisa menghasilkan nomor ganda dalam satu jadwal, yang membuat indeks unik
+// idx_antrian_jadwal_nomor gagal dibuat. Antrian pertama tetap memakai nomornya, sisanya diberi
+// nomor baru setelah nomor terbesar jadwal tersebut dengan awalan yang sama
+func migrateNomorAntrianGanda(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&model.Antrian{}) {
+		return nil
+	}
+	err := db.Exec(`
+		WITH maks AS (
+			SELECT id_jadwal, COALESCE(MAX(substring(nomor_antrian from '([0-9]+)$')::int), 0) AS nomor
+			FROM antrian
+			GROUP BY id_jadwal
+		), ganda AS (
+			SELECT id_antrian, id_jadwal, nomor_antrian,
+				ROW_NUMBER() OVER (PARTITION BY id_jadwal, nomor_antrian ORDER BY id_antrian) AS urutan
+			FROM antrian
+		), baru AS (
+			SELECT g.id_antrian,
+				regexp_replace(g.nomor_antrian, '[0-9]+$', '') || (m.nomor + ROW_NUMBER() OVER (PARTITION BY g.id_jadwal ORDER BY g.id_antrian)) AS nomor_antrian
+			FROM ganda g
+			JOIN maks m ON m.id_jadwal = g.id_jadwal
+			WHERE g.urutan > 1
+		)
+		UPDATE antrian a
+		SET nomor_antrian = baru.nomor_antrian
+		FROM baru
+		WHERE a.id_antrian = baru.id_antrian`).Error
+	if err != nil {
+		return fmt.Errorf("failed to migrate duplicate nomor antrian: %w", err)
+	}
+	return nil
+}
+
+// jadwal yang sudah punya antrian sebelum ada antrian_sequence melanjutkan dari nomor terbesarnya,
+// termasuk nomor format lama seperti A5, supaya antrian hari itu tidak mulai lagi dari 1
+func migrateAntrianSequence(db *gorm.DB) error {
+	err := db.Exec(`
+		INSERT INTO antrian_sequence (id_jadwal, last_number, updated_at)
+		SELECT id_jadwal, COALESCE(MAX(substring(nomor_antrian from '([0-9]+)$')::int), 0), NOW()
+		FROM antrian
+		GROUP BY id_jadwal
+		ON CONFLICT (id_jadwal) DO UPDATE
+		SET last_number = GREATEST(antrian_sequence.last_number, EXCLUDED.last_number)`).Error
+	if err != nil {
+		return fmt.Errorf("failed to migrate antrian sequence: %w", err)
+	}
+	return nil
+}
+
+// pemeriksaan lama belum menyimpan dokter, diisi dari petugas pada jadwal antriannya
+func migratePemeriksaanDokter(db *gorm.DB) error {
+	err := db.Exec(`
+		UPDATE pemeriksaan p
+		SET id_dokter = j.id_petugas
+		FROM antrian a
+		JOIN jadwal j ON j.id_jadwal = a.id_jadwal
+		WHERE p.id_antrian = a.id_antrian AND p.id_dokter IS NULL`).Error
+	if err != nil {
+		return fmt.Errorf("failed to migrate pemeriksaan dokter: %w", err)
+	}
+	return nil
+}
+
+// pemeriksaan yang dibuat sebelum ada riwayat revisi dijadikan revisi pertama dari isi saat ini
+func migratePemeriksaanRevisi(db *gorm.DB) error {
+	var pending []model.Pemeriksaan
+	err := db.
+		Preload("Diagnosis", func(db *gorm.DB) *gorm.DB { return db.Order("urutan ASC") }).
+		Preload("DaftarTindakan", func(db *gorm.DB) *gorm.DB { return db.Order("urutan ASC") }).
+		Where("NOT EXISTS (SELECT 1 FROM pemeriksaan_revisi r WHERE r.id_pemeriksaan = pemeriksaan.id_pemeriksaan)").
+		FindInBatches(&pending, 200, func(tx *gorm.DB, batch int) error {
+			for _, p := range pending {
+				revisi, err := model.NewPemeriksaanRevisi(p, p.Revisi, model.AlasanRevisiAwal, p.DibuatOleh)
+				if err != nil {
+					return err
+				}
+				revisi.CreatedAt = p.CreatedAt
+				if err := db.Create(&revisi).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to migrate pemeriksaan revisi: %w", err)
+	}
+	return nil
+}
+
+type tandaVitalLama struct {
+	ID           int            `gorm:"column:id_pemeriksaan"`
+	Nadi         sql.NullString `gorm:"column:nadi"`
+	TekananDarah sql.NullString `gorm:"column:tekanan_darah"`
+	Suhu         sql.NullString `gorm:"column:suhu"`
+	BeratBadan   sql.NullString `gorm:"column:berat_badan"`
+}
+
+var kolomTandaVitalLama = []string{"nadi", "tekanan_darah", "suhu", "berat_badan"}
+
+// tanda vital lama berupa teks bebas dibaca ke kolom terstruktur, lalu kolom lamanya
+// diganti nama menjadi *_lama sebagai arsip sehingga langkah ini hanya berjalan sekali
+func migrateTandaVital(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&model.Pemeriksaan{}, "tekanan_darah") {
+		return nil
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var rows []tandaVitalLama
+		result := tx.Table("pemeriksaan").
+			Select("id_pemeriksaan, nadi, tekanan_darah, suhu, berat_badan").
+			Where("nadi IS NOT NULL OR tekanan_darah IS NOT NULL OR suhu IS NOT NULL OR berat_badan IS NOT NULL").
+			FindInBatches(&rows, 500, func(batchTx *gorm.DB, batch int) error {
+				for _, row := range rows {
+					vital := model.ParseTandaVitalLama(row.Nadi.String, row.TekananDarah.String, row.Suhu.String, row.BeratBadan.String)
+					if vital == (model.TandaVital{}) {
+						continue
+					}
+					err := tx.Model(&model.Pemeriksaan{}).
+						Where("id_pemeriksaan = ?", row.ID).
+						UpdateColumns(&model.Pemeriksaan{TandaVital: vital}).Error
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		for _, column := range kolomTandaVitalLama {
+			if err := tx.Migrator().RenameColumn(&model.Pemeriksaan{}, column, column+"_lama"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to migrate tanda vital: %w", err)
+	}
+	return nil
+}
+
+// diagnosis tunggal pada kolom id_icd dijadikan diagnosis primer untuk pemeriksaan
+// yang belum punya daftar diagnosis
+func migratePemeriksaanDiagnosis(db *gorm.DB) error {
+	err := db.Exec(`
+		INSERT INTO pemeriksaan_diagnosis (id_pemeriksaan, id_icd, jenis, kasus, urutan, created_at)
+		SELECT p.id_pemeriksaan, p.id_icd, ?, ?, 1, p.created_at
+		FROM pemeriksaan p
+		WHERE p.id_icd IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM pemeriksaan_diagnosis d WHERE d.id_pemeriksaan = p.id_pemeriksaan)`,
+		model.JenisDiagnosisPrimer, model.KasusBaru).Error
+	if err != nil {
+		return fmt.Errorf("failed to migrate pemeriksaan diagnosis: %w", err)
+	}
+	return nil
+}
+
+// indeks untuk GET /icd/search dan GET /prosedur/search: awalan kode lewat btree upper(kode),
+// nama lewat trigram
+func migrateKatalogSearch(db *gorm.DB) error {
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+		`CREATE INDEX IF NOT EXISTS idx_icd_kode_prefix ON icd (upper(kode_icd) text_pattern_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_icd_nama_trgm ON icd USING gin (nama_penyakit gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_prosedur_kode_prefix ON prosedur (upper(kode_prosedur) text_pattern_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_prosedur_nama_trgm ON prosedur USING gin (nama_prosedur gin_trgm_ops)`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to migrate katalog search index: %w", err)
+		}
+	}
+	return nil
+}
+
+// hasil lab yang dicatat langsung hanya satu per jenis pemeriksaan dalam satu pemeriksaan.
+// Simpan batch yang berjalan bersamaan dulu bisa membuat hasil ganda. Hasil klinis tidak dihapus
+// otomatis, migrasi berhenti dan menampilkan id hasil ganda agar diselesaikan manual
+func migrateHasilLabLangsung(db *gorm.DB) error {
+	var ganda []struct {
+		PemeriksaanID      int    `gorm:"column:id_pemeriksaan"`
+		JenisPemeriksaanID int    `gorm:"column:id_jenis_pemeriksaan"`
+		IDs                string `gorm:"column:ids"`
+	}
+	err := db.Raw(`
+		SELECT id_pemeriksaan, id_jenis_pemeriksaan,
+			string_agg(id_pemeriksaan_lab::text, ', ' ORDER BY id_pemeriksaan_lab) AS ids
+		FROM pemeriksaan_lab
+		WHERE id_order_lab_item IS NULL
+		GROUP BY id_pemeriksaan, id_jenis_pemeriksaan
+		HAVING COUNT(*) > 1
+		ORDER BY id_pemeriksaan, id_jenis_pemeriksaan`).Scan(&ganda).Error
+	if err != nil {
+		return fmt.Errorf("failed to migrate hasil lab langsung: %w", err)
+	}
+	if len(ganda) > 0 {
+		daftar := make([]string, 0, len(ganda))
+		for _, g := range ganda {
+			daftar = append(daftar, fmt.Sprintf("pemeriksaan %d jenis %d: id_pemeriksaan_lab [%s]", g.PemeriksaanID, g.JenisPemeriksaanID, g.IDs))
+		}
+		return fmt.Errorf("failed to migrate hasil lab langsung: duplicate direct lab results must be resolved manually before creating idx_pemeriksaan_lab_langsung: %s",
+			strings.Join(daftar, "; "))
+	}
+
+	err = db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_pemeriksaan_lab_langsung
+		ON pemeriksaan_lab (id_pemeriksaan, id_jenis_pemeriksaan) WHERE id_order_lab_item IS NULL`).Error
+	if err != nil {
+		return fmt.Errorf("failed to migrate hasil lab langsung: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/pkg/utils/pagination"
+	"gorm.io/gorm"
+)
+
+type ParamsGetAllObat struct {
+	NamaFilter   string `form:"nama" binding:"omitempty,sanitize"`
+	BentukFilter string `form:"bentuk_sediaan" binding:"omitempty,sanitize"`
+	StatusFilter string `form:"status" binding:"omitempty,oneof=aktif nonaktif"`
+	SortBy       string `form:"sort" binding:"omitempty,sanitize"`
+	Page         int    `form:"page" binding:"omitempty,gt=0"`
+	PageSize     int    `form:"pageSize" binding:"omitempty,gt=0"`
+}
+
+type ObatRepository struct {
+	DB *gorm.DB
+}
+
+func NewObatRepository(db *gorm.DB) *ObatRepository {
+	return &ObatRepository{DB: db}
+}
+
+func (r *ObatRepository) Create(obat model.Obat) (model.Obat, error) {
+	result := r.DB.Create(&obat)
+	return obat, result.Error
+}
+
+func (r *ObatRepository) GetAll(params ParamsGetAllObat) ([]model.Obat, pagination.Metadata, error) {
+	var allObat []model.Obat
+	var totalRecords int64
+
+	db := r.DB.Model(&model.Obat{})
+
+	if params.NamaFilter != "" {
+		db = db.Where("nama_obat ILIKE ? OR kode_obat ILIKE ?", "%"+params.NamaFilter+"%", "%"+params.NamaFilter+"%")
+	}
+	if params.BentukFilter != "" {
+		db = db.Where("bentuk_sediaan = ?", params.BentukFilter)
+	}
+	if params.StatusFilter != "" {
+		db = db.Where("status_obat = ?", params.StatusFilter)
+	}
+
+	if err := db.Count(&totalRecords).Error; err != nil {
+		return nil, pagination.Metadata{}, err
+	}
+
+	metadata := pagination.CalculateMetadata(int(totalRecords), params.Page, params.PageSize)
+
+	sortWhitelist := map[string]string{
+		"kode_asc":  "kode_obat ASC",
+		"kode_desc": "kode_obat DESC",
+		"nama_asc":  "nama_obat ASC",
+		"nama_desc": "nama_obat DESC",
+	}
+	orderByClause := "nama_obat ASC"
+	if sort, ok := sortWhitelist[params.SortBy]; ok {
+		orderByClause = sort
+	}
+	db = db.Order(orderByClause)
+
+	db = db.Limit(metadata.PageSize).Offset((metadata.CurrentPage - 1) * metadata.PageSize)
+
+	if err := db.Find(&allObat).Error; err != nil {
+		return nil, pagination.Metadata{}, err
+	}
+
+	return allObat, metadata, nil
+}
+
+func (r *ObatRepository) GetByID(id int) (model.Obat, error) {
+	var obat model.Obat
+	result := r.DB.First(&obat, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return model.Obat{}, ErrNotFound
+		}
+		return model.Obat{}, result.Error
+	}
+	return obat, nil
+}
+
+func (r *ObatRepository) GetByIDs(ids []int) ([]model.Obat, error) {
+	var list []model.Obat
+	if len(ids) == 0 {
+		return list, nil
+	}
+	err := r.DB.Where("id_obat IN ?", ids).Find(&list).Error
+	return list, err
+}
+
+func (r *ObatRepository) Update(id int, obat model.Obat) (model.Obat, error) {
+	obat.ID = id
+	result := r.DB.Model(&model.Obat{}).Where("id_obat = ?", id).Updates(&obat)
+	if result.Error != nil {
+		return model.Obat{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.Obat{}, ErrNotFound
+	}
+	return r.GetByID(id)
+}
+
+func (r *ObatRepository) Delete(id int) error {
+	result := r.DB.Delete(&model.Obat{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
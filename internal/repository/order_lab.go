@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/pkg/utils/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ParamsGetAllOrderLab struct {
+	StatusFilter    string `form:"status" binding:"omitempty,oneof=dipesan spesimen_diambil spesimen_diterima menunggu_validasi divalidasi dibatalkan"`
+	PrioritasFilter string `form:"prioritas" binding:"omitempty,oneof=rutin cito"`
+	PasienID        int    `form:"pasien_id" binding:"omitempty,gt=0"`
+	Page            int    `form:"page" binding:"omitempty,gt=0"`
+	PageSize        int    `form:"pageSize" binding:"omitempty,gt=0"`
+}
+
+type OrderLabRepository struct {
+	DB *gorm.DB
+}
+
+func NewOrderLabRepository(db *gorm.DB) *OrderLabRepository {
+	return &OrderLabRepository{DB: db}
+}
+
+func (r *OrderLabRepository) preload(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Pasien").
+		Preload("Dokter").
+		Preload("Pengambil").
+		Preload("Penerima").
+		Preload("Validator").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("urutan ASC") }).
+		Preload("Items.JenisPemeriksaanLab", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Items.JenisPemeriksaanLab.Rujukan").
+		Preload("Items.Panel", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Items.Hasil").
+		Preload("Items.Hasil.JenisPemeriksaanLab", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Items.Hasil.Panel", func(db *gorm.DB) *gorm.DB { return db.Unscoped() })
+}
+
+// order dan item-itemnya disimpan dalam satu transaksi
+func (r *OrderLabRepository) Create(order model.OrderLab) (model.OrderLab, error) {
+	if err := r.DB.Create(&order).Error; err != nil {
+		return model.OrderLab{}, err
+	}
+	return r.GetByID(order.ID)
+}
+
+func (r *OrderLabRepository) GetByID(id int) (model.OrderLab, error) {
+	var order model.OrderLab
+	result := r.preload(r.DB).First(&order, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return model.OrderLab{}, ErrNotFound
+		}
+		return model.OrderLab{}, result.Error
+	}
+	return order, nil
+}
+
+func (r *OrderLabRepository) GetAllByPemeriksaanID(pemeriksaanID int) ([]model.OrderLab, error) {
+	var list []model.OrderLab
+	err := r.preload(r.DB).
+		Where("id_pemeriksaan = ?", pemeriksaanID).
+		Order("created_at ASC").
+		Find(&list).Error
+	return list, err
+}
+
+// worklist laboratorium. Tanpa filter status hanya order yang belum selesai yang ditampilkan,
+// order cito lebih dulu lalu dari yang paling lama
+func (r *OrderLabRepository) GetAll(params ParamsGetAllOrderLab) ([]model.OrderLab, pagination.Metadata, error) {
+	var list []model.OrderLab
+	var totalRecords int64
+
+	db := r.DB.Model(&model.OrderLab{})
+	if params.StatusFilter != "" {
+		db = db.Where("status = ?", params.StatusFilter)
+	} else {
+		db = db.Where("status NOT IN ?", []string{model.StatusOrderLabDivalidasi, model.StatusOrderLabDibatalkan})
+	}
+	if params.PrioritasFilter != "" {
+		db = db.Where("prioritas = ?", params.PrioritasFilter)
+	}
+	if params.PasienID > 0 {
+		db = db.Where("id_pasien = ?", params.PasienID)
+	}
+
+	if err := db.Count(&totalRecords).Error; err != nil {
+		return nil, pagination.Metadata{}, err
+	}
+
+	metadata := pagination.CalculateMetadata(int(totalRecords), params.Page, params.PageSize)
+
+	err := r.preload(db).
+		Order(clause.OrderBy{Expression: gorm.Expr("CASE WHEN prioritas = ? THEN 0 ELSE 1 END, created_at ASC", model.PrioritasOrderLabCito)}).
+		Limit(metadata.PageSize).
+		Offset((metadata.CurrentPage - 1) * metadata.PageSize).
+		Find(&list).Error
+	if err != nil {
+		return nil, pagination.Metadata{}, err
+	}
+	return list, metadata, nil
+}
+
+func (r *OrderLabRepository) AmbilSpesimen(id int, actorID int, at time.Time) (model.OrderLab, error) {
+	return r.ubahStatus(id, []string{model.StatusOrderLabDipesan}, model.StatusOrderLabSpesimenDiambil, map[string]any{
+		"spesimen_diambil_at":   at,
+		"spesimen_diambil_oleh": sql.NullInt64{Int64: int64(actorID), Valid: actorID > 0},
+	})
+}
+
+func (r *OrderLabRepository) TerimaSpesimen(id int, actorID int, at time.Time) (model.OrderLab, error) {
+	return r.ubahStatus(id, []string{model.StatusOrderLabSpesimenDiambil}, model.StatusOrderLabSpesimenDiterima, map[string]any{
+		"spesimen_diterima_at":   at,
+		"spesimen_diterima_oleh": sql.NullInt64{Int64: int64(actorID), Valid: actorID > 0},
+	})
+}
+
+// validator tidak boleh pengisi salah satu hasil, dicek pada UPDATE yang sama agar hasil yang
+// disimpan validator tepat sebelum validasi ikut terhitung
+func (r *OrderLabRepository) Validasi(id int, actorID int, at time.Time) (model.OrderLab, error) {
+	bukanPengisi := func(db *gorm.DB) *gorm.DB {
+		return db.Where(`NOT EXISTS (
+			SELECT 1 FROM pemeriksaan_lab pl
+			JOIN order_lab_item i ON i.id_order_lab_item = pl.id_order_lab_item
+			WHERE i.id_order_lab = order_lab.id_order_lab AND pl.diisi_oleh = ?)`, actorID)
+	}
+	return r.ubahStatus(id, []string{model.StatusOrderLabMenungguValidasi}, model.StatusOrderLabDivalidasi, map[string]any{
+		"divalidasi_at":   at,
+		"divalidasi_oleh": sql.NullInt64{Int64: int64(actorID), Valid: actorID > 0},
+	}, bukanPengisi)
+}
+
+func (r *OrderLabRepository) Batalkan(id int, alasan string, at time.Time) (model.OrderLab, error) {
+	from := []string{model.StatusOrderLabDipesan, model.StatusOrderLabSpesimenDiambil, model.StatusOrderLabSpesimenDiterima, model.StatusOrderLabMenungguValidasi}
+	return r.ubahStatus(id, from, model.StatusOrderLabDibatalkan, map[string]any{
+		"dibatalkan_at": at,
+		"alasan_batal":  alasan,
+	})
+}
+
+// status hanya berubah bila status saat ini salah satu dari from dan syarat tambahan terpenuhi,
+// ErrNotFound berarti order tidak ada, statusnya sudah diubah request lain, atau syaratnya gagal
+func (r *OrderLabRepository) ubahStatus(id int, from []string, to string, fields map[string]any, syarat ...func(*gorm.DB) *gorm.DB) (model.OrderLab, error) {
+	fields["status"] = to
+	fields["updated_at"] = time.Now()
+	result := r.DB.Model(&model.OrderLab{}).
+		Where("id_order_lab = ?", id).
+		Where("status IN ?", from).
+		Scopes(syarat...).
+		Updates(fields)
+	if result.Error != nil {
+		return model.OrderLab{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.OrderLab{}, ErrNotFound
+	}
+	return r.GetByID(id)
+}
+
+// hasil disimpan per item order, item yang sudah punya hasil diperbarui. Status order ikut
+// diubah dalam transaksi yang sama selama order masih dalam tahap pengisian hasil
+func (r *OrderLabRepository) SimpanHasil(id int, hasil []model.PemeriksaanLab, status string) (model.OrderLab, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.OrderLab{}).
+			Where("id_order_lab = ?", id).
+			Where("status IN ?", []string{model.StatusOrderLabSpesimenDiterima, model.StatusOrderLabMenungguValidasi}).
+			Updates(map[string]any{"status": status, "updated_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id_order_lab_item"}},
+			DoUpdates: clause.AssignmentColumns([]string{"hasil", "nilai_numerik", "flag", "nilai_rujukan", "diisi_oleh", "updated_at"}),
+		}).Create(&hasil).Error
+	})
+	if err != nil {
+		return model.OrderLab{}, err
+	}
+	return r.GetByID(id)
+}
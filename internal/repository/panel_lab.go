@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"gorm.io/gorm"
+)
+
+type PanelLabRepository struct {
+	DB *gorm.DB
+}
+
+func NewPanelLabRepository(db *gorm.DB) *PanelLabRepository {
+	return &PanelLabRepository{DB: db}
+}
+
+func (r *PanelLabRepository) preload(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("urutan ASC") }).
+		Preload("Items.JenisPemeriksaanLab")
+}
+
+func (r *PanelLabRepository) Create(panel model.PanelLab) (model.PanelLab, error) {
+	if err := r.DB.Create(&panel).Error; err != nil {
+		return model.PanelLab{}, err
+	}
+	return r.GetByID(panel.ID)
+}
+
+func (r *PanelLabRepository) GetAll() ([]model.PanelLab, error) {
+	var list []model.PanelLab
+	err := r.preload(r.DB).Order("nama_panel ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *PanelLabRepository) GetByID(id int) (model.PanelLab, error) {
+	var panel model.PanelLab
+	result := r.preload(r.DB).First(&panel, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return model.PanelLab{}, ErrNotFound
+		}
+		return model.PanelLab{}, result.Error
+	}
+	return panel, nil
+}
+
+func (r *PanelLabRepository) GetByIDs(ids []int) ([]model.PanelLab, error) {
+	var list []model.PanelLab
+	if len(ids) == 0 {
+		return list, nil
+	}
+	err := r.preload(r.DB).Where("id_panel_lab IN ?", ids).Find(&list).Error
+	return list, err
+}
+
+func (r *PanelLabRepository) FindByName(name string) (model.PanelLab, error) {
+	var panel model.PanelLab
+	result := r.DB.Where("nama_panel = ?", name).First(&panel)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return model.PanelLab{}, ErrNotFound
+		}
+		return model.PanelLab{}, result.Error
+	}
+	return panel, nil
+}
+
+// item panel lama diganti seluruhnya. Order dan hasil yang sudah ada tidak ikut berubah
+func (r *PanelLabRepository) Update(id int, panel model.PanelLab) (model.PanelLab, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.PanelLab{}).
+			Where("id_panel_lab = ?", id).
+			Select("nama_panel", "deskripsi", "updated_at").
+			Updates(&panel)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		if err := tx.Where("id_panel_lab = ?", id).Delete(&model.PanelLabItem{}).Error; err != nil {
+			return err
+		}
+		for i := range panel.Items {
+			panel.Items[i].ID = 0
+			panel.Items[i].PanelLabID = id
+		}
+		return tx.Create(&panel.Items).Error
+	})
+	if err != nil {
+		return model.PanelLab{}, err
+	}
+	return r.GetByID(id)
+}
+
+func (r *PanelLabRepository) Delete(id int) error {
+	result := r.DB.Delete(&model.PanelLab{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
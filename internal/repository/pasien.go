@@ -73,7 +73,7 @@ func (r *PasienRepository) GetAll(params ParamsGetAllPasien) ([]model.Pasien, pa
 
 func (r *PasienRepository) GetById(id int) (model.Pasien, error) {
 	var pasien model.Pasien
-	result := r.DB.First(&pasien, id)
+	result := r.DB.Preload("Alergi", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).First(&pasien, id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return model.Pasien{}, ErrNotFound
@@ -116,3 +116,43 @@ func (r *PasienRepository) GetLastID() (int, error) {
 	}
 	return lastID, nil
 }
+
+func (r *PasienRepository) GetByUsername(username string) (model.Pasien, error) {
+	var pasien model.Pasien
+	result := r.DB.Where("username_pasien = ?", username).First(&pasien)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return model.Pasien{}, ErrNotFound
+		}
+		return model.Pasien{}, result.Error
+	}
+	return pasien, nil
+}
+
+func (r *PasienRepository) UpdatePassword(id int, newHashedPassword string) error {
+	result := r.DB.Model(&model.Pasien{}).Where("id_pasien = ?", id).Updates(map[string]any{
+		"password":         newHashedPassword,
+		"password_changed": true,
+		"token_version":    gorm.Expr("token_version + 1"),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// dipakai setiap request pasien, hanya membaca kolom token_version
+func (r *PasienRepository) GetTokenVersion(id int) (int, error) {
+	var pasien model.Pasien
+	result := r.DB.Select("token_version").Where("id_pasien = ?", id).Take(&pasien)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return 0, ErrNotFound
+		}
+		return 0, result.Error
+	}
+	return pasien.TokenVersion, nil
+}
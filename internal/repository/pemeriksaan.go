@@ -1,10 +1,13 @@
 package repository
 
 import (
+	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/franklindh/simedis-api/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PemeriksaanRepository struct {
@@ -27,10 +30,23 @@ func (r *PemeriksaanRepository) CheckExistingPemeriksaan(antrianID int) error {
 	return ErrNotFound
 }
 
+// menyimpan pemeriksaan beserta revisi pertamanya dalam satu transaksi
 func (r *PemeriksaanRepository) Create(pemeriksaan model.Pemeriksaan) (model.Pemeriksaan, error) {
-	result := r.DB.Create(&pemeriksaan)
-	if result.Error != nil {
-		return model.Pemeriksaan{}, result.Error
+	pemeriksaan.Revisi = 1
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&pemeriksaan).Error; err != nil {
+			return err
+		}
+
+		revisi, err := model.NewPemeriksaanRevisi(pemeriksaan, 1, model.AlasanRevisiAwal, pemeriksaan.DibuatOleh)
+		if err != nil {
+			return err
+		}
+		return tx.Create(&revisi).Error
+	})
+	if err != nil {
+		return model.Pemeriksaan{}, err
 	}
 
 	return r.GetById(pemeriksaan.ID)
@@ -43,6 +59,17 @@ func (r *PemeriksaanRepository) GetById(id int) (model.Pemeriksaan, error) {
 		Preload("Antrian.Pasien").
 		Preload("Antrian.Jadwal.Petugas").
 		Preload("Antrian.Jadwal.Poli").
+		Preload("Dokter").
+		Preload("PetugasPembuat").
+		Preload("PetugasPengubah").
+		Preload("Penandatangan").
+		Preload("Addendum", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Addendum.Petugas").
+		Preload("Diagnosis", func(db *gorm.DB) *gorm.DB { return db.Order("urutan ASC") }).
+		Preload("Diagnosis.Icd").
+		Preload("DaftarTindakan", func(db *gorm.DB) *gorm.DB { return db.Order("urutan ASC") }).
+		Preload("DaftarTindakan.Prosedur").
+		Preload("DaftarTindakan.Pelaksana").
 		First(&pemeriksaan, id)
 
 	if result.Error != nil {
@@ -59,18 +86,148 @@ func (r *PemeriksaanRepository) GetAllByPasienID(pasienID int) ([]model.Pemeriks
 	result := r.DB.
 		Joins("JOIN antrian ON pemeriksaan.id_antrian = antrian.id_antrian").
 		Where("antrian.id_pasien = ?", pasienID).
+		Where("pemeriksaan.dibatalkan_at IS NULL").
 		Preload("Icd").
 		Preload("Antrian.Pasien").
 		Preload("Antrian.Jadwal.Petugas").
 		Preload("Antrian.Jadwal.Poli").
+		Preload("Dokter").
+		Preload("PetugasPembuat").
+		Preload("PetugasPengubah").
+		Preload("Penandatangan").
+		Preload("Addendum", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Addendum.Petugas").
+		Preload("Diagnosis", func(db *gorm.DB) *gorm.DB { return db.Order("urutan ASC") }).
+		Preload("Diagnosis.Icd").
+		Preload("DaftarTindakan", func(db *gorm.DB) *gorm.DB { return db.Order("urutan ASC") }).
+		Preload("DaftarTindakan.Prosedur").
+		Preload("DaftarTindakan.Pelaksana").
 		Order("tanggal_pemeriksaan DESC, created_at DESC").
 		Find(&allPemeriksaan)
 
 	return allPemeriksaan, result.Error
 }
 
-func (r *PemeriksaanRepository) Update(id int, pemeriksaan model.Pemeriksaan) (model.Pemeriksaan, error) {
-	result := r.DB.Model(&model.Pemeriksaan{}).Where("id_pemeriksaan = ?", id).Updates(&pemeriksaan)
+// hanya kolom tanda vital yang diambil, urut dari pemeriksaan paling lama
+func (r *PemeriksaanRepository) GetVitalsByPasienID(pasienID int, from, to string) ([]model.Pemeriksaan, error) {
+	var pemeriksaanList []model.Pemeriksaan
+	db := r.DB.
+		Select("pemeriksaan.id_pemeriksaan, pemeriksaan.tanggal_pemeriksaan, pemeriksaan.created_at, "+
+			"pemeriksaan.tekanan_sistolik, pemeriksaan.tekanan_diastolik, pemeriksaan.nadi_bpm, "+
+			"pemeriksaan.suhu_celsius, pemeriksaan.berat_badan_kg, pemeriksaan.tinggi_badan_cm").
+		Joins("JOIN antrian ON pemeriksaan.id_antrian = antrian.id_antrian").
+		Where("antrian.id_pasien = ?", pasienID).
+		Where("pemeriksaan.dibatalkan_at IS NULL")
+
+	if from != "" {
+		db = db.Where("pemeriksaan.tanggal_pemeriksaan >= ?", from)
+	}
+	if to != "" {
+		db = db.Where("pemeriksaan.tanggal_pemeriksaan <= ?", to)
+	}
+
+	err := db.Order("pemeriksaan.tanggal_pemeriksaan ASC, pemeriksaan.created_at ASC").Find(&pemeriksaanList).Error
+	return pemeriksaanList, err
+}
+
+// setiap perubahan menaikkan nomor revisi dan menyimpan salinan lengkap hasilnya.
+// Baris dikunci supaya dua perubahan bersamaan tidak mendapat nomor revisi yang sama
+func (r *PemeriksaanRepository) Update(id int, pemeriksaan model.Pemeriksaan, alasan string) (model.Pemeriksaan, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var current model.Pemeriksaan
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("dibatalkan_at IS NULL AND ditandatangani_at IS NULL").
+			First(&current, id).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		pemeriksaan.Revisi = current.Revisi + 1
+		err = tx.Model(&model.Pemeriksaan{}).
+			Where("id_pemeriksaan = ?", id).
+			Omit(clause.Associations).
+			Updates(&pemeriksaan).Error
+		if err != nil {
+			return err
+		}
+
+		// daftar diagnosis yang dikirim menggantikan seluruh diagnosis sebelumnya
+		if pemeriksaan.Diagnosis != nil {
+			if err := replaceDiagnosis(tx, id, pemeriksaan.Diagnosis); err != nil {
+				return err
+			}
+		}
+
+		if pemeriksaan.DaftarTindakan != nil {
+			if err := replaceTindakan(tx, id, pemeriksaan.DaftarTindakan); err != nil {
+				return err
+			}
+		}
+
+		var updated model.Pemeriksaan
+		err = tx.
+			Preload("Diagnosis", func(db *gorm.DB) *gorm.DB { return db.Order("urutan ASC") }).
+			Preload("DaftarTindakan", func(db *gorm.DB) *gorm.DB { return db.Order("urutan ASC") }).
+			First(&updated, id).Error
+		if err != nil {
+			return err
+		}
+
+		revisi, err := model.NewPemeriksaanRevisi(updated, updated.Revisi, alasan, pemeriksaan.DiubahOleh)
+		if err != nil {
+			return err
+		}
+		return tx.Create(&revisi).Error
+	})
+	if err != nil {
+		return model.Pemeriksaan{}, err
+	}
+	return r.GetById(id)
+}
+
+func replaceDiagnosis(tx *gorm.DB, pemeriksaanID int, diagnosis []model.PemeriksaanDiagnosis) error {
+	if err := tx.Where("id_pemeriksaan = ?", pemeriksaanID).Delete(&model.PemeriksaanDiagnosis{}).Error; err != nil {
+		return err
+	}
+	if len(diagnosis) > 0 {
+		for i := range diagnosis {
+			diagnosis[i].PemeriksaanID = pemeriksaanID
+		}
+		if err := tx.Create(&diagnosis).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Model(&model.Pemeriksaan{}).
+		Where("id_pemeriksaan = ?", pemeriksaanID).
+		UpdateColumn("id_icd", model.PrimaryIcdID(diagnosis)).Error
+}
+
+func replaceTindakan(tx *gorm.DB, pemeriksaanID int, tindakan []model.PemeriksaanTindakan) error {
+	if err := tx.Where("id_pemeriksaan = ?", pemeriksaanID).Delete(&model.PemeriksaanTindakan{}).Error; err != nil {
+		return err
+	}
+	if len(tindakan) == 0 {
+		return nil
+	}
+	for i := range tindakan {
+		tindakan[i].PemeriksaanID = pemeriksaanID
+	}
+	return tx.Omit(clause.Associations).Create(&tindakan).Error
+}
+
+func (r *PemeriksaanRepository) Void(id int, alasan string, actorID int, at time.Time) (model.Pemeriksaan, error) {
+	result := r.DB.Model(&model.Pemeriksaan{}).
+		Where("id_pemeriksaan = ?", id).
+		Where("dibatalkan_at IS NULL AND ditandatangani_at IS NULL").
+		Updates(map[string]any{
+			"dibatalkan_at":   at,
+			"dibatalkan_oleh": sql.NullInt64{Int64: int64(actorID), Valid: actorID > 0},
+			"alasan_batal":    alasan,
+			"updated_at":      at,
+		})
 	if result.Error != nil {
 		return model.Pemeriksaan{}, result.Error
 	}
@@ -80,13 +237,44 @@ func (r *PemeriksaanRepository) Update(id int, pemeriksaan model.Pemeriksaan) (m
 	return r.GetById(id)
 }
 
-func (r *PemeriksaanRepository) Delete(id int) error {
-	result := r.DB.Delete(&model.Pemeriksaan{}, id)
+// hanya berhasil jika record belum ditandatangani dan masih pada revisi yang sama dengan yang di-hash
+func (r *PemeriksaanRepository) Finalize(id int, revisi int, signerID int, at time.Time, signature string) (model.Pemeriksaan, error) {
+	result := r.DB.Model(&model.Pemeriksaan{}).
+		Where("id_pemeriksaan = ?", id).
+		Where("revisi = ?", revisi).
+		Where("ditandatangani_at IS NULL AND dibatalkan_at IS NULL").
+		Updates(map[string]any{
+			"ditandatangani_at":   at,
+			"ditandatangani_oleh": signerID,
+			"tanda_tangan":        signature,
+		})
 	if result.Error != nil {
-		return result.Error
+		return model.Pemeriksaan{}, result.Error
 	}
 	if result.RowsAffected == 0 {
-		return ErrNotFound
+		return model.Pemeriksaan{}, ErrNotFound
 	}
-	return nil
+	return r.GetById(id)
+}
+
+func (r *PemeriksaanRepository) CreateAddendum(addendum model.PemeriksaanAddendum) (model.PemeriksaanAddendum, error) {
+	if err := r.DB.Create(&addendum).Error; err != nil {
+		return model.PemeriksaanAddendum{}, err
+	}
+
+	var created model.PemeriksaanAddendum
+	if err := r.DB.Preload("Petugas").First(&created, addendum.ID).Error; err != nil {
+		return model.PemeriksaanAddendum{}, err
+	}
+	return created, nil
+}
+
+func (r *PemeriksaanRepository) GetRevisions(pemeriksaanID int) ([]model.PemeriksaanRevisi, error) {
+	var revisions []model.PemeriksaanRevisi
+	err := r.DB.
+		Preload("Petugas").
+		Where("id_pemeriksaan = ?", pemeriksaanID).
+		Order("revisi ASC").
+		Find(&revisions).Error
+	return revisions, err
 }
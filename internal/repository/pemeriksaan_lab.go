@@ -5,6 +5,7 @@ import (
 
 	"github.com/franklindh/simedis-api/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PemeriksaanLabRepository struct {
@@ -15,23 +16,84 @@ func NewPemeriksaanLabRepository(db *gorm.DB) *PemeriksaanLabRepository {
 	return &PemeriksaanLabRepository{DB: db}
 }
 
-func (r *PemeriksaanLabRepository) Create(hasilLab model.PemeriksaanLab) (model.PemeriksaanLab, error) {
-	result := r.DB.Create(&hasilLab)
-	if result.Error != nil {
-		return model.PemeriksaanLab{}, result.Error
+// seluruh batch disimpan dalam satu transaksi. Hasil dengan ID sudah terisi menimpa hasil
+// langsung yang ada, sisanya dibuat baru. Hasil baru yang jenisnya ternyata sudah disimpan oleh
+// request lain menimpa hasil tersebut lewat indeks idx_pemeriksaan_lab_langsung. Hasil
+// dikembalikan sesuai urutan batch
+func (r *PemeriksaanLabRepository) SimpanBatch(list []model.PemeriksaanLab) ([]model.PemeriksaanLab, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range list {
+			if list[i].ID == 0 {
+				err := tx.Clauses(clause.OnConflict{
+					Columns:     []clause.Column{{Name: "id_pemeriksaan"}, {Name: "id_jenis_pemeriksaan"}},
+					TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "id_order_lab_item IS NULL"}}},
+					DoUpdates:   clause.AssignmentColumns([]string{"hasil", "nilai_numerik", "flag", "nilai_rujukan", "id_panel_lab", "updated_at"}),
+				}).Create(&list[i]).Error
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			result := tx.Model(&model.PemeriksaanLab{}).
+				Where("id_pemeriksaan_lab = ? AND id_order_lab_item IS NULL", list[i].ID).
+				Select("hasil", "nilai_numerik", "flag", "nilai_rujukan", "id_panel_lab", "updated_at").
+				Updates(&list[i])
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrNotFound
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(list))
+	for _, hasil := range list {
+		ids = append(ids, hasil.ID)
+	}
+	var saved []model.PemeriksaanLab
+	err = r.DB.Preload("JenisPemeriksaanLab").
+		Preload("Panel", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("id_pemeriksaan_lab IN ?", ids).
+		Find(&saved).Error
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]model.PemeriksaanLab, len(saved))
+	for _, hasil := range saved {
+		byID[hasil.ID] = hasil
+	}
+	for i, id := range ids {
+		list[i] = byID[id]
 	}
-	return r.GetById(hasilLab.ID)
+	return list, nil
 }
 
+// hasil dari order lab hanya ikut bila ordernya sudah divalidasi
 func (r *PemeriksaanLabRepository) GetAllByPemeriksaanID(pemeriksaanID int) ([]model.PemeriksaanLab, error) {
 	var results []model.PemeriksaanLab
-	err := r.DB.Preload("JenisPemeriksaanLab").Where("id_pemeriksaan = ?", pemeriksaanID).Find(&results).Error
+	err := r.DB.Preload("JenisPemeriksaanLab").
+		Preload("Panel", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Panel.Items").
+		Where("id_pemeriksaan = ?", pemeriksaanID).
+		Where(`id_order_lab_item IS NULL OR id_order_lab_item IN (
+			SELECT order_lab_item.id_order_lab_item FROM order_lab_item
+			JOIN order_lab ON order_lab.id_order_lab = order_lab_item.id_order_lab
+			WHERE order_lab.status = ?)`, model.StatusOrderLabDivalidasi).
+		Order("created_at ASC, id_pemeriksaan_lab ASC").
+		Find(&results).Error
 	return results, err
 }
 
 func (r *PemeriksaanLabRepository) GetById(id int) (model.PemeriksaanLab, error) {
 	var hasilLab model.PemeriksaanLab
-	result := r.DB.Preload("JenisPemeriksaanLab").First(&hasilLab, id)
+	result := r.DB.Preload("JenisPemeriksaanLab.Rujukan").
+		Preload("Panel", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		First(&hasilLab, id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return model.PemeriksaanLab{}, ErrNotFound
@@ -42,7 +104,10 @@ func (r *PemeriksaanLabRepository) GetById(id int) (model.PemeriksaanLab, error)
 }
 
 func (r *PemeriksaanLabRepository) Update(id int, hasilLab model.PemeriksaanLab) (model.PemeriksaanLab, error) {
-	result := r.DB.Model(&model.PemeriksaanLab{}).Where("id_pemeriksaan_lab = ?", id).Updates(&hasilLab)
+	result := r.DB.Model(&model.PemeriksaanLab{}).
+		Where("id_pemeriksaan_lab = ?", id).
+		Select("hasil", "nilai_numerik", "flag", "nilai_rujukan", "updated_at").
+		Updates(&hasilLab)
 	if result.Error != nil {
 		return model.PemeriksaanLab{}, result.Error
 	}
@@ -10,7 +10,7 @@ import (
 
 type ParamsGetAllPetugas struct {
 	NameOrUsernameFilter string `form:"search" binding:"omitempty,sanitize"`
-	RoleFilter           string `form:"role" binding:"omitempty,oneof=Administrasi Poliklinik Dokter Lab"`
+	RoleFilter           string `form:"role" binding:"omitempty,oneof=Administrasi Poliklinik Dokter Lab Apotek"`
 	StatusFilter         string `form:"status" binding:"omitempty,oneof=aktif nonaktif"`
 	SortBy               string `form:"sort" binding:"omitempty,sanitize"`
 	Page                 int    `form:"page" binding:"omitempty,gt=0"`
@@ -134,3 +134,15 @@ func (r *petugasRepository) UpdatePassword(id int, newHashedPassword string) err
 
 	return nil
 }
+
+func (r *petugasRepository) IncrementTokenVersion(id int) error {
+	result := r.DB.Model(&model.Petugas{}).Where("id_petugas = ?", id).
+		Update("token_version", gorm.Expr("token_version + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
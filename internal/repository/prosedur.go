@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/pkg/utils/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ParamsGetAllProsedur struct {
+	KodeFilter   string `form:"kode" binding:"omitempty,sanitize"`
+	NamaFilter   string `form:"nama" binding:"omitempty,sanitize"`
+	StatusFilter string `form:"status" binding:"omitempty,oneof=aktif nonaktif"`
+	SortBy       string `form:"sort" binding:"omitempty,sanitize"`
+	Page         int    `form:"page" binding:"omitempty,gt=0"`
+	PageSize     int    `form:"pageSize" binding:"omitempty,gt=0"`
+}
+
+type ProsedurRepository struct {
+	DB *gorm.DB
+}
+
+func NewProsedurRepository(db *gorm.DB) *ProsedurRepository {
+	return &ProsedurRepository{DB: db}
+}
+
+func (r *ProsedurRepository) Create(prosedur model.Prosedur) (model.Prosedur, error) {
+	result := r.DB.Create(&prosedur)
+	return prosedur, result.Error
+}
+
+func (r *ProsedurRepository) GetAll(params ParamsGetAllProsedur) ([]model.Prosedur, pagination.Metadata, error) {
+	var allProsedur []model.Prosedur
+	var totalRecords int64
+
+	db := r.DB.Model(&model.Prosedur{})
+
+	if params.KodeFilter != "" {
+		db = db.Where("kode_prosedur ILIKE ?", "%"+params.KodeFilter+"%")
+	}
+	if params.NamaFilter != "" {
+		db = db.Where("nama_prosedur ILIKE ?", "%"+params.NamaFilter+"%")
+	}
+	if params.StatusFilter != "" {
+		db = db.Where("status_prosedur = ?", params.StatusFilter)
+	}
+
+	if err := db.Count(&totalRecords).Error; err != nil {
+		return nil, pagination.Metadata{}, err
+	}
+
+	metadata := pagination.CalculateMetadata(int(totalRecords), params.Page, params.PageSize)
+
+	sortWhitelist := map[string]string{
+		"kode_asc":  "kode_prosedur ASC",
+		"kode_desc": "kode_prosedur DESC",
+		"nama_asc":  "nama_prosedur ASC",
+		"nama_desc": "nama_prosedur DESC",
+	}
+	orderByClause := "kode_prosedur ASC"
+	if sort, ok := sortWhitelist[params.SortBy]; ok {
+		orderByClause = sort
+	}
+	db = db.Order(orderByClause)
+
+	db = db.Limit(metadata.PageSize).Offset((metadata.CurrentPage - 1) * metadata.PageSize)
+
+	if err := db.Find(&allProsedur).Error; err != nil {
+		return nil, pagination.Metadata{}, err
+	}
+
+	return allProsedur, metadata, nil
+}
+
+func (r *ProsedurRepository) GetByID(id int) (model.Prosedur, error) {
+	var prosedur model.Prosedur
+	result := r.DB.
+		Preload("Induk").
+		Preload("Turunan", func(db *gorm.DB) *gorm.DB { return db.Order("kode_prosedur ASC") }).
+		First(&prosedur, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return model.Prosedur{}, ErrNotFound
+		}
+		return model.Prosedur{}, result.Error
+	}
+	return prosedur, nil
+}
+
+func (r *ProsedurRepository) Update(id int, prosedur model.Prosedur) (model.Prosedur, error) {
+	prosedur.ID = id
+	result := r.DB.Model(&model.Prosedur{}).Where("id_prosedur = ?", id).Updates(&prosedur)
+	if result.Error != nil {
+		return model.Prosedur{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.Prosedur{}, ErrNotFound
+	}
+	return r.GetByID(id)
+}
+
+func (r *ProsedurRepository) Delete(id int) error {
+	result := r.DB.Delete(&model.Prosedur{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// termasuk kode yang sudah dihapus, karena kode_prosedur tetap unik walau soft delete. Kode dicocokkan
+// tanpa membedakan huruf besar kecil agar baris lama seperti "a09" tetap ditemukan
+func (r *ProsedurRepository) GetByKodes(kodes []string) ([]model.Prosedur, error) {
+	var list []model.Prosedur
+	upper := make([]string, 0, len(kodes))
+	for _, kode := range kodes {
+		upper = append(upper, strings.ToUpper(kode))
+	}
+	kodes = upper
+	for start := 0; start < len(kodes); start += importBatchSize {
+		end := min(start+importBatchSize, len(kodes))
+		var batch []model.Prosedur
+		if err := r.DB.Unscoped().Where("upper(kode_prosedur) IN ?", kodes[start:end]).Find(&batch).Error; err != nil {
+			return nil, err
+		}
+		list = append(list, batch...)
+	}
+	return list, nil
+}
+
+// upsert berdasarkan kode_prosedur dalam satu transaksi, kode yang sebelumnya dihapus ikut dipulihkan
+func (r *ProsedurRepository) Upsert(list []model.Prosedur) error {
+	if len(list) == 0 {
+		return nil
+	}
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "kode_prosedur"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"nama_prosedur", "deskripsi_prosedur", "status_prosedur", "bab", "blok", "kode_induk", "deleted_at", "updated_at",
+			}),
+		}).CreateInBatches(&list, importBatchSize).Error
+	})
+}
+
+// urutan hasil sama dengan pencarian ICD: kode persis, awalan kode, awalan nama, lalu kemiripan nama
+func (r *ProsedurRepository) Search(params model.ParamsSearchKatalog) ([]model.Prosedur, error) {
+	var list []model.Prosedur
+	pattern := escapeLike(params.Query)
+	err := r.DB.
+		Where("status_prosedur = ?", "aktif").
+		Where("upper(kode_prosedur) LIKE upper(?) OR nama_prosedur ILIKE ? OR nama_prosedur % ?", pattern+"%", "%"+pattern+"%", params.Query).
+		Order(clause.OrderBy{Expression: gorm.Expr(`
+			CASE
+				WHEN upper(kode_prosedur) = upper(?) THEN 0
+				WHEN upper(kode_prosedur) LIKE upper(?) THEN 1
+				WHEN nama_prosedur ILIKE ? THEN 2
+				ELSE 3
+			END, similarity(nama_prosedur, ?) DESC, kode_prosedur ASC`,
+			params.Query, pattern+"%", pattern+"%", params.Query)}).
+		Limit(params.Limit).
+		Find(&list).Error
+	return list, err
+}
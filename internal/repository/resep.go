@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/pkg/utils/pagination"
+	"gorm.io/gorm"
+)
+
+type ParamsGetAllResep struct {
+	StatusFilter string `form:"status" binding:"omitempty,oneof=menunggu diserahkan"`
+	PasienID     int    `form:"pasien_id" binding:"omitempty,gt=0"`
+	Page         int    `form:"page" binding:"omitempty,gt=0"`
+	PageSize     int    `form:"pageSize" binding:"omitempty,gt=0"`
+}
+
+type ResepRepository struct {
+	DB *gorm.DB
+}
+
+func NewResepRepository(db *gorm.DB) *ResepRepository {
+	return &ResepRepository{DB: db}
+}
+
+func (r *ResepRepository) preload(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Pasien").
+		Preload("Dokter").
+		Preload("Penyerah").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("urutan ASC") }).
+		Preload("Items.Obat", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Peringatan")
+}
+
+// resep dan item-itemnya disimpan dalam satu transaksi
+func (r *ResepRepository) Create(resep model.Resep) (model.Resep, error) {
+	if err := r.DB.Create(&resep).Error; err != nil {
+		return model.Resep{}, err
+	}
+	return r.GetByID(resep.ID)
+}
+
+func (r *ResepRepository) GetByID(id int) (model.Resep, error) {
+	var resep model.Resep
+	result := r.preload(r.DB).First(&resep, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return model.Resep{}, ErrNotFound
+		}
+		return model.Resep{}, result.Error
+	}
+	return resep, nil
+}
+
+func (r *ResepRepository) GetAllByPemeriksaanID(pemeriksaanID int) ([]model.Resep, error) {
+	var list []model.Resep
+	err := r.preload(r.DB).
+		Where("id_pemeriksaan = ?", pemeriksaanID).
+		Order("created_at ASC").
+		Find(&list).Error
+	return list, err
+}
+
+// resep yang menunggu diurutkan dari yang paling lama supaya apotek melayani sesuai urutan
+func (r *ResepRepository) GetAll(params ParamsGetAllResep) ([]model.Resep, pagination.Metadata, error) {
+	var list []model.Resep
+	var totalRecords int64
+
+	db := r.DB.Model(&model.Resep{})
+	if params.StatusFilter != "" {
+		db = db.Where("status = ?", params.StatusFilter)
+	}
+	if params.PasienID > 0 {
+		db = db.Where("id_pasien = ?", params.PasienID)
+	}
+
+	if err := db.Count(&totalRecords).Error; err != nil {
+		return nil, pagination.Metadata{}, err
+	}
+
+	metadata := pagination.CalculateMetadata(int(totalRecords), params.Page, params.PageSize)
+
+	orderByClause := "created_at DESC"
+	if params.StatusFilter == model.StatusResepMenunggu {
+		orderByClause = "created_at ASC"
+	}
+
+	err := r.preload(db).
+		Order(orderByClause).
+		Limit(metadata.PageSize).
+		Offset((metadata.CurrentPage - 1) * metadata.PageSize).
+		Find(&list).Error
+	if err != nil {
+		return nil, pagination.Metadata{}, err
+	}
+	return list, metadata, nil
+}
+
+// hanya resep berstatus menunggu yang bisa diserahkan, ErrNotFound berarti resep tidak ada
+// atau sudah diserahkan request lain. Stok dikurangi sesuai mutasi keluar dalam transaksi yang
+// sama, ErrStokBerubah berarti sisa batch sudah tidak cukup dan resep tetap menunggu
+func (r *ResepRepository) Serahkan(id int, actorID int, at time.Time, mutasi []model.StokMutasi) (model.Resep, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Resep{}).
+			Where("id_resep = ?", id).
+			Where("status = ?", model.StatusResepMenunggu).
+			Updates(map[string]any{
+				"status":          model.StatusResepDiserahkan,
+				"diserahkan_at":   at,
+				"diserahkan_oleh": sql.NullInt64{Int64: int64(actorID), Valid: actorID > 0},
+				"updated_at":      at,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		for _, m := range mutasi {
+			if err := ubahSisaBatch(tx, m.StokBatchID, m.Jumlah); err != nil {
+				return err
+			}
+		}
+		if len(mutasi) == 0 {
+			return nil
+		}
+		return tx.Create(&mutasi).Error
+	})
+	if err != nil {
+		return model.Resep{}, err
+	}
+	return r.GetByID(id)
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"database/sql"
 	"fmt"
 
 	"github.com/franklindh/simedis-api/internal/model"
@@ -33,9 +34,9 @@ func seedPoli(db *gorm.DB) error {
 	}
 
 	polis := []model.Poli{
-		{Nama: "Poli Umum", Status: "aktif"},
-		{Nama: "Poli Gigi", Status: "aktif"},
-		{Nama: "Poli Anak", Status: "aktif"},
+		{Nama: "Poli Umum", Status: "aktif", KodeAntrian: sql.NullString{String: "UM", Valid: true}},
+		{Nama: "Poli Gigi", Status: "aktif", KodeAntrian: sql.NullString{String: "GI", Valid: true}},
+		{Nama: "Poli Anak", Status: "aktif", KodeAntrian: sql.NullString{String: "AN", Valid: true}},
 	}
 
 	if err := db.Create(&polis).Error; err != nil {
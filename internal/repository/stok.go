@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/pkg/utils/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// sisa batch sudah dipakai transaksi lain sehingga pengurangan akan membuatnya negatif
+var ErrStokBerubah = errors.New("stock changed by another transaction")
+
+type ParamsGetAllStokBatch struct {
+	ObatID       int  `form:"obat_id" binding:"omitempty,gt=0"`
+	IncludeHabis bool `form:"include_habis"`
+}
+
+type ParamsGetAllStokMutasi struct {
+	ObatID   int    `form:"obat_id" binding:"omitempty,gt=0"`
+	Jenis    string `form:"jenis" binding:"omitempty,oneof=masuk keluar penyesuaian"`
+	ResepID  int    `form:"resep_id" binding:"omitempty,gt=0"`
+	From     string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To       string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+	Page     int    `form:"page" binding:"omitempty,gt=0"`
+	PageSize int    `form:"pageSize" binding:"omitempty,gt=0"`
+}
+
+type StokRepository struct {
+	DB *gorm.DB
+}
+
+func NewStokRepository(db *gorm.DB) *StokRepository {
+	return &StokRepository{DB: db}
+}
+
+// batch dengan nomor yang sama untuk obat yang sama ditambah sisanya, mutasi masuk dicatat
+// dalam transaksi yang sama
+func (r *StokRepository) StokMasuk(batch model.StokBatch, mutasi model.StokMutasi) (model.StokBatch, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "id_obat"}, {Name: "nomor_batch"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"sisa":       gorm.Expr("stok_batch.sisa + excluded.sisa"),
+				"updated_at": gorm.Expr("excluded.updated_at"),
+			}),
+		}).Create(&batch).Error
+		if err != nil {
+			return err
+		}
+		mutasi.StokBatchID = batch.ID
+		return tx.Create(&mutasi).Error
+	})
+	if err != nil {
+		return model.StokBatch{}, err
+	}
+	return r.GetBatchByID(batch.ID)
+}
+
+func (r *StokRepository) Sesuaikan(mutasi model.StokMutasi) (model.StokBatch, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := ubahSisaBatch(tx, mutasi.StokBatchID, mutasi.Jumlah); err != nil {
+			return err
+		}
+		return tx.Create(&mutasi).Error
+	})
+	if err != nil {
+		return model.StokBatch{}, err
+	}
+	return r.GetBatchByID(mutasi.StokBatchID)
+}
+
+// sisa diubah dengan syarat tidak menjadi negatif, sehingga dua transaksi yang mengambil
+// batch yang sama tidak bisa mengeluarkan stok melebihi sisanya
+func ubahSisaBatch(tx *gorm.DB, batchID int, jumlah int) error {
+	result := tx.Model(&model.StokBatch{}).
+		Where("id_stok_batch = ?", batchID).
+		Where("sisa + ? >= 0", jumlah).
+		Updates(map[string]any{
+			"sisa":       gorm.Expr("sisa + ?", jumlah),
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStokBerubah
+	}
+	return nil
+}
+
+func (r *StokRepository) GetBatchByID(id int) (model.StokBatch, error) {
+	var batch model.StokBatch
+	result := r.DB.Preload("Obat", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).First(&batch, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return model.StokBatch{}, ErrNotFound
+		}
+		return model.StokBatch{}, result.Error
+	}
+	return batch, nil
+}
+
+func (r *StokRepository) GetBatchByNomor(obatID int, nomorBatch string) (model.StokBatch, error) {
+	var batch model.StokBatch
+	result := r.DB.Where("id_obat = ? AND nomor_batch = ?", obatID, nomorBatch).First(&batch)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return model.StokBatch{}, ErrNotFound
+		}
+		return model.StokBatch{}, result.Error
+	}
+	return batch, nil
+}
+
+// batch yang masih bisa dipakai dengan urutan FEFO: kedaluwarsa paling dekat lebih dulu
+func (r *StokRepository) GetBatchTersedia(obatIDs []int, today time.Time) ([]model.StokBatch, error) {
+	var list []model.StokBatch
+	if len(obatIDs) == 0 {
+		return list, nil
+	}
+	err := r.DB.
+		Where("id_obat IN ?", obatIDs).
+		Where("sisa > 0").
+		Where("tanggal_kedaluwarsa >= ?", today.Format("2006-01-02")).
+		Order("tanggal_kedaluwarsa ASC, id_stok_batch ASC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *StokRepository) GetAllBatch(params ParamsGetAllStokBatch) ([]model.StokBatch, error) {
+	var list []model.StokBatch
+	db := r.DB.Preload("Obat", func(db *gorm.DB) *gorm.DB { return db.Unscoped() })
+	if params.ObatID > 0 {
+		db = db.Where("id_obat = ?", params.ObatID)
+	}
+	if !params.IncludeHabis {
+		db = db.Where("sisa > 0")
+	}
+	err := db.Order("id_obat ASC, tanggal_kedaluwarsa ASC, id_stok_batch ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *StokRepository) GetAllMutasi(params ParamsGetAllStokMutasi) ([]model.StokMutasi, pagination.Metadata, error) {
+	var list []model.StokMutasi
+	var totalRecords int64
+
+	db := r.DB.Model(&model.StokMutasi{})
+	if params.ObatID > 0 {
+		db = db.Where("id_obat = ?", params.ObatID)
+	}
+	if params.Jenis != "" {
+		db = db.Where("jenis = ?", params.Jenis)
+	}
+	if params.ResepID > 0 {
+		db = db.Where("id_resep = ?", params.ResepID)
+	}
+	if params.From != "" {
+		db = db.Where("created_at >= ?", params.From)
+	}
+	if params.To != "" {
+		db = db.Where("created_at < CAST(? AS DATE) + INTERVAL '1 day'", params.To)
+	}
+
+	if err := db.Count(&totalRecords).Error; err != nil {
+		return nil, pagination.Metadata{}, err
+	}
+
+	metadata := pagination.CalculateMetadata(int(totalRecords), params.Page, params.PageSize)
+
+	err := db.
+		Preload("StokBatch").
+		Preload("Obat", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Petugas").
+		Order("created_at DESC, id_stok_mutasi DESC").
+		Limit(metadata.PageSize).
+		Offset((metadata.CurrentPage - 1) * metadata.PageSize).
+		Find(&list).Error
+	if err != nil {
+		return nil, pagination.Metadata{}, err
+	}
+	return list, metadata, nil
+}
+
+// obat aktif dengan stok minimum yang total sisa batch belum kedaluwarsanya sudah mencapai batas
+func (r *StokRepository) GetStokMenipis(today time.Time) ([]model.StokObat, error) {
+	var results []model.StokObat
+	err := r.DB.Table("obat").
+		Select(`obat.id_obat AS obat_id, obat.kode_obat, obat.nama_obat, obat.satuan, obat.stok_minimum,
+			COALESCE(SUM(stok_batch.sisa) FILTER (WHERE stok_batch.tanggal_kedaluwarsa >= ?), 0) AS total_stok`, today.Format("2006-01-02")).
+		Joins("LEFT JOIN stok_batch ON stok_batch.id_obat = obat.id_obat").
+		Where("obat.deleted_at IS NULL").
+		Where("obat.status_obat = ?", "aktif").
+		Where("obat.stok_minimum > 0").
+		Group("obat.id_obat, obat.kode_obat, obat.nama_obat, obat.satuan, obat.stok_minimum").
+		Having("COALESCE(SUM(stok_batch.sisa) FILTER (WHERE stok_batch.tanggal_kedaluwarsa >= ?), 0) <= obat.stok_minimum", today.Format("2006-01-02")).
+		Order("total_stok ASC, obat.nama_obat ASC").
+		Scan(&results).Error
+	return results, err
+}
+
+// batch yang masih bersisa dan kedaluwarsa paling lambat pada tanggal batas, termasuk
+// yang sudah lewat kedaluwarsa
+func (r *StokRepository) GetBatchKedaluwarsa(batas time.Time) ([]model.StokBatch, error) {
+	var list []model.StokBatch
+	err := r.DB.
+		Preload("Obat", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("sisa > 0").
+		Where("tanggal_kedaluwarsa <= ?", batas.Format("2006-01-02")).
+		Order("tanggal_kedaluwarsa ASC, id_stok_batch ASC").
+		Find(&list).Error
+	return list, err
+}
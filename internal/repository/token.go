@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TokenRepository struct {
+	DB *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) *TokenRepository {
+	return &TokenRepository{DB: db}
+}
+
+func (r *TokenRepository) CreateRefreshToken(token model.RefreshToken) (model.RefreshToken, error) {
+	result := r.DB.Create(&token)
+	return token, result.Error
+}
+
+func (r *TokenRepository) GetRefreshTokenByHash(tokenHash string) (model.RefreshToken, error) {
+	var token model.RefreshToken
+	result := r.DB.Where("token_hash = ?", tokenHash).First(&token)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return model.RefreshToken{}, ErrNotFound
+		}
+		return model.RefreshToken{}, result.Error
+	}
+	return token, nil
+}
+
+// token lama hanya bisa diganti sekali, request paralel dengan token yang sama akan dapat ErrNotFound
+func (r *TokenRepository) RotateRefreshToken(oldID int, newToken model.RefreshToken) (model.RefreshToken, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newToken).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&model.RefreshToken{}).
+			Where("id_refresh_token = ? AND revoked_at IS NULL", oldID).
+			Updates(map[string]any{"revoked_at": time.Now(), "replaced_by_id": newToken.ID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return model.RefreshToken{}, err
+	}
+	return newToken, nil
+}
+
+func (r *TokenRepository) RevokeRefreshToken(tokenHash string) error {
+	return r.DB.Model(&model.RefreshToken{}).
+		Where("token_hash = ? AND revoked_at IS NULL", tokenHash).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *TokenRepository) RevokeAllRefreshTokens(petugasID int) error {
+	return r.DB.Model(&model.RefreshToken{}).
+		Where("id_petugas = ? AND revoked_at IS NULL", petugasID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *TokenRepository) RevokeJTI(jti string, expiresAt time.Time) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		// sekalian bersihkan jti yang tokennya sudah kedaluwarsa
+		if err := tx.Where("expires_at < ?", time.Now()).Delete(&model.RevokedToken{}).Error; err != nil {
+			return err
+		}
+
+		revoked := model.RevokedToken{JTI: jti, ExpiresAt: expiresAt}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&revoked).Error
+	})
+}
+
+func (r *TokenRepository) IsJTIRevoked(jti string) (bool, error) {
+	var count int64
+	result := r.DB.Model(&model.RevokedToken{}).Where("jti = ?", jti).Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/franklindh/simedis-api/internal/handler"
+	"github.com/franklindh/simedis-api/internal/middleware"
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+func AlergiRoutes(rg *gin.RouterGroup, h *handler.AlergiHandler, auditRecorder middleware.AuditRecorder) {
+	alergiRoutes := rg.Group("/pasien/:id/alergi")
+	alergiRoutes.Use(middleware.AuditRead(auditRecorder, model.AuditEntityPasien))
+	{
+		alergiRoutes.GET("", h.GetAll)
+
+		user := alergiRoutes.Group("")
+		user.Use(middleware.Authorize("Dokter", "Poliklinik"))
+		{
+			user.POST("", h.Create)
+			user.PUT("/:alergi_id", h.Update)
+			user.DELETE("/:alergi_id", h.Delete)
+		}
+	}
+}
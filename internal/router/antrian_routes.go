@@ -10,6 +10,7 @@ func AntrianRoutes(rg *gin.RouterGroup, h *handler.AntrianHandler) {
 	antrianRoutes := rg.Group("/antrian")
 	{
 		antrianRoutes.GET("", h.GetAll)
+		antrianRoutes.GET("/stream", h.Stream)
 		antrianRoutes.GET("/:id", h.GetByID)
 
 		userAdmin := antrianRoutes.Group("")
@@ -20,9 +21,26 @@ func AntrianRoutes(rg *gin.RouterGroup, h *handler.AntrianHandler) {
 		}
 
 		userPoli := antrianRoutes.Group("")
-		userPoli.Use(middleware.Authorize("Administrasi", "Poliklnik"))
+		userPoli.Use(middleware.Authorize("Administrasi", "Poliklinik"))
 		{
 			userPoli.PUT("/:id", h.Update)
+			userPoli.POST("/:id/batal", h.Batal)
 		}
+
+		userPemeriksa := antrianRoutes.Group("")
+		userPemeriksa.Use(middleware.Authorize("Administrasi", "Poliklinik", "Dokter"))
+		{
+			userPemeriksa.POST("/:id/panggil", h.Panggil)
+			userPemeriksa.POST("/:id/periksa", h.Periksa)
+			userPemeriksa.POST("/:id/menunggu-lab", h.MenungguLab)
+			userPemeriksa.POST("/:id/selesai", h.Selesai)
+			userPemeriksa.POST("/:id/tidak-hadir", h.TidakHadir)
+		}
+	}
+
+	callNext := rg.Group("/jadwal/:id/antrian")
+	callNext.Use(middleware.Authorize("Administrasi", "Poliklinik", "Dokter"))
+	{
+		callNext.POST("/next", h.CallNext)
 	}
 }
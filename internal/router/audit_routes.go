@@ -0,0 +1,15 @@
+package router
+
+import (
+	"github.com/franklindh/simedis-api/internal/handler"
+	"github.com/franklindh/simedis-api/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func AuditRoutes(rg *gin.RouterGroup, h *handler.AuditHandler) {
+	auditRoutes := rg.Group("/audit")
+	auditRoutes.Use(middleware.Authorize("Administrasi"))
+	{
+		auditRoutes.GET("", h.GetAll)
+	}
+}
@@ -10,12 +10,15 @@ func IcdRoutes(rg *gin.RouterGroup, h *handler.IcdHandler) {
 	icdRoutes := rg.Group("/icd")
 	{
 		icdRoutes.GET("", h.GetAll)
+		icdRoutes.GET("/search", h.Search)
+		icdRoutes.GET("/kelompok", h.GetAllKelompok)
 		icdRoutes.GET("/:id", h.GetByID)
 
 		user := icdRoutes.Group("")
 		user.Use(middleware.Authorize("Administrasi"))
 		{
 			user.POST("", h.Create)
+			user.POST("/import", h.Import)
 			user.PUT("/:id", h.Update)
 			user.DELETE("/:id", h.Delete)
 		}
@@ -14,6 +14,7 @@ func LaporanRoutes(rg *gin.RouterGroup, h *handler.LaporanHandler) {
 		{
 			user.GET("/kunjungan-poli", h.GetKunjunganPoli)
 			user.GET("/penyakit-teratas", h.GetPenyakitTeratas)
+			user.GET("/tindakan-teratas", h.GetTindakanTeratas)
 		}
 	}
 }
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"github.com/franklindh/simedis-api/internal/handler"
+	"github.com/franklindh/simedis-api/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func ObatRoutes(rg *gin.RouterGroup, h *handler.ObatHandler) {
+	obatRoutes := rg.Group("/obat")
+	{
+		obatRoutes.GET("", h.GetAll)
+		obatRoutes.GET("/:id", h.GetByID)
+
+		user := obatRoutes.Group("")
+		user.Use(middleware.Authorize("Administrasi", "Apotek"))
+		{
+			user.POST("", h.Create)
+			user.PUT("/:id", h.Update)
+			user.DELETE("/:id", h.Delete)
+		}
+	}
+}
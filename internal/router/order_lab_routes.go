@@ -0,0 +1,30 @@
+package router
+
+import (
+	"github.com/franklindh/simedis-api/internal/handler"
+	"github.com/franklindh/simedis-api/internal/middleware"
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+func OrderLabRoutes(rg *gin.RouterGroup, h *handler.OrderLabHandler, auditRecorder middleware.AuditRecorder) {
+	pemeriksaanOrder := rg.Group("/pemeriksaan/:id/order-lab")
+	// :id adalah id pemeriksaan, akses baca dicatat pada entitas pemeriksaan
+	pemeriksaanOrder.Use(middleware.AuditRead(auditRecorder, model.AuditEntityPemeriksaan))
+	{
+		pemeriksaanOrder.GET("", middleware.Authorize("Dokter", "Poliklinik", "Lab"), h.GetAllByPemeriksaan)
+		pemeriksaanOrder.POST("", middleware.Authorize("Dokter"), h.Create)
+	}
+
+	orderRoutes := rg.Group("/order-lab")
+	orderRoutes.Use(middleware.AuditRead(auditRecorder, model.AuditEntityOrderLab))
+	{
+		orderRoutes.GET("", middleware.Authorize("Lab"), h.GetAll)
+		orderRoutes.GET("/:id", middleware.Authorize("Lab"), h.GetByID)
+		orderRoutes.POST("/:id/spesimen-diambil", middleware.Authorize("Lab", "Poliklinik"), h.AmbilSpesimen)
+		orderRoutes.POST("/:id/spesimen-diterima", middleware.Authorize("Lab"), h.TerimaSpesimen)
+		orderRoutes.PUT("/:id/hasil", middleware.Authorize("Lab"), h.SimpanHasil)
+		orderRoutes.POST("/:id/validasi", middleware.Authorize("Lab"), h.Validasi)
+		orderRoutes.POST("/:id/batal", middleware.Authorize("Dokter"), h.Batalkan)
+	}
+}
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"github.com/franklindh/simedis-api/internal/handler"
+	"github.com/franklindh/simedis-api/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func PanelLabRoutes(rg *gin.RouterGroup, h *handler.PanelLabHandler) {
+	panelLab := rg.Group("/panel-lab")
+	{
+		panelLab.GET("", h.GetAll)
+		panelLab.GET("/:id", h.GetByID)
+
+		user := panelLab.Group("")
+		user.Use(middleware.Authorize("Administrasi", "Lab"))
+		{
+			user.POST("", h.Create)
+			user.PUT("/:id", h.Update)
+			user.DELETE("/:id", h.Delete)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/franklindh/simedis-api/internal/handler"
+	"github.com/franklindh/simedis-api/internal/middleware"
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+func PasienPortalRoutes(rg *gin.RouterGroup, h *handler.PasienPortalHandler, auditRecorder middleware.AuditRecorder) {
+	meRoutes := rg.Group("/me")
+	{
+		meRoutes.PUT("/change-password", h.ChangePassword)
+		meRoutes.POST("/logout", h.Logout)
+
+		user := meRoutes.Group("")
+		user.Use(middleware.RequirePasswordChanged())
+		{
+			user.GET("", middleware.AuditRead(auditRecorder, model.AuditEntityPasien), h.GetProfile)
+			user.GET("/antrian", h.GetAntrian)
+			user.GET("/pemeriksaan", middleware.AuditRead(auditRecorder, model.AuditEntityPasien), h.GetRiwayatPemeriksaan)
+		}
+	}
+}
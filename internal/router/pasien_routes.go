@@ -3,11 +3,13 @@ package router
 import (
 	"github.com/franklindh/simedis-api/internal/handler"
 	"github.com/franklindh/simedis-api/internal/middleware"
+	"github.com/franklindh/simedis-api/internal/model"
 	"github.com/gin-gonic/gin"
 )
 
-func PasienRoutes(rg *gin.RouterGroup, h *handler.PasienHandler) {
+func PasienRoutes(rg *gin.RouterGroup, h *handler.PasienHandler, auditRecorder middleware.AuditRecorder) {
 	pasienRoutes := rg.Group("/pasien")
+	pasienRoutes.Use(middleware.AuditRead(auditRecorder, model.AuditEntityPasien))
 	{
 		pasienRoutes.GET("", h.GetAll)
 		pasienRoutes.GET("/:id", h.GetByID)
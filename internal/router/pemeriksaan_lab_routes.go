@@ -3,18 +3,24 @@ package router
 import (
 	"github.com/franklindh/simedis-api/internal/handler"
 	"github.com/franklindh/simedis-api/internal/middleware"
+	"github.com/franklindh/simedis-api/internal/model"
 	"github.com/gin-gonic/gin"
 )
 
-func PemeriksaanLabRoutes(rg *gin.RouterGroup, h *handler.PemeriksaanLabHandler) {
+func PemeriksaanLabRoutes(rg *gin.RouterGroup, h *handler.PemeriksaanLabHandler, auditRecorder middleware.AuditRecorder) {
 
 	hasilLabGroup := rg.Group("/pemeriksaan/:id/hasil-lab")
+	hasilLabGroup.Use(middleware.Authorize("Dokter", "Lab", "Poliklinik"))
+	// :id adalah id pemeriksaan, akses baca dicatat pada entitas pemeriksaan
+	hasilLabGroup.Use(middleware.AuditRead(auditRecorder, model.AuditEntityPemeriksaan))
 	{
 
-		hasilLabGroup.GET("", h.GetAll, middleware.Authorize("Dokter", "Lab", "Poliklinik"))
-		hasilLabGroup.POST("", h.Create, middleware.Authorize("Dokter", "Lab", "Poliklinik"))
+		hasilLabGroup.GET("", h.GetAll)
+		hasilLabGroup.GET("/report.pdf", h.CetakLaporan)
+		hasilLabGroup.POST("", h.Create)
+		hasilLabGroup.POST("/panel/:panel_id", h.CreatePanel)
 	}
 
-	rg.PUT("/hasil-lab/:hasil_id", h.Update, middleware.Authorize("Dokter", "Lab", "Poliklinik"))
-	rg.DELETE("/hasil-lab/:hasil_id", h.Delete, middleware.Authorize("Dokter", "Lab", "Poliklinik"))
+	rg.PUT("/hasil-lab/:hasil_id", middleware.Authorize("Dokter", "Lab", "Poliklinik"), h.Update)
+	rg.DELETE("/hasil-lab/:hasil_id", middleware.Authorize("Dokter", "Lab", "Poliklinik"), h.Delete)
 }
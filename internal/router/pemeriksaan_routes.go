@@ -3,19 +3,33 @@ package router
 import (
 	"github.com/franklindh/simedis-api/internal/handler"
 	"github.com/franklindh/simedis-api/internal/middleware"
+	"github.com/franklindh/simedis-api/internal/model"
 	"github.com/gin-gonic/gin"
 )
 
-func PemeriksaanRoutes(rg *gin.RouterGroup, h *handler.PemeriksaanHandler) {
+func PemeriksaanRoutes(rg *gin.RouterGroup, h *handler.PemeriksaanHandler, auditRecorder middleware.AuditRecorder) {
 	pemeriksaanRoutes := rg.Group("/pemeriksaan")
+	pemeriksaanRoutes.Use(middleware.AuditRead(auditRecorder, model.AuditEntityPemeriksaan))
 	{
 		pemeriksaanRoutes.GET("/:id", h.GetByID)
+		pemeriksaanRoutes.GET("/:id/revisions", h.GetRevisions)
+		pemeriksaanRoutes.GET("/:id/revisions/diff", h.DiffRevisions)
 
 		user := pemeriksaanRoutes.Group("")
 		user.Use(middleware.Authorize("Dokter", "Poliklinik"))
 		{
 			user.POST("", h.Create)
 			user.PUT("/:id", h.Update)
+			user.POST("/:id/batal", h.Void)
+			user.POST("/:id/addendum", h.AddAddendum)
+		}
+
+		dokter := pemeriksaanRoutes.Group("")
+		dokter.Use(middleware.Authorize("Dokter"))
+		{
+			dokter.POST("/:id/finalisasi", h.Finalize)
 		}
 	}
+
+	rg.GET("/pasien/:id/vitals", middleware.AuditRead(auditRecorder, model.AuditEntityPasien), h.GetVitalTrend)
 }
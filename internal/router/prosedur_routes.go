@@ -0,0 +1,25 @@
+package router
+
+import (
+	"github.com/franklindh/simedis-api/internal/handler"
+	"github.com/franklindh/simedis-api/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func ProsedurRoutes(rg *gin.RouterGroup, h *handler.ProsedurHandler) {
+	prosedurRoutes := rg.Group("/prosedur")
+	{
+		prosedurRoutes.GET("", h.GetAll)
+		prosedurRoutes.GET("/search", h.Search)
+		prosedurRoutes.GET("/:id", h.GetByID)
+
+		user := prosedurRoutes.Group("")
+		user.Use(middleware.Authorize("Administrasi"))
+		{
+			user.POST("", h.Create)
+			user.POST("/import", h.Import)
+			user.PUT("/:id", h.Update)
+			user.DELETE("/:id", h.Delete)
+		}
+	}
+}
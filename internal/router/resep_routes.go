@@ -0,0 +1,27 @@
+package router
+
+import (
+	"github.com/franklindh/simedis-api/internal/handler"
+	"github.com/franklindh/simedis-api/internal/middleware"
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+func ResepRoutes(rg *gin.RouterGroup, h *handler.ResepHandler, auditRecorder middleware.AuditRecorder) {
+	pemeriksaanResep := rg.Group("/pemeriksaan/:id/resep")
+	// :id adalah id pemeriksaan, akses baca dicatat pada entitas pemeriksaan
+	pemeriksaanResep.Use(middleware.AuditRead(auditRecorder, model.AuditEntityPemeriksaan))
+	{
+		pemeriksaanResep.GET("", middleware.Authorize("Dokter", "Poliklinik", "Apotek"), h.GetAllByPemeriksaan)
+		pemeriksaanResep.POST("", middleware.Authorize("Dokter"), h.Create)
+	}
+
+	resepRoutes := rg.Group("/resep")
+	resepRoutes.Use(middleware.Authorize("Dokter", "Apotek"))
+	resepRoutes.Use(middleware.AuditRead(auditRecorder, model.AuditEntityResep))
+	{
+		resepRoutes.GET("", h.GetAll)
+		resepRoutes.GET("/:id", h.GetByID)
+		resepRoutes.POST("/:id/serahkan", middleware.Authorize("Apotek"), h.Serahkan)
+	}
+}
@@ -3,6 +3,7 @@ package router
 import (
 	"time"
 
+	"github.com/franklindh/simedis-api/internal/broker"
 	"github.com/franklindh/simedis-api/internal/config"
 	"github.com/franklindh/simedis-api/internal/handler"
 	"github.com/franklindh/simedis-api/internal/middleware"
@@ -27,8 +28,15 @@ func New(app *config.Application) *gin.Engine {
 	poliService := service.NewPoliService(poliRepo)
 	poliHandler := handler.NewPoliHandler(poliService)
 
+	auditRepo := repository.NewAuditRepository(db)
+	auditService := service.NewAuditService(auditRepo)
+	auditHandler := handler.NewAuditHandler(auditService)
+
+	tokenRepo := repository.NewTokenRepository(db)
+	petugasCache := service.NewPetugasCache(cfg.AuthCacheTTL)
+
 	petugasRepo := repository.NewPetugasRepository(db)
-	petugasService := service.NewPetugasService(petugasRepo, cfg)
+	petugasService := service.NewPetugasService(petugasRepo, tokenRepo, petugasCache, cfg)
 	petugasHandler := handler.NewPetugasHandler(petugasService)
 
 	jadwalRepo := repository.NewJadwalRepository(db)
@@ -36,19 +44,25 @@ func New(app *config.Application) *gin.Engine {
 	jadwalHandler := handler.NewJadwalHandler(jadwalService)
 
 	pasienRepo := repository.NewPasienRepository(db)
-	pasienService := service.NewPasienService(pasienRepo)
+	pasienService := service.NewPasienService(pasienRepo, tokenRepo, auditService, cfg)
 	pasienHandler := handler.NewPasienHandler(pasienService)
 
+	antrianBroker := broker.NewMemoryBroker()
+
 	antrianRepo := repository.NewAntrianRepository(db)
-	antrianService := service.NewAntrianService(antrianRepo, jadwalRepo)
+	antrianService := service.NewAntrianService(antrianRepo, jadwalRepo, antrianBroker)
 	antrianHandler := handler.NewAntrianHandler(antrianService)
 
 	icdRepo := repository.NewIcdRepository(db)
 	icdService := service.NewIcdService(icdRepo)
 	icdHandler := handler.NewIcdHandler(icdService)
 
+	prosedurRepo := repository.NewProsedurRepository(db)
+	prosedurService := service.NewProsedurService(prosedurRepo)
+	prosedurHandler := handler.NewProsedurHandler(prosedurService)
+
 	pemeriksaanRepo := repository.NewPemeriksaanRepository(db)
-	pemeriksaanService := service.NewPemeriksaanService(pemeriksaanRepo, antrianRepo)
+	pemeriksaanService := service.NewPemeriksaanService(pemeriksaanRepo, antrianRepo, petugasRepo, antrianBroker, auditService, cfg)
 	pemeriksaanHandler := handler.NewPemeriksaanHandler(pemeriksaanService)
 
 	laporanRepo := repository.NewLaporanRepository(db)
@@ -59,10 +73,41 @@ func New(app *config.Application) *gin.Engine {
 	jenisPemeriksaanLabService := service.NewJenisPemeriksaanLabService(jenisPemeriksaanLabRepo)
 	jenisPemeriksaanLabHandler := handler.NewJenisPemeriksaanLabHandler(jenisPemeriksaanLabService)
 
+	panelLabRepo := repository.NewPanelLabRepository(db)
+	panelLabService := service.NewPanelLabService(panelLabRepo, jenisPemeriksaanLabRepo)
+	panelLabHandler := handler.NewPanelLabHandler(panelLabService)
+
+	orderLabRepo := repository.NewOrderLabRepository(db)
+
 	pemeriksaanLabRepo := repository.NewPemeriksaanLabRepository(db)
-	pemeriksaanLabService := service.NewPemeriksaanLabService(pemeriksaanLabRepo)
+	pemeriksaanLabService := service.NewPemeriksaanLabService(pemeriksaanLabRepo, jenisPemeriksaanLabRepo, panelLabRepo, pemeriksaanRepo, orderLabRepo, auditService, cfg)
 	pemeriksaanLabHandler := handler.NewPemeriksaanLabHandler(pemeriksaanLabService)
 
+	orderLabService := service.NewOrderLabService(orderLabRepo, jenisPemeriksaanLabRepo, panelLabRepo, pemeriksaanRepo, auditService)
+	orderLabHandler := handler.NewOrderLabHandler(orderLabService)
+
+	obatRepo := repository.NewObatRepository(db)
+	obatService := service.NewObatService(obatRepo)
+	obatHandler := handler.NewObatHandler(obatService)
+
+	stokRepo := repository.NewStokRepository(db)
+	stokService := service.NewStokService(stokRepo, obatRepo)
+	stokHandler := handler.NewStokHandler(stokService)
+
+	alergiRepo := repository.NewAlergiRepository(db)
+	alergiService := service.NewAlergiService(alergiRepo, pasienRepo, auditService)
+	alergiHandler := handler.NewAlergiHandler(alergiService)
+
+	interaksiObatRepo := repository.NewInteraksiObatRepository(db)
+
+	resepRepo := repository.NewResepRepository(db)
+	resepService := service.NewResepService(resepRepo, obatRepo, stokRepo, alergiRepo, interaksiObatRepo, pemeriksaanRepo, auditService)
+	resepHandler := handler.NewResepHandler(resepService)
+
+	pasienPortalHandler := handler.NewPasienPortalHandler(pasienService, antrianService, pemeriksaanService)
+
+	authService := service.NewAuthService(tokenRepo, petugasRepo, pasienRepo, petugasCache)
+
 	router.Use(secure.New(secure.Config{
 		STSSeconds:           31536000,
 		STSIncludeSubdomains: true,
@@ -89,24 +134,44 @@ func New(app *config.Application) *gin.Engine {
 	limitermiddleware := ginmiddleware.NewMiddleware(limiter.New(store, rate))
 	router.Use(limitermiddleware)
 
-	router.Use(gzip.Gzip(gzip.DefaultCompression))
+	// stream SSE tidak dikompres supaya event langsung terkirim
+	router.Use(gzip.Gzip(gzip.DefaultCompression, gzip.WithExcludedPathsRegexs([]string{`.*/stream$`})))
 
 	// public
 	router.POST("/login/petugas", petugasHandler.Login)
+	router.POST("/login/pasien", pasienHandler.Login)
+	router.POST("/token/refresh", petugasHandler.RefreshToken)
+	router.GET("/display/poli/:id", antrianHandler.Display)
+	router.GET("/display/poli/:id/stream", antrianHandler.DisplayStream)
 
 	authRoutes := router.Group("/")
-	authRoutes.Use(middleware.AuthMiddleware(cfg))
+	authRoutes.Use(middleware.AuthMiddleware(cfg, authService))
 	{
+		authRoutes.POST("/logout", petugasHandler.Logout)
 		PoliRoutes(authRoutes, poliHandler)
 		PetugasRoutes(authRoutes, petugasHandler)
 		JadwalRoutes(authRoutes, jadwalHandler)
-		PasienRoutes(authRoutes, pasienHandler)
+		PasienRoutes(authRoutes, pasienHandler, auditService)
+		AlergiRoutes(authRoutes, alergiHandler, auditService)
 		AntrianRoutes(authRoutes, antrianHandler)
 		IcdRoutes(authRoutes, icdHandler)
-		PemeriksaanRoutes(authRoutes, pemeriksaanHandler)
+		ProsedurRoutes(authRoutes, prosedurHandler)
+		PemeriksaanRoutes(authRoutes, pemeriksaanHandler, auditService)
 		LaporanRoutes(authRoutes, laporanHandler)
 		JenisPemeriksaanLabRoutes(authRoutes, jenisPemeriksaanLabHandler)
-		PemeriksaanLabRoutes(authRoutes, pemeriksaanLabHandler)
+		PanelLabRoutes(authRoutes, panelLabHandler)
+		PemeriksaanLabRoutes(authRoutes, pemeriksaanLabHandler, auditService)
+		OrderLabRoutes(authRoutes, orderLabHandler, auditService)
+		ObatRoutes(authRoutes, obatHandler)
+		ResepRoutes(authRoutes, resepHandler, auditService)
+		StokRoutes(authRoutes, stokHandler)
+		AuditRoutes(authRoutes, auditHandler)
+	}
+
+	pasienRoutes := router.Group("/")
+	pasienRoutes.Use(middleware.PasienAuthMiddleware(cfg, authService))
+	{
+		PasienPortalRoutes(pasienRoutes, pasienPortalHandler, auditService)
 	}
 
 	return router
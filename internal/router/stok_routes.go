@@ -0,0 +1,19 @@
+package router
+
+import (
+	"github.com/franklindh/simedis-api/internal/handler"
+	"github.com/franklindh/simedis-api/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func StokRoutes(rg *gin.RouterGroup, h *handler.StokHandler) {
+	stokRoutes := rg.Group("/stok")
+	stokRoutes.Use(middleware.Authorize("Apotek", "Administrasi"))
+	{
+		stokRoutes.GET("/batch", h.GetAllBatch)
+		stokRoutes.GET("/mutasi", h.GetAllMutasi)
+		stokRoutes.GET("/peringatan", h.GetPeringatan)
+		stokRoutes.POST("/masuk", middleware.Authorize("Apotek"), h.StokMasuk)
+		stokRoutes.POST("/penyesuaian", middleware.Authorize("Apotek"), h.Sesuaikan)
+	}
+}
@@ -1,22 +1,61 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func SignToken(userID, username, role string, secret []byte) (string, error) {
-	expTime := time.Now().Add(24 * time.Hour)
+const (
+	TokenTypePetugas = "petugas"
+	TokenTypePasien  = "pasien"
 
+	refreshTokenBytes = 32
+)
+
+// ver dibandingkan dengan token_version petugas, naik saat password/role/status berubah
+func SignToken(userID, username, role string, tokenVersion int, ttl time.Duration, secret []byte) (string, error) {
 	mapClaims := jwt.MapClaims{
-		"exp":      jwt.NewNumericDate(expTime),
-		"iat":      jwt.NewNumericDate(time.Now()),
 		"sub":      userID,
 		"username": username,
 		"role":     role,
+		"typ":      TokenTypePetugas,
+		"ver":      tokenVersion,
+	}
+
+	return signClaims(mapClaims, ttl, secret)
+}
+
+// token pasien dipisah lewat claim typ supaya tidak bisa dipakai di route petugas, ver
+// dibandingkan dengan token_version pasien yang naik saat password berubah
+func SignPasienToken(pasienID, username string, passwordChanged bool, tokenVersion int, secret []byte) (string, error) {
+	mapClaims := jwt.MapClaims{
+		"sub":              pasienID,
+		"username":         username,
+		"role":             "Pasien",
+		"typ":              TokenTypePasien,
+		"password_changed": passwordChanged,
+		"ver":              tokenVersion,
+	}
+
+	return signClaims(mapClaims, 24*time.Hour, secret)
+}
+
+func signClaims(mapClaims jwt.MapClaims, ttl time.Duration, secret []byte) (string, error) {
+	jti, err := randomHex(16)
+	if err != nil {
+		return "", err
 	}
 
+	now := time.Now()
+	mapClaims["jti"] = jti
+	mapClaims["exp"] = jwt.NewNumericDate(now.Add(ttl))
+	mapClaims["iat"] = jwt.NewNumericDate(now)
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, mapClaims)
 
 	tokenString, err := token.SignedString(secret)
@@ -26,3 +65,25 @@ func SignToken(userID, username, role string, secret []byte) (string, error) {
 
 	return tokenString, nil
 }
+
+// refresh token berupa string acak, yang disimpan di database hanya hash-nya
+func GenerateRefreshToken() (string, error) {
+	b := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -25,9 +25,21 @@ func FormatValidationError(err error) string {
 			case "required":
 				message = fmt.Sprintf("Field '%s' is required", e.Field())
 			case "min":
-				message = fmt.Sprintf("Field '%s' must be at least %s characters long", e.Field(), e.Param())
+				if isNumberKind(e.Kind()) {
+					message = fmt.Sprintf("Field '%s' must be at least %s", e.Field(), e.Param())
+				} else {
+					message = fmt.Sprintf("Field '%s' must be at least %s characters long", e.Field(), e.Param())
+				}
 			case "max":
-				message = fmt.Sprintf("Field '%s' must not exceed %s characters", e.Field(), e.Param())
+				if isNumberKind(e.Kind()) {
+					message = fmt.Sprintf("Field '%s' must not exceed %s", e.Field(), e.Param())
+				} else {
+					message = fmt.Sprintf("Field '%s' must not exceed %s characters", e.Field(), e.Param())
+				}
+			case "required_with":
+				message = fmt.Sprintf("Field '%s' is required when '%s' is filled", e.Field(), e.Param())
+			case "gtfield":
+				message = fmt.Sprintf("Field '%s' must be greater than '%s'", e.Field(), e.Param())
 			case "oneof":
 				message = fmt.Sprintf("Field '%s' must be one of [%s]", e.Field(), e.Param())
 			default:
@@ -42,6 +54,16 @@ func FormatValidationError(err error) string {
 	return err.Error()
 }
 
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
 func ValidatePetugasUsername(petugas model.CreatePetugasRequest) error {
 	if petugas.Username == "" || petugas.Nama == "" {
 		return errors.New("username and name are required fields")
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/franklindh/simedis-api/internal/model"
+)
+
+var ErrAlergiDuplikat = errors.New("allergy for this substance is already recorded")
+
+type AlergiService struct {
+	repo       AlergiRepository
+	pasienRepo PasienRepository
+	audit      AuditRecorder
+}
+
+func NewAlergiService(repo AlergiRepository, pasienRepo PasienRepository, audit AuditRecorder) *AlergiService {
+	return &AlergiService{repo: repo, pasienRepo: pasienRepo, audit: audit}
+}
+
+func (s *AlergiService) CreateAlergi(ctx context.Context, pasienID int, req model.AlergiPasienRequest, actorID int) (model.AlergiPasienResponse, error) {
+	if _, err := s.pasienRepo.GetById(pasienID); err != nil {
+		return model.AlergiPasienResponse{}, err
+	}
+	if err := s.cekDuplikat(pasienID, 0, req.Zat); err != nil {
+		return model.AlergiPasienResponse{}, err
+	}
+
+	alergi := req.ToModel(pasienID)
+	alergi.DicatatOleh = sql.NullInt64{Int64: int64(actorID), Valid: actorID > 0}
+	created, err := s.repo.Create(alergi)
+	if err != nil {
+		return model.AlergiPasienResponse{}, fmt.Errorf("failed to create allergy: %w", err)
+	}
+
+	resp := model.ToAlergiPasienResponse(created)
+	recordAudit(ctx, s.audit, model.AuditActionCreate, model.AuditEntityAlergiPasien, created.ID, nil, resp)
+	return resp, nil
+}
+
+func (s *AlergiService) GetAllByPasienID(ctx context.Context, pasienID int) ([]model.AlergiPasienResponse, error) {
+	list, err := s.repo.GetAllByPasienID(pasienID)
+	if err != nil {
+		return nil, err
+	}
+	return model.ToAlergiPasienResponseList(list), nil
+}
+
+func (s *AlergiService) UpdateAlergi(ctx context.Context, pasienID int, id int, req model.AlergiPasienRequest) (model.AlergiPasienResponse, error) {
+	existing, err := s.repo.GetByID(pasienID, id)
+	if err != nil {
+		return model.AlergiPasienResponse{}, err
+	}
+	if err := s.cekDuplikat(pasienID, id, req.Zat); err != nil {
+		return model.AlergiPasienResponse{}, err
+	}
+
+	updated, err := s.repo.Update(pasienID, id, req.ToModel(pasienID))
+	if err != nil {
+		return model.AlergiPasienResponse{}, err
+	}
+
+	resp := model.ToAlergiPasienResponse(updated)
+	recordAudit(ctx, s.audit, model.AuditActionUpdate, model.AuditEntityAlergiPasien, id, model.ToAlergiPasienResponse(existing), resp)
+	return resp, nil
+}
+
+func (s *AlergiService) DeleteAlergi(ctx context.Context, pasienID int, id int) error {
+	existing, err := s.repo.GetByID(pasienID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(pasienID, id); err != nil {
+		return err
+	}
+	recordAudit(ctx, s.audit, model.AuditActionDelete, model.AuditEntityAlergiPasien, id, model.ToAlergiPasienResponse(existing), nil)
+	return nil
+}
+
+// satu zat hanya dicatat sekali per pasien, exceptID dipakai saat update
+func (s *AlergiService) cekDuplikat(pasienID int, exceptID int, zat string) error {
+	list, err := s.repo.GetAllByPasienID(pasienID)
+	if err != nil {
+		return err
+	}
+	for _, a := range list {
+		if a.ID != exceptID && model.NormalisasiZat(a.Zat) == model.NormalisasiZat(zat) {
+			return ErrAlergiDuplikat
+		}
+	}
+	return nil
+}
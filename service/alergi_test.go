@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAlergiService_CreateAlergi(t *testing.T) {
+	mockRepo := new(MockAlergiRepository)
+	mockPasienRepo := new(MockPasienRepository)
+	alergiService := NewAlergiService(mockRepo, mockPasienRepo, nil)
+	req := model.AlergiPasienRequest{Zat: "Amoksisilin", Reaksi: "ruam", TingkatKeparahan: model.KeparahanAlergiSedang}
+
+	t.Run("Success: Allergy recorded", func(t *testing.T) {
+		mockPasienRepo.On("GetById", 7).Return(model.Pasien{ID: 7}, nil).Once()
+		mockRepo.On("GetAllByPasienID", 7).Return([]model.AlergiPasien{{ID: 1, Zat: "Penisilin"}}, nil).Once()
+		mockRepo.On("Create", mock.MatchedBy(func(a model.AlergiPasien) bool {
+			return a.PasienID == 7 && a.Zat == "Amoksisilin" && a.DicatatOleh.Int64 == 3
+		})).Return(model.AlergiPasien{ID: 2, PasienID: 7, Zat: "Amoksisilin", TingkatKeparahan: model.KeparahanAlergiSedang}, nil).Once()
+
+		result, err := alergiService.CreateAlergi(context.Background(), 7, req, 3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Substance already recorded", func(t *testing.T) {
+		mockPasienRepo.On("GetById", 7).Return(model.Pasien{ID: 7}, nil).Once()
+		mockRepo.On("GetAllByPasienID", 7).Return([]model.AlergiPasien{{ID: 2, Zat: "amoksisilin "}}, nil).Once()
+
+		_, err := alergiService.CreateAlergi(context.Background(), 7, req, 3)
+
+		assert.ErrorIs(t, err, ErrAlergiDuplikat)
+		mockRepo.AssertNumberOfCalls(t, "Create", 1)
+	})
+
+	t.Run("Fail: Pasien not found", func(t *testing.T) {
+		mockPasienRepo.On("GetById", 99).Return(model.Pasien{}, repository.ErrNotFound).Once()
+
+		_, err := alergiService.CreateAlergi(context.Background(), 99, req, 3)
+
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
+func TestAlergiService_UpdateAlergi(t *testing.T) {
+	mockRepo := new(MockAlergiRepository)
+	alergiService := NewAlergiService(mockRepo, nil, nil)
+
+	t.Run("Success: Same substance kept on update", func(t *testing.T) {
+		existing := model.AlergiPasien{ID: 2, PasienID: 7, Zat: "Amoksisilin", TingkatKeparahan: model.KeparahanAlergiRingan}
+		mockRepo.On("GetByID", 7, 2).Return(existing, nil).Once()
+		mockRepo.On("GetAllByPasienID", 7).Return([]model.AlergiPasien{existing}, nil).Once()
+		mockRepo.On("Update", 7, 2, mock.AnythingOfType("model.AlergiPasien")).Return(model.AlergiPasien{ID: 2, PasienID: 7, Zat: "Amoksisilin", TingkatKeparahan: model.KeparahanAlergiBerat}, nil).Once()
+
+		result, err := alergiService.UpdateAlergi(context.Background(), 7, 2, model.AlergiPasienRequest{Zat: "Amoksisilin", TingkatKeparahan: model.KeparahanAlergiBerat})
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.KeparahanAlergiBerat, result.TingkatKeparahan)
+		mockRepo.AssertExpectations(t)
+	})
+}
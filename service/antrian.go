@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
+	"time"
 
 	"github.com/franklindh/simedis-api/internal/model"
 	"github.com/franklindh/simedis-api/internal/repository"
@@ -16,15 +16,22 @@ var (
 	ErrForeignKey      = errors.New("invalid jadwal_id or pasien_id")
 	ErrAntrianExists   = errors.New("pasien sudah terdaftar di jadwal ini")
 	ErrScheduleOverlap = errors.New("pasien memiliki jadwal lain yang tumpang tindih")
+
+	ErrNomorAntrianConflict = errors.New("nomor antrian bentrok, silakan coba lagi")
+	ErrInvalidTransition    = errors.New("perubahan status antrian tidak diizinkan")
+	ErrAntrianKosong        = errors.New("tidak ada pasien yang menunggu di jadwal ini")
 )
 
+const displayAntrianLimit = 200
+
 type AntrianService struct {
 	repo       AntrianRepository
 	jadwalRepo JadwalRepository
+	broker     AntrianBroker
 }
 
-func NewAntrianService(repo AntrianRepository, jadwalRepo JadwalRepository) *AntrianService {
-	return &AntrianService{repo: repo, jadwalRepo: jadwalRepo}
+func NewAntrianService(repo AntrianRepository, jadwalRepo JadwalRepository, broker AntrianBroker) *AntrianService {
+	return &AntrianService{repo: repo, jadwalRepo: jadwalRepo, broker: broker}
 }
 
 func (s *AntrianService) CreateAntrian(ctx context.Context, req model.CreateAntrianRequest) (model.AntrianResponse, error) {
@@ -50,22 +57,19 @@ func (s *AntrianService) CreateAntrian(ctx context.Context, req model.CreateAntr
 		return model.AntrianResponse{}, ErrAntrianExists
 	}
 
-	count, err := s.repo.CountTodayByJadwal(req.JadwalID)
-	if err != nil {
-		return model.AntrianResponse{}, fmt.Errorf("error counting antrian for today's schedule: %w", err)
-	}
-	initial := strings.ToUpper(string(jadwal.Poli.Nama[0]))
-	nomorAntrian := fmt.Sprintf("%s%d", initial, count+1)
-
-	antrian := req.ToModel(nomorAntrian)
-	createdAntrian, err := s.repo.Create(antrian)
+	antrian := req.ToModel()
+	createdAntrian, err := s.repo.CreateWithSequence(antrian, jadwal.Poli.PrefixAntrian())
 	if err != nil {
 		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23503" {
 			return model.AntrianResponse{}, ErrForeignKey
 		}
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return model.AntrianResponse{}, ErrNomorAntrianConflict
+		}
 		return model.AntrianResponse{}, fmt.Errorf("failed to create antrian: %w", err)
 	}
 
+	publishAntrianEvent(s.broker, model.AntrianEventCreated, createdAntrian)
 	return model.ToAntrianResponse(createdAntrian), nil
 }
 
@@ -85,15 +89,107 @@ func (s *AntrianService) GetAntrianByID(ctx context.Context, id int) (model.Antr
 	return model.ToAntrianResponse(antrian), nil
 }
 
+func (s *AntrianService) GetUpcomingAntrianPasien(ctx context.Context, pasienID int) ([]model.AntrianResponse, error) {
+	allAntrian, err := s.repo.GetUpcomingByPasienID(pasienID)
+	if err != nil {
+		return nil, err
+	}
+	return model.ToAntrianResponseList(allAntrian), nil
+}
+
 func (s *AntrianService) UpdateAntrian(ctx context.Context, id int, req model.UpdateAntrianRequest) (model.AntrianResponse, error) {
 	antrianUpdate := req.ToModel()
 	updatedAntrian, err := s.repo.Update(id, antrianUpdate)
 	if err != nil {
 		return model.AntrianResponse{}, err
 	}
+
+	publishAntrianEvent(s.broker, model.AntrianEventUpdated, updatedAntrian)
+	return model.ToAntrianResponse(updatedAntrian), nil
+}
+
+// memindahkan antrian ke status berikutnya sesuai state machine di model.CanTransitionAntrian
+func (s *AntrianService) TransitionAntrian(ctx context.Context, id int, toStatus string, actorID int) (model.AntrianResponse, error) {
+	antrian, err := s.repo.GetByID(id)
+	if err != nil {
+		return model.AntrianResponse{}, err
+	}
+
+	if !model.CanTransitionAntrian(antrian.Status, toStatus) {
+		return model.AntrianResponse{}, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, antrian.Status, toStatus)
+	}
+
+	updatedAntrian, err := s.repo.UpdateStatus(id, antrian.Status, toStatus, actorID, time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			// status sudah diubah request lain di antara GetByID dan UpdateStatus
+			return model.AntrianResponse{}, fmt.Errorf("%w: status antrian sudah berubah", ErrInvalidTransition)
+		}
+		return model.AntrianResponse{}, err
+	}
+
+	eventType := model.AntrianEventStatusChanged
+	if toStatus == model.StatusAntrianDipanggil {
+		eventType = model.AntrianEventCalled
+	}
+	publishAntrianEvent(s.broker, eventType, updatedAntrian)
 	return model.ToAntrianResponse(updatedAntrian), nil
 }
 
+func (s *AntrianService) CallNextAntrian(ctx context.Context, jadwalID int, actorID int) (model.AntrianResponse, error) {
+	if _, err := s.jadwalRepo.GetById(jadwalID); err != nil {
+		return model.AntrianResponse{}, err
+	}
+
+	antrian, err := s.repo.CallNext(jadwalID, actorID, time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.AntrianResponse{}, ErrAntrianKosong
+		}
+		return model.AntrianResponse{}, fmt.Errorf("failed to call next antrian: %w", err)
+	}
+
+	publishAntrianEvent(s.broker, model.AntrianEventCalled, antrian)
+	return model.ToAntrianResponse(antrian), nil
+}
+
 func (s *AntrianService) DeleteAntrian(ctx context.Context, id int) error {
-	return s.repo.Delete(id)
+	antrian, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+
+	publishAntrianEvent(s.broker, model.AntrianEventDeleted, antrian)
+	return nil
+}
+
+func (s *AntrianService) SubscribeAntrian(filter model.AntrianEventFilter) (<-chan model.AntrianEvent, func()) {
+	return s.broker.Subscribe(filter)
+}
+
+// kondisi antrian hari ini untuk layar ruang tunggu, dikirim sebelum stream event
+func (s *AntrianService) GetDisplayAntrianPoli(ctx context.Context, poliID int) ([]model.AntrianDisplayEvent, error) {
+	params := repository.ParamsGetAllAntrian{
+		TanggalFilter: time.Now().Format("2006-01-02"),
+		PoliIDFilter:  poliID,
+		Page:          1,
+		PageSize:      displayAntrianLimit,
+	}
+
+	allAntrian, _, err := s.repo.GetAll(params)
+	if err != nil {
+		return nil, err
+	}
+	return model.ToAntrianDisplayList(allAntrian), nil
+}
+
+func publishAntrianEvent(broker AntrianBroker, eventType string, antrian model.Antrian) {
+	if broker == nil {
+		return
+	}
+	broker.Publish(model.NewAntrianEvent(eventType, antrian))
 }
@@ -2,24 +2,171 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"sync"
 	"testing"
 
+	"github.com/franklindh/simedis-api/internal/broker"
 	"github.com/franklindh/simedis-api/internal/model"
 	"github.com/franklindh/simedis-api/internal/repository"
 	"github.com/franklindh/simedis-api/pkg/utils/pagination"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
 func TestAntrianService_CreateAntrian(t *testing.T) {
+	jadwal := model.Jadwal{
+		ID:     1,
+		PoliID: 2,
+		Poli:   model.Poli{ID: 2, Nama: "Poli Umum", KodeAntrian: sql.NullString{String: "UM", Valid: true}},
+	}
+	req := model.CreateAntrianRequest{JadwalID: 1, PasienID: 10, Prioritas: "Non Gawat"}
 
+	t.Run("Success: Nomor antrian memakai kode poli", func(t *testing.T) {
+		mockAntrianRepo := new(MockAntrianRepository)
+		mockJadwalRepo := new(MockJadwalRepository)
+		service := NewAntrianService(mockAntrianRepo, mockJadwalRepo, nil)
+
+		mockJadwalRepo.On("GetById", 1).Return(jadwal, nil).Once()
+		mockAntrianRepo.On("CheckForOverlappingAntrian", 10, jadwal.Tanggal, jadwal.WaktuMulai, jadwal.WaktuSelesai).Return(false, nil).Once()
+		mockAntrianRepo.On("CheckAntrian", 10, 1).Return(false, nil).Once()
+		mockAntrianRepo.On("CreateWithSequence", mock.AnythingOfType("model.Antrian"), "UM").
+			Return(model.Antrian{ID: 1, NomorAntrian: "UM-001", Status: "Menunggu"}, nil).Once()
+
+		result, err := service.CreateAntrian(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "UM-001", result.NomorAntrian)
+		mockJadwalRepo.AssertExpectations(t)
+		mockAntrianRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success: Poli tanpa kode memakai prefix dari id", func(t *testing.T) {
+		mockAntrianRepo := new(MockAntrianRepository)
+		mockJadwalRepo := new(MockJadwalRepository)
+		service := NewAntrianService(mockAntrianRepo, mockJadwalRepo, nil)
+
+		jadwalTanpaKode := jadwal
+		jadwalTanpaKode.Poli = model.Poli{ID: 7, Nama: "Poli Usia Lanjut"}
+
+		mockJadwalRepo.On("GetById", 1).Return(jadwalTanpaKode, nil).Once()
+		mockAntrianRepo.On("CheckForOverlappingAntrian", 10, jadwal.Tanggal, jadwal.WaktuMulai, jadwal.WaktuSelesai).Return(false, nil).Once()
+		mockAntrianRepo.On("CheckAntrian", 10, 1).Return(false, nil).Once()
+		mockAntrianRepo.On("CreateWithSequence", mock.AnythingOfType("model.Antrian"), "P7").
+			Return(model.Antrian{ID: 1, NomorAntrian: "P7-001"}, nil).Once()
+
+		result, err := service.CreateAntrian(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "P7-001", result.NomorAntrian)
+		mockAntrianRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Pasien sudah terdaftar", func(t *testing.T) {
+		mockAntrianRepo := new(MockAntrianRepository)
+		mockJadwalRepo := new(MockJadwalRepository)
+		service := NewAntrianService(mockAntrianRepo, mockJadwalRepo, nil)
+
+		mockJadwalRepo.On("GetById", 1).Return(jadwal, nil).Once()
+		mockAntrianRepo.On("CheckForOverlappingAntrian", 10, jadwal.Tanggal, jadwal.WaktuMulai, jadwal.WaktuSelesai).Return(false, nil).Once()
+		mockAntrianRepo.On("CheckAntrian", 10, 1).Return(true, nil).Once()
+
+		_, err := service.CreateAntrian(context.Background(), req)
+
+		assert.True(t, errors.Is(err, ErrAntrianExists))
+		mockAntrianRepo.AssertNotCalled(t, "CreateWithSequence", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Unique constraint nomor antrian", func(t *testing.T) {
+		mockAntrianRepo := new(MockAntrianRepository)
+		mockJadwalRepo := new(MockJadwalRepository)
+		service := NewAntrianService(mockAntrianRepo, mockJadwalRepo, nil)
+
+		mockJadwalRepo.On("GetById", 1).Return(jadwal, nil).Once()
+		mockAntrianRepo.On("CheckForOverlappingAntrian", 10, jadwal.Tanggal, jadwal.WaktuMulai, jadwal.WaktuSelesai).Return(false, nil).Once()
+		mockAntrianRepo.On("CheckAntrian", 10, 1).Return(false, nil).Once()
+		mockAntrianRepo.On("CreateWithSequence", mock.AnythingOfType("model.Antrian"), "UM").
+			Return(model.Antrian{}, &pgconn.PgError{Code: "23505"}).Once()
+
+		_, err := service.CreateAntrian(context.Background(), req)
+
+		assert.True(t, errors.Is(err, ErrNomorAntrianConflict))
+	})
+}
+
+// meniru antrian_sequence dan indeks unik idx_antrian_jadwal_nomor: nomor dialokasikan di
+// bawah lock seperti upsert baris sequence, dan nomor yang sudah dipakai ditolak dengan 23505
+type sequenceAntrianRepository struct {
+	*MockAntrianRepository
+	mu         sync.Mutex
+	lastNumber map[int]int
+	nomor      map[string]bool
+}
+
+func (r *sequenceAntrianRepository) CreateWithSequence(antrian model.Antrian, prefix string) (model.Antrian, error) {
+	r.mu.Lock()
+	r.lastNumber[antrian.JadwalID]++
+	next := r.lastNumber[antrian.JadwalID]
+	r.mu.Unlock()
+
+	antrian.NomorAntrian = model.FormatNomorAntrian(prefix, next)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.nomor[antrian.NomorAntrian] {
+		return model.Antrian{}, &pgconn.PgError{Code: "23505", ConstraintName: "idx_antrian_jadwal_nomor"}
+	}
+	r.nomor[antrian.NomorAntrian] = true
+	antrian.ID = len(r.nomor)
+	return antrian, nil
+}
+
+func TestAntrianService_CreateAntrian_Concurrent(t *testing.T) {
+	const total = 100
+
+	mockAntrianRepo := new(MockAntrianRepository)
+	mockJadwalRepo := new(MockJadwalRepository)
+	repo := &sequenceAntrianRepository{MockAntrianRepository: mockAntrianRepo, lastNumber: map[int]int{}, nomor: map[string]bool{}}
+	service := NewAntrianService(repo, mockJadwalRepo, nil)
+
+	jadwal := model.Jadwal{ID: 1, PoliID: 2, Poli: model.Poli{ID: 2, KodeAntrian: sql.NullString{String: "UM", Valid: true}}}
+	mockJadwalRepo.On("GetById", 1).Return(jadwal, nil)
+	mockAntrianRepo.On("CheckForOverlappingAntrian", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+	mockAntrianRepo.On("CheckAntrian", mock.Anything, 1).Return(false, nil)
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	nomor := make([]string, total)
+	errs := make([]error, total)
+	for i := range total {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			res, err := service.CreateAntrian(context.Background(), model.CreateAntrianRequest{JadwalID: 1, PasienID: i + 1, Prioritas: "Non Gawat"})
+			nomor[i], errs[i] = res.NomorAntrian, err
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	seen := make(map[string]bool, total)
+	for i := range total {
+		assert.NoError(t, errs[i])
+		assert.False(t, seen[nomor[i]], "nomor antrian %s dibagikan lebih dari sekali", nomor[i])
+		seen[nomor[i]] = true
+	}
+	for n := 1; n <= total; n++ {
+		assert.True(t, seen[model.FormatNomorAntrian("UM", n)], "nomor %d tidak terpakai", n)
+	}
 }
 
 func TestAntrianService_GetAllAntrian(t *testing.T) {
 	mockAntrianRepo := new(MockAntrianRepository)
 	mockJadwalRepo := new(MockJadwalRepository)
-	service := NewAntrianService(mockAntrianRepo, mockJadwalRepo)
+	service := NewAntrianService(mockAntrianRepo, mockJadwalRepo, nil)
 
 	params := repository.ParamsGetAllAntrian{Page: 1, PageSize: 5}
 
@@ -44,7 +191,7 @@ func TestAntrianService_GetAllAntrian(t *testing.T) {
 func TestAntrianService_GetAntrianByID(t *testing.T) {
 	mockAntrianRepo := new(MockAntrianRepository)
 	mockJadwalRepo := new(MockJadwalRepository)
-	service := NewAntrianService(mockAntrianRepo, mockJadwalRepo)
+	service := NewAntrianService(mockAntrianRepo, mockJadwalRepo, nil)
 
 	t.Run("Success: Antrian found", func(t *testing.T) {
 		mockAntrian := model.Antrian{ID: 1, NomorAntrian: "G1", Pasien: model.Pasien{NamaPasien: "Pasien A"}}
@@ -69,23 +216,44 @@ func TestAntrianService_GetAntrianByID(t *testing.T) {
 	})
 }
 
+func TestAntrianService_GetUpcomingAntrianPasien(t *testing.T) {
+	mockAntrianRepo := new(MockAntrianRepository)
+	mockJadwalRepo := new(MockJadwalRepository)
+	service := NewAntrianService(mockAntrianRepo, mockJadwalRepo, nil)
+
+	t.Run("Success: Get upcoming antrian of pasien", func(t *testing.T) {
+		mockAntrians := []model.Antrian{
+			{ID: 3, NomorAntrian: "U2", Status: "Menunggu", Pasien: model.Pasien{ID: 5, NamaPasien: "Andi"}},
+		}
+		mockAntrianRepo.On("GetUpcomingByPasienID", 5).Return(mockAntrians, nil).Once()
+
+		results, err := service.GetUpcomingAntrianPasien(context.Background(), 5)
+
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Equal(t, "U2", results[0].NomorAntrian)
+		mockAntrianRepo.AssertExpectations(t)
+	})
+}
+
 func TestAntrianService_UpdateAntrian(t *testing.T) {
 	mockAntrianRepo := new(MockAntrianRepository)
 	mockJadwalRepo := new(MockJadwalRepository)
-	service := NewAntrianService(mockAntrianRepo, mockJadwalRepo)
+	service := NewAntrianService(mockAntrianRepo, mockJadwalRepo, nil)
 
-	req := model.UpdateAntrianRequest{Status: "Selesai", Prioritas: "Gawat"}
+	req := model.UpdateAntrianRequest{Prioritas: "Gawat"}
 
 	t.Run("Success: Update antrian", func(t *testing.T) {
 		updatedModel := req.ToModel()
 		updatedModel.ID = 1
+		updatedModel.Status = "Menunggu"
 		mockAntrianRepo.On("Update", 1, mock.AnythingOfType("model.Antrian")).Return(updatedModel, nil).Once()
 
 		result, err := service.UpdateAntrian(context.Background(), 1, req)
 
 		assert.NoError(t, err)
 		assert.Equal(t, 1, result.ID)
-		assert.Equal(t, "Selesai", result.Status)
+		assert.Equal(t, "Menunggu", result.Status)
 		assert.Equal(t, "Gawat", result.Prioritas)
 		mockAntrianRepo.AssertExpectations(t)
 	})
@@ -101,23 +269,176 @@ func TestAntrianService_UpdateAntrian(t *testing.T) {
 	})
 }
 
+func TestAntrianService_TransitionAntrian(t *testing.T) {
+	testCases := []struct {
+		name          string
+		from          string
+		to            string
+		expectedError error
+	}{
+		{name: "Success: Menunggu ke Dipanggil", from: "Menunggu", to: "Dipanggil"},
+		{name: "Success: Dipanggil ke Diperiksa", from: "Dipanggil", to: "Diperiksa"},
+		{name: "Success: Diperiksa ke Menunggu Lab", from: "Diperiksa", to: "Menunggu Lab"},
+		{name: "Success: Menunggu Lab ke Selesai", from: "Menunggu Lab", to: "Selesai"},
+		{name: "Success: Menunggu ke Batal", from: "Menunggu", to: "Batal"},
+		{name: "Success: Dipanggil ke Tidak Hadir", from: "Dipanggil", to: "Tidak Hadir"},
+		{name: "Fail: Menunggu langsung Selesai", from: "Menunggu", to: "Selesai", expectedError: ErrInvalidTransition},
+		{name: "Fail: Selesai kembali ke Dipanggil", from: "Selesai", to: "Dipanggil", expectedError: ErrInvalidTransition},
+		{name: "Fail: Batal adalah status akhir", from: "Batal", to: "Menunggu", expectedError: ErrInvalidTransition},
+		{name: "Fail: Diperiksa tidak bisa Tidak Hadir", from: "Diperiksa", to: "Tidak Hadir", expectedError: ErrInvalidTransition},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockAntrianRepo := new(MockAntrianRepository)
+			service := NewAntrianService(mockAntrianRepo, new(MockJadwalRepository), nil)
+
+			mockAntrianRepo.On("GetByID", 1).Return(model.Antrian{ID: 1, Status: tc.from}, nil).Once()
+			if tc.expectedError == nil {
+				updated := model.Antrian{ID: 1, Status: tc.to}
+				mockAntrianRepo.On("UpdateStatus", 1, tc.from, tc.to, 7, mock.AnythingOfType("time.Time")).Return(updated, nil).Once()
+			}
+
+			result, err := service.TransitionAntrian(context.Background(), 1, tc.to, 7)
+
+			if tc.expectedError != nil {
+				assert.True(t, errors.Is(err, tc.expectedError))
+				mockAntrianRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.to, result.Status)
+			}
+			mockAntrianRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("Fail: Status berubah oleh request lain", func(t *testing.T) {
+		mockAntrianRepo := new(MockAntrianRepository)
+		service := NewAntrianService(mockAntrianRepo, new(MockJadwalRepository), nil)
+
+		mockAntrianRepo.On("GetByID", 1).Return(model.Antrian{ID: 1, Status: "Menunggu"}, nil).Once()
+		mockAntrianRepo.On("UpdateStatus", 1, "Menunggu", "Dipanggil", 7, mock.AnythingOfType("time.Time")).Return(model.Antrian{}, repository.ErrNotFound).Once()
+
+		_, err := service.TransitionAntrian(context.Background(), 1, "Dipanggil", 7)
+
+		assert.True(t, errors.Is(err, ErrInvalidTransition))
+	})
+
+	t.Run("Fail: Antrian not found", func(t *testing.T) {
+		mockAntrianRepo := new(MockAntrianRepository)
+		service := NewAntrianService(mockAntrianRepo, new(MockJadwalRepository), nil)
+
+		mockAntrianRepo.On("GetByID", 99).Return(model.Antrian{}, repository.ErrNotFound).Once()
+
+		_, err := service.TransitionAntrian(context.Background(), 99, "Dipanggil", 7)
+
+		assert.True(t, errors.Is(err, repository.ErrNotFound))
+	})
+}
+
+func TestAntrianService_CallNextAntrian(t *testing.T) {
+	t.Run("Success: Pasien berikutnya dipanggil", func(t *testing.T) {
+		mockAntrianRepo := new(MockAntrianRepository)
+		mockJadwalRepo := new(MockJadwalRepository)
+		service := NewAntrianService(mockAntrianRepo, mockJadwalRepo, nil)
+
+		mockJadwalRepo.On("GetById", 1).Return(model.Jadwal{ID: 1}, nil).Once()
+		mockAntrianRepo.On("CallNext", 1, 7, mock.AnythingOfType("time.Time")).
+			Return(model.Antrian{ID: 4, NomorAntrian: "UM-004", Prioritas: "Gawat", Status: "Dipanggil"}, nil).Once()
+
+		result, err := service.CallNextAntrian(context.Background(), 1, 7)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "UM-004", result.NomorAntrian)
+		assert.Equal(t, "Dipanggil", result.Status)
+		mockJadwalRepo.AssertExpectations(t)
+		mockAntrianRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Tidak ada pasien menunggu", func(t *testing.T) {
+		mockAntrianRepo := new(MockAntrianRepository)
+		mockJadwalRepo := new(MockJadwalRepository)
+		service := NewAntrianService(mockAntrianRepo, mockJadwalRepo, nil)
+
+		mockJadwalRepo.On("GetById", 1).Return(model.Jadwal{ID: 1}, nil).Once()
+		mockAntrianRepo.On("CallNext", 1, 7, mock.AnythingOfType("time.Time")).Return(model.Antrian{}, repository.ErrNotFound).Once()
+
+		_, err := service.CallNextAntrian(context.Background(), 1, 7)
+
+		assert.True(t, errors.Is(err, ErrAntrianKosong))
+	})
+
+	t.Run("Fail: Jadwal not found", func(t *testing.T) {
+		mockAntrianRepo := new(MockAntrianRepository)
+		mockJadwalRepo := new(MockJadwalRepository)
+		service := NewAntrianService(mockAntrianRepo, mockJadwalRepo, nil)
+
+		mockJadwalRepo.On("GetById", 99).Return(model.Jadwal{}, repository.ErrNotFound).Once()
+
+		_, err := service.CallNextAntrian(context.Background(), 99, 7)
+
+		assert.True(t, errors.Is(err, repository.ErrNotFound))
+		mockAntrianRepo.AssertNotCalled(t, "CallNext", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
 func TestAntrianService_DeleteAntrian(t *testing.T) {
-	mockAntrianRepo := new(MockAntrianRepository)
-	mockJadwalRepo := new(MockJadwalRepository)
-	service := NewAntrianService(mockAntrianRepo, mockJadwalRepo)
+	t.Run("Success: Delete antrian and publish event", func(t *testing.T) {
+		mockAntrianRepo := new(MockAntrianRepository)
+		antrianBroker := broker.NewMemoryBroker()
+		service := NewAntrianService(mockAntrianRepo, new(MockJadwalRepository), antrianBroker)
+
+		events, unsubscribe := antrianBroker.Subscribe(model.AntrianEventFilter{JadwalID: 3})
+		defer unsubscribe()
 
-	t.Run("Success: Delete antrian", func(t *testing.T) {
+		mockAntrianRepo.On("GetByID", 1).Return(model.Antrian{ID: 1, JadwalID: 3, NomorAntrian: "UM-001"}, nil).Once()
 		mockAntrianRepo.On("Delete", 1).Return(nil).Once()
+
 		err := service.DeleteAntrian(context.Background(), 1)
+
 		assert.NoError(t, err)
+		event := <-events
+		assert.Equal(t, model.AntrianEventDeleted, event.Type)
+		assert.Equal(t, "UM-001", event.NomorAntrian)
 		mockAntrianRepo.AssertExpectations(t)
 	})
 
 	t.Run("Fail: Antrian to delete not found", func(t *testing.T) {
-		mockAntrianRepo.On("Delete", 99).Return(repository.ErrNotFound).Once()
+		mockAntrianRepo := new(MockAntrianRepository)
+		service := NewAntrianService(mockAntrianRepo, new(MockJadwalRepository), nil)
+
+		mockAntrianRepo.On("GetByID", 99).Return(model.Antrian{}, repository.ErrNotFound).Once()
 		err := service.DeleteAntrian(context.Background(), 99)
 		assert.Error(t, err)
 		assert.True(t, errors.Is(err, repository.ErrNotFound))
-		mockAntrianRepo.AssertExpectations(t)
+		mockAntrianRepo.AssertNotCalled(t, "Delete", 99)
+	})
+}
+
+func TestAntrianService_PublishEvents(t *testing.T) {
+	t.Run("Success: Transition publishes event only to matching poli", func(t *testing.T) {
+		mockAntrianRepo := new(MockAntrianRepository)
+		antrianBroker := broker.NewMemoryBroker()
+		service := NewAntrianService(mockAntrianRepo, new(MockJadwalRepository), antrianBroker)
+
+		poliEvents, unsubscribePoli := antrianBroker.Subscribe(model.AntrianEventFilter{PoliID: 2})
+		defer unsubscribePoli()
+		otherEvents, unsubscribeOther := antrianBroker.Subscribe(model.AntrianEventFilter{PoliID: 5})
+		defer unsubscribeOther()
+
+		called := model.Antrian{ID: 1, JadwalID: 3, NomorAntrian: "UM-001", Status: "Dipanggil", Jadwal: model.Jadwal{ID: 3, PoliID: 2}, Pasien: model.Pasien{NamaPasien: "Andi"}}
+		mockAntrianRepo.On("GetByID", 1).Return(model.Antrian{ID: 1, Status: "Menunggu"}, nil).Once()
+		mockAntrianRepo.On("UpdateStatus", 1, "Menunggu", "Dipanggil", 7, mock.AnythingOfType("time.Time")).Return(called, nil).Once()
+
+		_, err := service.TransitionAntrian(context.Background(), 1, "Dipanggil", 7)
+
+		assert.NoError(t, err)
+		event := <-poliEvents
+		assert.Equal(t, model.AntrianEventCalled, event.Type)
+		assert.Equal(t, "Dipanggil", event.Status)
+
+		display := event.ToDisplay()
+		assert.Equal(t, "UM-001", display.NomorAntrian)
+		assert.Len(t, otherEvents, 0)
 	})
 }
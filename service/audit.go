@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/franklindh/simedis-api/internal/audit"
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/pkg/utils/pagination"
+)
+
+type AuditService struct {
+	repo AuditRepository
+}
+
+func NewAuditService(repo AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// mencatat satu entri audit, identitas pelaku diambil dari context request
+func (s *AuditService) Record(ctx context.Context, entry model.AuditLog) error {
+	if actor, ok := audit.ActorFromContext(ctx); ok {
+		entry.ActorID = sql.NullInt64{Int64: int64(actor.ID), Valid: actor.ID > 0}
+		entry.ActorUsername = actor.Username
+		entry.ActorRole = actor.Role
+		entry.IPAddress = actor.IP
+	}
+
+	if err := s.repo.Create(entry); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+func (s *AuditService) GetAllAudit(ctx context.Context, params repository.ParamsGetAllAudit) ([]model.AuditLogResponse, pagination.Metadata, error) {
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.PageSize <= 0 {
+		params.PageSize = 20
+	}
+
+	logs, metadata, err := s.repo.GetAll(params)
+	if err != nil {
+		return nil, pagination.Metadata{}, fmt.Errorf("failed to get audit log: %w", err)
+	}
+	return model.ToAuditLogResponseList(logs), metadata, nil
+}
+
+// hook audit untuk perubahan data: create hanya menyimpan after, delete hanya before,
+// update menyimpan field yang berubah saja. Kegagalan audit dicatat ke log tanpa membatalkan request
+func recordAudit(ctx context.Context, recorder AuditRecorder, action, entity string, entityID int, before, after any) {
+	if recorder == nil {
+		return
+	}
+
+	entry := model.AuditLog{
+		Action:   action,
+		Entity:   entity,
+		EntityID: strconv.Itoa(entityID),
+	}
+
+	var changes any
+	switch action {
+	case model.AuditActionCreate:
+		changes = after
+	case model.AuditActionDelete:
+		changes = before
+	case model.AuditActionUpdate, model.AuditActionVoid:
+		diff, err := audit.Diff(before, after)
+		if err != nil {
+			log.Printf("audit: failed to diff %s %d: %v", entity, entityID, err)
+		}
+		changes = diff
+	}
+
+	if changes != nil {
+		raw, err := json.Marshal(changes)
+		if err != nil {
+			log.Printf("audit: failed to encode changes for %s %d: %v", entity, entityID, err)
+		} else {
+			entry.Changes = sql.NullString{String: string(raw), Valid: true}
+		}
+	}
+
+	if err := recorder.Record(ctx, entry); err != nil {
+		log.Printf("audit: %v", err)
+	}
+}
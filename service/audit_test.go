@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/franklindh/simedis-api/internal/audit"
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/pkg/utils/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuditService_Record(t *testing.T) {
+	mockRepo := new(MockAuditRepository)
+	service := NewAuditService(mockRepo)
+
+	t.Run("Success: Actor is taken from context", func(t *testing.T) {
+		ctx := audit.WithActor(context.Background(), audit.Actor{ID: 7, Username: "dokter1", Role: "Dokter", IP: "10.0.0.1"})
+
+		mockRepo.On("Create", mock.MatchedBy(func(entry model.AuditLog) bool {
+			return entry.ActorID.Valid && entry.ActorID.Int64 == 7 &&
+				entry.ActorUsername == "dokter1" &&
+				entry.ActorRole == "Dokter" &&
+				entry.IPAddress == "10.0.0.1" &&
+				entry.Action == model.AuditActionRead
+		})).Return(nil).Once()
+
+		err := service.Record(ctx, model.AuditLog{Action: model.AuditActionRead, Entity: model.AuditEntityPasien, EntityID: "1"})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Repository error", func(t *testing.T) {
+		mockRepo.On("Create", mock.AnythingOfType("model.AuditLog")).Return(errors.New("db error")).Once()
+
+		err := service.Record(context.Background(), model.AuditLog{Action: model.AuditActionRead})
+
+		assert.Error(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestRecordAudit_UpdateStoresChangedFieldsOnly(t *testing.T) {
+	mockRepo := new(MockAuditRepository)
+	service := NewAuditService(mockRepo)
+
+	var saved model.AuditLog
+	mockRepo.On("Create", mock.AnythingOfType("model.AuditLog")).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(model.AuditLog)
+	}).Return(nil).Once()
+
+	before := model.PasienResponse{ID: 1, NamaPasien: "Budi", NIK: "1234567890123456"}
+	after := model.PasienResponse{ID: 1, NamaPasien: "Budi Updated", NIK: "1234567890123456"}
+	recordAudit(context.Background(), service, model.AuditActionUpdate, model.AuditEntityPasien, 1, before, after)
+
+	mockRepo.AssertExpectations(t)
+	assert.Equal(t, model.AuditActionUpdate, saved.Action)
+	assert.Equal(t, "1", saved.EntityID)
+	assert.True(t, saved.Changes.Valid)
+
+	var changes map[string]audit.Change
+	assert.NoError(t, json.Unmarshal([]byte(saved.Changes.String), &changes))
+	assert.Len(t, changes, 1)
+	assert.Equal(t, "Budi", changes["nama_pasien"].Old)
+	assert.Equal(t, "Budi Updated", changes["nama_pasien"].New)
+}
+
+func TestAuditService_GetAllAudit(t *testing.T) {
+	mockRepo := new(MockAuditRepository)
+	service := NewAuditService(mockRepo)
+
+	t.Run("Success: Default pagination is applied", func(t *testing.T) {
+		logs := []model.AuditLog{{ID: 1, Action: model.AuditActionRead, Entity: model.AuditEntityPasien, EntityID: "1"}}
+		expectedParams := repository.ParamsGetAllAudit{Entity: model.AuditEntityPasien, Page: 1, PageSize: 20}
+		mockRepo.On("GetAll", expectedParams).Return(logs, pagination.Metadata{TotalRecords: 1}, nil).Once()
+
+		result, metadata, err := service.GetAllAudit(context.Background(), repository.ParamsGetAllAudit{Entity: model.AuditEntityPasien})
+
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, 1, metadata.TotalRecords)
+		mockRepo.AssertExpectations(t)
+	})
+}
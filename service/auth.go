@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+)
+
+var (
+	ErrTokenRevoked = errors.New("token has been revoked")
+)
+
+type AuthService struct {
+	tokenRepo   TokenRepository
+	petugasRepo PetugasRepository
+	pasienRepo  PasienRepository
+	cache       *PetugasCache
+}
+
+func NewAuthService(tokenRepo TokenRepository, petugasRepo PetugasRepository, pasienRepo PasienRepository, cache *PetugasCache) *AuthService {
+	return &AuthService{tokenRepo: tokenRepo, petugasRepo: petugasRepo, pasienRepo: pasienRepo, cache: cache}
+}
+
+// mengembalikan data petugas terkini, role dan status dari token tidak dipercaya
+func (s *AuthService) ValidatePetugasToken(ctx context.Context, petugasID int, jti string, tokenVersion int) (model.Petugas, error) {
+	if err := s.checkJTI(jti); err != nil {
+		return model.Petugas{}, err
+	}
+
+	petugas, err := s.currentPetugas(petugasID)
+	if err != nil {
+		return model.Petugas{}, err
+	}
+	if petugas.TokenVersion != tokenVersion {
+		return model.Petugas{}, ErrTokenRevoked
+	}
+	if petugas.Status != StatusPetugasAktif {
+		return model.Petugas{}, ErrPetugasInactive
+	}
+
+	return petugas, nil
+}
+
+func (s *AuthService) currentPetugas(petugasID int) (model.Petugas, error) {
+	if petugas, ok := s.cache.Get(petugasID); ok {
+		return petugas, nil
+	}
+
+	petugas, err := s.petugasRepo.GetById(petugasID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Petugas{}, ErrTokenRevoked
+		}
+		return model.Petugas{}, fmt.Errorf("database error: %w", err)
+	}
+
+	s.cache.Set(petugas)
+	return petugas, nil
+}
+
+// token pasien ditolak bila dicabut lewat logout atau dibuat sebelum password terakhir diganti
+func (s *AuthService) ValidatePasienToken(ctx context.Context, pasienID int, jti string, tokenVersion int) error {
+	if err := s.checkJTI(jti); err != nil {
+		return err
+	}
+
+	currentVersion, err := s.pasienRepo.GetTokenVersion(pasienID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrTokenRevoked
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+	if currentVersion != tokenVersion {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+func (s *AuthService) checkJTI(jti string) error {
+	if jti == "" {
+		return ErrTokenRevoked
+	}
+
+	revoked, err := s.tokenRepo.IsJTIRevoked(jti)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if revoked {
+		return ErrTokenRevoked
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthService_ValidatePetugasToken(t *testing.T) {
+	t.Run("Success: Token valid and live petugas returned", func(t *testing.T) {
+		mockTokenRepo := new(MockTokenRepository)
+		mockPetugasRepo := new(MockPetugasRepository)
+		service := NewAuthService(mockTokenRepo, mockPetugasRepo, nil, nil)
+
+		mockTokenRepo.On("IsJTIRevoked", "jti-1").Return(false, nil).Once()
+		mockPetugasRepo.On("GetById", 1).Return(model.Petugas{ID: 1, Role: "Dokter", Status: "aktif", TokenVersion: 3}, nil).Once()
+
+		petugas, err := service.ValidatePetugasToken(context.Background(), 1, "jti-1", 3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Dokter", petugas.Role)
+		mockTokenRepo.AssertExpectations(t)
+		mockPetugasRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: JTI revoked by logout", func(t *testing.T) {
+		mockTokenRepo := new(MockTokenRepository)
+		mockPetugasRepo := new(MockPetugasRepository)
+		service := NewAuthService(mockTokenRepo, mockPetugasRepo, nil, nil)
+
+		mockTokenRepo.On("IsJTIRevoked", "jti-1").Return(true, nil).Once()
+
+		_, err := service.ValidatePetugasToken(context.Background(), 1, "jti-1", 3)
+
+		assert.True(t, errors.Is(err, ErrTokenRevoked))
+		mockPetugasRepo.AssertNotCalled(t, "GetById", 1)
+	})
+
+	t.Run("Fail: Token version outdated", func(t *testing.T) {
+		mockTokenRepo := new(MockTokenRepository)
+		mockPetugasRepo := new(MockPetugasRepository)
+		service := NewAuthService(mockTokenRepo, mockPetugasRepo, nil, nil)
+
+		mockTokenRepo.On("IsJTIRevoked", "jti-1").Return(false, nil).Once()
+		mockPetugasRepo.On("GetById", 1).Return(model.Petugas{ID: 1, Status: "aktif", TokenVersion: 4}, nil).Once()
+
+		_, err := service.ValidatePetugasToken(context.Background(), 1, "jti-1", 3)
+
+		assert.True(t, errors.Is(err, ErrTokenRevoked))
+	})
+
+	t.Run("Fail: Petugas nonaktif", func(t *testing.T) {
+		mockTokenRepo := new(MockTokenRepository)
+		mockPetugasRepo := new(MockPetugasRepository)
+		service := NewAuthService(mockTokenRepo, mockPetugasRepo, nil, nil)
+
+		mockTokenRepo.On("IsJTIRevoked", "jti-1").Return(false, nil).Once()
+		mockPetugasRepo.On("GetById", 1).Return(model.Petugas{ID: 1, Status: "nonaktif"}, nil).Once()
+
+		_, err := service.ValidatePetugasToken(context.Background(), 1, "jti-1", 0)
+
+		assert.True(t, errors.Is(err, ErrPetugasInactive))
+	})
+
+	t.Run("Fail: Petugas deleted", func(t *testing.T) {
+		mockTokenRepo := new(MockTokenRepository)
+		mockPetugasRepo := new(MockPetugasRepository)
+		service := NewAuthService(mockTokenRepo, mockPetugasRepo, nil, nil)
+
+		mockTokenRepo.On("IsJTIRevoked", "jti-1").Return(false, nil).Once()
+		mockPetugasRepo.On("GetById", 1).Return(model.Petugas{}, repository.ErrNotFound).Once()
+
+		_, err := service.ValidatePetugasToken(context.Background(), 1, "jti-1", 0)
+
+		assert.True(t, errors.Is(err, ErrTokenRevoked))
+	})
+
+	t.Run("Success: Cached petugas reused until invalidated", func(t *testing.T) {
+		mockTokenRepo := new(MockTokenRepository)
+		mockPetugasRepo := new(MockPetugasRepository)
+		cache := NewPetugasCache(time.Minute)
+		service := NewAuthService(mockTokenRepo, mockPetugasRepo, nil, cache)
+
+		mockTokenRepo.On("IsJTIRevoked", "jti-1").Return(false, nil)
+		mockPetugasRepo.On("GetById", 1).Return(model.Petugas{ID: 1, Role: "Dokter", Status: "aktif"}, nil).Once()
+
+		_, err := service.ValidatePetugasToken(context.Background(), 1, "jti-1", 0)
+		assert.NoError(t, err)
+		_, err = service.ValidatePetugasToken(context.Background(), 1, "jti-1", 0)
+		assert.NoError(t, err)
+		mockPetugasRepo.AssertNumberOfCalls(t, "GetById", 1)
+
+		cache.Invalidate(1)
+		mockPetugasRepo.On("GetById", 1).Return(model.Petugas{ID: 1, Role: "Lab", Status: "aktif"}, nil).Once()
+
+		petugas, err := service.ValidatePetugasToken(context.Background(), 1, "jti-1", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "Lab", petugas.Role)
+		mockPetugasRepo.AssertNumberOfCalls(t, "GetById", 2)
+	})
+}
+
+func TestAuthService_ValidatePasienToken(t *testing.T) {
+	t.Run("Success: Token version matches", func(t *testing.T) {
+		mockTokenRepo := new(MockTokenRepository)
+		mockPasienRepo := new(MockPasienRepository)
+		service := NewAuthService(mockTokenRepo, nil, mockPasienRepo, nil)
+
+		mockTokenRepo.On("IsJTIRevoked", "jti-p").Return(false, nil).Once()
+		mockPasienRepo.On("GetTokenVersion", 5).Return(1, nil).Once()
+
+		err := service.ValidatePasienToken(context.Background(), 5, "jti-p", 1)
+
+		assert.NoError(t, err)
+		mockPasienRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Token issued before password change", func(t *testing.T) {
+		mockTokenRepo := new(MockTokenRepository)
+		mockPasienRepo := new(MockPasienRepository)
+		service := NewAuthService(mockTokenRepo, nil, mockPasienRepo, nil)
+
+		mockTokenRepo.On("IsJTIRevoked", "jti-p").Return(false, nil).Once()
+		mockPasienRepo.On("GetTokenVersion", 5).Return(2, nil).Once()
+
+		err := service.ValidatePasienToken(context.Background(), 5, "jti-p", 1)
+
+		assert.ErrorIs(t, err, ErrTokenRevoked)
+	})
+
+	t.Run("Fail: Pasien deleted", func(t *testing.T) {
+		mockTokenRepo := new(MockTokenRepository)
+		mockPasienRepo := new(MockPasienRepository)
+		service := NewAuthService(mockTokenRepo, nil, mockPasienRepo, nil)
+
+		mockTokenRepo.On("IsJTIRevoked", "jti-p").Return(false, nil).Once()
+		mockPasienRepo.On("GetTokenVersion", 5).Return(0, repository.ErrNotFound).Once()
+
+		err := service.ValidatePasienToken(context.Background(), 5, "jti-p", 0)
+
+		assert.ErrorIs(t, err, ErrTokenRevoked)
+	})
+}
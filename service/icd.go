@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 
+	"github.com/franklindh/simedis-api/internal/icdimport"
 	"github.com/franklindh/simedis-api/internal/model"
 	"github.com/franklindh/simedis-api/internal/repository"
 	"github.com/franklindh/simedis-api/pkg/utils/pagination"
@@ -21,6 +24,10 @@ type IcdRepository interface {
 	GetByID(id int) (model.Icd, error)
 	Update(id int, icd model.Icd) (model.Icd, error)
 	Delete(id int) error
+	GetByKodes(kodes []string) ([]model.Icd, error)
+	Upsert(kelompok []model.IcdKelompok, icds []model.Icd) error
+	Search(params model.ParamsSearchKatalog) ([]model.Icd, error)
+	GetAllKelompok(params model.ParamsGetAllIcdKelompok) ([]model.IcdKelompok, error)
 }
 
 type IcdService struct {
@@ -56,7 +63,34 @@ func (s *IcdService) GetIcdByID(ctx context.Context, id int) (model.IcdResponse,
 	if err != nil {
 		return model.IcdResponse{}, err
 	}
-	return model.ToIcdResponse(icd), nil
+	return model.ToIcdDetailResponse(icd), nil
+}
+
+func (s *IcdService) SearchIcd(ctx context.Context, params model.ParamsSearchKatalog) ([]model.IcdResponse, error) {
+	params.Query = strings.TrimSpace(params.Query)
+	if params.Query == "" {
+		return []model.IcdResponse{}, nil
+	}
+	if params.Limit == 0 {
+		params.Limit = defaultSearchLimit
+	}
+	icds, err := s.repo.Search(params)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]model.IcdResponse, 0, len(icds))
+	for _, icd := range icds {
+		responses = append(responses, model.ToIcdResponse(icd))
+	}
+	return responses, nil
+}
+
+func (s *IcdService) GetAllIcdKelompok(ctx context.Context, params model.ParamsGetAllIcdKelompok) ([]model.IcdKelompokResponse, error) {
+	kelompok, err := s.repo.GetAllKelompok(params)
+	if err != nil {
+		return nil, err
+	}
+	return model.ToIcdKelompokResponseList(kelompok), nil
 }
 
 func (s *IcdService) UpdateIcd(ctx context.Context, id int, req model.UpdateIcdRequest) (model.IcdResponse, error) {
@@ -74,3 +108,85 @@ func (s *IcdService) UpdateIcd(ctx context.Context, id int, req model.UpdateIcdR
 func (s *IcdService) DeleteIcd(ctx context.Context, id int) error {
 	return s.repo.Delete(id)
 }
+
+// impor katalog ICD dari CSV atau ClaML. Kode yang sudah ada diperbarui, kode dengan isi sama
+// dilewati, dan pada dry run tidak ada yang ditulis ke database
+func (s *IcdService) ImportIcd(ctx context.Context, source io.Reader, format string, dryRun bool) (model.ImportReport, error) {
+	parsed, err := parseKatalog(source, format)
+	if err != nil {
+		return model.ImportReport{}, err
+	}
+	entries := parsed.Entries
+	report := newImportReport(parsed, dryRun)
+	report.Kelompok = len(parsed.Kelompok)
+
+	existingList, err := s.repo.GetByKodes(importKodes(entries))
+	if err != nil {
+		return model.ImportReport{}, err
+	}
+	existing := make(map[string]model.Icd, len(existingList))
+	for _, icd := range existingList {
+		existing[strings.ToUpper(icd.KodeIcd)] = icd
+	}
+
+	var changed []model.Icd
+	for _, e := range entries {
+		icd := icdFromImport(e)
+		current, found := existing[e.Kode]
+		// file tanpa kolom status tidak mengubah status kode yang sudah ada
+		if found && e.Status == "" {
+			icd.Status = current.Status
+		}
+		switch {
+		case !found:
+			report.Inserted++
+		case icdImportEqual(current, icd):
+			report.Unchanged++
+			continue
+		default:
+			// kode disimpan dengan penulisan yang sudah ada agar upsert mengenai baris yang sama
+			icd.KodeIcd = current.KodeIcd
+			report.Updated++
+		}
+		changed = append(changed, icd)
+	}
+
+	if dryRun {
+		return report, nil
+	}
+	kelompok := make([]model.IcdKelompok, 0, len(parsed.Kelompok))
+	for _, k := range parsed.Kelompok {
+		kelompok = append(kelompok, model.IcdKelompok{
+			Kode:      k.Kode,
+			Nama:      k.Nama,
+			Jenis:     k.Jenis,
+			KodeInduk: nullString(k.KodeInduk),
+		})
+	}
+	if err := s.repo.Upsert(kelompok, changed); err != nil {
+		return model.ImportReport{}, fmt.Errorf("failed to import ICD: %w", err)
+	}
+	return report, nil
+}
+
+func icdFromImport(e icdimport.Entry) model.Icd {
+	return model.Icd{
+		KodeIcd:      e.Kode,
+		NamaPenyakit: e.Nama,
+		Deskripsi:    nullString(e.Deskripsi),
+		Status:       importStatus(e),
+		Bab:          nullString(e.Bab),
+		Blok:         nullString(e.Blok),
+		KodeInduk:    nullString(e.KodeInduk),
+	}
+}
+
+func icdImportEqual(current, imported model.Icd) bool {
+	return !current.DeletedAt.Valid &&
+		current.NamaPenyakit == imported.NamaPenyakit &&
+		current.Deskripsi == imported.Deskripsi &&
+		current.Status == imported.Status &&
+		current.Bab == imported.Bab &&
+		current.Blok == imported.Blok &&
+		current.KodeInduk == imported.KodeInduk
+}
@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"strings"
 	"testing"
 
+	"github.com/franklindh/simedis-api/internal/icdimport"
 	"github.com/franklindh/simedis-api/internal/model"
 	"github.com/franklindh/simedis-api/internal/repository"
 	"github.com/franklindh/simedis-api/pkg/utils/pagination"
@@ -42,6 +45,22 @@ func (m *MockIcdRepository) Delete(id int) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
+func (m *MockIcdRepository) GetByKodes(kodes []string) ([]model.Icd, error) {
+	args := m.Called(kodes)
+	return args.Get(0).([]model.Icd), args.Error(1)
+}
+func (m *MockIcdRepository) Upsert(kelompok []model.IcdKelompok, icds []model.Icd) error {
+	args := m.Called(kelompok, icds)
+	return args.Error(0)
+}
+func (m *MockIcdRepository) Search(params model.ParamsSearchKatalog) ([]model.Icd, error) {
+	args := m.Called(params)
+	return args.Get(0).([]model.Icd), args.Error(1)
+}
+func (m *MockIcdRepository) GetAllKelompok(params model.ParamsGetAllIcdKelompok) ([]model.IcdKelompok, error) {
+	args := m.Called(params)
+	return args.Get(0).([]model.IcdKelompok), args.Error(1)
+}
 
 func TestIcdService_CreateIcd(t *testing.T) {
 	mockRepo := new(MockIcdRepository)
@@ -111,6 +130,26 @@ func TestIcdService_GetIcdByID(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("Success: Detail includes hierarchy", func(t *testing.T) {
+		mockModel := model.Icd{
+			ID: 2, KodeIcd: "J01", NamaPenyakit: "Sinusitis akut",
+			KelompokBab:  &model.IcdKelompok{Kode: "X", Nama: "Penyakit sistem pernapasan", Jenis: model.JenisKelompokBab},
+			KelompokBlok: &model.IcdKelompok{Kode: "J00-J06", Nama: "ISPA atas", Jenis: model.JenisKelompokBlok},
+			Turunan:      []model.Icd{{ID: 3, KodeIcd: "J01.0", NamaPenyakit: "Sinusitis maksilaris akut"}},
+		}
+		mockRepo.On("GetByID", 2).Return(mockModel, nil).Once()
+
+		result, err := service.GetIcdByID(context.Background(), 2)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, result.Hierarki)
+		assert.Equal(t, "X", result.Hierarki.Bab.Kode)
+		assert.Equal(t, "J00-J06", result.Hierarki.Blok.Kode)
+		assert.Nil(t, result.Hierarki.Induk)
+		assert.Len(t, result.Hierarki.Turunan, 1)
+		assert.Equal(t, "J01.0", result.Hierarki.Turunan[0].KodeIcd)
+	})
+
 	t.Run("Fail: ICD not found", func(t *testing.T) {
 		mockRepo.On("GetByID", 99).Return(model.Icd{}, repository.ErrNotFound).Once()
 
@@ -170,3 +209,159 @@ func TestIcdService_DeleteIcd(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestIcdService_ImportIcd(t *testing.T) {
+	csvFile := "kode_icd;nama_penyakit;bab;blok;status\n" +
+		"J00;Nasofaringitis akut;X;J00-J06;aktif\n" +
+		"J01;Sinusitis akut;X;J00-J06;\n" +
+		"J01.0;Sinusitis maksilaris akut;;;\n" +
+		"A09;Diare dan gastroenteritis;I;A00-A09;\n" +
+		";Tanpa kode;;;\n" +
+		"J00;Duplikat;X;J00-J06;\n"
+	existing := []model.Icd{
+		{ID: 1, KodeIcd: "J00", NamaPenyakit: "Nasofaringitis akut", Status: "aktif",
+			Bab: sql.NullString{String: "X", Valid: true}, Blok: sql.NullString{String: "J00-J06", Valid: true}},
+		{ID: 2, KodeIcd: "A09", NamaPenyakit: "Diare", Status: "nonaktif"},
+	}
+
+	t.Run("Success: Upsert by kode with hierarchy", func(t *testing.T) {
+		mockRepo := new(MockIcdRepository)
+		service := NewIcdService(mockRepo)
+
+		var (
+			kelompok []model.IcdKelompok
+			saved    []model.Icd
+		)
+		mockRepo.On("GetByKodes", []string{"J00", "J01", "J01.0", "A09"}).Return(existing, nil).Once()
+		mockRepo.On("Upsert", mock.AnythingOfType("[]model.IcdKelompok"), mock.AnythingOfType("[]model.Icd")).Run(func(args mock.Arguments) {
+			kelompok = args.Get(0).([]model.IcdKelompok)
+			saved = args.Get(1).([]model.Icd)
+		}).Return(nil).Once()
+
+		report, err := service.ImportIcd(context.Background(), strings.NewReader(csvFile), icdimport.FormatCSV, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 6, report.Total)
+		assert.Equal(t, 2, report.Inserted)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, 1, report.Unchanged)
+		assert.Equal(t, 2, report.Skipped)
+		assert.Len(t, report.Errors, 2)
+		assert.Equal(t, 7, report.Errors[1].Baris)
+
+		assert.Len(t, kelompok, 4)
+		assert.Equal(t, model.JenisKelompokBlok, kelompok[1].Jenis)
+		assert.Equal(t, "X", kelompok[1].KodeInduk.String)
+		assert.Len(t, saved, 3)
+		assert.Equal(t, "J01.0", saved[1].KodeIcd)
+		assert.Equal(t, "J01", saved[1].KodeInduk.String)
+		assert.Equal(t, "J00-J06", saved[1].Blok.String)
+		assert.Equal(t, "X", saved[1].Bab.String)
+		// status kosong di file tidak mengaktifkan kembali kode yang nonaktif
+		assert.Equal(t, "nonaktif", saved[2].Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success: Legacy lowercase kode is updated in place", func(t *testing.T) {
+		mockRepo := new(MockIcdRepository)
+		service := NewIcdService(mockRepo)
+
+		var saved []model.Icd
+		mockRepo.On("GetByKodes", []string{"A09"}).Return([]model.Icd{{ID: 2, KodeIcd: "a09", NamaPenyakit: "Diare", Status: "aktif"}}, nil).Once()
+		mockRepo.On("Upsert", mock.AnythingOfType("[]model.IcdKelompok"), mock.AnythingOfType("[]model.Icd")).Run(func(args mock.Arguments) {
+			saved = args.Get(1).([]model.Icd)
+		}).Return(nil).Once()
+
+		report, err := service.ImportIcd(context.Background(), strings.NewReader("kode_icd;nama_penyakit\nA09;Diare dan gastroenteritis\n"), icdimport.FormatCSV, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, report.Inserted)
+		assert.Equal(t, 1, report.Updated)
+		if assert.Len(t, saved, 1) {
+			assert.Equal(t, "a09", saved[0].KodeIcd)
+		}
+	})
+
+	t.Run("Success: Dry run does not write", func(t *testing.T) {
+		mockRepo := new(MockIcdRepository)
+		service := NewIcdService(mockRepo)
+		mockRepo.On("GetByKodes", mock.Anything).Return([]model.Icd{}, nil).Once()
+
+		report, err := service.ImportIcd(context.Background(), strings.NewReader(csvFile), icdimport.FormatCSV, true)
+
+		assert.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 4, report.Inserted)
+		mockRepo.AssertNotCalled(t, "Upsert", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success: ClaML categories with chapter and block", func(t *testing.T) {
+		claml := `<?xml version="1.0" encoding="UTF-8"?>
+<ClaML version="2.0.0">
+  <Class code="X" kind="chapter"><Rubric kind="preferred"><Label xml:lang="en">Diseases of the respiratory system</Label></Rubric></Class>
+  <Class code="J00-J06" kind="block"><SuperClass code="X"/><Rubric kind="preferred"><Label>Acute upper respiratory infections</Label></Rubric></Class>
+  <Class code="J01" kind="category"><SuperClass code="J00-J06"/><Rubric kind="preferred"><Label>Acute sinusitis</Label></Rubric></Class>
+  <Class code="J01.0" kind="category"><SuperClass code="J01"/><Rubric kind="preferred"><Label>Acute <Reference>maxillary</Reference> sinusitis</Label></Rubric></Class>
+</ClaML>`
+		mockRepo := new(MockIcdRepository)
+		service := NewIcdService(mockRepo)
+
+		var (
+			kelompok []model.IcdKelompok
+			saved    []model.Icd
+		)
+		mockRepo.On("GetByKodes", []string{"J01", "J01.0"}).Return([]model.Icd{}, nil).Once()
+		mockRepo.On("Upsert", mock.AnythingOfType("[]model.IcdKelompok"), mock.AnythingOfType("[]model.Icd")).Run(func(args mock.Arguments) {
+			kelompok = args.Get(0).([]model.IcdKelompok)
+			saved = args.Get(1).([]model.Icd)
+		}).Return(nil).Once()
+
+		report, err := service.ImportIcd(context.Background(), strings.NewReader(claml), icdimport.FormatClaML, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, report.Inserted)
+		assert.Len(t, saved, 2)
+		assert.Equal(t, "Acute maxillary sinusitis", saved[1].NamaPenyakit)
+		assert.Equal(t, "J01", saved[1].KodeInduk.String)
+		assert.Equal(t, "J00-J06", saved[1].Blok.String)
+		assert.Equal(t, "X", saved[1].Bab.String)
+		assert.Equal(t, "aktif", saved[1].Status)
+		assert.Equal(t, 2, report.Kelompok)
+		assert.Equal(t, "Diseases of the respiratory system", kelompok[0].Nama)
+		assert.Equal(t, "X", kelompok[1].KodeInduk.String)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: CSV without required columns", func(t *testing.T) {
+		service := NewIcdService(new(MockIcdRepository))
+
+		_, err := service.ImportIcd(context.Background(), strings.NewReader("kode,deskripsi\nA00,Kolera\n"), icdimport.FormatCSV, false)
+
+		assert.ErrorIs(t, err, ErrImportFormat)
+	})
+}
+
+func TestIcdService_SearchIcd(t *testing.T) {
+	mockRepo := new(MockIcdRepository)
+	service := NewIcdService(mockRepo)
+
+	t.Run("Success: Default limit and trimmed query", func(t *testing.T) {
+		mockRepo.On("Search", model.ParamsSearchKatalog{Query: "J01", Limit: 20}).
+			Return([]model.Icd{{ID: 2, KodeIcd: "J01", NamaPenyakit: "Sinusitis akut"}}, nil).Once()
+
+		result, err := service.SearchIcd(context.Background(), model.ParamsSearchKatalog{Query: " J01 "})
+
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+		assert.Nil(t, result[0].Hierarki)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success: Blank query returns empty list", func(t *testing.T) {
+		result, err := service.SearchIcd(context.Background(), model.ParamsSearchKatalog{Query: "   "})
+
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+		mockRepo.AssertNotCalled(t, "Search", model.ParamsSearchKatalog{Query: "", Limit: 20})
+	})
+}
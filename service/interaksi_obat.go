@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/franklindh/simedis-api/internal/icdimport"
+	"github.com/franklindh/simedis-api/internal/model"
+)
+
+// tingkat interaksi dari file sumber, istilah bahasa Inggris ikut dikenali
+var tingkatInteraksi = map[string]string{
+	"minor":    model.TingkatInteraksiMinor,
+	"moderat":  model.TingkatInteraksiModerat,
+	"moderate": model.TingkatInteraksiModerat,
+	"mayor":    model.TingkatInteraksiMayor,
+	"major":    model.TingkatInteraksiMayor,
+}
+
+type InteraksiObatService struct {
+	repo InteraksiObatRepository
+}
+
+func NewInteraksiObatService(repo InteraksiObatRepository) *InteraksiObatService {
+	return &InteraksiObatService{repo: repo}
+}
+
+// impor tabel interaksi dari CSV dengan header zat_a, zat_b, tingkat, deskripsi. Pasangan
+// yang sudah ada diperbarui tingkat dan deskripsinya, pasangan yang tidak ada di file dibiarkan
+func (s *InteraksiObatService) ImportInteraksi(ctx context.Context, source io.Reader, format string, dryRun bool) (model.ImportReport, error) {
+	if format != icdimport.FormatCSV {
+		return model.ImportReport{}, fmt.Errorf("%w: %v", ErrImportFormat, icdimport.ErrUnsupportedFormat)
+	}
+	parsed, rowErrors, err := parseInteraksiCSV(source)
+	if err != nil {
+		return model.ImportReport{}, fmt.Errorf("%w: %v", ErrImportFormat, err)
+	}
+	report := newImportReport(icdimport.Result{Errors: rowErrors}, dryRun)
+	report.Total += len(parsed)
+
+	existingList, err := s.repo.GetAll()
+	if err != nil {
+		return model.ImportReport{}, err
+	}
+	existing := make(map[[2]string]model.InteraksiObat, len(existingList))
+	for _, i := range existingList {
+		existing[[2]string{i.ZatA, i.ZatB}] = i
+	}
+
+	var changed []model.InteraksiObat
+	for _, i := range parsed {
+		current, found := existing[[2]string{i.ZatA, i.ZatB}]
+		switch {
+		case !found:
+			report.Inserted++
+		case current.Tingkat == i.Tingkat && current.Deskripsi == i.Deskripsi:
+			report.Unchanged++
+			continue
+		default:
+			report.Updated++
+		}
+		changed = append(changed, i)
+	}
+
+	if dryRun {
+		return report, nil
+	}
+	if err := s.repo.Upsert(changed); err != nil {
+		return model.ImportReport{}, fmt.Errorf("failed to import drug interactions: %w", err)
+	}
+	return report, nil
+}
+
+func parseInteraksiCSV(r io.Reader) ([]model.InteraksiObat, []icdimport.RowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	position := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, exists := position[name]; !exists {
+			position[name] = i
+		}
+	}
+	for _, column := range []string{"zat_a", "zat_b", "tingkat"} {
+		if _, ok := position[column]; !ok {
+			return nil, nil, fmt.Errorf("csv header must contain a %s column", column)
+		}
+	}
+
+	var (
+		list      []model.InteraksiObat
+		rowErrors []icdimport.RowError
+		seen      = make(map[[2]string]bool)
+	)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read csv: %w", err)
+		}
+		baris, _ := reader.FieldPos(0)
+
+		value := func(column string) string {
+			i, ok := position[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.Join(strings.Fields(record[i]), " ")
+		}
+
+		zatA, zatB := model.PasanganZat(value("zat_a"), value("zat_b"))
+		kode := zatA + "+" + zatB
+		tingkat, tingkatValid := tingkatInteraksi[strings.ToLower(value("tingkat"))]
+		switch {
+		case zatA == "" && zatB == "":
+			continue
+		case zatA == "" || zatB == "":
+			rowErrors = append(rowErrors, icdimport.RowError{Baris: baris, Kode: kode, Pesan: "both substances are required"})
+			continue
+		case zatA == zatB:
+			rowErrors = append(rowErrors, icdimport.RowError{Baris: baris, Kode: kode, Pesan: "substance cannot interact with itself"})
+			continue
+		case !tingkatValid:
+			rowErrors = append(rowErrors, icdimport.RowError{Baris: baris, Kode: kode, Pesan: "tingkat must be minor, moderat, or mayor"})
+			continue
+		case seen[[2]string{zatA, zatB}]:
+			rowErrors = append(rowErrors, icdimport.RowError{Baris: baris, Kode: kode, Pesan: "duplicate pair in file"})
+			continue
+		}
+		seen[[2]string{zatA, zatB}] = true
+		list = append(list, model.InteraksiObat{ZatA: zatA, ZatB: zatB, Tingkat: tingkat, Deskripsi: value("deskripsi")})
+	}
+	return list, rowErrors, nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInteraksiObatService_ImportInteraksi(t *testing.T) {
+	csvData := `zat_a,zat_b,tingkat,deskripsi
+Warfarin,Amoksisilin,moderate,meningkatkan INR
+simvastatin,klaritromisin,mayor,risiko rabdomiolisis
+ibuprofen,aspirin,minor,mengurangi efek antiplatelet
+aspirin,ibuprofen,minor,duplikat
+parasetamol,parasetamol,minor,
+metformin,,moderat,
+`
+
+	t.Run("Success: Pairs normalized and compared with existing", func(t *testing.T) {
+		mockRepo := new(MockInteraksiObatRepository)
+		interaksiService := NewInteraksiObatService(mockRepo)
+		mockRepo.On("GetAll").Return([]model.InteraksiObat{
+			{ID: 1, ZatA: "klaritromisin", ZatB: "simvastatin", Tingkat: "mayor", Deskripsi: "risiko rabdomiolisis"},
+			{ID: 2, ZatA: "aspirin", ZatB: "ibuprofen", Tingkat: "moderat", Deskripsi: "mengurangi efek antiplatelet"},
+		}, nil).Once()
+		mockRepo.On("Upsert", mock.MatchedBy(func(list []model.InteraksiObat) bool {
+			return len(list) == 2 &&
+				list[0].ZatA == "amoksisilin" && list[0].ZatB == "warfarin" && list[0].Tingkat == model.TingkatInteraksiModerat &&
+				list[1].ZatA == "aspirin" && list[1].Tingkat == model.TingkatInteraksiMinor
+		})).Return(nil).Once()
+
+		report, err := interaksiService.ImportInteraksi(context.Background(), strings.NewReader(csvData), "csv", false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 6, report.Total)
+		assert.Equal(t, 1, report.Inserted)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, 1, report.Unchanged)
+		assert.Equal(t, 3, report.Skipped)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Format other than csv", func(t *testing.T) {
+		interaksiService := NewInteraksiObatService(new(MockInteraksiObatRepository))
+
+		_, err := interaksiService.ImportInteraksi(context.Background(), strings.NewReader(csvData), "claml", false)
+
+		assert.ErrorIs(t, err, ErrImportFormat)
+	})
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"time"
 
 	"github.com/franklindh/simedis-api/internal/model"
@@ -10,13 +11,16 @@ import (
 
 type AntrianRepository interface {
 	Create(antrian model.Antrian) (model.Antrian, error)
+	CreateWithSequence(antrian model.Antrian, prefix string) (model.Antrian, error)
 	GetAll(params repository.ParamsGetAllAntrian) ([]model.Antrian, pagination.Metadata, error)
 	GetByID(id int) (model.Antrian, error)
 	Update(id int, antrian model.Antrian) (model.Antrian, error)
+	UpdateStatus(id int, fromStatus, toStatus string, actorID int, at time.Time) (model.Antrian, error)
+	CallNext(jadwalID int, actorID int, at time.Time) (model.Antrian, error)
 	Delete(id int) error
 	CheckAntrian(pasienID, jadwalID int) (bool, error)
 	CheckForOverlappingAntrian(pasienID int, tanggal, waktuMulai, waktuSelesai time.Time) (bool, error)
-	CountTodayByJadwal(jadwalID int) (int64, error)
+	GetUpcomingByPasienID(pasienID int) ([]model.Antrian, error)
 }
 
 type JadwalRepository interface {
@@ -34,6 +38,17 @@ type JenisPemeriksaanLabRepository interface {
 	Update(id int, jenis model.JenisPemeriksaanLab) (model.JenisPemeriksaanLab, error)
 	Delete(id int) error
 	FindByName(name string) (model.JenisPemeriksaanLab, error)
+	GetByIDs(ids []int) ([]model.JenisPemeriksaanLab, error)
+}
+
+type PanelLabRepository interface {
+	Create(panel model.PanelLab) (model.PanelLab, error)
+	GetAll() ([]model.PanelLab, error)
+	GetByID(id int) (model.PanelLab, error)
+	GetByIDs(ids []int) ([]model.PanelLab, error)
+	FindByName(name string) (model.PanelLab, error)
+	Update(id int, panel model.PanelLab) (model.PanelLab, error)
+	Delete(id int) error
 }
 
 type PasienRepository interface {
@@ -43,23 +58,85 @@ type PasienRepository interface {
 	Update(id int, pasien model.Pasien) (model.Pasien, error)
 	Delete(id int) error
 	GetLastID() (int, error)
+	GetByUsername(username string) (model.Pasien, error)
+	UpdatePassword(id int, newHashedPassword string) error
+	GetTokenVersion(id int) (int, error)
 }
 
 type PemeriksaanLabRepository interface {
-	Create(hasilLab model.PemeriksaanLab) (model.PemeriksaanLab, error)
+	SimpanBatch(list []model.PemeriksaanLab) ([]model.PemeriksaanLab, error)
 	GetAllByPemeriksaanID(pemeriksaanID int) ([]model.PemeriksaanLab, error)
 	GetById(id int) (model.PemeriksaanLab, error)
 	Update(id int, hasilLab model.PemeriksaanLab) (model.PemeriksaanLab, error)
 	Delete(id int) error
 }
 
+type OrderLabRepository interface {
+	Create(order model.OrderLab) (model.OrderLab, error)
+	GetByID(id int) (model.OrderLab, error)
+	GetAllByPemeriksaanID(pemeriksaanID int) ([]model.OrderLab, error)
+	GetAll(params repository.ParamsGetAllOrderLab) ([]model.OrderLab, pagination.Metadata, error)
+	AmbilSpesimen(id int, actorID int, at time.Time) (model.OrderLab, error)
+	TerimaSpesimen(id int, actorID int, at time.Time) (model.OrderLab, error)
+	Validasi(id int, actorID int, at time.Time) (model.OrderLab, error)
+	Batalkan(id int, alasan string, at time.Time) (model.OrderLab, error)
+	SimpanHasil(id int, hasil []model.PemeriksaanLab, status string) (model.OrderLab, error)
+}
+
+type ResepRepository interface {
+	Create(resep model.Resep) (model.Resep, error)
+	GetByID(id int) (model.Resep, error)
+	GetAllByPemeriksaanID(pemeriksaanID int) ([]model.Resep, error)
+	GetAll(params repository.ParamsGetAllResep) ([]model.Resep, pagination.Metadata, error)
+	Serahkan(id int, actorID int, at time.Time, mutasi []model.StokMutasi) (model.Resep, error)
+}
+
+type StokRepository interface {
+	StokMasuk(batch model.StokBatch, mutasi model.StokMutasi) (model.StokBatch, error)
+	Sesuaikan(mutasi model.StokMutasi) (model.StokBatch, error)
+	GetBatchByID(id int) (model.StokBatch, error)
+	GetBatchByNomor(obatID int, nomorBatch string) (model.StokBatch, error)
+	GetBatchTersedia(obatIDs []int, today time.Time) ([]model.StokBatch, error)
+	GetAllBatch(params repository.ParamsGetAllStokBatch) ([]model.StokBatch, error)
+	GetAllMutasi(params repository.ParamsGetAllStokMutasi) ([]model.StokMutasi, pagination.Metadata, error)
+	GetStokMenipis(today time.Time) ([]model.StokObat, error)
+	GetBatchKedaluwarsa(batas time.Time) ([]model.StokBatch, error)
+}
+
+type AlergiRepository interface {
+	Create(alergi model.AlergiPasien) (model.AlergiPasien, error)
+	GetAllByPasienID(pasienID int) ([]model.AlergiPasien, error)
+	GetByID(pasienID int, id int) (model.AlergiPasien, error)
+	Update(pasienID int, id int, alergi model.AlergiPasien) (model.AlergiPasien, error)
+	Delete(pasienID int, id int) error
+}
+
+type InteraksiObatRepository interface {
+	GetByZat(zat []string) ([]model.InteraksiObat, error)
+	GetAll() ([]model.InteraksiObat, error)
+	Upsert(list []model.InteraksiObat) error
+}
+
+type ObatRepository interface {
+	Create(obat model.Obat) (model.Obat, error)
+	GetAll(params repository.ParamsGetAllObat) ([]model.Obat, pagination.Metadata, error)
+	GetByID(id int) (model.Obat, error)
+	GetByIDs(ids []int) ([]model.Obat, error)
+	Update(id int, obat model.Obat) (model.Obat, error)
+	Delete(id int) error
+}
+
 type PemeriksaanRepository interface {
 	CheckExistingPemeriksaan(antrianID int) error
 	Create(pemeriksaan model.Pemeriksaan) (model.Pemeriksaan, error)
 	GetById(id int) (model.Pemeriksaan, error)
 	GetAllByPasienID(pasienID int) ([]model.Pemeriksaan, error)
-	Update(id int, pemeriksaan model.Pemeriksaan) (model.Pemeriksaan, error)
-	Delete(id int) error
+	GetVitalsByPasienID(pasienID int, from, to string) ([]model.Pemeriksaan, error)
+	Update(id int, pemeriksaan model.Pemeriksaan, alasan string) (model.Pemeriksaan, error)
+	Void(id int, alasan string, actorID int, at time.Time) (model.Pemeriksaan, error)
+	GetRevisions(pemeriksaanID int) ([]model.PemeriksaanRevisi, error)
+	Finalize(id int, revisi int, signerID int, at time.Time, signature string) (model.Pemeriksaan, error)
+	CreateAddendum(addendum model.PemeriksaanAddendum) (model.PemeriksaanAddendum, error)
 }
 
 type PetugasRepository interface {
@@ -70,6 +147,7 @@ type PetugasRepository interface {
 	Update(id int, petugas model.Petugas) (model.Petugas, error)
 	Delete(id int) error
 	UpdatePassword(id int, newHashedPassword string) error
+	IncrementTokenVersion(id int) error
 }
 
 type PoliRepository interface {
@@ -80,3 +158,27 @@ type PoliRepository interface {
 	Delete(id int) error
 	FindByName(name string) (model.Poli, error)
 }
+
+type TokenRepository interface {
+	CreateRefreshToken(token model.RefreshToken) (model.RefreshToken, error)
+	GetRefreshTokenByHash(tokenHash string) (model.RefreshToken, error)
+	RotateRefreshToken(oldID int, newToken model.RefreshToken) (model.RefreshToken, error)
+	RevokeRefreshToken(tokenHash string) error
+	RevokeAllRefreshTokens(petugasID int) error
+	RevokeJTI(jti string, expiresAt time.Time) error
+	IsJTIRevoked(jti string) (bool, error)
+}
+
+type AntrianBroker interface {
+	Publish(event model.AntrianEvent)
+	Subscribe(filter model.AntrianEventFilter) (<-chan model.AntrianEvent, func())
+}
+
+type AuditRepository interface {
+	Create(entry model.AuditLog) error
+	GetAll(params repository.ParamsGetAllAudit) ([]model.AuditLog, pagination.Metadata, error)
+}
+
+type AuditRecorder interface {
+	Record(ctx context.Context, entry model.AuditLog) error
+}
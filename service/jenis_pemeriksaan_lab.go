@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
 
 	"github.com/franklindh/simedis-api/internal/model"
 	"github.com/franklindh/simedis-api/internal/repository"
@@ -12,6 +14,7 @@ import (
 
 var (
 	ErrJenisPemeriksaanConflict = errors.New("jenis pemeriksaan with that name already exists")
+	ErrRujukanLabInvalid        = errors.New("invalid result type or reference range")
 )
 
 type JenisPemeriksaanLabService struct {
@@ -24,6 +27,9 @@ func NewJenisPemeriksaanLabService(repo JenisPemeriksaanLabRepository) *JenisPem
 
 func (s *JenisPemeriksaanLabService) Create(ctx context.Context, req model.CreateJenisPemeriksaanLabRequest) (model.JenisPemeriksaanLabResponse, error) {
 	jenis := req.ToModel()
+	if err := validateRujukan(jenis); err != nil {
+		return model.JenisPemeriksaanLabResponse{}, err
+	}
 
 	created, err := s.repo.Create(jenis)
 	if err != nil {
@@ -62,7 +68,21 @@ func (s *JenisPemeriksaanLabService) Update(ctx context.Context, id int, req mod
 		return model.JenisPemeriksaanLabResponse{}, ErrJenisPemeriksaanConflict
 	}
 
-	jenis := req.ToModel()
+	current, err := s.repo.GetById(id)
+	if err != nil {
+		return model.JenisPemeriksaanLabResponse{}, err
+	}
+
+	jenis := req.ToModel(current)
+	// rentang yang tidak diganti tetap harus cocok dengan tipe hasil yang baru
+	efektif := jenis
+	if efektif.Rujukan == nil {
+		efektif.Rujukan = current.Rujukan
+	}
+	if err := validateRujukan(efektif); err != nil {
+		return model.JenisPemeriksaanLabResponse{}, err
+	}
+
 	updated, err := s.repo.Update(id, jenis)
 	if err != nil {
 
@@ -78,3 +98,88 @@ func (s *JenisPemeriksaanLabService) Update(ctx context.Context, id int, req mod
 func (s *JenisPemeriksaanLabService) Delete(ctx context.Context, id int) error {
 	return s.repo.Delete(id)
 }
+
+// rentang rujukan hanya untuk hasil numerik, pilihan dan nilai normal hanya untuk hasil
+// kategori. Rentang dengan jenis kelamin yang sama tidak boleh tumpang tindih umurnya
+func validateRujukan(jenis model.JenisPemeriksaanLab) error {
+	pilihan := jenis.DaftarPilihan()
+	normal := jenis.DaftarNilaiNormal()
+
+	switch jenis.TipeHasil {
+	case model.TipeHasilKategori:
+		if len(pilihan) == 0 {
+			return fmt.Errorf("%w: pilihan_hasil is required for kategori", ErrRujukanLabInvalid)
+		}
+		if len(jenis.Rujukan) > 0 {
+			return fmt.Errorf("%w: rujukan is only allowed for numerik", ErrRujukanLabInvalid)
+		}
+		for _, nilai := range normal {
+			if !containsFold(pilihan, nilai) {
+				return fmt.Errorf("%w: nilai_normal %q is not in pilihan_hasil", ErrRujukanLabInvalid, nilai)
+			}
+		}
+		return nil
+	case model.TipeHasilNumerik:
+		if len(pilihan) > 0 || len(normal) > 0 {
+			return fmt.Errorf("%w: pilihan_hasil and nilai_normal are only allowed for kategori", ErrRujukanLabInvalid)
+		}
+	default:
+		if len(pilihan) > 0 || len(normal) > 0 || len(jenis.Rujukan) > 0 {
+			return fmt.Errorf("%w: teks results have no reference range", ErrRujukanLabInvalid)
+		}
+		return nil
+	}
+
+	for i, r := range jenis.Rujukan {
+		if !r.BatasBawah.Valid && !r.BatasAtas.Valid && !r.KritisBawah.Valid && !r.KritisAtas.Valid {
+			return fmt.Errorf("%w: rujukan %d has no limits", ErrRujukanLabInvalid, i)
+		}
+		if r.UmurMinHari.Valid && r.UmurMaksHari.Valid && r.UmurMinHari.Int64 >= r.UmurMaksHari.Int64 {
+			return fmt.Errorf("%w: rujukan %d umur_min_hari must be below umur_maks_hari", ErrRujukanLabInvalid, i)
+		}
+		if r.BatasBawah.Valid && r.BatasAtas.Valid && r.BatasBawah.Float64 > r.BatasAtas.Float64 {
+			return fmt.Errorf("%w: rujukan %d batas_bawah is above batas_atas", ErrRujukanLabInvalid, i)
+		}
+		if r.KritisBawah.Valid && r.BatasBawah.Valid && r.KritisBawah.Float64 > r.BatasBawah.Float64 {
+			return fmt.Errorf("%w: rujukan %d kritis_bawah is above batas_bawah", ErrRujukanLabInvalid, i)
+		}
+		if r.KritisAtas.Valid && r.BatasAtas.Valid && r.KritisAtas.Float64 < r.BatasAtas.Float64 {
+			return fmt.Errorf("%w: rujukan %d kritis_atas is below batas_atas", ErrRujukanLabInvalid, i)
+		}
+		for j := 0; j < i; j++ {
+			if rujukanTumpangTindih(jenis.Rujukan[j], r) {
+				return fmt.Errorf("%w: rujukan %d overlaps rujukan %d", ErrRujukanLabInvalid, i, j)
+			}
+		}
+	}
+	return nil
+}
+
+func rujukanTumpangTindih(a, b model.RentangRujukanLab) bool {
+	if a.JenisKelamin != b.JenisKelamin {
+		return false
+	}
+	awalA, akhirA := batasUmur(a)
+	awalB, akhirB := batasUmur(b)
+	return awalA < akhirB && awalB < akhirA
+}
+
+func batasUmur(r model.RentangRujukanLab) (int64, int64) {
+	awal, akhir := int64(0), int64(math.MaxInt64)
+	if r.UmurMinHari.Valid {
+		awal = r.UmurMinHari.Int64
+	}
+	if r.UmurMaksHari.Valid {
+		akhir = r.UmurMaksHari.Int64
+	}
+	return awal, akhir
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
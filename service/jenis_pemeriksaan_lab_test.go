@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
 
@@ -42,6 +43,56 @@ func TestJenisPemeriksaanLabService_Create(t *testing.T) {
 		assert.True(t, errors.Is(err, ErrJenisPemeriksaanConflict))
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Success: Numeric jenis with ranges per sex", func(t *testing.T) {
+		bawahL, atasL, bawahP, atasP := 13.0, 17.0, 12.0, 15.0
+		numerik := model.CreateJenisPemeriksaanLabRequest{
+			NamaPemeriksaan: "Hemoglobin",
+			TipeHasil:       model.TipeHasilNumerik,
+			Rujukan: []model.RentangRujukanLabRequest{
+				{JenisKelamin: "L", BatasBawah: &bawahL, BatasAtas: &atasL},
+				{JenisKelamin: "P", BatasBawah: &bawahP, BatasAtas: &atasP},
+			},
+		}
+		mockRepo.On("Create", mock.MatchedBy(func(j model.JenisPemeriksaanLab) bool {
+			return j.TipeHasil == model.TipeHasilNumerik && len(j.Rujukan) == 2
+		})).Return(numerik.ToModel(), nil).Once()
+
+		result, err := service.Create(context.Background(), numerik)
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Rujukan, 2)
+	})
+
+	t.Run("Fail: Overlapping age bands for the same sex", func(t *testing.T) {
+		batas, umur := 10.0, 365
+		numerik := model.CreateJenisPemeriksaanLabRequest{
+			NamaPemeriksaan: "Leukosit",
+			TipeHasil:       model.TipeHasilNumerik,
+			Rujukan: []model.RentangRujukanLabRequest{
+				{UmurMaksHari: &umur, BatasAtas: &batas},
+				{BatasAtas: &batas},
+			},
+		}
+
+		_, err := service.Create(context.Background(), numerik)
+
+		assert.ErrorIs(t, err, ErrRujukanLabInvalid)
+	})
+
+	t.Run("Fail: Normal value outside categorical choices", func(t *testing.T) {
+		kategori := model.CreateJenisPemeriksaanLabRequest{
+			NamaPemeriksaan: "HBsAg",
+			TipeHasil:       model.TipeHasilKategori,
+			PilihanHasil:    []string{"Reaktif", "Non Reaktif"},
+			NilaiNormal:     []string{"Negatif"},
+		}
+
+		_, err := service.Create(context.Background(), kategori)
+
+		assert.ErrorIs(t, err, ErrRujukanLabInvalid)
+		mockRepo.AssertNumberOfCalls(t, "Create", 3)
+	})
 }
 
 func TestJenisPemeriksaanLabService_GetAll(t *testing.T) {
@@ -95,10 +146,17 @@ func TestJenisPemeriksaanLabService_Update(t *testing.T) {
 	mockRepo := new(MockJenisPemeriksaanLabRepository)
 	service := NewJenisPemeriksaanLabService(mockRepo)
 	req := model.UpdateJenisPemeriksaanLabRequest{NamaPemeriksaan: "Trombosit Baru"}
+	current := model.JenisPemeriksaanLab{
+		ID:              1,
+		NamaPemeriksaan: "Trombosit",
+		TipeHasil:       model.TipeHasilNumerik,
+		Rujukan:         []model.RentangRujukanLab{{ID: 3, BatasBawah: sql.NullFloat64{Float64: 150, Valid: true}}},
+	}
 
 	t.Run("Success: Update", func(t *testing.T) {
 		mockRepo.On("FindByName", "Trombosit Baru").Return(model.JenisPemeriksaanLab{}, repository.ErrNotFound).Once()
-		updatedModel := req.ToModel()
+		mockRepo.On("GetById", 1).Return(current, nil).Once()
+		updatedModel := req.ToModel(current)
 		updatedModel.ID = 1
 		mockRepo.On("Update", 1, mock.AnythingOfType("model.JenisPemeriksaanLab")).Return(updatedModel, nil).Once()
 
@@ -109,6 +167,45 @@ func TestJenisPemeriksaanLabService_Update(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("Success: Omitted tipe_hasil and rujukan keep stored values", func(t *testing.T) {
+		mockRepo.On("FindByName", "Trombosit Baru").Return(model.JenisPemeriksaanLab{}, repository.ErrNotFound).Once()
+		mockRepo.On("GetById", 1).Return(current, nil).Once()
+		mockRepo.On("Update", 1, mock.MatchedBy(func(j model.JenisPemeriksaanLab) bool {
+			return j.TipeHasil == model.TipeHasilNumerik && j.Rujukan == nil
+		})).Return(current, nil).Once()
+
+		_, err := service.Update(context.Background(), 1, req)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success: Empty rujukan clears stored ranges", func(t *testing.T) {
+		kosong := req
+		kosong.Rujukan = []model.RentangRujukanLabRequest{}
+		mockRepo.On("FindByName", "Trombosit Baru").Return(model.JenisPemeriksaanLab{}, repository.ErrNotFound).Once()
+		mockRepo.On("GetById", 1).Return(current, nil).Once()
+		mockRepo.On("Update", 1, mock.MatchedBy(func(j model.JenisPemeriksaanLab) bool {
+			return j.Rujukan != nil && len(j.Rujukan) == 0
+		})).Return(current, nil).Once()
+
+		_, err := service.Update(context.Background(), 1, kosong)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Switching to teks while stored ranges remain", func(t *testing.T) {
+		teks := req
+		teks.TipeHasil = model.TipeHasilTeks
+		mockRepo.On("FindByName", "Trombosit Baru").Return(model.JenisPemeriksaanLab{}, repository.ErrNotFound).Once()
+		mockRepo.On("GetById", 1).Return(current, nil).Once()
+
+		_, err := service.Update(context.Background(), 1, teks)
+
+		assert.ErrorIs(t, err, ErrRujukanLabInvalid)
+	})
+
 	t.Run("Fail: Name conflict with another item", func(t *testing.T) {
 		existing := model.JenisPemeriksaanLab{ID: 2, NamaPemeriksaan: "Trombosit Baru"}
 		mockRepo.On("FindByName", "Trombosit Baru").Return(existing, nil).Once()
@@ -122,7 +219,7 @@ func TestJenisPemeriksaanLabService_Update(t *testing.T) {
 
 	t.Run("Fail: Item to update not found", func(t *testing.T) {
 		mockRepo.On("FindByName", "Trombosit Baru").Return(model.JenisPemeriksaanLab{}, repository.ErrNotFound).Once()
-		mockRepo.On("Update", 99, mock.AnythingOfType("model.JenisPemeriksaanLab")).Return(model.JenisPemeriksaanLab{}, repository.ErrNotFound).Once()
+		mockRepo.On("GetById", 99).Return(model.JenisPemeriksaanLab{}, repository.ErrNotFound).Once()
 
 		_, err := service.Update(context.Background(), 99, req)
 
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/franklindh/simedis-api/internal/icdimport"
+	"github.com/franklindh/simedis-api/internal/model"
+)
+
+// dipakai bersama oleh impor katalog ICD-10 (diagnosis) dan ICD-9-CM (prosedur)
+
+var ErrImportFormat = errors.New("invalid import file")
+
+const (
+	statusKatalogAktif = "aktif"
+	maxImportErrors    = 100
+	defaultSearchLimit = 20
+)
+
+func parseKatalog(source io.Reader, format string) (icdimport.Result, error) {
+	parsed, err := icdimport.Parse(source, format)
+	if err != nil {
+		return icdimport.Result{}, fmt.Errorf("%w: %v", ErrImportFormat, err)
+	}
+	return parsed, nil
+}
+
+// baris yang dilewati langsung dihitung, daftar alasannya dibatasi agar respons tetap kecil
+func newImportReport(parsed icdimport.Result, dryRun bool) model.ImportReport {
+	report := model.ImportReport{
+		DryRun: dryRun,
+		Total:  len(parsed.Entries) + len(parsed.Errors),
+		Errors: []model.ImportError{},
+	}
+	for _, rowErr := range parsed.Errors {
+		report.Skipped++
+		if len(report.Errors) < maxImportErrors {
+			report.Errors = append(report.Errors, model.ImportError{Baris: rowErr.Baris, Kode: rowErr.Kode, Pesan: rowErr.Pesan})
+		}
+	}
+	return report
+}
+
+func importKodes(entries []icdimport.Entry) []string {
+	kodes := make([]string, 0, len(entries))
+	for _, e := range entries {
+		kodes = append(kodes, e.Kode)
+	}
+	return kodes
+}
+
+func importStatus(e icdimport.Entry) string {
+	if e.Status == "" {
+		return statusKatalogAktif
+	}
+	return e.Status
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
@@ -32,7 +32,7 @@ func (s *LaporanService) GetLaporanKunjunganPerPoli(ctx context.Context, startDa
 	return s.repo.GetLaporanKunjunganPerPoli(startDate, endDate)
 }
 
-func (s *LaporanService) GetLaporanPenyakitTeratas(ctx context.Context, startDate, endDate string, limit int) ([]model.LaporanPenyakitTeratas, error) {
+func (s *LaporanService) GetLaporanPenyakitTeratas(ctx context.Context, startDate, endDate string, limit int, includeSekunder bool) ([]model.LaporanPenyakitTeratas, error) {
 	layout := "2006-01-02"
 	start, err1 := time.Parse(layout, startDate)
 	end, err2 := time.Parse(layout, endDate)
@@ -46,5 +46,22 @@ func (s *LaporanService) GetLaporanPenyakitTeratas(ctx context.Context, startDat
 		limit = 10
 	}
 
-	return s.repo.GetLaporanPenyakitTeratas(startDate, endDate, limit)
+	return s.repo.GetLaporanPenyakitTeratas(startDate, endDate, limit, includeSekunder)
+}
+
+func (s *LaporanService) GetLaporanTindakanTeratas(ctx context.Context, startDate, endDate string, limit int) ([]model.LaporanTindakanTeratas, error) {
+	layout := "2006-01-02"
+	start, err1 := time.Parse(layout, startDate)
+	end, err2 := time.Parse(layout, endDate)
+	if err1 != nil || err2 != nil {
+		return nil, fmt.Errorf("invalid date format, please use YYYY-MM-DD")
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("endDate cannot be before startDate")
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	return s.repo.GetLaporanTindakanTeratas(startDate, endDate, limit)
 }
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/pkg/utils/pdf"
+)
+
+var ErrHasilLabKosong = errors.New("no lab results to print for this pemeriksaan")
+
+// laporan hasil lab dalam bentuk PDF. Yang dicetak sama dengan yang terlihat dokter, yaitu
+// hasil yang dicatat langsung dan hasil order lab yang sudah divalidasi
+func (s *PemeriksaanLabService) CetakLaporan(ctx context.Context, pemeriksaanID int) ([]byte, error) {
+	laporan, err := s.susunLaporan(pemeriksaanID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return renderLaporanHasilLab(s.kopLaporan(), laporan), nil
+}
+
+func (s *PemeriksaanLabService) susunLaporan(pemeriksaanID int, at time.Time) (model.LaporanHasilLab, error) {
+	pemeriksaan, err := s.pemeriksaanRepo.GetById(pemeriksaanID)
+	if err != nil {
+		return model.LaporanHasilLab{}, err
+	}
+
+	hasil, err := s.repo.GetAllByPemeriksaanID(pemeriksaanID)
+	if err != nil {
+		return model.LaporanHasilLab{}, err
+	}
+	if len(hasil) == 0 {
+		return model.LaporanHasilLab{}, ErrHasilLabKosong
+	}
+
+	orders, err := s.orderRepo.GetAllByPemeriksaanID(pemeriksaanID)
+	if err != nil {
+		return model.LaporanHasilLab{}, err
+	}
+	orderByItem := make(map[int64]model.OrderLab)
+	for _, order := range orders {
+		if order.Status != model.StatusOrderLabDivalidasi {
+			continue
+		}
+		for _, item := range order.Items {
+			orderByItem[int64(item.ID)] = order
+		}
+	}
+
+	laporan := model.LaporanHasilLab{
+		PemeriksaanID:      pemeriksaan.ID,
+		TanggalPemeriksaan: pemeriksaan.TanggalPemeriksaan,
+		Pasien:             pemeriksaan.Antrian.Pasien,
+		Kelompok:           model.KelompokkanHasilLab(hasil),
+		DicetakAt:          at,
+	}
+
+	// dokter pengirim dan validator diambil dari order asal hasil, satu kali per order
+	dicatat := make(map[int]bool)
+	for _, h := range hasil {
+		if !h.OrderLabItemID.Valid {
+			continue
+		}
+		order, ok := orderByItem[h.OrderLabItemID.Int64]
+		if !ok || dicatat[order.ID] {
+			continue
+		}
+		dicatat[order.ID] = true
+		if order.Dokter.Nama != "" && !slices.Contains(laporan.DokterPengirim, order.Dokter.Nama) {
+			laporan.DokterPengirim = append(laporan.DokterPengirim, order.Dokter.Nama)
+		}
+		if order.DivalidasiAt.Valid {
+			laporan.Validasi = append(laporan.Validasi, model.ValidasiHasilLab{Nama: order.Validator.Nama, Waktu: order.DivalidasiAt.Time})
+		}
+	}
+
+	// hasil yang dicatat langsung tidak punya order, dokter penanggung jawab pemeriksaan yang ditampilkan
+	if len(laporan.DokterPengirim) == 0 {
+		switch {
+		case pemeriksaan.Dokter.Nama != "":
+			laporan.DokterPengirim = []string{pemeriksaan.Dokter.Nama}
+		case pemeriksaan.Antrian.Jadwal.Petugas.Nama != "":
+			laporan.DokterPengirim = []string{pemeriksaan.Antrian.Jadwal.Petugas.Nama}
+		}
+	}
+	return laporan, nil
+}
+
+type kopLaporan struct {
+	nama    string
+	alamat  string
+	telepon string
+}
+
+func (s *PemeriksaanLabService) kopLaporan() kopLaporan {
+	if s.config == nil {
+		return kopLaporan{nama: "Klinik"}
+	}
+	return kopLaporan{nama: s.config.KlinikNama, alamat: s.config.KlinikAlamat, telepon: s.config.KlinikTelepon}
+}
+
+// kolom tabel hasil: x dan lebar dalam point
+var kolomLaporanLab = []struct {
+	judul string
+	x     float64
+	lebar float64
+}{
+	{"Pemeriksaan", 40, 170},
+	{"Hasil", 215, 95},
+	{"Flag", 315, 35},
+	{"Satuan", 355, 60},
+	{"Nilai Rujukan", 420, 135},
+}
+
+const (
+	marginLaporan      = 40.0
+	barisLaporan       = 12.0
+	ukuranHurufLaporan = 9.0
+)
+
+func renderLaporanHasilLab(kop kopLaporan, l model.LaporanHasilLab) []byte {
+	doc := pdf.New()
+	kanan := doc.Width() - marginLaporan
+	batasBawah := doc.Height() - 70
+	doc.AddPage()
+
+	// kop klinik
+	y := 50.0
+	doc.Text(marginLaporan, y, 14, true, kop.nama)
+	kontak := strings.Join(slices.DeleteFunc([]string{kop.alamat, kop.telepon}, func(s string) bool { return s == "" }), " - ")
+	if kontak != "" {
+		y += 14
+		doc.Text(marginLaporan, y, ukuranHurufLaporan, false, kontak)
+	}
+	y += 8
+	doc.Line(marginLaporan, y, kanan, y, 1)
+	y += 22
+	doc.TextCenter(doc.Width()/2, y, 12, true, "HASIL PEMERIKSAAN LABORATORIUM")
+
+	// identitas pasien di kiri, data pemeriksaan di kanan
+	y += 22
+	kiri := [][2]string{
+		{"Nama Pasien", l.Pasien.NamaPasien},
+		{"No. Rekam Medis", atauStrip(l.Pasien.NoRekamMedis.String)},
+		{"Jenis Kelamin", jenisKelaminLaporan(l.Pasien.JKPasien)},
+		{"Tanggal Lahir", tanggalLahirLaporan(l.Pasien.TanggalLahirPasien, l.TanggalPemeriksaan)},
+	}
+	kananInfo := [][2]string{
+		{"No. Pemeriksaan", fmt.Sprintf("%d", l.PemeriksaanID)},
+		{"Tanggal Periksa", tanggalLaporan(l.TanggalPemeriksaan)},
+		{"Dokter Pengirim", atauStrip(strings.Join(l.DokterPengirim, ", "))},
+	}
+	for i := range max(len(kiri), len(kananInfo)) {
+		if i < len(kiri) {
+			doc.Text(marginLaporan, y, ukuranHurufLaporan, false, kiri[i][0])
+			doc.Text(marginLaporan+80, y, ukuranHurufLaporan, false, ": "+kiri[i][1])
+		}
+		if i < len(kananInfo) {
+			doc.Text(320, y, ukuranHurufLaporan, false, kananInfo[i][0])
+			doc.Text(400, y, ukuranHurufLaporan, false, ": "+kananInfo[i][1])
+		}
+		y += barisLaporan + 2
+	}
+
+	judulTabel := func(y float64) float64 {
+		doc.FillRect(marginLaporan, y, kanan-marginLaporan, 16, 0.88)
+		for _, k := range kolomLaporanLab {
+			doc.Text(k.x+2, y+11, ukuranHurufLaporan, true, k.judul)
+		}
+		return y + 16 + barisLaporan
+	}
+	halamanBaru := func() float64 {
+		doc.AddPage()
+		doc.Text(marginLaporan, 50, ukuranHurufLaporan, true, fmt.Sprintf("Hasil Laboratorium - %s (%s)", l.Pasien.NamaPasien, atauStrip(l.Pasien.NoRekamMedis.String)))
+		return judulTabel(64)
+	}
+
+	y = judulTabel(y + 6)
+	for _, kelompok := range l.Kelompok {
+		if kelompok.Panel != nil {
+			if y+barisLaporan*2 > batasBawah {
+				y = halamanBaru()
+			}
+			doc.Text(marginLaporan+2, y, ukuranHurufLaporan, true, kelompok.Panel.Nama)
+			y += barisLaporan
+		}
+		indent := 0.0
+		if kelompok.Panel != nil {
+			indent = 10
+		}
+		for _, h := range kelompok.Hasil {
+			kolom := [][]string{
+				pdf.WrapText(h.JenisPemeriksaan.Nama, kolomLaporanLab[0].lebar-4-indent, ukuranHurufLaporan, false),
+				pdf.WrapText(h.Hasil, kolomLaporanLab[1].lebar-4, ukuranHurufLaporan, h.Flag != ""),
+				{h.Flag},
+				pdf.WrapText(h.Satuan, kolomLaporanLab[3].lebar-4, ukuranHurufLaporan, false),
+				pdf.WrapText(atauStrip(h.NilaiRujukan), kolomLaporanLab[4].lebar-4, ukuranHurufLaporan, false),
+			}
+			tinggi := 0
+			for _, baris := range kolom {
+				tinggi = max(tinggi, len(baris))
+			}
+			if y+float64(tinggi-1)*barisLaporan > batasBawah {
+				y = halamanBaru()
+			}
+			for i, baris := range kolom {
+				x := kolomLaporanLab[i].x + 2
+				if i == 0 {
+					x += indent
+				}
+				tebal := i == 1 || i == 2
+				if tebal && h.Flag != "" {
+					doc.SetTextColor(0.8, 0, 0)
+				}
+				for j, teks := range baris {
+					doc.Text(x, y+float64(j)*barisLaporan, ukuranHurufLaporan, tebal && h.Flag != "", teks)
+				}
+				doc.SetTextColor(0, 0, 0)
+			}
+			y += float64(tinggi)*barisLaporan + 2
+		}
+	}
+
+	// keterangan flag dan validasi
+	penutup := 3 + max(len(l.Validasi), 1)
+	if y+float64(penutup)*barisLaporan+10 > batasBawah {
+		y = halamanBaru()
+	}
+	doc.Line(marginLaporan, y-barisLaporan+4, kanan, y-barisLaporan+4, 0.5)
+	y += 4
+	doc.Text(marginLaporan, y, 8, false, "Keterangan flag: H/L di atas/di bawah nilai rujukan, HH/LL nilai kritis, A hasil abnormal.")
+	y += barisLaporan * 1.5
+	doc.Text(marginLaporan, y, ukuranHurufLaporan, true, "Divalidasi oleh")
+	if len(l.Validasi) == 0 {
+		doc.Text(marginLaporan+80, y, ukuranHurufLaporan, false, ": -")
+	}
+	for _, v := range l.Validasi {
+		doc.Text(marginLaporan+80, y, ukuranHurufLaporan, false, fmt.Sprintf(": %s (%s)", atauStrip(v.Nama), waktuLaporan(v.Waktu)))
+		y += barisLaporan
+	}
+
+	// nomor halaman ditulis setelah jumlah halaman diketahui
+	for i := range doc.PageCount() {
+		doc.SetPage(i)
+		y := doc.Height() - 30
+		doc.Line(marginLaporan, y-12, kanan, y-12, 0.5)
+		doc.Text(marginLaporan, y, 8, false, "Dicetak "+waktuLaporan(l.DicetakAt))
+		doc.TextRight(kanan, y, 8, false, fmt.Sprintf("Halaman %d dari %d", i+1, doc.PageCount()))
+	}
+	return doc.Bytes()
+}
+
+func atauStrip(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func jenisKelaminLaporan(jk string) string {
+	switch jk {
+	case "L":
+		return "Laki-laki"
+	case "P":
+		return "Perempuan"
+	}
+	return atauStrip(jk)
+}
+
+func tanggalLahirLaporan(lahir, at time.Time) string {
+	if lahir.IsZero() {
+		return "-"
+	}
+	umur := at.Year() - lahir.Year()
+	if at.Month() < lahir.Month() || (at.Month() == lahir.Month() && at.Day() < lahir.Day()) {
+		umur--
+	}
+	return fmt.Sprintf("%s (%d tahun)", tanggalLaporan(lahir), max(umur, 0))
+}
+
+func tanggalLaporan(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("02-01-2006")
+}
+
+func waktuLaporan(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("02-01-2006 15:04")
+}
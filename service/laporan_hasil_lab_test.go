@@ -0,0 +1,129 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/franklindh/simedis-api/internal/config"
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPemeriksaanLabService_CetakLaporan(t *testing.T) {
+	mockRepo := new(MockPemeriksaanLabRepository)
+	mockPemeriksaanRepo := new(MockPemeriksaanRepository)
+	mockOrderRepo := new(MockOrderLabRepository)
+	cfg := &config.Config{KlinikNama: "Klinik Sehat", KlinikAlamat: "Jl. Merdeka 1"}
+	service := NewPemeriksaanLabService(mockRepo, nil, nil, mockPemeriksaanRepo, mockOrderRepo, nil, cfg)
+
+	divalidasiAt := time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC)
+	pemeriksaan := model.Pemeriksaan{
+		ID:                 100,
+		TanggalPemeriksaan: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		Antrian: model.Antrian{Pasien: model.Pasien{
+			NamaPasien:         "Siti Aminah",
+			NoRekamMedis:       sql.NullString{String: "RM-0001", Valid: true},
+			JKPasien:           "P",
+			TanggalLahirPasien: time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC),
+		}},
+		Dokter: model.Petugas{Nama: "dr. Penanggung Jawab"},
+	}
+	panel := &model.PanelLab{ID: 20, NamaPanel: "Darah Lengkap", Items: []model.PanelLabItem{{JenisPemeriksaanID: 1, Urutan: 1}}}
+	hasil := []model.PemeriksaanLab{
+		{
+			ID:                  1,
+			JenisPemeriksaanID:  1,
+			Hasil:               "6.2",
+			Flag:                sql.NullString{String: model.FlagLabKritisRendah, Valid: true},
+			NilaiRujukan:        sql.NullString{String: "12 - 16", Valid: true},
+			OrderLabItemID:      sql.NullInt64{Int64: 7, Valid: true},
+			PanelLabID:          sql.NullInt64{Int64: 20, Valid: true},
+			Panel:               panel,
+			JenisPemeriksaanLab: model.JenisPemeriksaanLab{ID: 1, NamaPemeriksaan: "Hemoglobin", Satuan: sql.NullString{String: "g/dL", Valid: true}},
+		},
+		{ID: 2, JenisPemeriksaanID: 2, Hasil: "Negatif", JenisPemeriksaanLab: model.JenisPemeriksaanLab{ID: 2, NamaPemeriksaan: "Protein Urine"}},
+	}
+
+	t.Run("Success: Requesting doctor and validator taken from the validated order", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 100).Return(pemeriksaan, nil).Once()
+		mockRepo.On("GetAllByPemeriksaanID", 100).Return(hasil, nil).Once()
+		mockOrderRepo.On("GetAllByPemeriksaanID", 100).Return([]model.OrderLab{
+			{
+				ID:           3,
+				Status:       model.StatusOrderLabDivalidasi,
+				Dokter:       model.Petugas{Nama: "dr. Budi"},
+				Validator:    model.Petugas{Nama: "Rina Analis"},
+				DivalidasiAt: sql.NullTime{Time: divalidasiAt, Valid: true},
+				Items:        []model.OrderLabItem{{ID: 7, JenisPemeriksaanID: 1}},
+			},
+			// order yang belum divalidasi tidak ikut walaupun itemnya sama
+			{ID: 4, Status: model.StatusOrderLabDipesan, Dokter: model.Petugas{Nama: "dr. Lain"}, Items: []model.OrderLabItem{{ID: 8}}},
+		}, nil).Once()
+
+		laporan, err := service.susunLaporan(100, divalidasiAt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"dr. Budi"}, laporan.DokterPengirim)
+		if assert.Len(t, laporan.Validasi, 1) {
+			assert.Equal(t, "Rina Analis", laporan.Validasi[0].Nama)
+		}
+		if assert.Len(t, laporan.Kelompok, 2) {
+			assert.Equal(t, "Darah Lengkap", laporan.Kelompok[0].Panel.Nama)
+		}
+	})
+
+	t.Run("Success: PDF contains patient, results and validator", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 100).Return(pemeriksaan, nil).Once()
+		mockRepo.On("GetAllByPemeriksaanID", 100).Return(hasil, nil).Once()
+		mockOrderRepo.On("GetAllByPemeriksaanID", 100).Return([]model.OrderLab{{
+			ID:           3,
+			Status:       model.StatusOrderLabDivalidasi,
+			Dokter:       model.Petugas{Nama: "dr. Budi"},
+			Validator:    model.Petugas{Nama: "Rina Analis"},
+			DivalidasiAt: sql.NullTime{Time: divalidasiAt, Valid: true},
+			Items:        []model.OrderLabItem{{ID: 7}},
+		}}, nil).Once()
+
+		laporan, err := service.CetakLaporan(context.Background(), 100)
+
+		assert.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(laporan, []byte("%PDF-")))
+		assert.True(t, bytes.HasSuffix(laporan, []byte("%%EOF\n")))
+		for _, teks := range []string{"Klinik Sehat", "Siti Aminah", "RM-0001", "dr. Budi", "Hemoglobin", "(LL)", "12 - 16", "g/dL", "Darah Lengkap", "Rina Analis"} {
+			assert.Contains(t, string(laporan), teks)
+		}
+	})
+
+	t.Run("Success: Direct results fall back to the attending doctor", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 100).Return(pemeriksaan, nil).Once()
+		mockRepo.On("GetAllByPemeriksaanID", 100).Return(hasil[1:], nil).Once()
+		mockOrderRepo.On("GetAllByPemeriksaanID", 100).Return([]model.OrderLab{}, nil).Once()
+
+		laporan, err := service.susunLaporan(100, time.Now())
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"dr. Penanggung Jawab"}, laporan.DokterPengirim)
+		assert.Empty(t, laporan.Validasi)
+	})
+
+	t.Run("Fail: No lab results", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 100).Return(pemeriksaan, nil).Once()
+		mockRepo.On("GetAllByPemeriksaanID", 100).Return([]model.PemeriksaanLab{}, nil).Once()
+
+		_, err := service.CetakLaporan(context.Background(), 100)
+
+		assert.ErrorIs(t, err, ErrHasilLabKosong)
+	})
+
+	t.Run("Fail: Pemeriksaan not found", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 404).Return(model.Pemeriksaan{}, repository.ErrNotFound).Once()
+
+		_, err := service.CetakLaporan(context.Background(), 404)
+
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
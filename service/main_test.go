@@ -34,6 +34,14 @@ func (m *MockAntrianRepository) Update(id int, antrian model.Antrian) (model.Ant
 	args := m.Called(id, antrian)
 	return args.Get(0).(model.Antrian), args.Error(1)
 }
+func (m *MockAntrianRepository) UpdateStatus(id int, fromStatus, toStatus string, actorID int, at time.Time) (model.Antrian, error) {
+	args := m.Called(id, fromStatus, toStatus, actorID, at)
+	return args.Get(0).(model.Antrian), args.Error(1)
+}
+func (m *MockAntrianRepository) CallNext(jadwalID int, actorID int, at time.Time) (model.Antrian, error) {
+	args := m.Called(jadwalID, actorID, at)
+	return args.Get(0).(model.Antrian), args.Error(1)
+}
 func (m *MockAntrianRepository) Delete(id int) error {
 	args := m.Called(id)
 	return args.Error(0)
@@ -46,9 +54,16 @@ func (m *MockAntrianRepository) CheckForOverlappingAntrian(pasienID int, tanggal
 	args := m.Called(pasienID, tanggal, waktuMulai, waktuSelesai)
 	return args.Bool(0), args.Error(1)
 }
-func (m *MockAntrianRepository) CountTodayByJadwal(jadwalID int) (int64, error) {
-	args := m.Called(jadwalID)
-	return args.Get(0).(int64), args.Error(1)
+func (m *MockAntrianRepository) CreateWithSequence(antrian model.Antrian, prefix string) (model.Antrian, error) {
+	args := m.Called(antrian, prefix)
+	return args.Get(0).(model.Antrian), args.Error(1)
+}
+func (m *MockAntrianRepository) GetUpcomingByPasienID(pasienID int) ([]model.Antrian, error) {
+	args := m.Called(pasienID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Antrian), args.Error(1)
 }
 
 type MockJadwalRepository struct {
@@ -114,6 +129,122 @@ func (m *MockJenisPemeriksaanLabRepository) FindByName(name string) (model.Jenis
 	args := m.Called(name)
 	return args.Get(0).(model.JenisPemeriksaanLab), args.Error(1)
 }
+func (m *MockJenisPemeriksaanLabRepository) GetByIDs(ids []int) ([]model.JenisPemeriksaanLab, error) {
+	args := m.Called(ids)
+	return args.Get(0).([]model.JenisPemeriksaanLab), args.Error(1)
+}
+
+type MockObatRepository struct {
+	mock.Mock
+}
+
+var _ ObatRepository = (*MockObatRepository)(nil)
+
+func (m *MockObatRepository) Create(obat model.Obat) (model.Obat, error) {
+	args := m.Called(obat)
+	return args.Get(0).(model.Obat), args.Error(1)
+}
+func (m *MockObatRepository) GetAll(params repository.ParamsGetAllObat) ([]model.Obat, pagination.Metadata, error) {
+	args := m.Called(params)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(pagination.Metadata), args.Error(2)
+	}
+	return args.Get(0).([]model.Obat), args.Get(1).(pagination.Metadata), args.Error(2)
+}
+func (m *MockObatRepository) GetByID(id int) (model.Obat, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.Obat), args.Error(1)
+}
+func (m *MockObatRepository) GetByIDs(ids []int) ([]model.Obat, error) {
+	args := m.Called(ids)
+	return args.Get(0).([]model.Obat), args.Error(1)
+}
+func (m *MockObatRepository) Update(id int, obat model.Obat) (model.Obat, error) {
+	args := m.Called(id, obat)
+	return args.Get(0).(model.Obat), args.Error(1)
+}
+func (m *MockObatRepository) Delete(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+type MockOrderLabRepository struct {
+	mock.Mock
+}
+
+var _ OrderLabRepository = (*MockOrderLabRepository)(nil)
+
+func (m *MockOrderLabRepository) Create(order model.OrderLab) (model.OrderLab, error) {
+	args := m.Called(order)
+	return args.Get(0).(model.OrderLab), args.Error(1)
+}
+func (m *MockOrderLabRepository) GetByID(id int) (model.OrderLab, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.OrderLab), args.Error(1)
+}
+func (m *MockOrderLabRepository) GetAllByPemeriksaanID(pemeriksaanID int) ([]model.OrderLab, error) {
+	args := m.Called(pemeriksaanID)
+	return args.Get(0).([]model.OrderLab), args.Error(1)
+}
+func (m *MockOrderLabRepository) GetAll(params repository.ParamsGetAllOrderLab) ([]model.OrderLab, pagination.Metadata, error) {
+	args := m.Called(params)
+	return args.Get(0).([]model.OrderLab), args.Get(1).(pagination.Metadata), args.Error(2)
+}
+func (m *MockOrderLabRepository) AmbilSpesimen(id int, actorID int, at time.Time) (model.OrderLab, error) {
+	args := m.Called(id, actorID, at)
+	return args.Get(0).(model.OrderLab), args.Error(1)
+}
+func (m *MockOrderLabRepository) TerimaSpesimen(id int, actorID int, at time.Time) (model.OrderLab, error) {
+	args := m.Called(id, actorID, at)
+	return args.Get(0).(model.OrderLab), args.Error(1)
+}
+func (m *MockOrderLabRepository) Validasi(id int, actorID int, at time.Time) (model.OrderLab, error) {
+	args := m.Called(id, actorID, at)
+	return args.Get(0).(model.OrderLab), args.Error(1)
+}
+func (m *MockOrderLabRepository) Batalkan(id int, alasan string, at time.Time) (model.OrderLab, error) {
+	args := m.Called(id, alasan, at)
+	return args.Get(0).(model.OrderLab), args.Error(1)
+}
+func (m *MockOrderLabRepository) SimpanHasil(id int, hasil []model.PemeriksaanLab, status string) (model.OrderLab, error) {
+	args := m.Called(id, hasil, status)
+	return args.Get(0).(model.OrderLab), args.Error(1)
+}
+
+type MockPanelLabRepository struct {
+	mock.Mock
+}
+
+var _ PanelLabRepository = (*MockPanelLabRepository)(nil)
+
+func (m *MockPanelLabRepository) Create(panel model.PanelLab) (model.PanelLab, error) {
+	args := m.Called(panel)
+	return args.Get(0).(model.PanelLab), args.Error(1)
+}
+func (m *MockPanelLabRepository) GetAll() ([]model.PanelLab, error) {
+	args := m.Called()
+	return args.Get(0).([]model.PanelLab), args.Error(1)
+}
+func (m *MockPanelLabRepository) GetByID(id int) (model.PanelLab, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.PanelLab), args.Error(1)
+}
+func (m *MockPanelLabRepository) GetByIDs(ids []int) ([]model.PanelLab, error) {
+	args := m.Called(ids)
+	return args.Get(0).([]model.PanelLab), args.Error(1)
+}
+func (m *MockPanelLabRepository) FindByName(name string) (model.PanelLab, error) {
+	args := m.Called(name)
+	return args.Get(0).(model.PanelLab), args.Error(1)
+}
+func (m *MockPanelLabRepository) Update(id int, panel model.PanelLab) (model.PanelLab, error) {
+	args := m.Called(id, panel)
+	return args.Get(0).(model.PanelLab), args.Error(1)
+}
+func (m *MockPanelLabRepository) Delete(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
 
 type MockPasienRepository struct {
 	mock.Mock
@@ -158,20 +289,36 @@ func (m *MockPasienRepository) GetLastID() (int, error) {
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockPasienRepository) GetByUsername(username string) (model.Pasien, error) {
+	args := m.Called(username)
+	return args.Get(0).(model.Pasien), args.Error(1)
+}
+
+func (m *MockPasienRepository) UpdatePassword(id int, newHashedPassword string) error {
+	args := m.Called(id, newHashedPassword)
+	return args.Error(0)
+}
+func (m *MockPasienRepository) GetTokenVersion(id int) (int, error) {
+	args := m.Called(id)
+	return args.Int(0), args.Error(1)
+}
+
 type MockPemeriksaanLabRepository struct {
 	mock.Mock
 }
 
 var _ PemeriksaanLabRepository = (*MockPemeriksaanLabRepository)(nil)
 
-func (m *MockPemeriksaanLabRepository) Create(hasilLab model.PemeriksaanLab) (model.PemeriksaanLab, error) {
-	args := m.Called(hasilLab)
+func (m *MockPemeriksaanLabRepository) SimpanBatch(list []model.PemeriksaanLab) ([]model.PemeriksaanLab, error) {
+	args := m.Called(list)
 
-	if retFn, ok := args.Get(0).(func(model.PemeriksaanLab) model.PemeriksaanLab); ok {
-		return retFn(hasilLab), args.Error(1)
+	if retFn, ok := args.Get(0).(func([]model.PemeriksaanLab) []model.PemeriksaanLab); ok {
+		return retFn(list), args.Error(1)
 	}
-
-	return args.Get(0).(model.PemeriksaanLab), args.Error(1)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.PemeriksaanLab), args.Error(1)
 }
 func (m *MockPemeriksaanLabRepository) GetAllByPemeriksaanID(pemeriksaanID int) ([]model.PemeriksaanLab, error) {
 	args := m.Called(pemeriksaanID)
@@ -219,13 +366,38 @@ func (m *MockPemeriksaanRepository) GetAllByPasienID(pasienID int) ([]model.Peme
 	}
 	return args.Get(0).([]model.Pemeriksaan), args.Error(1)
 }
-func (m *MockPemeriksaanRepository) Update(id int, pemeriksaan model.Pemeriksaan) (model.Pemeriksaan, error) {
-	args := m.Called(id, pemeriksaan)
+func (m *MockPemeriksaanRepository) GetVitalsByPasienID(pasienID int, from, to string) ([]model.Pemeriksaan, error) {
+	args := m.Called(pasienID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Pemeriksaan), args.Error(1)
+}
+func (m *MockPemeriksaanRepository) Update(id int, pemeriksaan model.Pemeriksaan, alasan string) (model.Pemeriksaan, error) {
+	args := m.Called(id, pemeriksaan, alasan)
 	return args.Get(0).(model.Pemeriksaan), args.Error(1)
 }
-func (m *MockPemeriksaanRepository) Delete(id int) error {
-	args := m.Called(id)
-	return args.Error(0)
+func (m *MockPemeriksaanRepository) Void(id int, alasan string, actorID int, at time.Time) (model.Pemeriksaan, error) {
+	args := m.Called(id, alasan, actorID, at)
+	return args.Get(0).(model.Pemeriksaan), args.Error(1)
+}
+func (m *MockPemeriksaanRepository) Finalize(id int, revisi int, signerID int, at time.Time, signature string) (model.Pemeriksaan, error) {
+	args := m.Called(id, revisi, signerID, at, signature)
+	if retFn, ok := args.Get(0).(func(int, int, int, time.Time, string) model.Pemeriksaan); ok {
+		return retFn(id, revisi, signerID, at, signature), args.Error(1)
+	}
+	return args.Get(0).(model.Pemeriksaan), args.Error(1)
+}
+func (m *MockPemeriksaanRepository) CreateAddendum(addendum model.PemeriksaanAddendum) (model.PemeriksaanAddendum, error) {
+	args := m.Called(addendum)
+	return args.Get(0).(model.PemeriksaanAddendum), args.Error(1)
+}
+func (m *MockPemeriksaanRepository) GetRevisions(pemeriksaanID int) ([]model.PemeriksaanRevisi, error) {
+	args := m.Called(pemeriksaanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.PemeriksaanRevisi), args.Error(1)
 }
 
 type MockPetugasRepository struct {
@@ -270,6 +442,11 @@ func (m *MockPetugasRepository) UpdatePassword(id int, newHashedPassword string)
 	return args.Error(0)
 }
 
+func (m *MockPetugasRepository) IncrementTokenVersion(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
 type MockPoliRepository struct {
 	mock.Mock
 }
@@ -305,3 +482,189 @@ func (m *MockPoliRepository) FindByName(name string) (model.Poli, error) {
 	args := m.Called(name)
 	return args.Get(0).(model.Poli), args.Error(1)
 }
+
+type MockResepRepository struct {
+	mock.Mock
+}
+
+var _ ResepRepository = (*MockResepRepository)(nil)
+
+func (m *MockResepRepository) Create(resep model.Resep) (model.Resep, error) {
+	args := m.Called(resep)
+	return args.Get(0).(model.Resep), args.Error(1)
+}
+func (m *MockResepRepository) GetByID(id int) (model.Resep, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.Resep), args.Error(1)
+}
+func (m *MockResepRepository) GetAllByPemeriksaanID(pemeriksaanID int) ([]model.Resep, error) {
+	args := m.Called(pemeriksaanID)
+	return args.Get(0).([]model.Resep), args.Error(1)
+}
+func (m *MockResepRepository) GetAll(params repository.ParamsGetAllResep) ([]model.Resep, pagination.Metadata, error) {
+	args := m.Called(params)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(pagination.Metadata), args.Error(2)
+	}
+	return args.Get(0).([]model.Resep), args.Get(1).(pagination.Metadata), args.Error(2)
+}
+func (m *MockResepRepository) Serahkan(id int, actorID int, at time.Time, mutasi []model.StokMutasi) (model.Resep, error) {
+	args := m.Called(id, actorID, at, mutasi)
+	return args.Get(0).(model.Resep), args.Error(1)
+}
+
+type MockAlergiRepository struct {
+	mock.Mock
+}
+
+func (m *MockAlergiRepository) Create(alergi model.AlergiPasien) (model.AlergiPasien, error) {
+	args := m.Called(alergi)
+	return args.Get(0).(model.AlergiPasien), args.Error(1)
+}
+
+func (m *MockAlergiRepository) GetAllByPasienID(pasienID int) ([]model.AlergiPasien, error) {
+	args := m.Called(pasienID)
+	return args.Get(0).([]model.AlergiPasien), args.Error(1)
+}
+
+func (m *MockAlergiRepository) GetByID(pasienID int, id int) (model.AlergiPasien, error) {
+	args := m.Called(pasienID, id)
+	return args.Get(0).(model.AlergiPasien), args.Error(1)
+}
+
+func (m *MockAlergiRepository) Update(pasienID int, id int, alergi model.AlergiPasien) (model.AlergiPasien, error) {
+	args := m.Called(pasienID, id, alergi)
+	return args.Get(0).(model.AlergiPasien), args.Error(1)
+}
+
+func (m *MockAlergiRepository) Delete(pasienID int, id int) error {
+	args := m.Called(pasienID, id)
+	return args.Error(0)
+}
+
+type MockInteraksiObatRepository struct {
+	mock.Mock
+}
+
+func (m *MockInteraksiObatRepository) GetByZat(zat []string) ([]model.InteraksiObat, error) {
+	args := m.Called(zat)
+	return args.Get(0).([]model.InteraksiObat), args.Error(1)
+}
+
+func (m *MockInteraksiObatRepository) GetAll() ([]model.InteraksiObat, error) {
+	args := m.Called()
+	return args.Get(0).([]model.InteraksiObat), args.Error(1)
+}
+
+func (m *MockInteraksiObatRepository) Upsert(list []model.InteraksiObat) error {
+	args := m.Called(list)
+	return args.Error(0)
+}
+
+type MockStokRepository struct {
+	mock.Mock
+}
+
+func (m *MockStokRepository) StokMasuk(batch model.StokBatch, mutasi model.StokMutasi) (model.StokBatch, error) {
+	args := m.Called(batch, mutasi)
+	return args.Get(0).(model.StokBatch), args.Error(1)
+}
+
+func (m *MockStokRepository) Sesuaikan(mutasi model.StokMutasi) (model.StokBatch, error) {
+	args := m.Called(mutasi)
+	return args.Get(0).(model.StokBatch), args.Error(1)
+}
+
+func (m *MockStokRepository) GetBatchByID(id int) (model.StokBatch, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.StokBatch), args.Error(1)
+}
+
+func (m *MockStokRepository) GetBatchByNomor(obatID int, nomorBatch string) (model.StokBatch, error) {
+	args := m.Called(obatID, nomorBatch)
+	return args.Get(0).(model.StokBatch), args.Error(1)
+}
+
+func (m *MockStokRepository) GetBatchTersedia(obatIDs []int, today time.Time) ([]model.StokBatch, error) {
+	args := m.Called(obatIDs, today)
+	return args.Get(0).([]model.StokBatch), args.Error(1)
+}
+
+func (m *MockStokRepository) GetAllBatch(params repository.ParamsGetAllStokBatch) ([]model.StokBatch, error) {
+	args := m.Called(params)
+	return args.Get(0).([]model.StokBatch), args.Error(1)
+}
+
+func (m *MockStokRepository) GetAllMutasi(params repository.ParamsGetAllStokMutasi) ([]model.StokMutasi, pagination.Metadata, error) {
+	args := m.Called(params)
+	return args.Get(0).([]model.StokMutasi), args.Get(1).(pagination.Metadata), args.Error(2)
+}
+
+func (m *MockStokRepository) GetStokMenipis(today time.Time) ([]model.StokObat, error) {
+	args := m.Called(today)
+	return args.Get(0).([]model.StokObat), args.Error(1)
+}
+
+func (m *MockStokRepository) GetBatchKedaluwarsa(batas time.Time) ([]model.StokBatch, error) {
+	args := m.Called(batas)
+	return args.Get(0).([]model.StokBatch), args.Error(1)
+}
+
+type MockTokenRepository struct {
+	mock.Mock
+}
+
+var _ TokenRepository = (*MockTokenRepository)(nil)
+
+func (m *MockTokenRepository) CreateRefreshToken(token model.RefreshToken) (model.RefreshToken, error) {
+	args := m.Called(token)
+	return args.Get(0).(model.RefreshToken), args.Error(1)
+}
+
+func (m *MockTokenRepository) GetRefreshTokenByHash(tokenHash string) (model.RefreshToken, error) {
+	args := m.Called(tokenHash)
+	return args.Get(0).(model.RefreshToken), args.Error(1)
+}
+
+func (m *MockTokenRepository) RotateRefreshToken(oldID int, newToken model.RefreshToken) (model.RefreshToken, error) {
+	args := m.Called(oldID, newToken)
+	return args.Get(0).(model.RefreshToken), args.Error(1)
+}
+
+func (m *MockTokenRepository) RevokeRefreshToken(tokenHash string) error {
+	args := m.Called(tokenHash)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) RevokeAllRefreshTokens(petugasID int) error {
+	args := m.Called(petugasID)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) RevokeJTI(jti string, expiresAt time.Time) error {
+	args := m.Called(jti, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) IsJTIRevoked(jti string) (bool, error) {
+	args := m.Called(jti)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+var _ AuditRepository = (*MockAuditRepository)(nil)
+
+func (m *MockAuditRepository) Create(entry model.AuditLog) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+func (m *MockAuditRepository) GetAll(params repository.ParamsGetAllAudit) ([]model.AuditLog, pagination.Metadata, error) {
+	args := m.Called(params)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(pagination.Metadata), args.Error(2)
+	}
+	return args.Get(0).([]model.AuditLog), args.Get(1).(pagination.Metadata), args.Error(2)
+}
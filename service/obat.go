@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/pkg/utils/pagination"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrObatConflict = errors.New("drug code already exists")
+)
+
+type ObatService struct {
+	repo ObatRepository
+}
+
+func NewObatService(repo ObatRepository) *ObatService {
+	return &ObatService{repo: repo}
+}
+
+func (s *ObatService) CreateObat(ctx context.Context, req model.CreateObatRequest) (model.ObatResponse, error) {
+	created, err := s.repo.Create(req.ToModel())
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return model.ObatResponse{}, ErrObatConflict
+		}
+		return model.ObatResponse{}, fmt.Errorf("failed to create obat: %w", err)
+	}
+	return model.ToObatResponse(created), nil
+}
+
+func (s *ObatService) GetAllObat(ctx context.Context, params repository.ParamsGetAllObat) ([]model.ObatResponse, pagination.Metadata, error) {
+	list, metadata, err := s.repo.GetAll(params)
+	if err != nil {
+		return nil, metadata, err
+	}
+	return model.ToObatResponseList(list), metadata, nil
+}
+
+func (s *ObatService) GetObatByID(ctx context.Context, id int) (model.ObatResponse, error) {
+	obat, err := s.repo.GetByID(id)
+	if err != nil {
+		return model.ObatResponse{}, err
+	}
+	return model.ToObatResponse(obat), nil
+}
+
+func (s *ObatService) UpdateObat(ctx context.Context, id int, req model.UpdateObatRequest) (model.ObatResponse, error) {
+	updated, err := s.repo.Update(id, req.ToModel())
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return model.ObatResponse{}, ErrObatConflict
+		}
+		return model.ObatResponse{}, err
+	}
+	return model.ToObatResponse(updated), nil
+}
+
+func (s *ObatService) DeleteObat(ctx context.Context, id int) error {
+	return s.repo.Delete(id)
+}
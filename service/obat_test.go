@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestObatService_CreateObat(t *testing.T) {
+	mockRepo := new(MockObatRepository)
+	obatService := NewObatService(mockRepo)
+	req := model.CreateObatRequest{KodeObat: "PCT500", NamaObat: "Parasetamol", BentukSediaan: "tablet", Kekuatan: "500 mg", Satuan: "tablet", Status: "aktif"}
+
+	t.Run("Success: Create obat", func(t *testing.T) {
+		created := req.ToModel()
+		created.ID = 1
+		mockRepo.On("Create", mock.AnythingOfType("model.Obat")).Return(created, nil).Once()
+
+		result, err := obatService.CreateObat(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "500 mg", result.Kekuatan)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Drug code conflict", func(t *testing.T) {
+		pgErr := &pgconn.PgError{Code: "23505"}
+		mockRepo.On("Create", mock.AnythingOfType("model.Obat")).Return(model.Obat{}, pgErr).Once()
+
+		_, err := obatService.CreateObat(context.Background(), req)
+
+		assert.True(t, errors.Is(err, ErrObatConflict))
+		mockRepo.AssertExpectations(t)
+	})
+}
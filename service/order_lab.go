@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/pkg/utils/pagination"
+)
+
+var (
+	ErrJenisPemeriksaanInvalid = errors.New("jenis pemeriksaan lab not found")
+	ErrOrderLabJenisDuplikat   = errors.New("the same jenis pemeriksaan cannot be ordered twice in one lab order")
+	ErrOrderLabStatus          = errors.New("lab order status does not allow this action")
+	ErrOrderLabItemInvalid     = errors.New("item does not belong to this lab order")
+	ErrValidatorPengisiHasil   = errors.New("results must be validated by a different lab user than the one who entered them")
+)
+
+type OrderLabService struct {
+	repo            OrderLabRepository
+	jenisRepo       JenisPemeriksaanLabRepository
+	panelRepo       PanelLabRepository
+	pemeriksaanRepo PemeriksaanRepository
+	audit           AuditRecorder
+}
+
+func NewOrderLabService(repo OrderLabRepository, jenisRepo JenisPemeriksaanLabRepository, panelRepo PanelLabRepository, pemeriksaanRepo PemeriksaanRepository, audit AuditRecorder) *OrderLabService {
+	return &OrderLabService{repo: repo, jenisRepo: jenisRepo, panelRepo: panelRepo, pemeriksaanRepo: pemeriksaanRepo, audit: audit}
+}
+
+// order ditulis oleh dokter yang login untuk pemeriksaan yang belum dibatalkan
+func (s *OrderLabService) CreateOrder(ctx context.Context, pemeriksaanID int, req model.CreateOrderLabRequest, dokterID int) (model.OrderLabResponse, error) {
+	pemeriksaan, err := s.pemeriksaanRepo.GetById(pemeriksaanID)
+	if err != nil {
+		return model.OrderLabResponse{}, err
+	}
+	if pemeriksaan.IsVoid() {
+		return model.OrderLabResponse{}, ErrPemeriksaanVoided
+	}
+	if err := validateJenisPemeriksaan(s.jenisRepo, req.JenisPemeriksaanIDs, ErrOrderLabJenisDuplikat); err != nil {
+		return model.OrderLabResponse{}, err
+	}
+	items, err := s.susunItem(req)
+	if err != nil {
+		return model.OrderLabResponse{}, err
+	}
+
+	order := req.ToModel(pemeriksaanID)
+	order.PasienID = pemeriksaan.Antrian.PasienID
+	order.DokterID = dokterID
+	order.Items = items
+
+	created, err := s.repo.Create(order)
+	if err != nil {
+		return model.OrderLabResponse{}, fmt.Errorf("failed to create lab order: %w", err)
+	}
+
+	resp := model.ToOrderLabResponse(created, false)
+	recordAudit(ctx, s.audit, model.AuditActionCreate, model.AuditEntityOrderLab, created.ID, nil, resp)
+	return resp, nil
+}
+
+// item panel lebih dulu sesuai urutan panel, lalu jenis satuan. Jenis yang sudah masuk lewat
+// panel sebelumnya tidak diulang
+func (s *OrderLabService) susunItem(req model.CreateOrderLabRequest) ([]model.OrderLabItem, error) {
+	var panels []model.PanelLab
+	if len(req.PanelIDs) > 0 {
+		var err error
+		if panels, err = s.panelRepo.GetByIDs(req.PanelIDs); err != nil {
+			return nil, err
+		}
+	}
+	panelByID := make(map[int]model.PanelLab, len(panels))
+	for _, panel := range panels {
+		panelByID[panel.ID] = panel
+	}
+
+	var items []model.OrderLabItem
+	added := make(map[int]bool)
+	tambah := func(jenisID int, panelID sql.NullInt64) {
+		if added[jenisID] {
+			return
+		}
+		added[jenisID] = true
+		items = append(items, model.OrderLabItem{JenisPemeriksaanID: jenisID, PanelLabID: panelID, Urutan: len(items) + 1})
+	}
+
+	dipesan := make(map[int]bool, len(req.PanelIDs))
+	for _, panelID := range req.PanelIDs {
+		panel, ok := panelByID[panelID]
+		if !ok {
+			return nil, fmt.Errorf("%w: id %d", ErrPanelLabInvalid, panelID)
+		}
+		if dipesan[panelID] {
+			return nil, ErrOrderLabJenisDuplikat
+		}
+		dipesan[panelID] = true
+		for _, item := range panel.Items {
+			if item.JenisPemeriksaanLab.ID == 0 {
+				return nil, fmt.Errorf("%w: id %d in panel %s", ErrJenisPemeriksaanInvalid, item.JenisPemeriksaanID, panel.NamaPanel)
+			}
+			tambah(item.JenisPemeriksaanID, sql.NullInt64{Int64: int64(panel.ID), Valid: true})
+		}
+	}
+	for _, jenisID := range req.JenisPemeriksaanIDs {
+		tambah(jenisID, sql.NullInt64{})
+	}
+	return items, nil
+}
+
+// detail untuk petugas lab, hasil yang belum divalidasi ikut ditampilkan
+func (s *OrderLabService) GetOrderByID(ctx context.Context, id int) (model.OrderLabResponse, error) {
+	order, err := s.repo.GetByID(id)
+	if err != nil {
+		return model.OrderLabResponse{}, err
+	}
+	return model.ToOrderLabResponse(order, true), nil
+}
+
+// daftar order untuk dokter, hasil baru ditampilkan setelah order divalidasi
+func (s *OrderLabService) GetAllByPemeriksaanID(ctx context.Context, pemeriksaanID int) ([]model.OrderLabResponse, error) {
+	list, err := s.repo.GetAllByPemeriksaanID(pemeriksaanID)
+	if err != nil {
+		return nil, err
+	}
+	return model.ToOrderLabResponseList(list, func(o model.OrderLab) bool {
+		return o.Status == model.StatusOrderLabDivalidasi
+	}), nil
+}
+
+func (s *OrderLabService) GetAllOrder(ctx context.Context, params repository.ParamsGetAllOrderLab) ([]model.OrderLabResponse, pagination.Metadata, error) {
+	list, metadata, err := s.repo.GetAll(params)
+	if err != nil {
+		return nil, metadata, err
+	}
+	return model.ToOrderLabResponseList(list, func(model.OrderLab) bool { return true }), metadata, nil
+}
+
+func (s *OrderLabService) AmbilSpesimen(ctx context.Context, id int, actorID int) (model.OrderLabResponse, error) {
+	return s.ubahStatus(ctx, id, model.StatusOrderLabDipesan, func() (model.OrderLab, error) {
+		return s.repo.AmbilSpesimen(id, actorID, time.Now())
+	})
+}
+
+func (s *OrderLabService) TerimaSpesimen(ctx context.Context, id int, actorID int) (model.OrderLabResponse, error) {
+	return s.ubahStatus(ctx, id, model.StatusOrderLabSpesimenDiambil, func() (model.OrderLab, error) {
+		return s.repo.TerimaSpesimen(id, actorID, time.Now())
+	})
+}
+
+// validasi dilakukan petugas lab yang tidak mengisi satu pun hasil pada order ini
+func (s *OrderLabService) Validasi(ctx context.Context, id int, actorID int) (model.OrderLabResponse, error) {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return model.OrderLabResponse{}, err
+	}
+	if existing.Status != model.StatusOrderLabMenungguValidasi {
+		return model.OrderLabResponse{}, ErrOrderLabStatus
+	}
+	if diisiOleh(existing, actorID) {
+		return model.OrderLabResponse{}, ErrValidatorPengisiHasil
+	}
+
+	return s.ubahStatus(ctx, id, model.StatusOrderLabMenungguValidasi, func() (model.OrderLab, error) {
+		validated, err := s.repo.Validasi(id, actorID, time.Now())
+		if errors.Is(err, repository.ErrNotFound) {
+			// validator bisa saja menyimpan hasil setelah pengecekan di atas
+			if current, getErr := s.repo.GetByID(id); getErr == nil &&
+				current.Status == model.StatusOrderLabMenungguValidasi && diisiOleh(current, actorID) {
+				return model.OrderLab{}, ErrValidatorPengisiHasil
+			}
+		}
+		return validated, err
+	})
+}
+
+func diisiOleh(order model.OrderLab, petugasID int) bool {
+	for _, item := range order.Items {
+		if item.Hasil != nil && item.Hasil.DiisiOleh.Valid && int(item.Hasil.DiisiOleh.Int64) == petugasID {
+			return true
+		}
+	}
+	return false
+}
+
+// order yang sudah divalidasi tidak bisa dibatalkan
+func (s *OrderLabService) Batalkan(ctx context.Context, id int, req model.BatalOrderLabRequest) (model.OrderLabResponse, error) {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return model.OrderLabResponse{}, err
+	}
+	if !existing.IsAktif() {
+		return model.OrderLabResponse{}, ErrOrderLabStatus
+	}
+
+	updated, err := s.repo.Batalkan(id, req.Alasan, time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.OrderLabResponse{}, ErrOrderLabStatus
+		}
+		return model.OrderLabResponse{}, err
+	}
+
+	resp := model.ToOrderLabResponse(updated, true)
+	recordAudit(ctx, s.audit, model.AuditActionUpdate, model.AuditEntityOrderLab, id, model.ToOrderLabResponse(existing, true), resp)
+	return resp, nil
+}
+
+// hasil diisi setelah spesimen diterima dan bisa diperbaiki selama belum divalidasi. Order
+// menunggu validasi setelah semua item punya hasil. Flag hasil dihitung dari data pasien order
+func (s *OrderLabService) SimpanHasil(ctx context.Context, id int, reqs []model.HasilOrderLabRequest, actorID int) (model.OrderLabResponse, error) {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return model.OrderLabResponse{}, err
+	}
+	if existing.Status != model.StatusOrderLabSpesimenDiterima && existing.Status != model.StatusOrderLabMenungguValidasi {
+		return model.OrderLabResponse{}, ErrOrderLabStatus
+	}
+
+	items := make(map[int]model.OrderLabItem, len(existing.Items))
+	terisi := make(map[int]bool, len(existing.Items))
+	for _, item := range existing.Items {
+		items[item.ID] = item
+		terisi[item.ID] = item.Hasil != nil
+	}
+
+	now := time.Now()
+	hasil := make([]model.PemeriksaanLab, 0, len(reqs))
+	diminta := make(map[int]bool, len(reqs))
+	for _, req := range reqs {
+		item, ok := items[req.OrderLabItemID]
+		if !ok {
+			return model.OrderLabResponse{}, fmt.Errorf("%w: item %d", ErrOrderLabItemInvalid, req.OrderLabItemID)
+		}
+		if diminta[item.ID] {
+			return model.OrderLabResponse{}, fmt.Errorf("%w: item %d is sent twice", ErrOrderLabItemInvalid, item.ID)
+		}
+		diminta[item.ID] = true
+		terisi[item.ID] = true
+		hasilLab := model.PemeriksaanLab{
+			PemeriksaanID:      existing.PemeriksaanID,
+			JenisPemeriksaanID: item.JenisPemeriksaanID,
+			Hasil:              req.Hasil,
+			OrderLabItemID:     sql.NullInt64{Int64: int64(item.ID), Valid: true},
+			PanelLabID:         item.PanelLabID,
+			DiisiOleh:          sql.NullInt64{Int64: int64(actorID), Valid: actorID > 0},
+		}
+		if err := interpretasiHasil(&hasilLab, item.JenisPemeriksaanLab, existing.Pasien, now); err != nil {
+			return model.OrderLabResponse{}, err
+		}
+		hasil = append(hasil, hasilLab)
+	}
+
+	status := model.StatusOrderLabMenungguValidasi
+	for _, ok := range terisi {
+		if !ok {
+			status = model.StatusOrderLabSpesimenDiterima
+			break
+		}
+	}
+
+	updated, err := s.repo.SimpanHasil(id, hasil, status)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.OrderLabResponse{}, ErrOrderLabStatus
+		}
+		return model.OrderLabResponse{}, fmt.Errorf("failed to save lab results: %w", err)
+	}
+
+	resp := model.ToOrderLabResponse(updated, true)
+	recordAudit(ctx, s.audit, model.AuditActionUpdate, model.AuditEntityOrderLab, id, model.ToOrderLabResponse(existing, true), resp)
+	return resp, nil
+}
+
+// transisi status sederhana: cek status saat ini lalu ubah secara kondisional di repository.
+// ErrNotFound dari repository berarti status sudah diubah request lain
+func (s *OrderLabService) ubahStatus(ctx context.Context, id int, from string, update func() (model.OrderLab, error)) (model.OrderLabResponse, error) {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return model.OrderLabResponse{}, err
+	}
+	if existing.Status != from {
+		return model.OrderLabResponse{}, ErrOrderLabStatus
+	}
+
+	updated, err := update()
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.OrderLabResponse{}, ErrOrderLabStatus
+		}
+		return model.OrderLabResponse{}, err
+	}
+
+	resp := model.ToOrderLabResponse(updated, true)
+	recordAudit(ctx, s.audit, model.AuditActionUpdate, model.AuditEntityOrderLab, id, model.ToOrderLabResponse(existing, true), resp)
+	return resp, nil
+}
+
+// jenis pemeriksaan harus ada dan tidak boleh disebut dua kali. Dipakai untuk order dan panel
+func validateJenisPemeriksaan(jenisRepo JenisPemeriksaanLabRepository, ids []int, errDuplikat error) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return errDuplikat
+		}
+		seen[id] = true
+	}
+
+	list, err := jenisRepo.GetByIDs(ids)
+	if err != nil {
+		return err
+	}
+	found := make(map[int]bool, len(list))
+	for _, jenis := range list {
+		found[jenis.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return fmt.Errorf("%w: id %d", ErrJenisPemeriksaanInvalid, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func orderLabDiterima() model.OrderLab {
+	return model.OrderLab{
+		ID:            1,
+		PemeriksaanID: 10,
+		Status:        model.StatusOrderLabSpesimenDiterima,
+		Items: []model.OrderLabItem{
+			{ID: 11, OrderLabID: 1, JenisPemeriksaanID: 3, Urutan: 1},
+			{ID: 12, OrderLabID: 1, JenisPemeriksaanID: 4, Urutan: 2},
+		},
+	}
+}
+
+func TestOrderLabService_CreateOrder(t *testing.T) {
+	mockRepo := new(MockOrderLabRepository)
+	mockJenisRepo := new(MockJenisPemeriksaanLabRepository)
+	mockPanelRepo := new(MockPanelLabRepository)
+	mockPemeriksaanRepo := new(MockPemeriksaanRepository)
+	orderLabService := NewOrderLabService(mockRepo, mockJenisRepo, mockPanelRepo, mockPemeriksaanRepo, nil)
+
+	pemeriksaan := model.Pemeriksaan{ID: 10, Antrian: model.Antrian{PasienID: 8}}
+
+	t.Run("Success: Order created with patient from antrian", func(t *testing.T) {
+		req := model.CreateOrderLabRequest{JenisPemeriksaanIDs: []int{3, 4}}
+		mockPemeriksaanRepo.On("GetById", 10).Return(pemeriksaan, nil).Once()
+		mockJenisRepo.On("GetByIDs", []int{3, 4}).Return([]model.JenisPemeriksaanLab{{ID: 3}, {ID: 4}}, nil).Once()
+		mockRepo.On("Create", mock.MatchedBy(func(o model.OrderLab) bool {
+			return o.PasienID == 8 && o.DokterID == 2 && o.Prioritas == model.PrioritasOrderLabRutin &&
+				o.Status == model.StatusOrderLabDipesan && len(o.Items) == 2 && o.Items[1].Urutan == 2
+		})).Return(model.OrderLab{ID: 1, PemeriksaanID: 10, Status: model.StatusOrderLabDipesan}, nil).Once()
+
+		result, err := orderLabService.CreateOrder(context.Background(), 10, req, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.StatusOrderLabDipesan, result.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success: Panel items first and shared jenis not repeated", func(t *testing.T) {
+		req := model.CreateOrderLabRequest{PanelIDs: []int{20}, JenisPemeriksaanIDs: []int{4, 5}}
+		darahLengkap := model.PanelLab{ID: 20, NamaPanel: "Darah Lengkap", Items: []model.PanelLabItem{
+			{JenisPemeriksaanID: 3, Urutan: 1, JenisPemeriksaanLab: model.JenisPemeriksaanLab{ID: 3}},
+			{JenisPemeriksaanID: 4, Urutan: 2, JenisPemeriksaanLab: model.JenisPemeriksaanLab{ID: 4}},
+		}}
+		mockPemeriksaanRepo.On("GetById", 10).Return(pemeriksaan, nil).Once()
+		mockJenisRepo.On("GetByIDs", []int{4, 5}).Return([]model.JenisPemeriksaanLab{{ID: 4}, {ID: 5}}, nil).Once()
+		mockPanelRepo.On("GetByIDs", []int{20}).Return([]model.PanelLab{darahLengkap}, nil).Once()
+		mockRepo.On("Create", mock.MatchedBy(func(o model.OrderLab) bool {
+			return len(o.Items) == 3 &&
+				o.Items[0].JenisPemeriksaanID == 3 && o.Items[0].PanelLabID.Int64 == 20 &&
+				o.Items[1].JenisPemeriksaanID == 4 && o.Items[1].PanelLabID.Int64 == 20 &&
+				o.Items[2].JenisPemeriksaanID == 5 && !o.Items[2].PanelLabID.Valid && o.Items[2].Urutan == 3
+		})).Return(model.OrderLab{ID: 2, PemeriksaanID: 10, Status: model.StatusOrderLabDipesan}, nil).Once()
+
+		_, err := orderLabService.CreateOrder(context.Background(), 10, req, 2)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Unknown panel", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 10).Return(pemeriksaan, nil).Once()
+		mockPanelRepo.On("GetByIDs", []int{99}).Return([]model.PanelLab{}, nil).Once()
+
+		_, err := orderLabService.CreateOrder(context.Background(), 10, model.CreateOrderLabRequest{PanelIDs: []int{99}}, 2)
+
+		assert.ErrorIs(t, err, ErrPanelLabInvalid)
+	})
+
+	t.Run("Fail: Pemeriksaan voided", func(t *testing.T) {
+		voided := pemeriksaan
+		voided.DibatalkanAt = sql.NullTime{Valid: true}
+		mockPemeriksaanRepo.On("GetById", 11).Return(voided, nil).Once()
+
+		_, err := orderLabService.CreateOrder(context.Background(), 11, model.CreateOrderLabRequest{JenisPemeriksaanIDs: []int{3}}, 2)
+
+		assert.ErrorIs(t, err, ErrPemeriksaanVoided)
+	})
+
+	t.Run("Fail: Same jenis ordered twice", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 10).Return(pemeriksaan, nil).Once()
+
+		_, err := orderLabService.CreateOrder(context.Background(), 10, model.CreateOrderLabRequest{JenisPemeriksaanIDs: []int{3, 3}}, 2)
+
+		assert.ErrorIs(t, err, ErrOrderLabJenisDuplikat)
+	})
+
+	t.Run("Fail: Unknown jenis pemeriksaan", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 10).Return(pemeriksaan, nil).Once()
+		mockJenisRepo.On("GetByIDs", []int{3, 99}).Return([]model.JenisPemeriksaanLab{{ID: 3}}, nil).Once()
+
+		_, err := orderLabService.CreateOrder(context.Background(), 10, model.CreateOrderLabRequest{JenisPemeriksaanIDs: []int{3, 99}}, 2)
+
+		assert.ErrorIs(t, err, ErrJenisPemeriksaanInvalid)
+		mockRepo.AssertNumberOfCalls(t, "Create", 2)
+	})
+}
+
+func TestOrderLabService_SimpanHasil(t *testing.T) {
+	mockRepo := new(MockOrderLabRepository)
+	orderLabService := NewOrderLabService(mockRepo, nil, nil, nil, nil)
+
+	t.Run("Success: Partial results keep order in spesimen_diterima", func(t *testing.T) {
+		mockRepo.On("GetByID", 1).Return(orderLabDiterima(), nil).Once()
+		mockRepo.On("SimpanHasil", 1, mock.MatchedBy(func(hasil []model.PemeriksaanLab) bool {
+			return len(hasil) == 1 && hasil[0].JenisPemeriksaanID == 3 && hasil[0].PemeriksaanID == 10 &&
+				hasil[0].OrderLabItemID.Int64 == 11 && hasil[0].DiisiOleh.Int64 == 5
+		}), model.StatusOrderLabSpesimenDiterima).Return(orderLabDiterima(), nil).Once()
+
+		_, err := orderLabService.SimpanHasil(context.Background(), 1, []model.HasilOrderLabRequest{{OrderLabItemID: 11, Hasil: "13.5"}}, 5)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success: Last result moves order to menunggu_validasi", func(t *testing.T) {
+		order := orderLabDiterima()
+		order.Items[0].Hasil = &model.PemeriksaanLab{ID: 20, OrderLabItemID: sql.NullInt64{Int64: 11, Valid: true}}
+		mockRepo.On("GetByID", 1).Return(order, nil).Once()
+		mockRepo.On("SimpanHasil", 1, mock.Anything, model.StatusOrderLabMenungguValidasi).Return(order, nil).Once()
+
+		_, err := orderLabService.SimpanHasil(context.Background(), 1, []model.HasilOrderLabRequest{{OrderLabItemID: 12, Hasil: "Negatif"}}, 5)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Item from another order", func(t *testing.T) {
+		mockRepo.On("GetByID", 1).Return(orderLabDiterima(), nil).Once()
+
+		_, err := orderLabService.SimpanHasil(context.Background(), 1, []model.HasilOrderLabRequest{{OrderLabItemID: 99, Hasil: "1"}}, 5)
+
+		assert.ErrorIs(t, err, ErrOrderLabItemInvalid)
+		mockRepo.AssertNumberOfCalls(t, "SimpanHasil", 2)
+	})
+
+	t.Run("Fail: Specimen not yet received", func(t *testing.T) {
+		order := orderLabDiterima()
+		order.Status = model.StatusOrderLabSpesimenDiambil
+		mockRepo.On("GetByID", 1).Return(order, nil).Once()
+
+		_, err := orderLabService.SimpanHasil(context.Background(), 1, []model.HasilOrderLabRequest{{OrderLabItemID: 11, Hasil: "1"}}, 5)
+
+		assert.ErrorIs(t, err, ErrOrderLabStatus)
+	})
+}
+
+func TestOrderLabService_Validasi(t *testing.T) {
+	mockRepo := new(MockOrderLabRepository)
+	orderLabService := NewOrderLabService(mockRepo, nil, nil, nil, nil)
+
+	order := orderLabDiterima()
+	order.Status = model.StatusOrderLabMenungguValidasi
+	order.Items[0].Hasil = &model.PemeriksaanLab{ID: 20, DiisiOleh: sql.NullInt64{Int64: 5, Valid: true}}
+	order.Items[1].Hasil = &model.PemeriksaanLab{ID: 21, DiisiOleh: sql.NullInt64{Int64: 6, Valid: true}}
+
+	t.Run("Success: Validated by a second lab user", func(t *testing.T) {
+		validated := order
+		validated.Status = model.StatusOrderLabDivalidasi
+		mockRepo.On("GetByID", 1).Return(order, nil).Twice()
+		mockRepo.On("Validasi", 1, 7, mock.AnythingOfType("time.Time")).Return(validated, nil).Once()
+
+		result, err := orderLabService.Validasi(context.Background(), 1, 7)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.StatusOrderLabDivalidasi, result.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Validator entered one of the results", func(t *testing.T) {
+		mockRepo.On("GetByID", 1).Return(order, nil).Once()
+
+		_, err := orderLabService.Validasi(context.Background(), 1, 6)
+
+		assert.ErrorIs(t, err, ErrValidatorPengisiHasil)
+		mockRepo.AssertNumberOfCalls(t, "Validasi", 1)
+	})
+
+	t.Run("Fail: Order changed by a concurrent request", func(t *testing.T) {
+		validated := order
+		validated.Status = model.StatusOrderLabDivalidasi
+		mockRepo.On("GetByID", 1).Return(order, nil).Twice()
+		mockRepo.On("Validasi", 1, 8, mock.AnythingOfType("time.Time")).Return(model.OrderLab{}, repository.ErrNotFound).Once()
+		mockRepo.On("GetByID", 1).Return(validated, nil).Once()
+
+		_, err := orderLabService.Validasi(context.Background(), 1, 8)
+
+		assert.ErrorIs(t, err, ErrOrderLabStatus)
+	})
+
+	t.Run("Fail: Validator entered a result after the check", func(t *testing.T) {
+		diisiValidator := order
+		diisiValidator.Items = append([]model.OrderLabItem(nil), order.Items...)
+		diisiValidator.Items[1].Hasil = &model.PemeriksaanLab{ID: 21, DiisiOleh: sql.NullInt64{Int64: 9, Valid: true}}
+		mockRepo.On("GetByID", 1).Return(order, nil).Twice()
+		mockRepo.On("Validasi", 1, 9, mock.AnythingOfType("time.Time")).Return(model.OrderLab{}, repository.ErrNotFound).Once()
+		mockRepo.On("GetByID", 1).Return(diisiValidator, nil).Once()
+
+		_, err := orderLabService.Validasi(context.Background(), 1, 9)
+
+		assert.ErrorIs(t, err, ErrValidatorPengisiHasil)
+	})
+}
+
+func TestOrderLabService_Batalkan(t *testing.T) {
+	mockRepo := new(MockOrderLabRepository)
+	orderLabService := NewOrderLabService(mockRepo, nil, nil, nil, nil)
+
+	t.Run("Fail: Validated order cannot be cancelled", func(t *testing.T) {
+		mockRepo.On("GetByID", 1).Return(model.OrderLab{ID: 1, Status: model.StatusOrderLabDivalidasi}, nil).Once()
+
+		_, err := orderLabService.Batalkan(context.Background(), 1, model.BatalOrderLabRequest{Alasan: "salah pasien"})
+
+		assert.ErrorIs(t, err, ErrOrderLabStatus)
+		mockRepo.AssertNotCalled(t, "Batalkan", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrPanelLabConflict      = errors.New("panel lab with that name already exists")
+	ErrPanelLabInvalid       = errors.New("panel lab not found")
+	ErrPanelLabJenisDuplikat = errors.New("the same jenis pemeriksaan cannot be listed twice in one panel")
+	ErrPanelLabItemInvalid   = errors.New("jenis pemeriksaan is not part of the panel")
+)
+
+type PanelLabService struct {
+	repo      PanelLabRepository
+	jenisRepo JenisPemeriksaanLabRepository
+}
+
+func NewPanelLabService(repo PanelLabRepository, jenisRepo JenisPemeriksaanLabRepository) *PanelLabService {
+	return &PanelLabService{repo: repo, jenisRepo: jenisRepo}
+}
+
+func (s *PanelLabService) Create(ctx context.Context, req model.PanelLabRequest) (model.PanelLabResponse, error) {
+	if err := validateJenisPemeriksaan(s.jenisRepo, req.JenisPemeriksaanIDs, ErrPanelLabJenisDuplikat); err != nil {
+		return model.PanelLabResponse{}, err
+	}
+
+	created, err := s.repo.Create(req.ToModel())
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return model.PanelLabResponse{}, ErrPanelLabConflict
+		}
+		return model.PanelLabResponse{}, fmt.Errorf("failed to create panel lab: %w", err)
+	}
+	return model.ToPanelLabResponse(created), nil
+}
+
+func (s *PanelLabService) GetAll(ctx context.Context) ([]model.PanelLabResponse, error) {
+	list, err := s.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return model.ToPanelLabResponseList(list), nil
+}
+
+func (s *PanelLabService) GetByID(ctx context.Context, id int) (model.PanelLabResponse, error) {
+	panel, err := s.repo.GetByID(id)
+	if err != nil {
+		return model.PanelLabResponse{}, err
+	}
+	return model.ToPanelLabResponse(panel), nil
+}
+
+func (s *PanelLabService) Update(ctx context.Context, id int, req model.PanelLabRequest) (model.PanelLabResponse, error) {
+	existing, err := s.repo.FindByName(req.NamaPanel)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return model.PanelLabResponse{}, fmt.Errorf("database error on check: %w", err)
+	}
+	if err == nil && existing.ID != id {
+		return model.PanelLabResponse{}, ErrPanelLabConflict
+	}
+	if err := validateJenisPemeriksaan(s.jenisRepo, req.JenisPemeriksaanIDs, ErrPanelLabJenisDuplikat); err != nil {
+		return model.PanelLabResponse{}, err
+	}
+
+	updated, err := s.repo.Update(id, req.ToModel())
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return model.PanelLabResponse{}, ErrPanelLabConflict
+		}
+		return model.PanelLabResponse{}, err
+	}
+	return model.ToPanelLabResponse(updated), nil
+}
+
+func (s *PanelLabService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(id)
+}
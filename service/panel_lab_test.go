@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPanelLabService_Create(t *testing.T) {
+	mockRepo := new(MockPanelLabRepository)
+	mockJenisRepo := new(MockJenisPemeriksaanLabRepository)
+	panelLabService := NewPanelLabService(mockRepo, mockJenisRepo)
+
+	req := model.PanelLabRequest{NamaPanel: "Darah Lengkap", JenisPemeriksaanIDs: []int{3, 1, 2}}
+
+	t.Run("Success: Items keep request order", func(t *testing.T) {
+		mockJenisRepo.On("GetByIDs", []int{3, 1, 2}).Return([]model.JenisPemeriksaanLab{{ID: 1}, {ID: 2}, {ID: 3}}, nil).Once()
+		mockRepo.On("Create", mock.MatchedBy(func(p model.PanelLab) bool {
+			return len(p.Items) == 3 && p.Items[0].JenisPemeriksaanID == 3 && p.Items[0].Urutan == 1 && p.Items[2].Urutan == 3
+		})).Return(model.PanelLab{ID: 1, NamaPanel: "Darah Lengkap", Items: []model.PanelLabItem{{JenisPemeriksaanID: 3, Urutan: 1}}}, nil).Once()
+
+		result, err := panelLabService.Create(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.ID)
+		assert.Len(t, result.Items, 1)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Jenis listed twice", func(t *testing.T) {
+		_, err := panelLabService.Create(context.Background(), model.PanelLabRequest{NamaPanel: "Lipid", JenisPemeriksaanIDs: []int{4, 4}})
+
+		assert.ErrorIs(t, err, ErrPanelLabJenisDuplikat)
+	})
+
+	t.Run("Fail: Name conflict", func(t *testing.T) {
+		mockJenisRepo.On("GetByIDs", []int{3, 1, 2}).Return([]model.JenisPemeriksaanLab{{ID: 1}, {ID: 2}, {ID: 3}}, nil).Once()
+		mockRepo.On("Create", mock.AnythingOfType("model.PanelLab")).Return(model.PanelLab{}, &pgconn.PgError{Code: "23505"}).Once()
+
+		_, err := panelLabService.Create(context.Background(), req)
+
+		assert.ErrorIs(t, err, ErrPanelLabConflict)
+	})
+}
+
+func TestPanelLabService_Update(t *testing.T) {
+	mockRepo := new(MockPanelLabRepository)
+	mockJenisRepo := new(MockJenisPemeriksaanLabRepository)
+	panelLabService := NewPanelLabService(mockRepo, mockJenisRepo)
+
+	req := model.PanelLabRequest{NamaPanel: "Darah Lengkap", JenisPemeriksaanIDs: []int{1}}
+
+	t.Run("Fail: Name used by another panel", func(t *testing.T) {
+		mockRepo.On("FindByName", "Darah Lengkap").Return(model.PanelLab{ID: 2}, nil).Once()
+
+		_, err := panelLabService.Update(context.Background(), 1, req)
+
+		assert.ErrorIs(t, err, ErrPanelLabConflict)
+	})
+
+	t.Run("Fail: Unknown jenis pemeriksaan", func(t *testing.T) {
+		mockRepo.On("FindByName", "Darah Lengkap").Return(model.PanelLab{}, repository.ErrNotFound).Once()
+		mockJenisRepo.On("GetByIDs", []int{1}).Return([]model.JenisPemeriksaanLab{}, nil).Once()
+
+		_, err := panelLabService.Update(context.Background(), 1, req)
+
+		assert.ErrorIs(t, err, ErrJenisPemeriksaanInvalid)
+		mockRepo.AssertNumberOfCalls(t, "Update", 0)
+	})
+}
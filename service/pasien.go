@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/franklindh/simedis-api/internal/config"
 	"github.com/franklindh/simedis-api/internal/model"
 	"github.com/franklindh/simedis-api/internal/repository"
 	"github.com/franklindh/simedis-api/pkg/utils"
@@ -14,15 +16,83 @@ import (
 )
 
 var (
-	ErrPasienConflict = errors.New("data with the same NIK, username, or nomor kartu jaminan already exists")
+	ErrPasienConflict        = errors.New("data with the same NIK, username, or nomor kartu jaminan already exists")
+	ErrPasswordSameAsDefault = errors.New("new password must be different from the default password")
 )
 
 type PasienService struct {
-	repo PasienRepository
+	repo      PasienRepository
+	tokenRepo TokenRepository
+	audit     AuditRecorder
+	config    *config.Config
 }
 
-func NewPasienService(repo PasienRepository) *PasienService {
-	return &PasienService{repo: repo}
+func NewPasienService(repo PasienRepository, tokenRepo TokenRepository, audit AuditRecorder, cfg *config.Config) *PasienService {
+	return &PasienService{repo: repo, tokenRepo: tokenRepo, audit: audit, config: cfg}
+}
+
+func (s *PasienService) Login(ctx context.Context, req model.LoginPasienRequest) (model.LoginPasienResponse, error) {
+	pasien, err := s.repo.GetByUsername(req.Username)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.LoginPasienResponse{}, ErrInvalidCredentials
+		}
+		return model.LoginPasienResponse{}, fmt.Errorf("database error: %w", err)
+	}
+
+	err = utils.VerifyPassword(req.Password, pasien.Password)
+	if err != nil {
+		return model.LoginPasienResponse{}, ErrInvalidCredentials
+	}
+
+	jwtSecret := []byte(s.config.JWTSecret)
+	token, err := utils.SignPasienToken(strconv.Itoa(pasien.ID), pasien.UsernamePasien, pasien.PasswordChanged, pasien.TokenVersion, jwtSecret)
+	if err != nil {
+		return model.LoginPasienResponse{}, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return model.LoginPasienResponse{Token: token, PasswordChanged: pasien.PasswordChanged}, nil
+}
+
+// password baru dikembalikan bersama token baru karena token lama masih membawa password_changed=false.
+// token_version ikut naik sehingga semua token lama, termasuk yang bocor, tidak berlaku lagi
+func (s *PasienService) ChangePassword(ctx context.Context, id int, req model.ChangePasswordRequest) (model.LoginPasienResponse, error) {
+	pasien, err := s.repo.GetById(id)
+	if err != nil {
+		return model.LoginPasienResponse{}, err
+	}
+
+	err = utils.VerifyPassword(req.OldPassword, pasien.Password)
+	if err != nil {
+		return model.LoginPasienResponse{}, ErrOldPasswordMismatch
+	}
+
+	if req.NewPassword == pasien.NIK {
+		return model.LoginPasienResponse{}, ErrPasswordSameAsDefault
+	}
+
+	newHashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return model.LoginPasienResponse{}, fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.repo.UpdatePassword(id, newHashedPassword); err != nil {
+		return model.LoginPasienResponse{}, err
+	}
+
+	jwtSecret := []byte(s.config.JWTSecret)
+	token, err := utils.SignPasienToken(strconv.Itoa(pasien.ID), pasien.UsernamePasien, true, pasien.TokenVersion+1, jwtSecret)
+	if err != nil {
+		return model.LoginPasienResponse{}, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return model.LoginPasienResponse{Token: token, PasswordChanged: true}, nil
+}
+
+func (s *PasienService) Logout(ctx context.Context, jti string, expiresAt time.Time) error {
+	if err := s.tokenRepo.RevokeJTI(jti, expiresAt); err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+	return nil
 }
 
 func (s *PasienService) CreatePasien(ctx context.Context, req model.CreatePasienRequest) (model.PasienResponse, error) {
@@ -48,6 +118,7 @@ func (s *PasienService) CreatePasien(ctx context.Context, req model.CreatePasien
 	noRekamMedis := fmt.Sprintf("RM-%s-%04d", time.Now().Format("20060102"), lastID+1)
 
 	pasien := req.ToModel(username, hashedPassword, noRekamMedis)
+	pasien.PasswordChanged = req.Password != ""
 
 	createdPasien, err := s.repo.Create(pasien)
 	if err != nil {
@@ -57,7 +128,9 @@ func (s *PasienService) CreatePasien(ctx context.Context, req model.CreatePasien
 		return model.PasienResponse{}, fmt.Errorf("failed to create patient: %w", err)
 	}
 
-	return model.ToPasienResponse(createdPasien), nil
+	response := model.ToPasienResponse(createdPasien)
+	recordAudit(ctx, s.audit, model.AuditActionCreate, model.AuditEntityPasien, createdPasien.ID, nil, response)
+	return response, nil
 }
 
 func (s *PasienService) GetAllPasien(ctx context.Context, params repository.ParamsGetAllPasien) ([]model.PasienResponse, pagination.Metadata, error) {
@@ -78,6 +151,11 @@ func (s *PasienService) GetPasienByID(ctx context.Context, id int) (model.Pasien
 }
 
 func (s *PasienService) UpdatePasien(ctx context.Context, id int, req model.UpdatePasienRequest) (model.PasienResponse, error) {
+	existing, err := s.repo.GetById(id)
+	if err != nil {
+		return model.PasienResponse{}, err
+	}
+
 	pasienUpdate := req.ToModel()
 
 	updatedPasien, err := s.repo.Update(id, pasienUpdate)
@@ -88,9 +166,21 @@ func (s *PasienService) UpdatePasien(ctx context.Context, id int, req model.Upda
 		return model.PasienResponse{}, err
 	}
 
-	return model.ToPasienResponse(updatedPasien), nil
+	response := model.ToPasienResponse(updatedPasien)
+	recordAudit(ctx, s.audit, model.AuditActionUpdate, model.AuditEntityPasien, id, model.ToPasienResponse(existing), response)
+	return response, nil
 }
 
 func (s *PasienService) DeletePasien(ctx context.Context, id int) error {
-	return s.repo.Delete(id)
+	existing, err := s.repo.GetById(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.audit, model.AuditActionDelete, model.AuditEntityPasien, id, model.ToPasienResponse(existing), nil)
+	return nil
 }
@@ -6,9 +6,12 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/franklindh/simedis-api/internal/config"
 	"github.com/franklindh/simedis-api/internal/model"
 	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/pkg/utils"
 	"github.com/franklindh/simedis-api/pkg/utils/pagination"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -16,7 +19,7 @@ import (
 
 func TestPasienService_CreatePasien(t *testing.T) {
 	mockRepo := new(MockPasienRepository)
-	service := NewPasienService(mockRepo)
+	service := NewPasienService(mockRepo, new(MockTokenRepository), nil, &config.Config{})
 
 	req := model.CreatePasienRequest{
 		NIK:                "1234567890123456",
@@ -65,7 +68,7 @@ func TestPasienService_CreatePasien(t *testing.T) {
 
 func TestPasienService_GetAllPasien(t *testing.T) {
 	mockRepo := new(MockPasienRepository)
-	service := NewPasienService(mockRepo)
+	service := NewPasienService(mockRepo, new(MockTokenRepository), nil, &config.Config{})
 	params := repository.ParamsGetAllPasien{Page: 1, PageSize: 5}
 
 	t.Run("Success: Get all pasien", func(t *testing.T) {
@@ -88,7 +91,7 @@ func TestPasienService_GetAllPasien(t *testing.T) {
 
 func TestPasienService_GetPasienByID(t *testing.T) {
 	mockRepo := new(MockPasienRepository)
-	service := NewPasienService(mockRepo)
+	service := NewPasienService(mockRepo, new(MockTokenRepository), nil, &config.Config{})
 
 	t.Run("Success: Pasien found", func(t *testing.T) {
 		mockPasien := model.Pasien{ID: 1, NamaPasien: "Cici"}
@@ -115,7 +118,7 @@ func TestPasienService_GetPasienByID(t *testing.T) {
 
 func TestPasienService_UpdatePasien(t *testing.T) {
 	mockRepo := new(MockPasienRepository)
-	service := NewPasienService(mockRepo)
+	service := NewPasienService(mockRepo, new(MockTokenRepository), nil, &config.Config{})
 
 	req := model.UpdatePasienRequest{
 		NIK:        "1234567890123456",
@@ -125,6 +128,7 @@ func TestPasienService_UpdatePasien(t *testing.T) {
 	t.Run("Success: Update pasien", func(t *testing.T) {
 		updatedModel := req.ToModel()
 		updatedModel.ID = 1
+		mockRepo.On("GetById", 1).Return(model.Pasien{ID: 1, NamaPasien: "Budi"}, nil).Once()
 		mockRepo.On("Update", 1, mock.AnythingOfType("model.Pasien")).Return(updatedModel, nil).Once()
 
 		result, err := service.UpdatePasien(context.Background(), 1, req)
@@ -134,13 +138,23 @@ func TestPasienService_UpdatePasien(t *testing.T) {
 		assert.Equal(t, "Budi Updated", result.NamaPasien)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Fail: Pasien to update not found", func(t *testing.T) {
+		mockRepo.On("GetById", 99).Return(model.Pasien{}, repository.ErrNotFound).Once()
+
+		_, err := service.UpdatePasien(context.Background(), 99, req)
+
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		mockRepo.AssertNotCalled(t, "Update", 99, mock.Anything)
+	})
 }
 
 func TestPasienService_DeletePasien(t *testing.T) {
 	mockRepo := new(MockPasienRepository)
-	service := NewPasienService(mockRepo)
+	service := NewPasienService(mockRepo, new(MockTokenRepository), nil, &config.Config{})
 
 	t.Run("Success: Delete pasien", func(t *testing.T) {
+		mockRepo.On("GetById", 1).Return(model.Pasien{ID: 1}, nil).Once()
 		mockRepo.On("Delete", 1).Return(nil).Once()
 		err := service.DeletePasien(context.Background(), 1)
 		assert.NoError(t, err)
@@ -148,10 +162,112 @@ func TestPasienService_DeletePasien(t *testing.T) {
 	})
 
 	t.Run("Fail: Pasien to delete not found", func(t *testing.T) {
-		mockRepo.On("Delete", 99).Return(repository.ErrNotFound).Once()
+		mockRepo.On("GetById", 99).Return(model.Pasien{}, repository.ErrNotFound).Once()
 		err := service.DeletePasien(context.Background(), 99)
 		assert.Error(t, err)
 		assert.True(t, errors.Is(err, repository.ErrNotFound))
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestPasienService_Login(t *testing.T) {
+	hashedNIK, _ := utils.HashPassword("1234567890123456")
+	cfg := &config.Config{JWTSecret: "secretkeyrahasia"}
+
+	t.Run("Success: Login with default password", func(t *testing.T) {
+		mockRepo := new(MockPasienRepository)
+		service := NewPasienService(mockRepo, new(MockTokenRepository), nil, cfg)
+
+		mockPasien := model.Pasien{ID: 1, UsernamePasien: "1234567890123456", NIK: "1234567890123456", Password: hashedNIK}
+		mockRepo.On("GetByUsername", "1234567890123456").Return(mockPasien, nil).Once()
+
+		result, err := service.Login(context.Background(), model.LoginPasienRequest{Username: "1234567890123456", Password: "1234567890123456"})
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, result.Token)
+		assert.False(t, result.PasswordChanged)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Wrong password", func(t *testing.T) {
+		mockRepo := new(MockPasienRepository)
+		service := NewPasienService(mockRepo, new(MockTokenRepository), nil, cfg)
+
+		mockPasien := model.Pasien{ID: 1, UsernamePasien: "budi", Password: hashedNIK}
+		mockRepo.On("GetByUsername", "budi").Return(mockPasien, nil).Once()
+
+		_, err := service.Login(context.Background(), model.LoginPasienRequest{Username: "budi", Password: "salah"})
+
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, ErrInvalidCredentials))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Pasien not found", func(t *testing.T) {
+		mockRepo := new(MockPasienRepository)
+		service := NewPasienService(mockRepo, new(MockTokenRepository), nil, cfg)
+
+		mockRepo.On("GetByUsername", "notfound").Return(model.Pasien{}, repository.ErrNotFound).Once()
+
+		_, err := service.Login(context.Background(), model.LoginPasienRequest{Username: "notfound", Password: "x"})
+
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, ErrInvalidCredentials))
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestPasienService_ChangePassword(t *testing.T) {
+	nik := "1234567890123456"
+	hashedNIK, _ := utils.HashPassword(nik)
+	cfg := &config.Config{JWTSecret: "secretkeyrahasia"}
+	mockPasien := model.Pasien{ID: 1, NIK: nik, UsernamePasien: nik, Password: hashedNIK}
+
+	t.Run("Success: Change default password", func(t *testing.T) {
+		mockRepo := new(MockPasienRepository)
+		service := NewPasienService(mockRepo, new(MockTokenRepository), nil, cfg)
+
+		mockRepo.On("GetById", 1).Return(mockPasien, nil).Once()
+		mockRepo.On("UpdatePassword", 1, mock.AnythingOfType("string")).Return(nil).Once()
+
+		req := model.ChangePasswordRequest{OldPassword: nik, NewPassword: "passwordBaru456", ConfirmPassword: "passwordBaru456"}
+		result, err := service.ChangePassword(context.Background(), 1, req)
+
+		assert.NoError(t, err)
+		assert.True(t, result.PasswordChanged)
+		// token baru membawa versi setelah password diganti, token lama dengan versi 0 ditolak
+		claims := jwt.MapClaims{}
+		_, err = jwt.ParseWithClaims(result.Token, claims, func(*jwt.Token) (any, error) { return []byte(cfg.JWTSecret), nil })
+		assert.NoError(t, err)
+		assert.Equal(t, float64(1), claims["ver"])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: New password equals NIK", func(t *testing.T) {
+		mockRepo := new(MockPasienRepository)
+		service := NewPasienService(mockRepo, new(MockTokenRepository), nil, cfg)
+
+		mockRepo.On("GetById", 1).Return(mockPasien, nil).Once()
+
+		req := model.ChangePasswordRequest{OldPassword: nik, NewPassword: nik, ConfirmPassword: nik}
+		_, err := service.ChangePassword(context.Background(), 1, req)
+
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, ErrPasswordSameAsDefault))
+		mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Old password does not match", func(t *testing.T) {
+		mockRepo := new(MockPasienRepository)
+		service := NewPasienService(mockRepo, new(MockTokenRepository), nil, cfg)
+
+		mockRepo.On("GetById", 1).Return(mockPasien, nil).Once()
+
+		req := model.ChangePasswordRequest{OldPassword: "salah", NewPassword: "passwordBaru456", ConfirmPassword: "passwordBaru456"}
+		_, err := service.ChangePassword(context.Background(), 1, req)
+
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, ErrOldPasswordMismatch))
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -2,27 +2,51 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/franklindh/simedis-api/internal/audit"
+	"github.com/franklindh/simedis-api/internal/config"
 	"github.com/franklindh/simedis-api/internal/model"
 	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 var (
 	ErrPemeriksaanExists = errors.New("pemeriksaan for this antrian already exists")
+	ErrDokterInvalid     = errors.New("dokter must be an active petugas with role Dokter")
+	ErrPemeriksaanVoided = errors.New("pemeriksaan has been voided")
+	ErrRevisiNotFound    = errors.New("revisi not found")
+	ErrRevisiRange       = errors.New("from must be lower than to")
+	ErrPemeriksaanLocked = errors.New("pemeriksaan has been signed and can only be amended with an addendum")
+	ErrPemeriksaanDraft  = errors.New("pemeriksaan has not been signed yet")
+	ErrSignerNotDokter   = errors.New("only the responsible dokter can sign this pemeriksaan")
+	ErrInvalidDateRange  = errors.New("to cannot be before from")
+	ErrDiagnosisPrimer   = errors.New("diagnosis must contain exactly one primer")
+	ErrDiagnosisDuplikat = errors.New("diagnosis contains duplicate icd")
+	ErrIcdNotFound       = errors.New("icd not found")
+	ErrProsedurNotFound  = errors.New("procedure not found")
+	ErrPelaksanaInvalid  = errors.New("pelaksana must be an active petugas")
 )
 
 type PemeriksaanService struct {
-	repo        PemeriksaanRepository
-	antrianRepo AntrianRepository
+	repo          PemeriksaanRepository
+	antrianRepo   AntrianRepository
+	petugasRepo   PetugasRepository
+	antrianBroker AntrianBroker
+	audit         AuditRecorder
+	config        *config.Config
 }
 
-func NewPemeriksaanService(repo PemeriksaanRepository, antrianRepo AntrianRepository) *PemeriksaanService {
-	return &PemeriksaanService{repo: repo, antrianRepo: antrianRepo}
+func NewPemeriksaanService(repo PemeriksaanRepository, antrianRepo AntrianRepository, petugasRepo PetugasRepository, antrianBroker AntrianBroker, audit AuditRecorder, cfg *config.Config) *PemeriksaanService {
+	return &PemeriksaanService{repo: repo, antrianRepo: antrianRepo, petugasRepo: petugasRepo, antrianBroker: antrianBroker, audit: audit, config: cfg}
 }
 
-func (s *PemeriksaanService) CreatePemeriksaan(ctx context.Context, req model.CreatePemeriksaanRequest) (model.PemeriksaanResponse, error) {
+// authorID adalah petugas yang sedang login, dicatat sebagai pembuat rekam medis
+func (s *PemeriksaanService) CreatePemeriksaan(ctx context.Context, req model.CreatePemeriksaanRequest, authorID int) (model.PemeriksaanResponse, error) {
 
 	err := s.repo.CheckExistingPemeriksaan(req.AntrianID)
 	if err == nil {
@@ -32,20 +56,44 @@ func (s *PemeriksaanService) CreatePemeriksaan(ctx context.Context, req model.Cr
 		return model.PemeriksaanResponse{}, fmt.Errorf("error checking existing pemeriksaan: %w", err)
 	}
 
+	antrian, err := s.antrianRepo.GetByID(req.AntrianID)
+	if err != nil {
+		return model.PemeriksaanResponse{}, err
+	}
+	// rekam medis hanya dibuat untuk pasien yang sudah dipanggil atau sedang diperiksa
+	if antrian.Status != model.StatusAntrianDiperiksa && !model.CanTransitionAntrian(antrian.Status, model.StatusAntrianDiperiksa) {
+		return model.PemeriksaanResponse{}, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, antrian.Status, model.StatusAntrianDiperiksa)
+	}
+
+	dokterID, err := s.resolveDokter(req.DokterID, authorID, antrian)
+	if err != nil {
+		return model.PemeriksaanResponse{}, err
+	}
+
 	pemeriksaan := req.ToModel()
+	if err := validateDiagnosis(pemeriksaan.Diagnosis); err != nil {
+		return model.PemeriksaanResponse{}, err
+	}
+	pemeriksaan.DokterID = sql.NullInt64{Int64: int64(dokterID), Valid: dokterID > 0}
+	pemeriksaan.DibuatOleh = sql.NullInt64{Int64: int64(authorID), Valid: authorID > 0}
+	if err := s.resolvePelaksana(pemeriksaan.DaftarTindakan, pemeriksaan.DokterID); err != nil {
+		return model.PemeriksaanResponse{}, err
+	}
 
 	createdPemeriksaan, err := s.repo.Create(pemeriksaan)
 	if err != nil {
-		return model.PemeriksaanResponse{}, err
+		return model.PemeriksaanResponse{}, mapReferensiError(err)
 	}
+	recordAudit(ctx, s.audit, model.AuditActionCreate, model.AuditEntityPemeriksaan, createdPemeriksaan.ID, nil, model.ToPemeriksaanResponse(createdPemeriksaan))
 
-	antrian, err := s.antrianRepo.GetByID(req.AntrianID)
-	if err == nil {
-		antrian.Status = "Selesai"
-		s.antrianRepo.Update(antrian.ID, antrian)
+	if model.CanTransitionAntrian(antrian.Status, model.StatusAntrianDiperiksa) {
+		updatedAntrian, err := s.antrianRepo.UpdateStatus(antrian.ID, antrian.Status, model.StatusAntrianDiperiksa, authorID, time.Now())
+		if err == nil {
+			publishAntrianEvent(s.antrianBroker, model.AntrianEventStatusChanged, updatedAntrian)
+		}
 	}
 
-	return model.ToPemeriksaanResponse(createdPemeriksaan), nil
+	return s.toResponse(createdPemeriksaan), nil
 }
 
 func (s *PemeriksaanService) GetPemeriksaanByID(ctx context.Context, id int) (model.PemeriksaanResponse, error) {
@@ -53,7 +101,7 @@ func (s *PemeriksaanService) GetPemeriksaanByID(ctx context.Context, id int) (mo
 	if err != nil {
 		return model.PemeriksaanResponse{}, err
 	}
-	return model.ToPemeriksaanResponse(pemeriksaan), nil
+	return s.toResponse(pemeriksaan), nil
 }
 
 func (s *PemeriksaanService) GetRiwayatPemeriksaanPasien(ctx context.Context, pasienID int) ([]model.PemeriksaanResponse, error) {
@@ -62,20 +110,344 @@ func (s *PemeriksaanService) GetRiwayatPemeriksaanPasien(ctx context.Context, pa
 		return nil, err
 	}
 
-	return model.ToPemeriksaanResponseList(allPemeriksaan), nil
+	responses := make([]model.PemeriksaanResponse, 0, len(allPemeriksaan))
+	for _, p := range allPemeriksaan {
+		responses = append(responses, s.toResponse(p))
+	}
+	return responses, nil
+}
+
+func (s *PemeriksaanService) GetVitalTrend(ctx context.Context, pasienID int, params model.ParamsVitalTrend) (model.VitalTrendResponse, error) {
+	if params.From != "" && params.To != "" && params.From > params.To {
+		return model.VitalTrendResponse{}, ErrInvalidDateRange
+	}
+
+	pemeriksaanList, err := s.repo.GetVitalsByPasienID(pasienID, params.From, params.To)
+	if err != nil {
+		return model.VitalTrendResponse{}, err
+	}
+	return model.ToVitalTrendResponse(pasienID, pemeriksaanList, params), nil
 }
 
-func (s *PemeriksaanService) UpdatePemeriksaan(ctx context.Context, id int, req model.UpdatePemeriksaanRequest) (model.PemeriksaanResponse, error) {
+func (s *PemeriksaanService) UpdatePemeriksaan(ctx context.Context, id int, req model.UpdatePemeriksaanRequest, authorID int) (model.PemeriksaanResponse, error) {
+	existing, err := s.repo.GetById(id)
+	if err != nil {
+		return model.PemeriksaanResponse{}, err
+	}
+	if existing.IsVoid() {
+		return model.PemeriksaanResponse{}, ErrPemeriksaanVoided
+	}
+	if existing.IsSigned() {
+		return model.PemeriksaanResponse{}, ErrPemeriksaanLocked
+	}
+
+	if req.DokterID != nil {
+		if _, err := s.resolveDokter(req.DokterID, authorID, existing.Antrian); err != nil {
+			return model.PemeriksaanResponse{}, err
+		}
+	}
+
 	pemeriksaanUpdate := req.ToModel()
+	if err := validateDiagnosis(pemeriksaanUpdate.Diagnosis); err != nil {
+		return model.PemeriksaanResponse{}, err
+	}
+	pemeriksaanUpdate.DiubahOleh = sql.NullInt64{Int64: int64(authorID), Valid: authorID > 0}
+	dokterID := existing.DokterID
+	if pemeriksaanUpdate.DokterID.Valid {
+		dokterID = pemeriksaanUpdate.DokterID
+	}
+	if err := s.resolvePelaksana(pemeriksaanUpdate.DaftarTindakan, dokterID); err != nil {
+		return model.PemeriksaanResponse{}, err
+	}
+
+	updatedPemeriksaan, err := s.repo.Update(id, pemeriksaanUpdate, req.Alasan)
+	if err != nil {
+		return model.PemeriksaanResponse{}, mapReferensiError(err)
+	}
+
+	response := s.toResponse(updatedPemeriksaan)
+	recordAudit(ctx, s.audit, model.AuditActionUpdate, model.AuditEntityPemeriksaan, id, model.ToPemeriksaanResponse(existing), response)
+	return response, nil
+}
+
+// rekam medis tidak boleh dihapus, pembatalan tetap menyimpan seluruh isi dan revisinya.
+// Record yang sudah ditandatangani tidak bisa dibatalkan, koreksinya lewat addendum
+func (s *PemeriksaanService) VoidPemeriksaan(ctx context.Context, id int, req model.VoidPemeriksaanRequest, actorID int) (model.PemeriksaanResponse, error) {
+	existing, err := s.repo.GetById(id)
+	if err != nil {
+		return model.PemeriksaanResponse{}, err
+	}
+	if existing.IsVoid() {
+		return model.PemeriksaanResponse{}, ErrPemeriksaanVoided
+	}
+	if existing.IsSigned() {
+		return model.PemeriksaanResponse{}, ErrPemeriksaanLocked
+	}
+
+	voided, err := s.repo.Void(id, req.Alasan, actorID, time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			// record dibatalkan atau ditandatangani oleh request lain setelah dibaca
+			if current, getErr := s.repo.GetById(id); getErr == nil && current.IsSigned() && !current.IsVoid() {
+				return model.PemeriksaanResponse{}, ErrPemeriksaanLocked
+			}
+			return model.PemeriksaanResponse{}, ErrPemeriksaanVoided
+		}
+		return model.PemeriksaanResponse{}, err
+	}
+
+	response := s.toResponse(voided)
+	recordAudit(ctx, s.audit, model.AuditActionVoid, model.AuditEntityPemeriksaan, id, model.ToPemeriksaanResponse(existing), response)
+	return response, nil
+}
+
+func (s *PemeriksaanService) GetRevisions(ctx context.Context, id int) ([]model.PemeriksaanRevisiResponse, error) {
+	if _, err := s.repo.GetById(id); err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.repo.GetRevisions(id)
+	if err != nil {
+		return nil, err
+	}
+	return model.ToPemeriksaanRevisiResponseList(revisions), nil
+}
+
+// tanpa parameter, revisi terbaru dibandingkan dengan revisi sebelumnya
+func (s *PemeriksaanService) DiffRevisions(ctx context.Context, id int, params model.ParamsDiffRevisi) (model.PemeriksaanRevisiDiffResponse, error) {
+	if _, err := s.repo.GetById(id); err != nil {
+		return model.PemeriksaanRevisiDiffResponse{}, err
+	}
+
+	revisions, err := s.repo.GetRevisions(id)
+	if err != nil {
+		return model.PemeriksaanRevisiDiffResponse{}, err
+	}
+	if len(revisions) == 0 {
+		return model.PemeriksaanRevisiDiffResponse{}, ErrRevisiNotFound
+	}
 
-	updatedPemeriksaan, err := s.repo.Update(id, pemeriksaanUpdate)
+	if params.Ke == 0 {
+		params.Ke = revisions[len(revisions)-1].Revisi
+	}
+	if params.Dari == 0 {
+		params.Dari = params.Ke - 1
+	}
+	if params.Dari >= params.Ke {
+		return model.PemeriksaanRevisiDiffResponse{}, ErrRevisiRange
+	}
+
+	byNumber := make(map[int]model.PemeriksaanRevisi, len(revisions))
+	for _, r := range revisions {
+		byNumber[r.Revisi] = r
+	}
+
+	to, ok := byNumber[params.Ke]
+	if !ok {
+		return model.PemeriksaanRevisiDiffResponse{}, ErrRevisiNotFound
+	}
+	after, err := to.Snapshot()
 	if err != nil {
+		return model.PemeriksaanRevisiDiffResponse{}, err
+	}
+
+	// revisi 0 berarti kosong, sehingga revisi pertama tampil sebagai isi awal
+	var before any
+	if params.Dari > 0 {
+		from, ok := byNumber[params.Dari]
+		if !ok {
+			return model.PemeriksaanRevisiDiffResponse{}, ErrRevisiNotFound
+		}
+		snapshot, err := from.Snapshot()
+		if err != nil {
+			return model.PemeriksaanRevisiDiffResponse{}, err
+		}
+		before = snapshot
+	}
+
+	changes, err := audit.Diff(before, after)
+	if err != nil {
+		return model.PemeriksaanRevisiDiffResponse{}, err
+	}
+	return model.PemeriksaanRevisiDiffResponse{Dari: params.Dari, Ke: params.Ke, Changes: changes}, nil
+}
+
+// menandatangani rekam medis oleh dokter penanggung jawab. Setelah ini isi terkunci dan
+// perubahan hanya bisa ditambahkan sebagai addendum
+func (s *PemeriksaanService) FinalizePemeriksaan(ctx context.Context, id int, signerID int) (model.PemeriksaanResponse, error) {
+	existing, err := s.repo.GetById(id)
+	if err != nil {
+		return model.PemeriksaanResponse{}, err
+	}
+	if existing.IsVoid() {
+		return model.PemeriksaanResponse{}, ErrPemeriksaanVoided
+	}
+	if existing.IsSigned() {
+		return model.PemeriksaanResponse{}, ErrPemeriksaanLocked
+	}
+	if existing.DokterID.Valid && existing.DokterID.Int64 != int64(signerID) {
+		return model.PemeriksaanResponse{}, ErrSignerNotDokter
+	}
+
+	// presisi timestamp postgres hanya mikrodetik, dibulatkan agar hash bisa dihitung ulang
+	signedAt := time.Now().UTC().Truncate(time.Microsecond)
+	hash, err := signPemeriksaan(s.config.RecordSignatureSecret, existing, int64(signerID), signedAt)
+	if err != nil {
+		return model.PemeriksaanResponse{}, fmt.Errorf("failed to sign pemeriksaan: %w", err)
+	}
+
+	signed, err := s.repo.Finalize(id, existing.Revisi, signerID, signedAt, hash)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.PemeriksaanResponse{}, ErrPemeriksaanLocked
+		}
 		return model.PemeriksaanResponse{}, err
 	}
 
-	return model.ToPemeriksaanResponse(updatedPemeriksaan), nil
+	response := s.toResponse(signed)
+	recordAudit(ctx, s.audit, model.AuditActionUpdate, model.AuditEntityPemeriksaan, id, s.toResponse(existing), response)
+	return response, nil
+}
+
+func (s *PemeriksaanService) AddAddendum(ctx context.Context, id int, req model.CreateAddendumRequest, authorID int) (model.AddendumResponse, error) {
+	existing, err := s.repo.GetById(id)
+	if err != nil {
+		return model.AddendumResponse{}, err
+	}
+	if existing.IsVoid() {
+		return model.AddendumResponse{}, ErrPemeriksaanVoided
+	}
+	if !existing.IsSigned() {
+		return model.AddendumResponse{}, ErrPemeriksaanDraft
+	}
+
+	addendum := model.PemeriksaanAddendum{
+		PemeriksaanID: id,
+		Isi:           req.Isi,
+		DibuatOleh:    sql.NullInt64{Int64: int64(authorID), Valid: authorID > 0},
+	}
+	created, err := s.repo.CreateAddendum(addendum)
+	if err != nil {
+		return model.AddendumResponse{}, err
+	}
+
+	response := model.ToAddendumResponse(created)
+	recordAudit(ctx, s.audit, model.AuditActionCreate, model.AuditEntityPemeriksaan, id, nil, response)
+	return response, nil
+}
+
+func (s *PemeriksaanService) toResponse(p model.Pemeriksaan) model.PemeriksaanResponse {
+	response := model.ToPemeriksaanResponse(p)
+	if response.TandaTangan != nil {
+		response.TandaTangan.Valid = verifyPemeriksaanSignature(s.config.RecordSignatureSecret, p)
+	}
+	return response
 }
 
-func (s *PemeriksaanService) DeletePemeriksaan(ctx context.Context, id int) error {
-	return s.repo.Delete(id)
+// daftar diagnosis yang terisi harus punya tepat satu diagnosis primer dan tanpa ICD ganda
+func validateDiagnosis(diagnosis []model.PemeriksaanDiagnosis) error {
+	if len(diagnosis) == 0 {
+		return nil
+	}
+
+	primer := 0
+	seen := make(map[int]bool, len(diagnosis))
+	for _, d := range diagnosis {
+		if d.Jenis == model.JenisDiagnosisPrimer {
+			primer++
+		}
+		if seen[d.IcdID] {
+			return ErrDiagnosisDuplikat
+		}
+		seen[d.IcdID] = true
+	}
+	if primer != 1 {
+		return ErrDiagnosisPrimer
+	}
+	return nil
+}
+
+// tindakan tanpa pelaksana dianggap dilakukan dokter penanggung jawab, pelaksana yang
+// disebutkan harus petugas aktif (dokter atau perawat poliklinik)
+func (s *PemeriksaanService) resolvePelaksana(tindakan []model.PemeriksaanTindakan, dokterID sql.NullInt64) error {
+	checked := make(map[int64]bool)
+	for i := range tindakan {
+		if !tindakan[i].PelaksanaID.Valid {
+			tindakan[i].PelaksanaID = dokterID
+			continue
+		}
+		id := tindakan[i].PelaksanaID.Int64
+		if checked[id] {
+			continue
+		}
+		petugas, err := s.petugasRepo.GetById(int(id))
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrPelaksanaInvalid
+			}
+			return err
+		}
+		if petugas.Status != StatusPetugasAktif {
+			return ErrPelaksanaInvalid
+		}
+		checked[id] = true
+	}
+	return nil
+}
+
+// foreign key yang gagal pada diagnosis atau tindakan berarti kode yang dikirim tidak ada
+func mapReferensiError(err error) error {
+	pgErr, ok := err.(*pgconn.PgError)
+	if !ok || pgErr.Code != "23503" {
+		return err
+	}
+	switch {
+	case strings.Contains(pgErr.ConstraintName, "prosedur"):
+		return ErrProsedurNotFound
+	case strings.Contains(pgErr.ConstraintName, "icd"):
+		return ErrIcdNotFound
+	}
+	return err
+}
+
+// dokter penanggung jawab: dari request jika diisi, lalu petugas yang login jika dia Dokter,
+// terakhir dokter pada jadwal antrian
+func (s *PemeriksaanService) resolveDokter(dokterID *int, authorID int, antrian model.Antrian) (int, error) {
+	if dokterID != nil {
+		return s.dokterAktif(*dokterID)
+	}
+
+	if authorID > 0 {
+		author, err := s.petugasRepo.GetById(authorID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return 0, err
+		}
+		if err == nil && author.Role == RolePetugasDokter && author.Status == StatusPetugasAktif {
+			return author.ID, nil
+		}
+	}
+
+	// petugas pada jadwal bisa saja bukan dokter atau sudah nonaktif, dokter harus dipilih manual
+	id, err := s.dokterAktif(antrian.Jadwal.PetugasID)
+	if errors.Is(err, ErrDokterInvalid) {
+		return 0, fmt.Errorf("%w: petugas on the jadwal is not an active dokter, dokter_id is required", ErrDokterInvalid)
+	}
+	return id, err
+}
+
+func (s *PemeriksaanService) dokterAktif(id int) (int, error) {
+	if id <= 0 {
+		return 0, ErrDokterInvalid
+	}
+	dokter, err := s.petugasRepo.GetById(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return 0, ErrDokterInvalid
+		}
+		return 0, err
+	}
+	if dokter.Role != RolePetugasDokter || dokter.Status != StatusPetugasAktif {
+		return 0, ErrDokterInvalid
+	}
+	return dokter.ID, nil
 }
@@ -2,52 +2,286 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/franklindh/simedis-api/internal/config"
 	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
+var (
+	ErrHasilLabOrder        = errors.New("lab result belongs to a lab order and can only be changed through the order")
+	ErrHasilLabTidakValid   = errors.New("lab result does not match the result type of the jenis pemeriksaan")
+	ErrHasilLabBatchInvalid = errors.New("lab result batch has invalid items")
+)
+
+// dikembalikan CreateBatch dan CreatePanel bila ada item yang tidak valid. Tidak ada hasil
+// yang disimpan
+type HasilLabBatchError struct {
+	Errors []model.HasilLabItemError
+}
+
+func (e *HasilLabBatchError) Error() string {
+	return fmt.Sprintf("%v: %d item(s)", ErrHasilLabBatchInvalid, len(e.Errors))
+}
+
+func (e *HasilLabBatchError) Is(target error) bool {
+	return target == ErrHasilLabBatchInvalid
+}
+
 type PemeriksaanLabService struct {
-	repo PemeriksaanLabRepository
+	repo            PemeriksaanLabRepository
+	jenisRepo       JenisPemeriksaanLabRepository
+	panelRepo       PanelLabRepository
+	pemeriksaanRepo PemeriksaanRepository
+	orderRepo       OrderLabRepository
+	audit           AuditRecorder
+	config          *config.Config
+}
+
+func NewPemeriksaanLabService(repo PemeriksaanLabRepository, jenisRepo JenisPemeriksaanLabRepository, panelRepo PanelLabRepository, pemeriksaanRepo PemeriksaanRepository, orderRepo OrderLabRepository, audit AuditRecorder, cfg *config.Config) *PemeriksaanLabService {
+	return &PemeriksaanLabService{repo: repo, jenisRepo: jenisRepo, panelRepo: panelRepo, pemeriksaanRepo: pemeriksaanRepo, orderRepo: orderRepo, audit: audit, config: cfg}
 }
 
-func NewPemeriksaanLabService(repo PemeriksaanLabRepository) *PemeriksaanLabService {
-	return &PemeriksaanLabService{repo: repo}
+// seluruh item disimpan dalam satu transaksi atau tidak sama sekali. Jenis yang sudah punya
+// hasil langsung pada pemeriksaan ini diperbarui hasilnya
+func (s *PemeriksaanLabService) CreateBatch(ctx context.Context, pemeriksaanID int, reqs []model.CreateHasilLabRequest) ([]model.PemeriksaanLabResponse, error) {
+	return s.createBatch(ctx, pemeriksaanID, reqs, nil)
 }
 
-func (s *PemeriksaanLabService) CreateBatch(ctx context.Context, pemeriksaanID int, reqs []model.CreateHasilLabRequest) ([]model.PemeriksaanLab, error) {
-	var createdResults []model.PemeriksaanLab
+// hasil untuk satu panel sekaligus. Setiap jenis harus bagian dari panel, jenis yang tidak
+// dikerjakan boleh tidak dikirim. Hasil disimpan mengikuti urutan item panel
+func (s *PemeriksaanLabService) CreatePanel(ctx context.Context, pemeriksaanID int, panelID int, reqs []model.CreateHasilLabRequest) ([]model.PemeriksaanLabResponse, error) {
+	panel, err := s.panelRepo.GetByID(panelID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: id %d", ErrPanelLabInvalid, panelID)
+		}
+		return nil, err
+	}
+	return s.createBatch(ctx, pemeriksaanID, reqs, &panel)
+}
+
+// semua item divalidasi lebih dulu dan seluruh kesalahannya dikembalikan sekaligus sebagai
+// HasilLabBatchError, baru kemudian batch disimpan
+func (s *PemeriksaanLabService) createBatch(ctx context.Context, pemeriksaanID int, reqs []model.CreateHasilLabRequest, panel *model.PanelLab) ([]model.PemeriksaanLabResponse, error) {
+	pemeriksaan, err := s.pemeriksaanRepo.GetById(pemeriksaanID)
+	if err != nil {
+		return nil, err
+	}
+	if pemeriksaan.IsVoid() {
+		return nil, ErrPemeriksaanVoided
+	}
+
+	ids := make([]int, 0, len(reqs))
 	for _, req := range reqs {
+		ids = append(ids, req.JenisPemeriksaanID)
+	}
+	jenisList, err := s.jenisRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	jenisByID := make(map[int]model.JenisPemeriksaanLab, len(jenisList))
+	for _, jenis := range jenisList {
+		jenisByID[jenis.ID] = jenis
+	}
+
+	existingList, err := s.repo.GetAllByPemeriksaanID(pemeriksaanID)
+	if err != nil {
+		return nil, err
+	}
+	existingByJenis := make(map[int]model.PemeriksaanLab, len(existingList))
+	for _, existing := range existingList {
+		if !existing.OrderLabItemID.Valid {
+			existingByJenis[existing.JenisPemeriksaanID] = existing
+		}
+	}
+
+	now := time.Now()
+	var itemErrors []model.HasilLabItemError
+	tolak := func(i int, req model.CreateHasilLabRequest, pesan string) {
+		itemErrors = append(itemErrors, model.HasilLabItemError{Index: i, JenisPemeriksaanID: req.JenisPemeriksaanID, Pesan: pesan})
+	}
+	indexByJenis := make(map[int]int, len(reqs))
+	batch := make([]model.PemeriksaanLab, 0, len(reqs))
+	for i, req := range reqs {
+		if first, ok := indexByJenis[req.JenisPemeriksaanID]; ok {
+			tolak(i, req, fmt.Sprintf("jenis pemeriksaan is already entered at index %d", first))
+			continue
+		}
+		indexByJenis[req.JenisPemeriksaanID] = i
+
+		jenis, ok := jenisByID[req.JenisPemeriksaanID]
+		if !ok {
+			tolak(i, req, ErrJenisPemeriksaanInvalid.Error())
+			continue
+		}
+		if panel != nil && panel.UrutanJenis(req.JenisPemeriksaanID) == 0 {
+			tolak(i, req, fmt.Sprintf("%v: %s", ErrPanelLabItemInvalid, panel.NamaPanel))
+			continue
+		}
+
 		hasilLab := model.PemeriksaanLab{
 			PemeriksaanID:      pemeriksaanID,
 			JenisPemeriksaanID: req.JenisPemeriksaanID,
 			Hasil:              req.Hasil,
 		}
-		created, err := s.repo.Create(hasilLab)
-		if err != nil {
+		if existing, ok := existingByJenis[req.JenisPemeriksaanID]; ok {
+			hasilLab.ID = existing.ID
+			hasilLab.PanelLabID = existing.PanelLabID
+		}
+		if panel != nil {
+			hasilLab.PanelLabID = sql.NullInt64{Int64: int64(panel.ID), Valid: true}
+		}
+		if err := interpretasiHasil(&hasilLab, jenis, pemeriksaan.Antrian.Pasien, now); err != nil {
+			tolak(i, req, err.Error())
+			continue
+		}
+		batch = append(batch, hasilLab)
+	}
+	if len(itemErrors) > 0 {
+		return nil, &HasilLabBatchError{Errors: itemErrors}
+	}
 
-			if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23503" {
-				return nil, fmt.Errorf("invalid data: %s", pgErr.Detail)
-			}
-			return nil, fmt.Errorf("failed to create lab result for jenis_id %d: %w", req.JenisPemeriksaanID, err)
+	if panel != nil {
+		slices.SortStableFunc(batch, func(a, b model.PemeriksaanLab) int {
+			return panel.UrutanJenis(a.JenisPemeriksaanID) - panel.UrutanJenis(b.JenisPemeriksaanID)
+		})
+	}
+
+	saved, err := s.repo.SimpanBatch(batch)
+	if err != nil {
+		// jenis pemeriksaan terhapus di antara validasi dan penyimpanan
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23503" {
+			return nil, fmt.Errorf("%w: %s", ErrJenisPemeriksaanInvalid, pgErr.Detail)
+		}
+		return nil, err
+	}
+
+	results := make([]model.PemeriksaanLabResponse, 0, len(saved))
+	for _, hasil := range saved {
+		results = append(results, model.ToPemeriksaanLabResponse(hasil))
+		if existing, ok := existingByJenis[hasil.JenisPemeriksaanID]; ok && existing.ID == hasil.ID {
+			recordAudit(ctx, s.audit, model.AuditActionUpdate, model.AuditEntityPemeriksaanLab, hasil.ID, existing, hasil)
+			continue
 		}
-		createdResults = append(createdResults, created)
+		recordAudit(ctx, s.audit, model.AuditActionCreate, model.AuditEntityPemeriksaanLab, hasil.ID, nil, hasil)
 	}
-	return createdResults, nil
+	return results, nil
 }
 
-func (s *PemeriksaanLabService) GetAllByPemeriksaanID(ctx context.Context, pemeriksaanID int) ([]model.PemeriksaanLab, error) {
-	return s.repo.GetAllByPemeriksaanID(pemeriksaanID)
+// hasil dikelompokkan per panel, hasil tanpa panel ada di kelompok dengan panel null
+func (s *PemeriksaanLabService) GetAllByPemeriksaanID(ctx context.Context, pemeriksaanID int) ([]model.HasilLabPanelResponse, error) {
+	results, err := s.repo.GetAllByPemeriksaanID(pemeriksaanID)
+	if err != nil {
+		return nil, err
+	}
+	return model.KelompokkanHasilLab(results), nil
 }
 
-func (s *PemeriksaanLabService) Update(ctx context.Context, id int, req model.UpdateHasilLabRequest) (model.PemeriksaanLab, error) {
+func (s *PemeriksaanLabService) Update(ctx context.Context, id int, req model.UpdateHasilLabRequest) (model.PemeriksaanLabResponse, error) {
+	existing, err := s.repo.GetById(id)
+	if err != nil {
+		return model.PemeriksaanLabResponse{}, err
+	}
+	if existing.OrderLabItemID.Valid {
+		return model.PemeriksaanLabResponse{}, ErrHasilLabOrder
+	}
+
+	pemeriksaan, err := s.pemeriksaanRepo.GetById(existing.PemeriksaanID)
+	if err != nil {
+		return model.PemeriksaanLabResponse{}, err
+	}
+
 	hasilLab := model.PemeriksaanLab{
 		Hasil: req.Hasil,
 	}
-	return s.repo.Update(id, hasilLab)
+	if err := interpretasiHasil(&hasilLab, existing.JenisPemeriksaanLab, pemeriksaan.Antrian.Pasien, time.Now()); err != nil {
+		return model.PemeriksaanLabResponse{}, err
+	}
+
+	updated, err := s.repo.Update(id, hasilLab)
+	if err != nil {
+		return model.PemeriksaanLabResponse{}, err
+	}
+
+	recordAudit(ctx, s.audit, model.AuditActionUpdate, model.AuditEntityPemeriksaanLab, id, existing, updated)
+	return model.ToPemeriksaanLabResponse(updated), nil
 }
 
 func (s *PemeriksaanLabService) Delete(ctx context.Context, id int) error {
-	return s.repo.Delete(id)
+	existing, err := s.repo.GetById(id)
+	if err != nil {
+		return err
+	}
+	if existing.OrderLabItemID.Valid {
+		return ErrHasilLabOrder
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.audit, model.AuditActionDelete, model.AuditEntityPemeriksaanLab, id, existing, nil)
+	return nil
+}
+
+// mengisi nilai numerik, flag, dan teks rujukan hasil sesuai tipe hasil jenis pemeriksaan.
+// Rentang rujukan dipilih berdasarkan jenis kelamin dan umur pasien pada saat hasil disimpan
+func interpretasiHasil(hasil *model.PemeriksaanLab, jenis model.JenisPemeriksaanLab, pasien model.Pasien, at time.Time) error {
+	hasil.NilaiNumerik = sql.NullFloat64{}
+	hasil.Flag = sql.NullString{}
+	hasil.NilaiRujukan = jenis.NilaiRujukan
+
+	switch jenis.TipeHasil {
+	case model.TipeHasilNumerik:
+		nilai, ok := model.ParseNilaiNumerik(hasil.Hasil)
+		if !ok {
+			return fmt.Errorf("%w: %s expects a number, got %q", ErrHasilLabTidakValid, jenis.NamaPemeriksaan, hasil.Hasil)
+		}
+		hasil.Hasil = strings.TrimSpace(hasil.Hasil)
+		hasil.NilaiNumerik = sql.NullFloat64{Float64: nilai, Valid: true}
+
+		rujukan, ok := jenis.PilihRujukan(pasien.JKPasien, model.UmurHari(pasien.TanggalLahirPasien, at))
+		if !ok {
+			return nil
+		}
+		if teks := rujukan.Teks(); teks != "" {
+			hasil.NilaiRujukan = sql.NullString{String: teks, Valid: true}
+		}
+		if flag := rujukan.Flag(nilai); flag != "" {
+			hasil.Flag = sql.NullString{String: flag, Valid: true}
+		}
+	case model.TipeHasilKategori:
+		pilihan := jenis.DaftarPilihan()
+		nilai := ""
+		for _, p := range pilihan {
+			if strings.EqualFold(p, strings.TrimSpace(hasil.Hasil)) {
+				nilai = p
+				break
+			}
+		}
+		if nilai == "" {
+			return fmt.Errorf("%w: %s expects one of %s, got %q", ErrHasilLabTidakValid, jenis.NamaPemeriksaan, strings.Join(pilihan, ", "), hasil.Hasil)
+		}
+		hasil.Hasil = nilai
+
+		normal := jenis.DaftarNilaiNormal()
+		if len(normal) == 0 {
+			return nil
+		}
+		if !hasil.NilaiRujukan.Valid {
+			hasil.NilaiRujukan = sql.NullString{String: strings.Join(normal, ", "), Valid: true}
+		}
+		if !containsFold(normal, nilai) {
+			hasil.Flag = sql.NullString{String: model.FlagLabAbnormal, Valid: true}
+		}
+	}
+	return nil
 }
@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/franklindh/simedis-api/internal/model"
 	"github.com/franklindh/simedis-api/internal/repository"
@@ -13,7 +15,7 @@ import (
 
 func TestPemeriksaanLabService_GetAllByPemeriksaanID(t *testing.T) {
 	mockRepo := new(MockPemeriksaanLabRepository)
-	service := NewPemeriksaanLabService(mockRepo)
+	service := NewPemeriksaanLabService(mockRepo, nil, nil, nil, nil, nil, nil)
 	pemeriksaanID := 100
 
 	t.Run("Success: Get all lab results for a pemeriksaan", func(t *testing.T) {
@@ -27,11 +29,37 @@ func TestPemeriksaanLabService_GetAllByPemeriksaanID(t *testing.T) {
 		results, err := service.GetAllByPemeriksaanID(context.Background(), pemeriksaanID)
 
 		assert.NoError(t, err)
-		assert.Len(t, results, 2)
-		assert.Equal(t, "Trombosit", results[0].JenisPemeriksaanLab.NamaPemeriksaan)
+		if assert.Len(t, results, 1) {
+			assert.Nil(t, results[0].Panel)
+			assert.Len(t, results[0].Hasil, 2)
+			assert.Equal(t, "Trombosit", results[0].Hasil[0].JenisPemeriksaan.Nama)
+		}
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("Success: Results grouped by panel in panel order", func(t *testing.T) {
+		panel := &model.PanelLab{ID: 20, NamaPanel: "Darah Lengkap", Items: []model.PanelLabItem{
+			{JenisPemeriksaanID: 1, Urutan: 1},
+			{JenisPemeriksaanID: 3, Urutan: 2},
+		}}
+		panelID := sql.NullInt64{Int64: 20, Valid: true}
+		mockRepo.On("GetAllByPemeriksaanID", pemeriksaanID).Return([]model.PemeriksaanLab{
+			{ID: 1, JenisPemeriksaanID: 3, PanelLabID: panelID, Panel: panel},
+			{ID: 2, JenisPemeriksaanID: 2},
+			{ID: 3, JenisPemeriksaanID: 1, PanelLabID: panelID, Panel: panel},
+		}, nil).Once()
+
+		results, err := service.GetAllByPemeriksaanID(context.Background(), pemeriksaanID)
+
+		assert.NoError(t, err)
+		if assert.Len(t, results, 2) {
+			assert.Equal(t, "Darah Lengkap", results[0].Panel.Nama)
+			assert.Equal(t, 3, results[0].Hasil[0].ID)
+			assert.Equal(t, 1, results[0].Hasil[1].ID)
+			assert.Nil(t, results[1].Panel)
+		}
+	})
+
 	t.Run("Success: Return empty slice when no results found", func(t *testing.T) {
 
 		mockRepo.On("GetAllByPemeriksaanID", pemeriksaanID).Return([]model.PemeriksaanLab{}, nil).Once()
@@ -46,22 +74,37 @@ func TestPemeriksaanLabService_GetAllByPemeriksaanID(t *testing.T) {
 
 func TestPemeriksaanLabService_CreateBatch(t *testing.T) {
 	mockRepo := new(MockPemeriksaanLabRepository)
-	service := NewPemeriksaanLabService(mockRepo)
+	mockJenisRepo := new(MockJenisPemeriksaanLabRepository)
+	mockPanelRepo := new(MockPanelLabRepository)
+	mockPemeriksaanRepo := new(MockPemeriksaanRepository)
+	service := NewPemeriksaanLabService(mockRepo, mockJenisRepo, mockPanelRepo, mockPemeriksaanRepo, nil, nil, nil)
+
+	pasien := model.Pasien{ID: 8, JKPasien: "P", TanggalLahirPasien: time.Now().AddDate(-30, 0, 0)}
+	mockPemeriksaanRepo.On("GetById", 100).Return(model.Pemeriksaan{ID: 100, Antrian: model.Antrian{Pasien: pasien}}, nil)
+	mockJenisRepo.On("GetByIDs", []int{1, 2}).Return([]model.JenisPemeriksaanLab{
+		{ID: 1, NamaPemeriksaan: "Trombosit", TipeHasil: model.TipeHasilTeks},
+		{ID: 2, NamaPemeriksaan: "Urine", TipeHasil: model.TipeHasilTeks},
+	}, nil)
 
 	reqs := []model.CreateHasilLabRequest{
 		{JenisPemeriksaanID: 1, Hasil: "150.000"},
 		{JenisPemeriksaanID: 2, Hasil: "Negatif"},
 	}
 	pemeriksaanID := 100
+	simpan := func(list []model.PemeriksaanLab) []model.PemeriksaanLab {
+		for i := range list {
+			if list[i].ID == 0 {
+				list[i].ID = list[i].JenisPemeriksaanID
+			}
+		}
+		return list
+	}
 
 	t.Run("Success: Create batch", func(t *testing.T) {
-
-		mockRepo.On("Create", mock.AnythingOfType("model.PemeriksaanLab")).
-			Return(func(lab model.PemeriksaanLab) model.PemeriksaanLab {
-
-				lab.ID = lab.JenisPemeriksaanID
-				return lab
-			}, nil).Times(2)
+		mockRepo.On("GetAllByPemeriksaanID", pemeriksaanID).Return([]model.PemeriksaanLab{}, nil).Once()
+		mockRepo.On("SimpanBatch", mock.MatchedBy(func(list []model.PemeriksaanLab) bool {
+			return len(list) == 2 && list[0].ID == 0 && list[1].ID == 0
+		})).Return(simpan, nil).Once()
 
 		results, err := service.CreateBatch(context.Background(), pemeriksaanID, reqs)
 
@@ -71,28 +114,209 @@ func TestPemeriksaanLabService_CreateBatch(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("Fail: One of the batch items fails", func(t *testing.T) {
+	t.Run("Success: Existing direct result updated instead of duplicated", func(t *testing.T) {
+		mockRepo.On("GetAllByPemeriksaanID", pemeriksaanID).Return([]model.PemeriksaanLab{
+			{ID: 41, PemeriksaanID: pemeriksaanID, JenisPemeriksaanID: 1, Hasil: "120.000"},
+			// hasil dari order lab tidak ikut diperbarui
+			{ID: 42, PemeriksaanID: pemeriksaanID, JenisPemeriksaanID: 2, OrderLabItemID: sql.NullInt64{Int64: 5, Valid: true}},
+		}, nil).Once()
+		mockRepo.On("SimpanBatch", mock.MatchedBy(func(list []model.PemeriksaanLab) bool {
+			return len(list) == 2 && list[0].ID == 41 && list[0].Hasil == "150.000" && list[1].ID == 0
+		})).Return(simpan, nil).Once()
 
-		mockRepo.On("Create", mock.AnythingOfType("model.PemeriksaanLab")).Return(model.PemeriksaanLab{ID: 1}, nil).Once()
-		mockRepo.On("Create", mock.AnythingOfType("model.PemeriksaanLab")).Return(model.PemeriksaanLab{}, errors.New("db error")).Once()
+		results, err := service.CreateBatch(context.Background(), pemeriksaanID, reqs)
 
-		_, err := service.CreateBatch(context.Background(), pemeriksaanID, reqs)
+		assert.NoError(t, err)
+		if assert.Len(t, results, 2) {
+			assert.Equal(t, 41, results[0].ID)
+			assert.Equal(t, 2, results[1].ID)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Repository error saves nothing", func(t *testing.T) {
+		mockRepo.On("GetAllByPemeriksaanID", pemeriksaanID).Return([]model.PemeriksaanLab{}, nil).Once()
+		mockRepo.On("SimpanBatch", mock.Anything).Return(nil, errors.New("db error")).Once()
+
+		results, err := service.CreateBatch(context.Background(), pemeriksaanID, reqs)
 
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "db error")
+		assert.Nil(t, results)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Fail: Duplicate and unknown jenis reported per item", func(t *testing.T) {
+		mockJenisRepo.On("GetByIDs", []int{1, 7, 1}).Return([]model.JenisPemeriksaanLab{{ID: 1, TipeHasil: model.TipeHasilTeks}}, nil).Once()
+		mockRepo.On("GetAllByPemeriksaanID", pemeriksaanID).Return([]model.PemeriksaanLab{}, nil).Once()
+
+		_, err := service.CreateBatch(context.Background(), pemeriksaanID, []model.CreateHasilLabRequest{
+			{JenisPemeriksaanID: 1, Hasil: "150.000"},
+			{JenisPemeriksaanID: 7, Hasil: "Negatif"},
+			{JenisPemeriksaanID: 1, Hasil: "160.000"},
+		})
+
+		assert.ErrorIs(t, err, ErrHasilLabBatchInvalid)
+		var batchErr *HasilLabBatchError
+		if assert.ErrorAs(t, err, &batchErr) && assert.Len(t, batchErr.Errors, 2) {
+			assert.Equal(t, 1, batchErr.Errors[0].Index)
+			assert.Equal(t, 7, batchErr.Errors[0].JenisPemeriksaanID)
+			assert.Equal(t, 2, batchErr.Errors[1].Index)
+			assert.Contains(t, batchErr.Errors[1].Pesan, "index 0")
+		}
+		mockRepo.AssertNumberOfCalls(t, "SimpanBatch", 3)
+	})
+
+	t.Run("Fail: Pemeriksaan not found", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 404).Return(model.Pemeriksaan{}, repository.ErrNotFound).Once()
+
+		_, err := service.CreateBatch(context.Background(), 404, reqs)
+
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		mockRepo.AssertNumberOfCalls(t, "SimpanBatch", 3)
+	})
+
+	t.Run("Fail: Pemeriksaan voided", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 101).Return(model.Pemeriksaan{ID: 101, DibatalkanAt: sql.NullTime{Time: time.Now(), Valid: true}}, nil).Once()
+
+		_, err := service.CreateBatch(context.Background(), 101, reqs)
+
+		assert.ErrorIs(t, err, ErrPemeriksaanVoided)
+		mockRepo.AssertNumberOfCalls(t, "SimpanBatch", 3)
+	})
+
+	hemoglobin := model.JenisPemeriksaanLab{
+		ID:              3,
+		NamaPemeriksaan: "Hemoglobin",
+		TipeHasil:       model.TipeHasilNumerik,
+		Rujukan: []model.RentangRujukanLab{
+			{BatasBawah: sql.NullFloat64{Float64: 11, Valid: true}, BatasAtas: sql.NullFloat64{Float64: 15, Valid: true}},
+			{
+				JenisKelamin: sql.NullString{String: "P", Valid: true},
+				UmurMinHari:  sql.NullInt64{Int64: 18 * 365, Valid: true},
+				BatasBawah:   sql.NullFloat64{Float64: 12, Valid: true},
+				BatasAtas:    sql.NullFloat64{Float64: 16, Valid: true},
+				KritisBawah:  sql.NullFloat64{Float64: 7, Valid: true},
+			},
+		},
+	}
+	urine := model.JenisPemeriksaanLab{
+		ID:              4,
+		NamaPemeriksaan: "Protein Urine",
+		TipeHasil:       model.TipeHasilKategori,
+		PilihanHasil:    sql.NullString{String: "Negatif,Positif", Valid: true},
+		NilaiNormal:     sql.NullString{String: "Negatif", Valid: true},
+	}
+
+	t.Run("Success: Results flagged against the most specific range", func(t *testing.T) {
+		mockJenisRepo.On("GetByIDs", []int{3, 4}).Return([]model.JenisPemeriksaanLab{hemoglobin, urine}, nil).Once()
+		mockRepo.On("GetAllByPemeriksaanID", pemeriksaanID).Return([]model.PemeriksaanLab{}, nil).Once()
+		mockRepo.On("SimpanBatch", mock.Anything).Return(simpan, nil).Once()
+
+		results, err := service.CreateBatch(context.Background(), pemeriksaanID, []model.CreateHasilLabRequest{
+			{JenisPemeriksaanID: 3, Hasil: "6,2"},
+			{JenisPemeriksaanID: 4, Hasil: "positif"},
+		})
+
+		assert.NoError(t, err)
+		if assert.Len(t, results, 2) {
+			assert.Equal(t, model.FlagLabKritisRendah, results[0].Flag)
+			assert.Equal(t, "12 - 16", results[0].NilaiRujukan)
+			assert.True(t, results[0].Kritis)
+			assert.Equal(t, "Positif", results[1].Hasil)
+			assert.Equal(t, model.FlagLabAbnormal, results[1].Flag)
+		}
+	})
+
+	t.Run("Fail: Non numeric result for numeric jenis", func(t *testing.T) {
+		mockJenisRepo.On("GetByIDs", []int{3}).Return([]model.JenisPemeriksaanLab{hemoglobin}, nil).Once()
+		mockRepo.On("GetAllByPemeriksaanID", pemeriksaanID).Return([]model.PemeriksaanLab{}, nil).Once()
+
+		_, err := service.CreateBatch(context.Background(), pemeriksaanID, []model.CreateHasilLabRequest{{JenisPemeriksaanID: 3, Hasil: "tinggi"}})
+
+		var batchErr *HasilLabBatchError
+		if assert.ErrorAs(t, err, &batchErr) && assert.Len(t, batchErr.Errors, 1) {
+			assert.Equal(t, 0, batchErr.Errors[0].Index)
+			assert.Contains(t, batchErr.Errors[0].Pesan, ErrHasilLabTidakValid.Error())
+		}
+		mockRepo.AssertNumberOfCalls(t, "SimpanBatch", 4)
+	})
+}
+
+func TestPemeriksaanLabService_CreatePanel(t *testing.T) {
+	mockRepo := new(MockPemeriksaanLabRepository)
+	mockJenisRepo := new(MockJenisPemeriksaanLabRepository)
+	mockPanelRepo := new(MockPanelLabRepository)
+	mockPemeriksaanRepo := new(MockPemeriksaanRepository)
+	service := NewPemeriksaanLabService(mockRepo, mockJenisRepo, mockPanelRepo, mockPemeriksaanRepo, nil, nil, nil)
+
+	panel := model.PanelLab{ID: 20, NamaPanel: "Darah Lengkap", Items: []model.PanelLabItem{
+		{JenisPemeriksaanID: 1, Urutan: 1},
+		{JenisPemeriksaanID: 2, Urutan: 2},
+	}}
+	mockPanelRepo.On("GetByID", 20).Return(panel, nil)
+	mockPemeriksaanRepo.On("GetById", 100).Return(model.Pemeriksaan{ID: 100}, nil)
+	mockRepo.On("GetAllByPemeriksaanID", 100).Return([]model.PemeriksaanLab{}, nil)
+
+	t.Run("Success: Results saved in panel order with panel id", func(t *testing.T) {
+		mockJenisRepo.On("GetByIDs", []int{2, 1}).Return([]model.JenisPemeriksaanLab{{ID: 1}, {ID: 2}}, nil).Once()
+		var urutan []int
+		mockRepo.On("SimpanBatch", mock.MatchedBy(func(list []model.PemeriksaanLab) bool {
+			return len(list) == 2 && list[0].PanelLabID.Int64 == 20 && list[1].PanelLabID.Int64 == 20
+		})).Run(func(args mock.Arguments) {
+			for _, lab := range args.Get(0).([]model.PemeriksaanLab) {
+				urutan = append(urutan, lab.JenisPemeriksaanID)
+			}
+		}).Return(func(list []model.PemeriksaanLab) []model.PemeriksaanLab { return list }, nil).Once()
+
+		results, err := service.CreatePanel(context.Background(), 100, 20, []model.CreateHasilLabRequest{
+			{JenisPemeriksaanID: 2, Hasil: "5000"},
+			{JenisPemeriksaanID: 1, Hasil: "13"},
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, results, 2)
+		assert.Equal(t, []int{1, 2}, urutan)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Jenis outside the panel", func(t *testing.T) {
+		mockJenisRepo.On("GetByIDs", []int{1, 9}).Return([]model.JenisPemeriksaanLab{{ID: 1}, {ID: 9}}, nil).Once()
+
+		_, err := service.CreatePanel(context.Background(), 100, 20, []model.CreateHasilLabRequest{
+			{JenisPemeriksaanID: 1, Hasil: "13"},
+			{JenisPemeriksaanID: 9, Hasil: "1"},
+		})
+
+		var batchErr *HasilLabBatchError
+		if assert.ErrorAs(t, err, &batchErr) && assert.Len(t, batchErr.Errors, 1) {
+			assert.Equal(t, 1, batchErr.Errors[0].Index)
+			assert.Contains(t, batchErr.Errors[0].Pesan, ErrPanelLabItemInvalid.Error())
+		}
+		mockRepo.AssertNumberOfCalls(t, "SimpanBatch", 1)
+	})
+
+	t.Run("Fail: Panel not found", func(t *testing.T) {
+		mockPanelRepo.On("GetByID", 99).Return(model.PanelLab{}, repository.ErrNotFound).Once()
+
+		_, err := service.CreatePanel(context.Background(), 100, 99, []model.CreateHasilLabRequest{{JenisPemeriksaanID: 1, Hasil: "1"}})
+
+		assert.ErrorIs(t, err, ErrPanelLabInvalid)
+	})
 }
 
 func TestPemeriksaanLabService_Update(t *testing.T) {
 	mockRepo := new(MockPemeriksaanLabRepository)
-	service := NewPemeriksaanLabService(mockRepo)
+	mockPemeriksaanRepo := new(MockPemeriksaanRepository)
+	service := NewPemeriksaanLabService(mockRepo, nil, nil, mockPemeriksaanRepo, nil, nil, nil)
 
 	req := model.UpdateHasilLabRequest{Hasil: "Positif"}
 
 	t.Run("Success: Update lab result", func(t *testing.T) {
 		mockModel := req.ToModel()
 		mockModel.ID = 1
+		mockRepo.On("GetById", 1).Return(model.PemeriksaanLab{ID: 1, PemeriksaanID: 100, Hasil: "Negatif"}, nil).Once()
+		mockPemeriksaanRepo.On("GetById", 100).Return(model.Pemeriksaan{ID: 100}, nil).Once()
 		mockRepo.On("Update", 1, mock.AnythingOfType("model.PemeriksaanLab")).Return(mockModel, nil).Once()
 
 		result, err := service.Update(context.Background(), 1, req)
@@ -103,20 +327,30 @@ func TestPemeriksaanLabService_Update(t *testing.T) {
 	})
 
 	t.Run("Fail: Lab result not found", func(t *testing.T) {
-		mockRepo.On("Update", 99, mock.AnythingOfType("model.PemeriksaanLab")).Return(model.PemeriksaanLab{}, repository.ErrNotFound).Once()
+		mockRepo.On("GetById", 99).Return(model.PemeriksaanLab{}, repository.ErrNotFound).Once()
 		_, err := service.Update(context.Background(), 99, req)
 		assert.Error(t, err)
 		assert.True(t, errors.Is(err, repository.ErrNotFound))
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Fail: Result belongs to a lab order", func(t *testing.T) {
+		mockRepo.On("GetById", 2).Return(model.PemeriksaanLab{ID: 2, OrderLabItemID: sql.NullInt64{Int64: 5, Valid: true}}, nil).Once()
+
+		_, err := service.Update(context.Background(), 2, req)
+
+		assert.ErrorIs(t, err, ErrHasilLabOrder)
+		mockRepo.AssertNumberOfCalls(t, "Update", 1)
+	})
 }
 
 func TestPemeriksaanLabService_Delete(t *testing.T) {
 	mockRepo := new(MockPemeriksaanLabRepository)
-	service := NewPemeriksaanLabService(mockRepo)
+	service := NewPemeriksaanLabService(mockRepo, nil, nil, nil, nil, nil, nil)
 
 	t.Run("Success: Delete lab result", func(t *testing.T) {
 
+		mockRepo.On("GetById", 1).Return(model.PemeriksaanLab{ID: 1}, nil).Once()
 		mockRepo.On("Delete", 1).Return(nil).Once()
 
 		err := service.Delete(context.Background(), 1)
@@ -127,7 +361,7 @@ func TestPemeriksaanLabService_Delete(t *testing.T) {
 
 	t.Run("Fail: Lab result to delete not found", func(t *testing.T) {
 
-		mockRepo.On("Delete", 99).Return(repository.ErrNotFound).Once()
+		mockRepo.On("GetById", 99).Return(model.PemeriksaanLab{}, repository.ErrNotFound).Once()
 
 		err := service.Delete(context.Background(), 99)
 
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/franklindh/simedis-api/internal/model"
+)
+
+// HMAC-SHA256 atas isi kanonik rekam medis, sehingga perubahan langsung di database
+// tanpa kunci server akan terdeteksi saat verifikasi
+func signPemeriksaan(secret string, p model.Pemeriksaan, signerID int64, at time.Time) (string, error) {
+	payload, err := json.Marshal(model.NewPemeriksaanSignaturePayload(p, signerID, at))
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+func verifyPemeriksaanSignature(secret string, p model.Pemeriksaan) bool {
+	if !p.IsSigned() || !p.TandaTangan.Valid {
+		return false
+	}
+
+	expected, err := signPemeriksaan(secret, p, p.DitandatanganiOleh.Int64, p.DitandatanganiAt.Time)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(expected), []byte(p.TandaTangan.String))
+}
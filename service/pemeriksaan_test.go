@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/franklindh/simedis-api/internal/config"
 	"github.com/franklindh/simedis-api/internal/model"
 	"github.com/franklindh/simedis-api/internal/repository"
 	"github.com/stretchr/testify/assert"
@@ -16,35 +17,191 @@ import (
 func TestPemeriksaanService_CreatePemeriksaan(t *testing.T) {
 	mockPemeriksaanRepo := new(MockPemeriksaanRepository)
 	mockAntrianRepo := new(MockAntrianRepository)
-	pemeriksaanService := NewPemeriksaanService(mockPemeriksaanRepo, mockAntrianRepo)
+	mockPetugasRepo := new(MockPetugasRepository)
+	pemeriksaanService := NewPemeriksaanService(mockPemeriksaanRepo, mockAntrianRepo, mockPetugasRepo, nil, nil, &config.Config{RecordSignatureSecret: "rahasia"})
 
 	inputDTO := model.CreatePemeriksaanRequest{
 		AntrianID:          1,
 		TanggalPemeriksaan: "2025-08-21",
 		Keluhan:            "Pusing",
 	}
+	mockAntrian := model.Antrian{ID: 1, Status: "Dipanggil", Jadwal: model.Jadwal{PetugasID: 5}}
+	mockPetugasRepo.On("GetById", 5).Return(model.Petugas{ID: 5, Role: "Dokter", Status: "aktif"}, nil)
 
 	t.Run("Success: Create pemeriksaan and update antrian status", func(t *testing.T) {
 		createdModel := inputDTO.ToModel()
 		createdModel.ID = 10
 		mockPemeriksaanRepo.On("CheckExistingPemeriksaan", 1).Return(repository.ErrNotFound).Once()
-		mockPemeriksaanRepo.On("Create", mock.AnythingOfType("model.Pemeriksaan")).Return(createdModel, nil).Once()
-		mockAntrian := model.Antrian{ID: 1, Status: "Menunggu Diagnosis"}
 		mockAntrianRepo.On("GetByID", 1).Return(mockAntrian, nil).Once()
-		mockAntrianRepo.On("Update", 1, mock.AnythingOfType("model.Antrian")).Return(model.Antrian{}, nil).Once()
+		mockPetugasRepo.On("GetById", 3).Return(model.Petugas{ID: 3, Role: "Poliklinik"}, nil).Once()
+		mockPemeriksaanRepo.On("Create", mock.MatchedBy(func(p model.Pemeriksaan) bool {
+			return p.DokterID.Int64 == 5 && p.DibuatOleh.Int64 == 3
+		})).Return(createdModel, nil).Once()
+		mockAntrianRepo.On("UpdateStatus", 1, "Dipanggil", "Diperiksa", 3, mock.AnythingOfType("time.Time")).Return(model.Antrian{}, nil).Once()
 
-		result, err := pemeriksaanService.CreatePemeriksaan(context.Background(), inputDTO)
+		result, err := pemeriksaanService.CreatePemeriksaan(context.Background(), inputDTO, 3)
 
 		assert.NoError(t, err)
 		assert.Equal(t, 10, result.ID)
 		mockPemeriksaanRepo.AssertExpectations(t)
 		mockAntrianRepo.AssertExpectations(t)
-		mockAntrianRepo.AssertCalled(t, "Update", 1, mock.Anything)
+		mockAntrianRepo.AssertCalled(t, "UpdateStatus", 1, "Dipanggil", "Diperiksa", 3, mock.Anything)
+	})
+
+	t.Run("Fail: Antrian not called yet or already closed", func(t *testing.T) {
+		for _, status := range []string{"Menunggu", "Batal", "Tidak Hadir", "Selesai"} {
+			t.Run(status, func(t *testing.T) {
+				mockPemeriksaanRepo.On("CheckExistingPemeriksaan", 1).Return(repository.ErrNotFound).Once()
+				mockAntrianRepo.On("GetByID", 1).Return(model.Antrian{ID: 1, Status: status, Jadwal: model.Jadwal{PetugasID: 5}}, nil).Once()
+
+				_, err := pemeriksaanService.CreatePemeriksaan(context.Background(), inputDTO, 3)
+
+				assert.ErrorIs(t, err, ErrInvalidTransition)
+			})
+		}
+		mockPemeriksaanRepo.AssertNumberOfCalls(t, "Create", 1)
+	})
+
+	t.Run("Success: Logged in dokter becomes responsible dokter", func(t *testing.T) {
+		mockPemeriksaanRepo.On("CheckExistingPemeriksaan", 1).Return(repository.ErrNotFound).Once()
+		mockAntrianRepo.On("GetByID", 1).Return(model.Antrian{ID: 1, Status: "Diperiksa", Jadwal: model.Jadwal{PetugasID: 5}}, nil).Once()
+		mockPetugasRepo.On("GetById", 8).Return(model.Petugas{ID: 8, Role: "Dokter", Status: "aktif"}, nil).Once()
+		mockPemeriksaanRepo.On("Create", mock.MatchedBy(func(p model.Pemeriksaan) bool {
+			return p.DokterID.Int64 == 8 && p.DibuatOleh.Int64 == 8
+		})).Return(model.Pemeriksaan{ID: 11}, nil).Once()
+
+		_, err := pemeriksaanService.CreatePemeriksaan(context.Background(), inputDTO, 8)
+
+		assert.NoError(t, err)
+		mockPemeriksaanRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Explicit dokter is not a dokter", func(t *testing.T) {
+		dokterID := 4
+		req := inputDTO
+		req.DokterID = &dokterID
+		mockPemeriksaanRepo.On("CheckExistingPemeriksaan", 1).Return(repository.ErrNotFound).Once()
+		mockAntrianRepo.On("GetByID", 1).Return(mockAntrian, nil).Once()
+		mockPetugasRepo.On("GetById", 4).Return(model.Petugas{ID: 4, Role: "Lab", Status: "aktif"}, nil).Once()
+
+		_, err := pemeriksaanService.CreatePemeriksaan(context.Background(), req, 3)
+
+		assert.ErrorIs(t, err, ErrDokterInvalid)
+		mockPemeriksaanRepo.AssertNotCalled(t, "Create", mock.MatchedBy(func(p model.Pemeriksaan) bool { return p.DokterID.Int64 == 4 }))
+	})
+
+	t.Run("Fail: Jadwal petugas is not an active dokter", func(t *testing.T) {
+		mockPemeriksaanRepo.On("CheckExistingPemeriksaan", 1).Return(repository.ErrNotFound).Once()
+		mockAntrianRepo.On("GetByID", 1).Return(model.Antrian{ID: 1, Status: "Dipanggil", Jadwal: model.Jadwal{PetugasID: 9}}, nil).Once()
+		mockPetugasRepo.On("GetById", 3).Return(model.Petugas{ID: 3, Role: "Poliklinik"}, nil).Once()
+		mockPetugasRepo.On("GetById", 9).Return(model.Petugas{ID: 9, Role: "Dokter", Status: "nonaktif"}, nil).Once()
+
+		_, err := pemeriksaanService.CreatePemeriksaan(context.Background(), inputDTO, 3)
+
+		assert.ErrorIs(t, err, ErrDokterInvalid)
+		assert.Contains(t, err.Error(), "dokter_id is required")
+		mockPemeriksaanRepo.AssertNotCalled(t, "Create", mock.MatchedBy(func(p model.Pemeriksaan) bool { return p.DokterID.Int64 == 9 }))
+	})
+
+	t.Run("Success: Primary diagnosis fills legacy icd_id", func(t *testing.T) {
+		req := inputDTO
+		req.Diagnosis = []model.DiagnosisRequest{
+			{IcdID: 21, Jenis: model.JenisDiagnosisSekunder, Kasus: model.KasusLama},
+			{IcdID: 20, Jenis: model.JenisDiagnosisPrimer, Kasus: model.KasusBaru},
+		}
+		mockPemeriksaanRepo.On("CheckExistingPemeriksaan", 1).Return(repository.ErrNotFound).Once()
+		mockAntrianRepo.On("GetByID", 1).Return(model.Antrian{ID: 1, Status: "Diperiksa", Jadwal: model.Jadwal{PetugasID: 5}}, nil).Once()
+		mockPetugasRepo.On("GetById", 3).Return(model.Petugas{ID: 3, Role: "Poliklinik"}, nil).Once()
+		mockPemeriksaanRepo.On("Create", mock.MatchedBy(func(p model.Pemeriksaan) bool {
+			return p.IcdID.Int64 == 20 && len(p.Diagnosis) == 2 && p.Diagnosis[1].Urutan == 2
+		})).Return(model.Pemeriksaan{ID: 12}, nil).Once()
+
+		_, err := pemeriksaanService.CreatePemeriksaan(context.Background(), req, 3)
+
+		assert.NoError(t, err)
+		mockPemeriksaanRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Diagnosis without exactly one primer", func(t *testing.T) {
+		cases := map[string][]model.DiagnosisRequest{
+			"no primer": {
+				{IcdID: 20, Jenis: model.JenisDiagnosisSekunder, Kasus: model.KasusBaru},
+			},
+			"two primer": {
+				{IcdID: 20, Jenis: model.JenisDiagnosisPrimer, Kasus: model.KasusBaru},
+				{IcdID: 21, Jenis: model.JenisDiagnosisPrimer, Kasus: model.KasusBaru},
+			},
+		}
+		for name, diagnosis := range cases {
+			t.Run(name, func(t *testing.T) {
+				req := inputDTO
+				req.Diagnosis = diagnosis
+				mockPemeriksaanRepo.On("CheckExistingPemeriksaan", 1).Return(repository.ErrNotFound).Once()
+				mockAntrianRepo.On("GetByID", 1).Return(mockAntrian, nil).Once()
+				mockPetugasRepo.On("GetById", 3).Return(model.Petugas{ID: 3, Role: "Poliklinik"}, nil).Once()
+
+				_, err := pemeriksaanService.CreatePemeriksaan(context.Background(), req, 3)
+
+				assert.ErrorIs(t, err, ErrDiagnosisPrimer)
+			})
+		}
+	})
+
+	t.Run("Fail: Duplicate icd in diagnosis", func(t *testing.T) {
+		req := inputDTO
+		req.Diagnosis = []model.DiagnosisRequest{
+			{IcdID: 20, Jenis: model.JenisDiagnosisPrimer, Kasus: model.KasusBaru},
+			{IcdID: 20, Jenis: model.JenisDiagnosisSekunder, Kasus: model.KasusBaru},
+		}
+		mockPemeriksaanRepo.On("CheckExistingPemeriksaan", 1).Return(repository.ErrNotFound).Once()
+		mockAntrianRepo.On("GetByID", 1).Return(mockAntrian, nil).Once()
+		mockPetugasRepo.On("GetById", 3).Return(model.Petugas{ID: 3, Role: "Poliklinik"}, nil).Once()
+
+		_, err := pemeriksaanService.CreatePemeriksaan(context.Background(), req, 3)
+
+		assert.ErrorIs(t, err, ErrDiagnosisDuplikat)
+	})
+
+	t.Run("Success: Tindakan without pelaksana defaults to dokter", func(t *testing.T) {
+		perawatID := 6
+		req := inputDTO
+		req.DaftarTindakan = []model.TindakanRequest{
+			{ProsedurID: 30},
+			{ProsedurID: 31, Jumlah: 2, PelaksanaID: &perawatID},
+		}
+		mockPemeriksaanRepo.On("CheckExistingPemeriksaan", 1).Return(repository.ErrNotFound).Once()
+		mockAntrianRepo.On("GetByID", 1).Return(model.Antrian{ID: 1, Status: "Diperiksa", Jadwal: model.Jadwal{PetugasID: 5}}, nil).Once()
+		mockPetugasRepo.On("GetById", 3).Return(model.Petugas{ID: 3, Role: "Poliklinik"}, nil).Once()
+		mockPetugasRepo.On("GetById", 6).Return(model.Petugas{ID: 6, Role: "Poliklinik", Status: "aktif"}, nil).Once()
+		mockPemeriksaanRepo.On("Create", mock.MatchedBy(func(p model.Pemeriksaan) bool {
+			return len(p.DaftarTindakan) == 2 &&
+				p.DaftarTindakan[0].PelaksanaID.Int64 == 5 && p.DaftarTindakan[0].Jumlah == 1 &&
+				p.DaftarTindakan[1].PelaksanaID.Int64 == 6 && p.DaftarTindakan[1].Jumlah == 2
+		})).Return(model.Pemeriksaan{ID: 13}, nil).Once()
+
+		_, err := pemeriksaanService.CreatePemeriksaan(context.Background(), req, 3)
+
+		assert.NoError(t, err)
+		mockPemeriksaanRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Tindakan pelaksana is not an active petugas", func(t *testing.T) {
+		pelaksanaID := 7
+		req := inputDTO
+		req.DaftarTindakan = []model.TindakanRequest{{ProsedurID: 30, PelaksanaID: &pelaksanaID}}
+		mockPemeriksaanRepo.On("CheckExistingPemeriksaan", 1).Return(repository.ErrNotFound).Once()
+		mockAntrianRepo.On("GetByID", 1).Return(mockAntrian, nil).Once()
+		mockPetugasRepo.On("GetById", 3).Return(model.Petugas{ID: 3, Role: "Poliklinik"}, nil).Once()
+		mockPetugasRepo.On("GetById", 7).Return(model.Petugas{ID: 7, Role: "Poliklinik", Status: "nonaktif"}, nil).Once()
+
+		_, err := pemeriksaanService.CreatePemeriksaan(context.Background(), req, 3)
+
+		assert.ErrorIs(t, err, ErrPelaksanaInvalid)
 	})
 
 	t.Run("Fail: Pemeriksaan already exists", func(t *testing.T) {
 		mockPemeriksaanRepo.On("CheckExistingPemeriksaan", 1).Return(nil).Once()
-		_, err := pemeriksaanService.CreatePemeriksaan(context.Background(), inputDTO)
+		_, err := pemeriksaanService.CreatePemeriksaan(context.Background(), inputDTO, 3)
 		assert.Error(t, err)
 		assert.True(t, errors.Is(err, ErrPemeriksaanExists))
 		mockPemeriksaanRepo.AssertExpectations(t)
@@ -54,7 +211,8 @@ func TestPemeriksaanService_CreatePemeriksaan(t *testing.T) {
 func TestPemeriksaanService_GetPemeriksaanByID(t *testing.T) {
 	mockPemeriksaanRepo := new(MockPemeriksaanRepository)
 	mockAntrianRepo := new(MockAntrianRepository)
-	pemeriksaanService := NewPemeriksaanService(mockPemeriksaanRepo, mockAntrianRepo)
+	mockPetugasRepo := new(MockPetugasRepository)
+	pemeriksaanService := NewPemeriksaanService(mockPemeriksaanRepo, mockAntrianRepo, mockPetugasRepo, nil, nil, &config.Config{RecordSignatureSecret: "rahasia"})
 
 	t.Run("Success: Pemeriksaan found", func(t *testing.T) {
 		mockModel := model.Pemeriksaan{
@@ -80,6 +238,20 @@ func TestPemeriksaanService_GetPemeriksaanByID(t *testing.T) {
 		mockPemeriksaanRepo.AssertExpectations(t)
 	})
 
+	t.Run("Success: Typed vital signs with computed BMI", func(t *testing.T) {
+		sistolik, diastolik, beratBadan, tinggiBadan := 130, 85, 70.0, 170.0
+		vital := model.TandaVitalRequest{Sistolik: &sistolik, Diastolik: &diastolik, BeratBadan: &beratBadan, TinggiBadan: &tinggiBadan}
+		mockPemeriksaanRepo.On("GetById", 2).Return(model.Pemeriksaan{ID: 2, TandaVital: vital.ToModel()}, nil).Once()
+
+		result, err := pemeriksaanService.GetPemeriksaanByID(context.Background(), 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "130/85", result.TandaVital.TekananDarah)
+		assert.NotNil(t, result.TandaVital.BMI)
+		assert.Equal(t, 24.2, *result.TandaVital.BMI)
+		assert.Nil(t, result.TandaVital.Nadi)
+	})
+
 	t.Run("Fail: Pemeriksaan not found", func(t *testing.T) {
 		mockPemeriksaanRepo.On("GetById", 99).Return(model.Pemeriksaan{}, repository.ErrNotFound).Once()
 		_, err := pemeriksaanService.GetPemeriksaanByID(context.Background(), 99)
@@ -92,7 +264,8 @@ func TestPemeriksaanService_GetPemeriksaanByID(t *testing.T) {
 func TestPemeriksaanService_GetRiwayatPemeriksaanPasien(t *testing.T) {
 	mockPemeriksaanRepo := new(MockPemeriksaanRepository)
 	mockAntrianRepo := new(MockAntrianRepository)
-	pemeriksaanService := NewPemeriksaanService(mockPemeriksaanRepo, mockAntrianRepo)
+	mockPetugasRepo := new(MockPetugasRepository)
+	pemeriksaanService := NewPemeriksaanService(mockPemeriksaanRepo, mockAntrianRepo, mockPetugasRepo, nil, nil, &config.Config{RecordSignatureSecret: "rahasia"})
 
 	t.Run("Success: Get patient history", func(t *testing.T) {
 		mockHistory := []model.Pemeriksaan{
@@ -120,19 +293,24 @@ func TestPemeriksaanService_GetRiwayatPemeriksaanPasien(t *testing.T) {
 func TestPemeriksaanService_UpdatePemeriksaan(t *testing.T) {
 	mockPemeriksaanRepo := new(MockPemeriksaanRepository)
 	mockAntrianRepo := new(MockAntrianRepository)
-	pemeriksaanService := NewPemeriksaanService(mockPemeriksaanRepo, mockAntrianRepo)
+	mockPetugasRepo := new(MockPetugasRepository)
+	pemeriksaanService := NewPemeriksaanService(mockPemeriksaanRepo, mockAntrianRepo, mockPetugasRepo, nil, nil, &config.Config{RecordSignatureSecret: "rahasia"})
 
 	inputDTO := model.UpdatePemeriksaanRequest{
 		TanggalPemeriksaan: "2025-08-22",
 		Keluhan:            "Sudah mendingan",
+		Alasan:             "Koreksi keluhan",
 	}
 
 	t.Run("Success: Update pemeriksaan", func(t *testing.T) {
 		updatedModel := inputDTO.ToModel()
 		updatedModel.ID = 1
-		mockPemeriksaanRepo.On("Update", 1, mock.AnythingOfType("model.Pemeriksaan")).Return(updatedModel, nil).Once()
+		mockPemeriksaanRepo.On("GetById", 1).Return(model.Pemeriksaan{ID: 1, Keluhan: sql.NullString{String: "Demam", Valid: true}}, nil).Once()
+		mockPemeriksaanRepo.On("Update", 1, mock.MatchedBy(func(p model.Pemeriksaan) bool {
+			return p.DiubahOleh.Valid && p.DiubahOleh.Int64 == 3
+		}), "Koreksi keluhan").Return(updatedModel, nil).Once()
 
-		result, err := pemeriksaanService.UpdatePemeriksaan(context.Background(), 1, inputDTO)
+		result, err := pemeriksaanService.UpdatePemeriksaan(context.Background(), 1, inputDTO, 3)
 
 		assert.NoError(t, err)
 		assert.Equal(t, 1, result.ID)
@@ -140,10 +318,30 @@ func TestPemeriksaanService_UpdatePemeriksaan(t *testing.T) {
 		mockPemeriksaanRepo.AssertExpectations(t)
 	})
 
+	t.Run("Fail: Pemeriksaan has been voided", func(t *testing.T) {
+		voided := model.Pemeriksaan{ID: 2, DibatalkanAt: sql.NullTime{Time: time.Now(), Valid: true}}
+		mockPemeriksaanRepo.On("GetById", 2).Return(voided, nil).Once()
+
+		_, err := pemeriksaanService.UpdatePemeriksaan(context.Background(), 2, inputDTO, 3)
+
+		assert.ErrorIs(t, err, ErrPemeriksaanVoided)
+		mockPemeriksaanRepo.AssertNotCalled(t, "Update", 2, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Signed pemeriksaan is locked", func(t *testing.T) {
+		signed := model.Pemeriksaan{ID: 3, DitandatanganiAt: sql.NullTime{Time: time.Now(), Valid: true}}
+		mockPemeriksaanRepo.On("GetById", 3).Return(signed, nil).Once()
+
+		_, err := pemeriksaanService.UpdatePemeriksaan(context.Background(), 3, inputDTO, 3)
+
+		assert.ErrorIs(t, err, ErrPemeriksaanLocked)
+		mockPemeriksaanRepo.AssertNotCalled(t, "Update", 3, mock.Anything, mock.Anything)
+	})
+
 	t.Run("Fail: Pemeriksaan to update not found", func(t *testing.T) {
-		mockPemeriksaanRepo.On("Update", 99, mock.AnythingOfType("model.Pemeriksaan")).Return(model.Pemeriksaan{}, repository.ErrNotFound).Once()
+		mockPemeriksaanRepo.On("GetById", 99).Return(model.Pemeriksaan{}, repository.ErrNotFound).Once()
 
-		_, err := pemeriksaanService.UpdatePemeriksaan(context.Background(), 99, inputDTO)
+		_, err := pemeriksaanService.UpdatePemeriksaan(context.Background(), 99, inputDTO, 3)
 
 		assert.Error(t, err)
 		assert.True(t, errors.Is(err, repository.ErrNotFound))
@@ -151,23 +349,284 @@ func TestPemeriksaanService_UpdatePemeriksaan(t *testing.T) {
 	})
 }
 
-func TestPemeriksaanService_DeletePemeriksaan(t *testing.T) {
+func TestPemeriksaanService_VoidPemeriksaan(t *testing.T) {
 	mockPemeriksaanRepo := new(MockPemeriksaanRepository)
 	mockAntrianRepo := new(MockAntrianRepository)
-	pemeriksaanService := NewPemeriksaanService(mockPemeriksaanRepo, mockAntrianRepo)
+	mockPetugasRepo := new(MockPetugasRepository)
+	pemeriksaanService := NewPemeriksaanService(mockPemeriksaanRepo, mockAntrianRepo, mockPetugasRepo, nil, nil, &config.Config{RecordSignatureSecret: "rahasia"})
+
+	req := model.VoidPemeriksaanRequest{Alasan: "Salah input pasien"}
+
+	t.Run("Success: Void pemeriksaan", func(t *testing.T) {
+		voided := model.Pemeriksaan{
+			ID:           1,
+			DibatalkanAt: sql.NullTime{Time: time.Now(), Valid: true},
+			AlasanBatal:  sql.NullString{String: req.Alasan, Valid: true},
+		}
+		mockPemeriksaanRepo.On("GetById", 1).Return(model.Pemeriksaan{ID: 1}, nil).Once()
+		mockPemeriksaanRepo.On("Void", 1, req.Alasan, 3, mock.AnythingOfType("time.Time")).Return(voided, nil).Once()
+
+		result, err := pemeriksaanService.VoidPemeriksaan(context.Background(), 1, req, 3)
 
-	t.Run("Success: Delete pemeriksaan", func(t *testing.T) {
-		mockPemeriksaanRepo.On("Delete", 1).Return(nil).Once()
-		err := pemeriksaanService.DeletePemeriksaan(context.Background(), 1)
 		assert.NoError(t, err)
+		assert.True(t, result.Dibatalkan)
+		assert.Equal(t, req.Alasan, result.AlasanBatal)
 		mockPemeriksaanRepo.AssertExpectations(t)
 	})
 
-	t.Run("Fail: Pemeriksaan to delete not found", func(t *testing.T) {
-		mockPemeriksaanRepo.On("Delete", 99).Return(repository.ErrNotFound).Once()
-		err := pemeriksaanService.DeletePemeriksaan(context.Background(), 99)
+	t.Run("Fail: Pemeriksaan already voided", func(t *testing.T) {
+		voided := model.Pemeriksaan{ID: 2, DibatalkanAt: sql.NullTime{Time: time.Now(), Valid: true}}
+		mockPemeriksaanRepo.On("GetById", 2).Return(voided, nil).Once()
+
+		_, err := pemeriksaanService.VoidPemeriksaan(context.Background(), 2, req, 3)
+
+		assert.ErrorIs(t, err, ErrPemeriksaanVoided)
+		mockPemeriksaanRepo.AssertNotCalled(t, "Void", 2, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Signed pemeriksaan cannot be voided", func(t *testing.T) {
+		signed := model.Pemeriksaan{ID: 3, DitandatanganiAt: sql.NullTime{Time: time.Now(), Valid: true}}
+		mockPemeriksaanRepo.On("GetById", 3).Return(signed, nil).Once()
+
+		_, err := pemeriksaanService.VoidPemeriksaan(context.Background(), 3, req, 3)
+
+		assert.ErrorIs(t, err, ErrPemeriksaanLocked)
+		mockPemeriksaanRepo.AssertNotCalled(t, "Void", 3, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Pemeriksaan signed before the void is written", func(t *testing.T) {
+		signed := model.Pemeriksaan{ID: 4, DitandatanganiAt: sql.NullTime{Time: time.Now(), Valid: true}}
+		mockPemeriksaanRepo.On("GetById", 4).Return(model.Pemeriksaan{ID: 4}, nil).Once()
+		mockPemeriksaanRepo.On("Void", 4, req.Alasan, 3, mock.AnythingOfType("time.Time")).Return(model.Pemeriksaan{}, repository.ErrNotFound).Once()
+		mockPemeriksaanRepo.On("GetById", 4).Return(signed, nil).Once()
+
+		_, err := pemeriksaanService.VoidPemeriksaan(context.Background(), 4, req, 3)
+
+		assert.ErrorIs(t, err, ErrPemeriksaanLocked)
+	})
+
+	t.Run("Fail: Pemeriksaan to void not found", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 99).Return(model.Pemeriksaan{}, repository.ErrNotFound).Once()
+		_, err := pemeriksaanService.VoidPemeriksaan(context.Background(), 99, req, 3)
 		assert.Error(t, err)
 		assert.True(t, errors.Is(err, repository.ErrNotFound))
 		mockPemeriksaanRepo.AssertExpectations(t)
 	})
 }
+
+func TestPemeriksaanService_DiffRevisions(t *testing.T) {
+	mockPemeriksaanRepo := new(MockPemeriksaanRepository)
+	mockAntrianRepo := new(MockAntrianRepository)
+	mockPetugasRepo := new(MockPetugasRepository)
+	pemeriksaanService := NewPemeriksaanService(mockPemeriksaanRepo, mockAntrianRepo, mockPetugasRepo, nil, nil, &config.Config{RecordSignatureSecret: "rahasia"})
+
+	first, _ := model.NewPemeriksaanRevisi(model.Pemeriksaan{ID: 1, Keluhan: sql.NullString{String: "Demam", Valid: true}}, 1, model.AlasanRevisiAwal, sql.NullInt64{})
+	second, _ := model.NewPemeriksaanRevisi(model.Pemeriksaan{ID: 1, Keluhan: sql.NullString{String: "Demam tinggi", Valid: true}}, 2, "Koreksi keluhan", sql.NullInt64{})
+	revisions := []model.PemeriksaanRevisi{first, second}
+
+	t.Run("Success: Default compares latest with previous revision", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 1).Return(model.Pemeriksaan{ID: 1}, nil).Once()
+		mockPemeriksaanRepo.On("GetRevisions", 1).Return(revisions, nil).Once()
+
+		result, err := pemeriksaanService.DiffRevisions(context.Background(), 1, model.ParamsDiffRevisi{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Dari)
+		assert.Equal(t, 2, result.Ke)
+		assert.Len(t, result.Changes, 1)
+		assert.Equal(t, "Demam", result.Changes["keluhan"].Old)
+		assert.Equal(t, "Demam tinggi", result.Changes["keluhan"].New)
+	})
+
+	t.Run("Fail: Revision does not exist", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 1).Return(model.Pemeriksaan{ID: 1}, nil).Once()
+		mockPemeriksaanRepo.On("GetRevisions", 1).Return(revisions, nil).Once()
+
+		_, err := pemeriksaanService.DiffRevisions(context.Background(), 1, model.ParamsDiffRevisi{Dari: 1, Ke: 5})
+
+		assert.ErrorIs(t, err, ErrRevisiNotFound)
+	})
+
+	t.Run("Fail: Invalid range", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 1).Return(model.Pemeriksaan{ID: 1}, nil).Once()
+		mockPemeriksaanRepo.On("GetRevisions", 1).Return(revisions, nil).Once()
+
+		_, err := pemeriksaanService.DiffRevisions(context.Background(), 1, model.ParamsDiffRevisi{Dari: 2, Ke: 1})
+
+		assert.ErrorIs(t, err, ErrRevisiRange)
+	})
+}
+
+func TestPemeriksaanService_FinalizePemeriksaan(t *testing.T) {
+	mockPemeriksaanRepo := new(MockPemeriksaanRepository)
+	mockAntrianRepo := new(MockAntrianRepository)
+	mockPetugasRepo := new(MockPetugasRepository)
+	pemeriksaanService := NewPemeriksaanService(mockPemeriksaanRepo, mockAntrianRepo, mockPetugasRepo, nil, nil, &config.Config{RecordSignatureSecret: "rahasia"})
+
+	draft := model.Pemeriksaan{
+		ID:        1,
+		AntrianID: 4,
+		Revisi:    2,
+		DokterID:  sql.NullInt64{Int64: 8, Valid: true},
+		Keluhan:   sql.NullString{String: "Demam", Valid: true},
+	}
+
+	t.Run("Success: Sign pemeriksaan and verify signature", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 1).Return(draft, nil).Once()
+		mockPemeriksaanRepo.On("Finalize", 1, 2, 8, mock.AnythingOfType("time.Time"), mock.AnythingOfType("string")).
+			Return(func(id, revisi, signerID int, at time.Time, signature string) model.Pemeriksaan {
+				signed := draft
+				signed.DitandatanganiAt = sql.NullTime{Time: at, Valid: true}
+				signed.DitandatanganiOleh = sql.NullInt64{Int64: int64(signerID), Valid: true}
+				signed.TandaTangan = sql.NullString{String: signature, Valid: true}
+				return signed
+			}, nil).Once()
+
+		result, err := pemeriksaanService.FinalizePemeriksaan(context.Background(), 1, 8)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, result.TandaTangan)
+		assert.True(t, result.TandaTangan.Valid)
+		mockPemeriksaanRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Signer is not the responsible dokter", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 1).Return(draft, nil).Once()
+
+		_, err := pemeriksaanService.FinalizePemeriksaan(context.Background(), 1, 9)
+
+		assert.ErrorIs(t, err, ErrSignerNotDokter)
+	})
+
+	t.Run("Fail: Already signed", func(t *testing.T) {
+		signed := draft
+		signed.DitandatanganiAt = sql.NullTime{Time: time.Now(), Valid: true}
+		mockPemeriksaanRepo.On("GetById", 1).Return(signed, nil).Once()
+
+		_, err := pemeriksaanService.FinalizePemeriksaan(context.Background(), 1, 8)
+
+		assert.ErrorIs(t, err, ErrPemeriksaanLocked)
+	})
+}
+
+func TestPemeriksaanService_SignatureVerification(t *testing.T) {
+	mockPemeriksaanRepo := new(MockPemeriksaanRepository)
+	mockAntrianRepo := new(MockAntrianRepository)
+	mockPetugasRepo := new(MockPetugasRepository)
+	pemeriksaanService := NewPemeriksaanService(mockPemeriksaanRepo, mockAntrianRepo, mockPetugasRepo, nil, nil, &config.Config{RecordSignatureSecret: "rahasia"})
+
+	signedAt := time.Date(2025, 8, 21, 10, 0, 0, 0, time.UTC)
+	signed := model.Pemeriksaan{
+		ID:                 1,
+		Revisi:             1,
+		Keluhan:            sql.NullString{String: "Demam", Valid: true},
+		DitandatanganiAt:   sql.NullTime{Time: signedAt, Valid: true},
+		DitandatanganiOleh: sql.NullInt64{Int64: 8, Valid: true},
+	}
+	hash, err := signPemeriksaan("rahasia", signed, 8, signedAt)
+	assert.NoError(t, err)
+	signed.TandaTangan = sql.NullString{String: hash, Valid: true}
+
+	t.Run("Success: Stored content matches signature", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 1).Return(signed, nil).Once()
+
+		result, err := pemeriksaanService.GetPemeriksaanByID(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.True(t, result.TandaTangan.Valid)
+	})
+
+	t.Run("Fail: Content changed after signing", func(t *testing.T) {
+		tampered := signed
+		tampered.Keluhan = sql.NullString{String: "Batuk", Valid: true}
+		mockPemeriksaanRepo.On("GetById", 1).Return(tampered, nil).Once()
+
+		result, err := pemeriksaanService.GetPemeriksaanByID(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.False(t, result.TandaTangan.Valid)
+	})
+}
+
+func TestPemeriksaanService_AddAddendum(t *testing.T) {
+	mockPemeriksaanRepo := new(MockPemeriksaanRepository)
+	mockAntrianRepo := new(MockAntrianRepository)
+	mockPetugasRepo := new(MockPetugasRepository)
+	pemeriksaanService := NewPemeriksaanService(mockPemeriksaanRepo, mockAntrianRepo, mockPetugasRepo, nil, nil, &config.Config{RecordSignatureSecret: "rahasia"})
+
+	req := model.CreateAddendumRequest{Isi: "Hasil lab menyusul, diagnosis dikonfirmasi"}
+
+	t.Run("Success: Add addendum to signed pemeriksaan", func(t *testing.T) {
+		signed := model.Pemeriksaan{ID: 1, DitandatanganiAt: sql.NullTime{Time: time.Now(), Valid: true}}
+		mockPemeriksaanRepo.On("GetById", 1).Return(signed, nil).Once()
+		mockPemeriksaanRepo.On("CreateAddendum", mock.MatchedBy(func(a model.PemeriksaanAddendum) bool {
+			return a.PemeriksaanID == 1 && a.Isi == req.Isi && a.DibuatOleh.Int64 == 8
+		})).Return(model.PemeriksaanAddendum{ID: 5, PemeriksaanID: 1, Isi: req.Isi}, nil).Once()
+
+		result, err := pemeriksaanService.AddAddendum(context.Background(), 1, req, 8)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, result.ID)
+		mockPemeriksaanRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Pemeriksaan not signed yet", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 2).Return(model.Pemeriksaan{ID: 2}, nil).Once()
+
+		_, err := pemeriksaanService.AddAddendum(context.Background(), 2, req, 8)
+
+		assert.ErrorIs(t, err, ErrPemeriksaanDraft)
+	})
+}
+
+func TestPemeriksaanService_GetVitalTrend(t *testing.T) {
+	mockPemeriksaanRepo := new(MockPemeriksaanRepository)
+	mockAntrianRepo := new(MockAntrianRepository)
+	mockPetugasRepo := new(MockPetugasRepository)
+	pemeriksaanService := NewPemeriksaanService(mockPemeriksaanRepo, mockAntrianRepo, mockPetugasRepo, nil, nil, &config.Config{RecordSignatureSecret: "rahasia"})
+
+	vital := func(sistolik, diastolik, nadi int) model.TandaVital {
+		return model.TandaVital{
+			Sistolik:  sql.NullInt64{Int64: int64(sistolik), Valid: true},
+			Diastolik: sql.NullInt64{Int64: int64(diastolik), Valid: true},
+			Nadi:      sql.NullInt64{Int64: int64(nadi), Valid: true},
+		}
+	}
+	riwayat := []model.Pemeriksaan{
+		{ID: 1, TanggalPemeriksaan: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), TandaVital: vital(120, 80, 72)},
+		{ID: 2, TanggalPemeriksaan: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), TandaVital: vital(150, 95, 110)},
+		{ID: 3, TanggalPemeriksaan: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	t.Run("Success: Chronological series with flags", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetVitalsByPasienID", 5, "", "").Return(riwayat, nil).Once()
+
+		result, err := pemeriksaanService.GetVitalTrend(context.Background(), 5, model.ParamsVitalTrend{})
+
+		assert.NoError(t, err)
+		assert.Len(t, result.TekananDarah, 2)
+		assert.Equal(t, "", result.TekananDarah[0].Flag)
+		assert.Equal(t, model.VitalFlagHigh, result.TekananDarah[1].Flag)
+		assert.Len(t, result.Nadi, 2)
+		assert.Equal(t, model.VitalFlagHigh, result.Nadi[1].Flag)
+		assert.Empty(t, result.Suhu)
+		mockPemeriksaanRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success: Filter by metric", func(t *testing.T) {
+		params := model.ParamsVitalTrend{From: "2025-06-01", To: "2025-08-31", Metric: model.VitalMetricNadi}
+		mockPemeriksaanRepo.On("GetVitalsByPasienID", 5, "2025-06-01", "2025-08-31").Return(riwayat, nil).Once()
+
+		result, err := pemeriksaanService.GetVitalTrend(context.Background(), 5, params)
+
+		assert.NoError(t, err)
+		assert.Nil(t, result.TekananDarah)
+		assert.Len(t, result.Nadi, 2)
+	})
+
+	t.Run("Fail: Invalid date range", func(t *testing.T) {
+		_, err := pemeriksaanService.GetVitalTrend(context.Background(), 5, model.ParamsVitalTrend{From: "2025-08-01", To: "2025-06-01"})
+
+		assert.ErrorIs(t, err, ErrInvalidDateRange)
+	})
+}
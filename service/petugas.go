@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/franklindh/simedis-api/internal/config"
 	"github.com/franklindh/simedis-api/internal/model"
@@ -18,37 +19,155 @@ var (
 	ErrPetugasConflict     = errors.New("username already exists")
 	ErrInvalidCredentials  = errors.New("invalid username or password")
 	ErrOldPasswordMismatch = errors.New("old password does not match")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrPetugasInactive     = errors.New("petugas account is inactive")
+)
+
+const (
+	StatusPetugasAktif = "aktif"
+	RolePetugasDokter  = "Dokter"
 )
 
 type PetugasService struct {
-	repo   PetugasRepository
-	config *config.Config
+	repo      PetugasRepository
+	tokenRepo TokenRepository
+	cache     *PetugasCache
+	config    *config.Config
 }
 
-func NewPetugasService(repo PetugasRepository, cfg *config.Config) *PetugasService {
-	return &PetugasService{repo: repo, config: cfg}
+func NewPetugasService(repo PetugasRepository, tokenRepo TokenRepository, cache *PetugasCache, cfg *config.Config) *PetugasService {
+	return &PetugasService{repo: repo, tokenRepo: tokenRepo, cache: cache, config: cfg}
 }
 
-func (s *PetugasService) Login(ctx context.Context, req model.LoginPetugasRequest) (string, error) {
+func (s *PetugasService) Login(ctx context.Context, req model.LoginPetugasRequest) (model.TokenResponse, error) {
 	user, err := s.repo.GetByUsername(req.Username)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
-			return "", ErrInvalidCredentials
+			return model.TokenResponse{}, ErrInvalidCredentials
 		}
-		return "", fmt.Errorf("database error: %w", err)
+		return model.TokenResponse{}, fmt.Errorf("database error: %w", err)
 	}
 
 	err = utils.VerifyPassword(req.Password, user.Password)
 	if err != nil {
-		return "", ErrInvalidCredentials
+		return model.TokenResponse{}, ErrInvalidCredentials
+	}
+
+	if user.Status != StatusPetugasAktif {
+		return model.TokenResponse{}, ErrPetugasInactive
+	}
+
+	accessToken, refreshToken, err := s.generateTokens(user)
+	if err != nil {
+		return model.TokenResponse{}, err
+	}
+
+	if _, err := s.tokenRepo.CreateRefreshToken(refreshToken); err != nil {
+		return model.TokenResponse{}, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return accessToken, nil
+}
+
+// refresh token dirotasi setiap dipakai, pemakaian ulang token lama mencabut seluruh sesi petugas
+func (s *PetugasService) RefreshToken(ctx context.Context, req model.RefreshTokenRequest) (model.TokenResponse, error) {
+	stored, err := s.tokenRepo.GetRefreshTokenByHash(utils.HashToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.TokenResponse{}, ErrInvalidRefreshToken
+		}
+		return model.TokenResponse{}, fmt.Errorf("database error: %w", err)
+	}
+
+	if stored.RevokedAt.Valid {
+		if err := s.tokenRepo.RevokeAllRefreshTokens(stored.PetugasID); err != nil {
+			return model.TokenResponse{}, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+		return model.TokenResponse{}, ErrInvalidRefreshToken
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return model.TokenResponse{}, ErrInvalidRefreshToken
+	}
+
+	user, err := s.repo.GetById(stored.PetugasID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.TokenResponse{}, ErrInvalidRefreshToken
+		}
+		return model.TokenResponse{}, fmt.Errorf("database error: %w", err)
+	}
+	if user.TokenVersion != stored.TokenVersion {
+		return model.TokenResponse{}, ErrInvalidRefreshToken
+	}
+	if user.Status != StatusPetugasAktif {
+		return model.TokenResponse{}, ErrPetugasInactive
+	}
+
+	accessToken, refreshToken, err := s.generateTokens(user)
+	if err != nil {
+		return model.TokenResponse{}, err
+	}
+
+	if _, err := s.tokenRepo.RotateRefreshToken(stored.ID, refreshToken); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.TokenResponse{}, ErrInvalidRefreshToken
+		}
+		return model.TokenResponse{}, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	return accessToken, nil
+}
+
+func (s *PetugasService) Logout(ctx context.Context, jti string, expiresAt time.Time, req model.LogoutRequest) error {
+	if err := s.tokenRepo.RevokeJTI(jti, expiresAt); err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
 	}
 
+	if req.RefreshToken != "" {
+		if err := s.tokenRepo.RevokeRefreshToken(utils.HashToken(req.RefreshToken)); err != nil {
+			return fmt.Errorf("failed to revoke refresh token: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *PetugasService) generateTokens(user model.Petugas) (model.TokenResponse, model.RefreshToken, error) {
 	jwtSecret := []byte(s.config.JWTSecret)
-	token, err := utils.SignToken(strconv.Itoa(user.ID), user.Username, user.Role, jwtSecret)
+	accessToken, err := utils.SignToken(strconv.Itoa(user.ID), user.Username, user.Role, user.TokenVersion, s.config.AccessTokenTTL, jwtSecret)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate token: %w", err)
+		return model.TokenResponse{}, model.RefreshToken{}, fmt.Errorf("failed to generate token: %w", err)
 	}
-	return token, nil
+
+	plainRefreshToken, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return model.TokenResponse{}, model.RefreshToken{}, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	refreshToken := model.RefreshToken{
+		PetugasID:    user.ID,
+		TokenHash:    utils.HashToken(plainRefreshToken),
+		TokenVersion: user.TokenVersion,
+		ExpiresAt:    time.Now().Add(s.config.RefreshTokenTTL),
+	}
+
+	return model.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: plainRefreshToken,
+		ExpiresIn:    int(s.config.AccessTokenTTL.Seconds()),
+	}, refreshToken, nil
+}
+
+// mencabut semua token petugas: access token lewat token_version, refresh token lewat revoked_at
+func (s *PetugasService) revokeSessions(id int) error {
+	defer s.cache.Invalidate(id)
+
+	if err := s.repo.IncrementTokenVersion(id); err != nil {
+		return fmt.Errorf("failed to increment token version: %w", err)
+	}
+	if err := s.tokenRepo.RevokeAllRefreshTokens(id); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
 }
 
 func (s *PetugasService) CreatePetugas(ctx context.Context, req model.CreatePetugasRequest) (model.PetugasResponse, error) {
@@ -101,6 +220,11 @@ func (s *PetugasService) GetPetugasByID(ctx context.Context, id int) (model.Petu
 
 func (s *PetugasService) UpdatePetugas(ctx context.Context, id int, req model.UpdatePetugasRequest) (model.PetugasResponse, error) {
 
+	existing, err := s.repo.GetById(id)
+	if err != nil {
+		return model.PetugasResponse{}, err
+	}
+
 	petugasUpdate := req.ToModel()
 
 	updatedPetugas, err := s.repo.Update(id, petugasUpdate)
@@ -111,12 +235,24 @@ func (s *PetugasService) UpdatePetugas(ctx context.Context, id int, req model.Up
 
 		return model.PetugasResponse{}, err
 	}
+	s.cache.Invalidate(id)
+
+	if existing.Role != updatedPetugas.Role || existing.Status != updatedPetugas.Status {
+		if err := s.revokeSessions(id); err != nil {
+			return model.PetugasResponse{}, err
+		}
+	}
 
 	return model.ToPetugasResponse(updatedPetugas), nil
 }
 
 func (s *PetugasService) DeletePetugas(ctx context.Context, id int) error {
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.cache.Invalidate(id)
+
+	return s.tokenRepo.RevokeAllRefreshTokens(id)
 }
 
 func (s *PetugasService) ChangePassword(ctx context.Context, id int, req model.ChangePasswordRequest) error {
@@ -132,5 +268,9 @@ func (s *PetugasService) ChangePassword(ctx context.Context, id int, req model.C
 	}
 
 	newHashedPassword, _ := utils.HashPassword(req.NewPassword)
-	return s.repo.UpdatePassword(id, newHashedPassword)
+	if err := s.repo.UpdatePassword(id, newHashedPassword); err != nil {
+		return err
+	}
+
+	return s.revokeSessions(id)
 }
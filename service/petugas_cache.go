@@ -0,0 +1,59 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/franklindh/simedis-api/internal/model"
+)
+
+// cache petugas untuk auth layer, TTL dibuat pendek dan entri dibuang setiap kali petugas diubah
+type PetugasCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[int]petugasCacheEntry
+}
+
+type petugasCacheEntry struct {
+	petugas   model.Petugas
+	expiresAt time.Time
+}
+
+func NewPetugasCache(ttl time.Duration) *PetugasCache {
+	return &PetugasCache{ttl: ttl, entries: make(map[int]petugasCacheEntry)}
+}
+
+func (c *PetugasCache) Get(id int) (model.Petugas, bool) {
+	if c == nil || c.ttl <= 0 {
+		return model.Petugas{}, false
+	}
+
+	c.mu.RLock()
+	entry, ok := c.entries[id]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(entry.expiresAt) {
+		return model.Petugas{}, false
+	}
+	return entry.petugas, true
+}
+
+func (c *PetugasCache) Set(petugas model.Petugas) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	c.entries[petugas.ID] = petugasCacheEntry{petugas: petugas, expiresAt: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+}
+
+func (c *PetugasCache) Invalidate(id int) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	delete(c.entries, id)
+	c.mu.Unlock()
+}
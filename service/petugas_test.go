@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/franklindh/simedis-api/internal/config"
 	"github.com/franklindh/simedis-api/internal/model"
@@ -21,7 +23,7 @@ func TestPetugasService_CreatePetugas(t *testing.T) {
 	}
 
 	mockRepo := new(MockPetugasRepository)
-	petugasService := NewPetugasService(mockRepo, cfg)
+	petugasService := NewPetugasService(mockRepo, new(MockTokenRepository), nil, cfg)
 
 	inputDTO := model.CreatePetugasRequest{
 		Username: "johndoe",
@@ -68,33 +70,39 @@ func TestPetugasService_CreatePetugas(t *testing.T) {
 func TestPetugasService_Login(t *testing.T) {
 	hashedDefaultPassword, _ := utils.HashPassword("passworddefault")
 	cfg := &config.Config{
-		JWTSecret: "secretkeyrahasia",
+		JWTSecret:       "secretkeyrahasia",
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: 24 * time.Hour,
 	}
 
 	testCases := []struct {
 		name          string
 		input         model.LoginPetugasRequest
-		setupMock     func(mockRepo *MockPetugasRepository)
+		setupMock     func(mockRepo *MockPetugasRepository, mockTokenRepo *MockTokenRepository)
 		expectedError error
 	}{
 		{
 			name:  "Success: Login successful",
 			input: model.LoginPetugasRequest{Username: "johndoe", Password: "passworddefault"},
-			setupMock: func(mockRepo *MockPetugasRepository) {
+			setupMock: func(mockRepo *MockPetugasRepository, mockTokenRepo *MockTokenRepository) {
 				userFromDB := model.Petugas{
 					ID:       1,
 					Username: "johndoe",
 					Password: hashedDefaultPassword,
 					Role:     "Dokter",
+					Status:   "aktif",
 				}
 				mockRepo.On("GetByUsername", "johndoe").Return(userFromDB, nil).Once()
+				mockTokenRepo.On("CreateRefreshToken", mock.MatchedBy(func(rt model.RefreshToken) bool {
+					return rt.PetugasID == 1 && rt.TokenHash != ""
+				})).Return(model.RefreshToken{ID: 1}, nil).Once()
 			},
 			expectedError: nil,
 		},
 		{
 			name:  "Fail: User not found",
 			input: model.LoginPetugasRequest{Username: "notfound", Password: "passworddefault"},
-			setupMock: func(mockRepo *MockPetugasRepository) {
+			setupMock: func(mockRepo *MockPetugasRepository, mockTokenRepo *MockTokenRepository) {
 				mockRepo.On("GetByUsername", "notfound").Return(model.Petugas{}, repository.ErrNotFound).Once()
 			},
 			expectedError: ErrInvalidCredentials,
@@ -102,7 +110,7 @@ func TestPetugasService_Login(t *testing.T) {
 		{
 			name:  "Fail: Wrong password",
 			input: model.LoginPetugasRequest{Username: "johndoe", Password: "wrongpassword"},
-			setupMock: func(mockRepo *MockPetugasRepository) {
+			setupMock: func(mockRepo *MockPetugasRepository, mockTokenRepo *MockTokenRepository) {
 				userFromDB := model.Petugas{
 					ID:       1,
 					Username: "johndoe",
@@ -112,32 +120,51 @@ func TestPetugasService_Login(t *testing.T) {
 			},
 			expectedError: ErrInvalidCredentials,
 		},
+		{
+			name:  "Fail: Petugas nonaktif",
+			input: model.LoginPetugasRequest{Username: "johndoe", Password: "passworddefault"},
+			setupMock: func(mockRepo *MockPetugasRepository, mockTokenRepo *MockTokenRepository) {
+				userFromDB := model.Petugas{
+					ID:       1,
+					Username: "johndoe",
+					Password: hashedDefaultPassword,
+					Role:     "Dokter",
+					Status:   "nonaktif",
+				}
+				mockRepo.On("GetByUsername", "johndoe").Return(userFromDB, nil).Once()
+			},
+			expectedError: ErrPetugasInactive,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			mockRepo := new(MockPetugasRepository)
-			tc.setupMock(mockRepo)
-			petugasService := NewPetugasService(mockRepo, cfg)
+			mockTokenRepo := new(MockTokenRepository)
+			tc.setupMock(mockRepo, mockTokenRepo)
+			petugasService := NewPetugasService(mockRepo, mockTokenRepo, nil, cfg)
 
-			token, err := petugasService.Login(context.Background(), tc.input)
+			tokens, err := petugasService.Login(context.Background(), tc.input)
 
 			if tc.expectedError != nil {
 				assert.Error(t, err)
 				assert.True(t, errors.Is(err, tc.expectedError))
-				assert.Empty(t, token)
+				assert.Empty(t, tokens.AccessToken)
 			} else {
 				assert.NoError(t, err)
-				assert.NotEmpty(t, token)
+				assert.NotEmpty(t, tokens.AccessToken)
+				assert.NotEmpty(t, tokens.RefreshToken)
+				assert.Equal(t, 900, tokens.ExpiresIn)
 			}
 			mockRepo.AssertExpectations(t)
+			mockTokenRepo.AssertExpectations(t)
 		})
 	}
 }
 
 func TestPetugasService_GetPetugasByID(t *testing.T) {
 	mockRepo := new(MockPetugasRepository)
-	petugasService := NewPetugasService(mockRepo, &config.Config{})
+	petugasService := NewPetugasService(mockRepo, new(MockTokenRepository), nil, &config.Config{})
 
 	t.Run("Success: Petugas found", func(t *testing.T) {
 		mockPetugas := model.Petugas{ID: 1, Username: "testuser", Nama: "Test User"}
@@ -164,16 +191,18 @@ func TestPetugasService_GetPetugasByID(t *testing.T) {
 }
 
 func TestPetugasService_UpdatePetugas(t *testing.T) {
-	mockRepo := new(MockPetugasRepository)
-	petugasService := NewPetugasService(mockRepo, &config.Config{})
-
 	inputDTO := model.UpdatePetugasRequest{
 		Nama:   "John Doe Updated",
 		Status: "nonaktif",
 		Role:   "Admin",
 	}
 
-	t.Run("Success: Petugas updated", func(t *testing.T) {
+	t.Run("Success: Petugas updated and sessions revoked on status change", func(t *testing.T) {
+		mockRepo := new(MockPetugasRepository)
+		mockTokenRepo := new(MockTokenRepository)
+		petugasService := NewPetugasService(mockRepo, mockTokenRepo, nil, &config.Config{})
+
+		existing := model.Petugas{ID: 1, Nama: "John Doe", Status: "aktif", Role: "Admin"}
 		mockReturnPetugas := model.Petugas{
 			ID:     1,
 			Nama:   "John Doe Updated",
@@ -181,7 +210,10 @@ func TestPetugasService_UpdatePetugas(t *testing.T) {
 			Role:   "Admin",
 		}
 
+		mockRepo.On("GetById", 1).Return(existing, nil).Once()
 		mockRepo.On("Update", 1, mock.AnythingOfType("model.Petugas")).Return(mockReturnPetugas, nil).Once()
+		mockRepo.On("IncrementTokenVersion", 1).Return(nil).Once()
+		mockTokenRepo.On("RevokeAllRefreshTokens", 1).Return(nil).Once()
 
 		result, err := petugasService.UpdatePetugas(context.Background(), 1, inputDTO)
 
@@ -189,12 +221,61 @@ func TestPetugasService_UpdatePetugas(t *testing.T) {
 		assert.Equal(t, "John Doe Updated", result.Nama)
 		assert.Equal(t, "nonaktif", result.Status)
 		mockRepo.AssertExpectations(t)
+		mockTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success: Sessions kept when role and status unchanged", func(t *testing.T) {
+		mockRepo := new(MockPetugasRepository)
+		mockTokenRepo := new(MockTokenRepository)
+		petugasService := NewPetugasService(mockRepo, mockTokenRepo, nil, &config.Config{})
+
+		existing := model.Petugas{ID: 1, Nama: "John Doe", Status: "nonaktif", Role: "Admin"}
+		mockReturnPetugas := model.Petugas{ID: 1, Nama: "John Doe Updated", Status: "nonaktif", Role: "Admin"}
+
+		mockRepo.On("GetById", 1).Return(existing, nil).Once()
+		mockRepo.On("Update", 1, mock.AnythingOfType("model.Petugas")).Return(mockReturnPetugas, nil).Once()
+
+		_, err := petugasService.UpdatePetugas(context.Background(), 1, inputDTO)
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "IncrementTokenVersion", mock.Anything)
+		mockTokenRepo.AssertNotCalled(t, "RevokeAllRefreshTokens", mock.Anything)
+	})
+
+	t.Run("Success: Cached petugas invalidated", func(t *testing.T) {
+		mockRepo := new(MockPetugasRepository)
+		cache := NewPetugasCache(time.Minute)
+		petugasService := NewPetugasService(mockRepo, new(MockTokenRepository), cache, &config.Config{})
+
+		existing := model.Petugas{ID: 1, Nama: "John Doe", Status: "nonaktif", Role: "Admin"}
+		cache.Set(existing)
+		mockRepo.On("GetById", 1).Return(existing, nil).Once()
+		mockRepo.On("Update", 1, mock.AnythingOfType("model.Petugas")).Return(model.Petugas{ID: 1, Nama: "John Doe Updated", Status: "nonaktif", Role: "Admin"}, nil).Once()
+
+		_, err := petugasService.UpdatePetugas(context.Background(), 1, inputDTO)
+
+		assert.NoError(t, err)
+		_, cached := cache.Get(1)
+		assert.False(t, cached)
+	})
+
+	t.Run("Fail: Petugas not found", func(t *testing.T) {
+		mockRepo := new(MockPetugasRepository)
+		petugasService := NewPetugasService(mockRepo, new(MockTokenRepository), nil, &config.Config{})
+
+		mockRepo.On("GetById", 99).Return(model.Petugas{}, repository.ErrNotFound).Once()
+
+		_, err := petugasService.UpdatePetugas(context.Background(), 99, inputDTO)
+
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, repository.ErrNotFound))
+		mockRepo.AssertExpectations(t)
 	})
 }
 
 func TestPetugasService_GetAllPetugas(t *testing.T) {
 	mockRepo := new(MockPetugasRepository)
-	petugasService := NewPetugasService(mockRepo, &config.Config{})
+	petugasService := NewPetugasService(mockRepo, new(MockTokenRepository), nil, &config.Config{})
 	params := repository.ParamsGetAllPetugas{Page: 1, PageSize: 5}
 
 	t.Run("Success: Get all petugas", func(t *testing.T) {
@@ -217,13 +298,16 @@ func TestPetugasService_GetAllPetugas(t *testing.T) {
 
 func TestPetugasService_DeletePetugas(t *testing.T) {
 	mockRepo := new(MockPetugasRepository)
-	petugasService := NewPetugasService(mockRepo, &config.Config{})
+	mockTokenRepo := new(MockTokenRepository)
+	petugasService := NewPetugasService(mockRepo, mockTokenRepo, nil, &config.Config{})
 
 	t.Run("Success: Petugas deleted", func(t *testing.T) {
 		mockRepo.On("Delete", 1).Return(nil).Once()
+		mockTokenRepo.On("RevokeAllRefreshTokens", 1).Return(nil).Once()
 		err := petugasService.DeletePetugas(context.Background(), 1)
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
+		mockTokenRepo.AssertExpectations(t)
 	})
 
 	t.Run("Fail: Petugas not found", func(t *testing.T) {
@@ -250,24 +334,28 @@ func TestPetugasService_ChangePassword(t *testing.T) {
 	t.Run("Success: Change password successfully", func(t *testing.T) {
 
 		mockRepo := new(MockPetugasRepository)
+		mockTokenRepo := new(MockTokenRepository)
 		cfg := &config.Config{}
-		service := NewPetugasService(mockRepo, cfg)
+		service := NewPetugasService(mockRepo, mockTokenRepo, nil, cfg)
 
 		mockPetugas := model.Petugas{ID: petugasID, Password: hashedOldPassword}
 		mockRepo.On("GetById", petugasID).Return(mockPetugas, nil).Once()
 		mockRepo.On("UpdatePassword", petugasID, mock.AnythingOfType("string")).Return(nil).Once()
+		mockRepo.On("IncrementTokenVersion", petugasID).Return(nil).Once()
+		mockTokenRepo.On("RevokeAllRefreshTokens", petugasID).Return(nil).Once()
 
 		err := service.ChangePassword(context.Background(), petugasID, req)
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
+		mockTokenRepo.AssertExpectations(t)
 	})
 
 	t.Run("Fail: Old password does not match", func(t *testing.T) {
 
 		mockRepo := new(MockPetugasRepository)
 		cfg := &config.Config{}
-		service := NewPetugasService(mockRepo, cfg)
+		service := NewPetugasService(mockRepo, new(MockTokenRepository), nil, cfg)
 
 		mockPetugas := model.Petugas{ID: petugasID, Password: "hash_yang_berbeda"}
 		mockRepo.On("GetById", petugasID).Return(mockPetugas, nil).Once()
@@ -283,7 +371,7 @@ func TestPetugasService_ChangePassword(t *testing.T) {
 
 		mockRepo := new(MockPetugasRepository)
 		cfg := &config.Config{}
-		service := NewPetugasService(mockRepo, cfg)
+		service := NewPetugasService(mockRepo, new(MockTokenRepository), nil, cfg)
 
 		mockRepo.On("GetById", petugasID).Return(model.Petugas{}, repository.ErrNotFound).Once()
 
@@ -294,3 +382,99 @@ func TestPetugasService_ChangePassword(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestPetugasService_RefreshToken(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret:       "secretkeyrahasia",
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: 24 * time.Hour,
+	}
+	plainToken := "refresh-token-lama"
+	tokenHash := utils.HashToken(plainToken)
+	req := model.RefreshTokenRequest{RefreshToken: plainToken}
+
+	t.Run("Success: Refresh token rotated", func(t *testing.T) {
+		mockRepo := new(MockPetugasRepository)
+		mockTokenRepo := new(MockTokenRepository)
+		service := NewPetugasService(mockRepo, mockTokenRepo, nil, cfg)
+
+		stored := model.RefreshToken{ID: 7, PetugasID: 1, TokenHash: tokenHash, TokenVersion: 2, ExpiresAt: time.Now().Add(time.Hour)}
+		mockTokenRepo.On("GetRefreshTokenByHash", tokenHash).Return(stored, nil).Once()
+		mockRepo.On("GetById", 1).Return(model.Petugas{ID: 1, Username: "johndoe", Role: "Dokter", Status: "aktif", TokenVersion: 2}, nil).Once()
+		mockTokenRepo.On("RotateRefreshToken", 7, mock.MatchedBy(func(rt model.RefreshToken) bool {
+			return rt.PetugasID == 1 && rt.TokenVersion == 2 && rt.TokenHash != tokenHash
+		})).Return(model.RefreshToken{ID: 8}, nil).Once()
+
+		tokens, err := service.RefreshToken(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.NotEqual(t, plainToken, tokens.RefreshToken)
+		mockRepo.AssertExpectations(t)
+		mockTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Reused refresh token revokes all sessions", func(t *testing.T) {
+		mockRepo := new(MockPetugasRepository)
+		mockTokenRepo := new(MockTokenRepository)
+		service := NewPetugasService(mockRepo, mockTokenRepo, nil, cfg)
+
+		stored := model.RefreshToken{ID: 7, PetugasID: 1, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: sql.NullTime{Time: time.Now(), Valid: true}}
+		mockTokenRepo.On("GetRefreshTokenByHash", tokenHash).Return(stored, nil).Once()
+		mockTokenRepo.On("RevokeAllRefreshTokens", 1).Return(nil).Once()
+
+		_, err := service.RefreshToken(context.Background(), req)
+
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, ErrInvalidRefreshToken))
+		mockTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Token version changed", func(t *testing.T) {
+		mockRepo := new(MockPetugasRepository)
+		mockTokenRepo := new(MockTokenRepository)
+		service := NewPetugasService(mockRepo, mockTokenRepo, nil, cfg)
+
+		stored := model.RefreshToken{ID: 7, PetugasID: 1, TokenVersion: 1, ExpiresAt: time.Now().Add(time.Hour)}
+		mockTokenRepo.On("GetRefreshTokenByHash", tokenHash).Return(stored, nil).Once()
+		mockRepo.On("GetById", 1).Return(model.Petugas{ID: 1, TokenVersion: 2}, nil).Once()
+
+		_, err := service.RefreshToken(context.Background(), req)
+
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, ErrInvalidRefreshToken))
+		mockTokenRepo.AssertNotCalled(t, "RotateRefreshToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Refresh token expired", func(t *testing.T) {
+		mockRepo := new(MockPetugasRepository)
+		mockTokenRepo := new(MockTokenRepository)
+		service := NewPetugasService(mockRepo, mockTokenRepo, nil, cfg)
+
+		stored := model.RefreshToken{ID: 7, PetugasID: 1, ExpiresAt: time.Now().Add(-time.Minute)}
+		mockTokenRepo.On("GetRefreshTokenByHash", tokenHash).Return(stored, nil).Once()
+
+		_, err := service.RefreshToken(context.Background(), req)
+
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, ErrInvalidRefreshToken))
+		mockRepo.AssertNotCalled(t, "GetById", mock.Anything)
+	})
+}
+
+func TestPetugasService_Logout(t *testing.T) {
+	mockRepo := new(MockPetugasRepository)
+	mockTokenRepo := new(MockTokenRepository)
+	service := NewPetugasService(mockRepo, mockTokenRepo, nil, &config.Config{})
+	expiresAt := time.Now().Add(10 * time.Minute)
+
+	t.Run("Success: Revoke access and refresh token", func(t *testing.T) {
+		mockTokenRepo.On("RevokeJTI", "jti-1", expiresAt).Return(nil).Once()
+		mockTokenRepo.On("RevokeRefreshToken", utils.HashToken("refresh")).Return(nil).Once()
+
+		err := service.Logout(context.Background(), "jti-1", expiresAt, model.LogoutRequest{RefreshToken: "refresh"})
+
+		assert.NoError(t, err)
+		mockTokenRepo.AssertExpectations(t)
+	})
+}
@@ -11,7 +11,8 @@ import (
 )
 
 var (
-	ErrPoliConflict = errors.New("data with that name already exists")
+	ErrPoliConflict     = errors.New("data with that name already exists")
+	ErrPoliKodeConflict = errors.New("kode antrian already used by another poli")
 )
 
 type PoliService struct {
@@ -29,6 +30,9 @@ func (s *PoliService) CreatePoli(ctx context.Context, req model.CreatePoliReques
 	createdPoli, err := s.repo.Create(poliInput)
 	if err != nil {
 		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			if pgErr.ConstraintName == "idx_poli_kode_antrian" {
+				return model.PoliResponse{}, ErrPoliKodeConflict
+			}
 			return model.PoliResponse{}, ErrPoliConflict
 		}
 		return model.PoliResponse{}, fmt.Errorf("failed to create poli: %w", err)
@@ -68,6 +72,12 @@ func (s *PoliService) UpdatePoli(ctx context.Context, id int, req model.UpdatePo
 
 	updatedPoli, err := s.repo.Update(id, poliUpdate)
 	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			if pgErr.ConstraintName == "idx_poli_kode_antrian" {
+				return model.PoliResponse{}, ErrPoliKodeConflict
+			}
+			return model.PoliResponse{}, ErrPoliConflict
+		}
 		return model.PoliResponse{}, err
 	}
 
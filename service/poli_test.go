@@ -38,6 +38,15 @@ func TestPoliService_CreatePoli(t *testing.T) {
 			},
 			expectedError: ErrPoliConflict,
 		},
+		{
+			name:     "Fail: Kode antrian conflict",
+			inputDTO: model.CreatePoliRequest{Name: "Poli Usia Lanjut", Status: "aktif", KodeAntrian: "UM"},
+			setupMock: func(mockRepo *MockPoliRepository, poli model.Poli) {
+				pgErr := &pgconn.PgError{Code: "23505", ConstraintName: "idx_poli_kode_antrian"}
+				mockRepo.On("Create", poli).Return(model.Poli{}, pgErr).Once()
+			},
+			expectedError: ErrPoliKodeConflict,
+		},
 		{
 			name:     "Fail: Generic database error",
 			inputDTO: model.CreatePoliRequest{Name: "Poli Jantung", Status: "aktif"},
@@ -218,6 +227,26 @@ func TestPoliService_UpdatePoli(t *testing.T) {
 			},
 			expectedError: ErrPoliConflict,
 		},
+		{
+			name:     "Fail: Concurrent name conflict on update",
+			inputID:  1,
+			inputDTO: model.UpdatePoliRequest{Name: "Poli Gigi", Status: "aktif"},
+			setupMock: func(mockRepo *MockPoliRepository) {
+				mockRepo.On("FindByName", "Poli Gigi").Return(model.Poli{}, repository.ErrNotFound).Once()
+				mockRepo.On("Update", 1, mock.AnythingOfType("model.Poli")).Return(model.Poli{}, &pgconn.PgError{Code: "23505", ConstraintName: "poli_nama_poli_key"}).Once()
+			},
+			expectedError: ErrPoliConflict,
+		},
+		{
+			name:     "Fail: Kode antrian conflict on update",
+			inputID:  1,
+			inputDTO: model.UpdatePoliRequest{Name: "Poli Gigi", Status: "aktif"},
+			setupMock: func(mockRepo *MockPoliRepository) {
+				mockRepo.On("FindByName", "Poli Gigi").Return(model.Poli{}, repository.ErrNotFound).Once()
+				mockRepo.On("Update", 1, mock.AnythingOfType("model.Poli")).Return(model.Poli{}, &pgconn.PgError{Code: "23505", ConstraintName: "idx_poli_kode_antrian"}).Once()
+			},
+			expectedError: ErrPoliKodeConflict,
+		},
 	}
 
 	for _, tc := range testCases {
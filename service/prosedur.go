@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/franklindh/simedis-api/internal/icdimport"
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/pkg/utils/pagination"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrProsedurConflict = errors.New("procedure code already exists")
+)
+
+type ProsedurRepository interface {
+	Create(prosedur model.Prosedur) (model.Prosedur, error)
+	GetAll(params repository.ParamsGetAllProsedur) ([]model.Prosedur, pagination.Metadata, error)
+	GetByID(id int) (model.Prosedur, error)
+	Update(id int, prosedur model.Prosedur) (model.Prosedur, error)
+	Delete(id int) error
+	GetByKodes(kodes []string) ([]model.Prosedur, error)
+	Upsert(list []model.Prosedur) error
+	Search(params model.ParamsSearchKatalog) ([]model.Prosedur, error)
+}
+
+type ProsedurService struct {
+	repo ProsedurRepository
+}
+
+func NewProsedurService(repo ProsedurRepository) *ProsedurService {
+	return &ProsedurService{repo: repo}
+}
+
+func (s *ProsedurService) CreateProsedur(ctx context.Context, req model.CreateProsedurRequest) (model.ProsedurResponse, error) {
+	created, err := s.repo.Create(req.ToModel())
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return model.ProsedurResponse{}, ErrProsedurConflict
+		}
+		return model.ProsedurResponse{}, fmt.Errorf("failed to create procedure: %w", err)
+	}
+	return model.ToProsedurResponse(created), nil
+}
+
+func (s *ProsedurService) GetAllProsedur(ctx context.Context, params repository.ParamsGetAllProsedur) ([]model.ProsedurResponse, pagination.Metadata, error) {
+	list, metadata, err := s.repo.GetAll(params)
+	if err != nil {
+		return nil, metadata, err
+	}
+	return model.ToProsedurResponseList(list), metadata, nil
+}
+
+func (s *ProsedurService) GetProsedurByID(ctx context.Context, id int) (model.ProsedurResponse, error) {
+	prosedur, err := s.repo.GetByID(id)
+	if err != nil {
+		return model.ProsedurResponse{}, err
+	}
+	return model.ToProsedurDetailResponse(prosedur), nil
+}
+
+func (s *ProsedurService) SearchProsedur(ctx context.Context, params model.ParamsSearchKatalog) ([]model.ProsedurResponse, error) {
+	params.Query = strings.TrimSpace(params.Query)
+	if params.Query == "" {
+		return []model.ProsedurResponse{}, nil
+	}
+	if params.Limit == 0 {
+		params.Limit = defaultSearchLimit
+	}
+	list, err := s.repo.Search(params)
+	if err != nil {
+		return nil, err
+	}
+	return model.ToProsedurResponseList(list), nil
+}
+
+func (s *ProsedurService) UpdateProsedur(ctx context.Context, id int, req model.UpdateProsedurRequest) (model.ProsedurResponse, error) {
+	updated, err := s.repo.Update(id, req.ToModel())
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return model.ProsedurResponse{}, ErrProsedurConflict
+		}
+		return model.ProsedurResponse{}, err
+	}
+	return model.ToProsedurResponse(updated), nil
+}
+
+func (s *ProsedurService) DeleteProsedur(ctx context.Context, id int) error {
+	return s.repo.Delete(id)
+}
+
+// impor katalog ICD-9-CM dari CSV atau ClaML, aturannya sama dengan ImportIcd. Bab dan blok
+// hanya disimpan sebagai kode pada prosedur
+func (s *ProsedurService) ImportProsedur(ctx context.Context, source io.Reader, format string, dryRun bool) (model.ImportReport, error) {
+	parsed, err := parseKatalog(source, format)
+	if err != nil {
+		return model.ImportReport{}, err
+	}
+	report := newImportReport(parsed, dryRun)
+
+	existingList, err := s.repo.GetByKodes(importKodes(parsed.Entries))
+	if err != nil {
+		return model.ImportReport{}, err
+	}
+	existing := make(map[string]model.Prosedur, len(existingList))
+	for _, p := range existingList {
+		existing[strings.ToUpper(p.KodeProsedur)] = p
+	}
+
+	var changed []model.Prosedur
+	for _, e := range parsed.Entries {
+		prosedur := prosedurFromImport(e)
+		current, found := existing[e.Kode]
+		// file tanpa kolom status tidak mengubah status kode yang sudah ada
+		if found && e.Status == "" {
+			prosedur.Status = current.Status
+		}
+		switch {
+		case !found:
+			report.Inserted++
+		case prosedurImportEqual(current, prosedur):
+			report.Unchanged++
+			continue
+		default:
+			prosedur.KodeProsedur = current.KodeProsedur
+			report.Updated++
+		}
+		changed = append(changed, prosedur)
+	}
+
+	if dryRun {
+		return report, nil
+	}
+	if err := s.repo.Upsert(changed); err != nil {
+		return model.ImportReport{}, fmt.Errorf("failed to import procedure: %w", err)
+	}
+	return report, nil
+}
+
+func prosedurFromImport(e icdimport.Entry) model.Prosedur {
+	return model.Prosedur{
+		KodeProsedur: e.Kode,
+		NamaProsedur: e.Nama,
+		Deskripsi:    nullString(e.Deskripsi),
+		Status:       importStatus(e),
+		Bab:          nullString(e.Bab),
+		Blok:         nullString(e.Blok),
+		KodeInduk:    nullString(e.KodeInduk),
+	}
+}
+
+func prosedurImportEqual(current, imported model.Prosedur) bool {
+	return !current.DeletedAt.Valid &&
+		current.NamaProsedur == imported.NamaProsedur &&
+		current.Deskripsi == imported.Deskripsi &&
+		current.Status == imported.Status &&
+		current.Bab == imported.Bab &&
+		current.Blok == imported.Blok &&
+		current.KodeInduk == imported.KodeInduk
+}
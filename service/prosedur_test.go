@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/franklindh/simedis-api/internal/icdimport"
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/pkg/utils/pagination"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProsedurRepository struct {
+	mock.Mock
+}
+
+var _ ProsedurRepository = (*MockProsedurRepository)(nil)
+
+func (m *MockProsedurRepository) Create(prosedur model.Prosedur) (model.Prosedur, error) {
+	args := m.Called(prosedur)
+	return args.Get(0).(model.Prosedur), args.Error(1)
+}
+func (m *MockProsedurRepository) GetAll(params repository.ParamsGetAllProsedur) ([]model.Prosedur, pagination.Metadata, error) {
+	args := m.Called(params)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(pagination.Metadata), args.Error(2)
+	}
+	return args.Get(0).([]model.Prosedur), args.Get(1).(pagination.Metadata), args.Error(2)
+}
+func (m *MockProsedurRepository) GetByID(id int) (model.Prosedur, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.Prosedur), args.Error(1)
+}
+func (m *MockProsedurRepository) Update(id int, prosedur model.Prosedur) (model.Prosedur, error) {
+	args := m.Called(id, prosedur)
+	return args.Get(0).(model.Prosedur), args.Error(1)
+}
+func (m *MockProsedurRepository) Delete(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+func (m *MockProsedurRepository) GetByKodes(kodes []string) ([]model.Prosedur, error) {
+	args := m.Called(kodes)
+	return args.Get(0).([]model.Prosedur), args.Error(1)
+}
+func (m *MockProsedurRepository) Upsert(list []model.Prosedur) error {
+	args := m.Called(list)
+	return args.Error(0)
+}
+func (m *MockProsedurRepository) Search(params model.ParamsSearchKatalog) ([]model.Prosedur, error) {
+	args := m.Called(params)
+	return args.Get(0).([]model.Prosedur), args.Error(1)
+}
+
+func TestProsedurService_CreateProsedur(t *testing.T) {
+	mockRepo := new(MockProsedurRepository)
+	service := NewProsedurService(mockRepo)
+	req := model.CreateProsedurRequest{KodeProsedur: "96.04", NamaProsedur: "Pemasangan pipa endotrakeal", Status: "aktif"}
+
+	t.Run("Success: Create procedure", func(t *testing.T) {
+		createdModel := req.ToModel()
+		createdModel.ID = 1
+		mockRepo.On("Create", mock.AnythingOfType("model.Prosedur")).Return(createdModel, nil).Once()
+
+		result, err := service.CreateProsedur(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "96.04", result.KodeProsedur)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Procedure code conflict", func(t *testing.T) {
+		pgErr := &pgconn.PgError{Code: "23505"}
+		mockRepo.On("Create", mock.AnythingOfType("model.Prosedur")).Return(model.Prosedur{}, pgErr).Once()
+
+		_, err := service.CreateProsedur(context.Background(), req)
+
+		assert.True(t, errors.Is(err, ErrProsedurConflict))
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestProsedurService_GetProsedurByID(t *testing.T) {
+	mockRepo := new(MockProsedurRepository)
+	service := NewProsedurService(mockRepo)
+
+	t.Run("Success: Detail includes children", func(t *testing.T) {
+		mockModel := model.Prosedur{
+			ID: 1, KodeProsedur: "47.0", NamaProsedur: "Apendektomi",
+			Turunan: []model.Prosedur{{ID: 2, KodeProsedur: "47.01", NamaProsedur: "Apendektomi laparoskopik"}},
+		}
+		mockRepo.On("GetByID", 1).Return(mockModel, nil).Once()
+
+		result, err := service.GetProsedurByID(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Turunan, 1)
+		assert.Equal(t, "47.01", result.Turunan[0].KodeProsedur)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Procedure not found", func(t *testing.T) {
+		mockRepo.On("GetByID", 99).Return(model.Prosedur{}, repository.ErrNotFound).Once()
+
+		_, err := service.GetProsedurByID(context.Background(), 99)
+
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestProsedurService_ImportProsedur(t *testing.T) {
+	csvFile := "kode_prosedur,nama_prosedur,bab,status\n" +
+		"47.0,Apendektomi,9,aktif\n" +
+		"47.01,Apendektomi laparoskopik,,\n" +
+		"96.04,Pemasangan pipa endotrakeal,16,\n" +
+		"47.0,Duplikat,9,\n"
+	existing := []model.Prosedur{
+		{ID: 1, KodeProsedur: "96.04", NamaProsedur: "Intubasi", Status: "nonaktif"},
+	}
+
+	t.Run("Success: Upsert by kode with parent code", func(t *testing.T) {
+		mockRepo := new(MockProsedurRepository)
+		service := NewProsedurService(mockRepo)
+
+		var saved []model.Prosedur
+		mockRepo.On("GetByKodes", []string{"47.0", "47.01", "96.04"}).Return(existing, nil).Once()
+		mockRepo.On("Upsert", mock.AnythingOfType("[]model.Prosedur")).Run(func(args mock.Arguments) {
+			saved = args.Get(0).([]model.Prosedur)
+		}).Return(nil).Once()
+
+		report, err := service.ImportProsedur(context.Background(), strings.NewReader(csvFile), icdimport.FormatCSV, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, report.Total)
+		assert.Equal(t, 2, report.Inserted)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, 1, report.Skipped)
+		assert.Len(t, saved, 3)
+		assert.Equal(t, "47.0", saved[1].KodeInduk.String)
+		assert.Equal(t, "9", saved[1].Bab.String)
+		assert.Equal(t, "nonaktif", saved[2].Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success: Dry run does not write", func(t *testing.T) {
+		mockRepo := new(MockProsedurRepository)
+		service := NewProsedurService(mockRepo)
+		mockRepo.On("GetByKodes", mock.Anything).Return([]model.Prosedur{}, nil).Once()
+
+		report, err := service.ImportProsedur(context.Background(), strings.NewReader(csvFile), icdimport.FormatCSV, true)
+
+		assert.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 3, report.Inserted)
+		mockRepo.AssertNotCalled(t, "Upsert", mock.Anything)
+	})
+}
+
+func TestProsedurService_SearchProsedur(t *testing.T) {
+	mockRepo := new(MockProsedurRepository)
+	service := NewProsedurService(mockRepo)
+
+	t.Run("Success: Default limit and trimmed query", func(t *testing.T) {
+		mockRepo.On("Search", model.ParamsSearchKatalog{Query: "apendektomi", Limit: 20}).
+			Return([]model.Prosedur{{ID: 1, KodeProsedur: "47.0", NamaProsedur: "Apendektomi"}}, nil).Once()
+
+		result, err := service.SearchProsedur(context.Background(), model.ParamsSearchKatalog{Query: " apendektomi "})
+
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+		mockRepo.AssertExpectations(t)
+	})
+}
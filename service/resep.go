@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/pkg/utils/pagination"
+)
+
+var (
+	ErrObatInvalid       = errors.New("obat not found or inactive")
+	ErrResepObatDuplikat = errors.New("the same obat cannot be prescribed twice in one resep")
+	ErrResepDiserahkan   = errors.New("resep has already been dispensed")
+)
+
+type ResepService struct {
+	repo            ResepRepository
+	obatRepo        ObatRepository
+	stokRepo        StokRepository
+	alergiRepo      AlergiRepository
+	interaksiRepo   InteraksiObatRepository
+	pemeriksaanRepo PemeriksaanRepository
+	audit           AuditRecorder
+}
+
+func NewResepService(repo ResepRepository, obatRepo ObatRepository, stokRepo StokRepository, alergiRepo AlergiRepository, interaksiRepo InteraksiObatRepository, pemeriksaanRepo PemeriksaanRepository, audit AuditRecorder) *ResepService {
+	return &ResepService{
+		repo:            repo,
+		obatRepo:        obatRepo,
+		stokRepo:        stokRepo,
+		alergiRepo:      alergiRepo,
+		interaksiRepo:   interaksiRepo,
+		pemeriksaanRepo: pemeriksaanRepo,
+		audit:           audit,
+	}
+}
+
+// resep ditulis oleh dokter yang login. Rekam medis yang sudah ditandatangani tetap bisa
+// diberi resep karena resep tidak mengubah isi rekam medis
+func (s *ResepService) CreateResep(ctx context.Context, pemeriksaanID int, req model.CreateResepRequest, dokterID int) (model.ResepResponse, error) {
+	pemeriksaan, err := s.pemeriksaanRepo.GetById(pemeriksaanID)
+	if err != nil {
+		return model.ResepResponse{}, err
+	}
+	if pemeriksaan.IsVoid() {
+		return model.ResepResponse{}, ErrPemeriksaanVoided
+	}
+
+	resep := req.ToModel(pemeriksaanID)
+	obatList, err := s.validateObat(resep.Items)
+	if err != nil {
+		return model.ResepResponse{}, err
+	}
+	resep.PasienID = pemeriksaan.Antrian.PasienID
+	resep.DokterID = dokterID
+
+	peringatan, err := s.cekPeringatan(resep, obatList)
+	if err != nil {
+		return model.ResepResponse{}, err
+	}
+	if belum := peringatanBelumDikonfirmasi(peringatan, req.KonfirmasiPeringatan); len(belum) > 0 {
+		return model.ResepResponse{}, &PeringatanResepError{Peringatan: belum}
+	}
+	for _, p := range peringatan {
+		resep.Peringatan = append(resep.Peringatan, p.ToModel())
+	}
+
+	created, err := s.repo.Create(resep)
+	if err != nil {
+		return model.ResepResponse{}, fmt.Errorf("failed to create resep: %w", err)
+	}
+
+	resp := model.ToResepResponse(created)
+	recordAudit(ctx, s.audit, model.AuditActionCreate, model.AuditEntityResep, created.ID, nil, resp)
+	return resp, nil
+}
+
+func (s *ResepService) GetResepByID(ctx context.Context, id int) (model.ResepResponse, error) {
+	resep, err := s.repo.GetByID(id)
+	if err != nil {
+		return model.ResepResponse{}, err
+	}
+	return model.ToResepResponse(resep), nil
+}
+
+func (s *ResepService) GetAllByPemeriksaanID(ctx context.Context, pemeriksaanID int) ([]model.ResepResponse, error) {
+	list, err := s.repo.GetAllByPemeriksaanID(pemeriksaanID)
+	if err != nil {
+		return nil, err
+	}
+	return model.ToResepResponseList(list), nil
+}
+
+func (s *ResepService) GetAllResep(ctx context.Context, params repository.ParamsGetAllResep) ([]model.ResepResponse, pagination.Metadata, error) {
+	list, metadata, err := s.repo.GetAll(params)
+	if err != nil {
+		return nil, metadata, err
+	}
+	return model.ToResepResponseList(list), metadata, nil
+}
+
+// menandai resep sudah diserahkan ke pasien oleh petugas apotek. Stok setiap obat dikurangi
+// dari batch yang paling cepat kedaluwarsa, resep tidak bisa diserahkan bila stok kurang
+func (s *ResepService) SerahkanResep(ctx context.Context, id int, actorID int) (model.ResepResponse, error) {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return model.ResepResponse{}, err
+	}
+	if existing.Status != model.StatusResepMenunggu {
+		return model.ResepResponse{}, ErrResepDiserahkan
+	}
+
+	now := time.Now()
+	obatIDs := make([]int, 0, len(existing.Items))
+	for _, item := range existing.Items {
+		obatIDs = append(obatIDs, item.ObatID)
+	}
+	batches, err := s.stokRepo.GetBatchTersedia(obatIDs, now)
+	if err != nil {
+		return model.ResepResponse{}, err
+	}
+	mutasi, err := alokasiFEFO(existing, batches, actorID)
+	if err != nil {
+		return model.ResepResponse{}, err
+	}
+
+	updated, err := s.repo.Serahkan(id, actorID, now, mutasi)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			// sudah diserahkan request lain di antara GetByID dan Serahkan
+			return model.ResepResponse{}, ErrResepDiserahkan
+		}
+		if errors.Is(err, repository.ErrStokBerubah) {
+			return model.ResepResponse{}, fmt.Errorf("%w: %v", ErrStokTidakCukup, err)
+		}
+		return model.ResepResponse{}, err
+	}
+
+	resp := model.ToResepResponse(updated)
+	recordAudit(ctx, s.audit, model.AuditActionUpdate, model.AuditEntityResep, id, model.ToResepResponse(existing), resp)
+	return resp, nil
+}
+
+// setiap obat harus ada, aktif, dan tidak diresepkan dua kali dalam resep yang sama
+func (s *ResepService) validateObat(items []model.ResepItem) ([]model.Obat, error) {
+	ids := make([]int, 0, len(items))
+	seen := make(map[int]bool, len(items))
+	for _, item := range items {
+		if seen[item.ObatID] {
+			return nil, ErrResepObatDuplikat
+		}
+		seen[item.ObatID] = true
+		ids = append(ids, item.ObatID)
+	}
+
+	list, err := s.obatRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	aktif := make(map[int]bool, len(list))
+	for _, obat := range list {
+		aktif[obat.ID] = obat.Status == statusKatalogAktif
+	}
+	for _, id := range ids {
+		if !aktif[id] {
+			return nil, fmt.Errorf("%w: id %d", ErrObatInvalid, id)
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/franklindh/simedis-api/internal/model"
+)
+
+var ErrPeringatanBelumDikonfirmasi = errors.New("resep has warnings that must be acknowledged")
+
+// dikembalikan CreateResep bila masih ada peringatan yang kodenya belum dikirim di
+// konfirmasi_peringatan, berisi peringatan yang belum dikonfirmasi
+type PeringatanResepError struct {
+	Peringatan []model.PeringatanResep
+}
+
+func (e *PeringatanResepError) Error() string {
+	return fmt.Sprintf("%v: %d warning(s)", ErrPeringatanBelumDikonfirmasi, len(e.Peringatan))
+}
+
+func (e *PeringatanResepError) Is(target error) bool {
+	return target == ErrPeringatanBelumDikonfirmasi
+}
+
+// cek alergi pasien terhadap zat aktif setiap obat, lalu interaksi antar obat dalam resep
+// dari tabel interaksi lokal. Urutan peringatan mengikuti urutan item resep
+func (s *ResepService) cekPeringatan(resep model.Resep, obatList []model.Obat) ([]model.PeringatanResep, error) {
+	obatByID := make(map[int]model.Obat, len(obatList))
+	for _, o := range obatList {
+		obatByID[o.ID] = o
+	}
+	items := make([]model.Obat, 0, len(resep.Items))
+	for _, item := range resep.Items {
+		items = append(items, obatByID[item.ObatID])
+	}
+
+	alergiList, err := s.alergiRepo.GetAllByPasienID(resep.PasienID)
+	if err != nil {
+		return nil, err
+	}
+	peringatan := peringatanAlergi(items, alergiList)
+
+	var semuaZat []string
+	for _, o := range items {
+		for _, zat := range o.DaftarZat() {
+			if !slices.Contains(semuaZat, zat) {
+				semuaZat = append(semuaZat, zat)
+			}
+		}
+	}
+	interaksiList, err := s.interaksiRepo.GetByZat(semuaZat)
+	if err != nil {
+		return nil, err
+	}
+	return append(peringatan, peringatanInteraksi(items, interaksiList)...), nil
+}
+
+// zat alergi cocok bila sama dengan zat aktif obat atau menjadi bagian namanya,
+// misalnya alergi "penisilin" cocok dengan "fenoksimetil penisilin"
+func peringatanAlergi(items []model.Obat, alergiList []model.AlergiPasien) []model.PeringatanResep {
+	var peringatan []model.PeringatanResep
+	for _, o := range items {
+		for _, alergi := range alergiList {
+			zatAlergi := model.NormalisasiZat(alergi.Zat)
+			cocok := strings.Contains(model.NormalisasiZat(o.NamaObat), zatAlergi)
+			for _, zat := range o.DaftarZat() {
+				cocok = cocok || strings.Contains(zat, zatAlergi)
+			}
+			if !cocok {
+				continue
+			}
+			pesan := fmt.Sprintf("patient is allergic to %s, prescribed %s", alergi.Zat, o.NamaObat)
+			if alergi.Reaksi.Valid {
+				pesan += fmt.Sprintf(" (reaksi: %s)", alergi.Reaksi.String)
+			}
+			peringatan = append(peringatan, model.PeringatanResep{
+				Kode:    fmt.Sprintf("alergi:%d:%d", alergi.ID, o.ID),
+				Jenis:   model.JenisPeringatanAlergi,
+				Tingkat: alergi.TingkatKeparahan,
+				Pesan:   pesan,
+				ObatIDs: []int{o.ID},
+			})
+		}
+	}
+	return peringatan
+}
+
+func peringatanInteraksi(items []model.Obat, interaksiList []model.InteraksiObat) []model.PeringatanResep {
+	var peringatan []model.PeringatanResep
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			a, b := items[i], items[j]
+			for _, interaksi := range interaksiList {
+				if !berinteraksi(a, b, interaksi) {
+					continue
+				}
+				pesan := fmt.Sprintf("%s interacts with %s (%s + %s)", a.NamaObat, b.NamaObat, interaksi.ZatA, interaksi.ZatB)
+				if interaksi.Deskripsi != "" {
+					pesan += ": " + interaksi.Deskripsi
+				}
+				peringatan = append(peringatan, model.PeringatanResep{
+					Kode:    fmt.Sprintf("interaksi:%d:%d:%d", interaksi.ID, min(a.ID, b.ID), max(a.ID, b.ID)),
+					Jenis:   model.JenisPeringatanInteraksi,
+					Tingkat: interaksi.Tingkat,
+					Pesan:   pesan,
+					ObatIDs: []int{a.ID, b.ID},
+				})
+			}
+		}
+	}
+	return peringatan
+}
+
+func berinteraksi(a, b model.Obat, interaksi model.InteraksiObat) bool {
+	zatA, zatB := a.DaftarZat(), b.DaftarZat()
+	return (slices.Contains(zatA, interaksi.ZatA) && slices.Contains(zatB, interaksi.ZatB)) ||
+		(slices.Contains(zatA, interaksi.ZatB) && slices.Contains(zatB, interaksi.ZatA))
+}
+
+func peringatanBelumDikonfirmasi(peringatan []model.PeringatanResep, konfirmasi []string) []model.PeringatanResep {
+	var belum []model.PeringatanResep
+	for _, p := range peringatan {
+		if !slices.Contains(konfirmasi, p.Kode) {
+			belum = append(belum, p)
+		}
+	}
+	return belum
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestResepService_CreateResep(t *testing.T) {
+	mockRepo := new(MockResepRepository)
+	mockObatRepo := new(MockObatRepository)
+	mockAlergiRepo := new(MockAlergiRepository)
+	mockInteraksiRepo := new(MockInteraksiObatRepository)
+	mockPemeriksaanRepo := new(MockPemeriksaanRepository)
+	resepService := NewResepService(mockRepo, mockObatRepo, nil, mockAlergiRepo, mockInteraksiRepo, mockPemeriksaanRepo, nil)
+
+	req := model.CreateResepRequest{
+		Items: []model.ResepItemRequest{
+			{ObatID: 1, Dosis: "500 mg", Frekuensi: "3x sehari", DurasiHari: 5, Rute: "oral", Jumlah: 15},
+			{ObatID: 2, Dosis: "1 tablet", Frekuensi: "1x sehari", DurasiHari: 3, Rute: "oral", Jumlah: 3, AturanPakai: "sesudah makan"},
+		},
+	}
+	pemeriksaan := model.Pemeriksaan{ID: 10, Antrian: model.Antrian{PasienID: 7}}
+	obat := []model.Obat{
+		{ID: 1, KodeObat: "AMX500", NamaObat: "Amoksisilin", Status: "aktif"},
+		{ID: 2, KodeObat: "PCT500", NamaObat: "Parasetamol", Status: "aktif"},
+	}
+
+	t.Run("Success: Resep linked to pasien and dokter", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 10).Return(pemeriksaan, nil).Once()
+		mockObatRepo.On("GetByIDs", []int{1, 2}).Return(obat, nil).Once()
+		mockAlergiRepo.On("GetAllByPasienID", 7).Return([]model.AlergiPasien{}, nil).Once()
+		mockInteraksiRepo.On("GetByZat", []string{"amoksisilin", "parasetamol"}).Return([]model.InteraksiObat{}, nil).Once()
+		mockRepo.On("Create", mock.MatchedBy(func(r model.Resep) bool {
+			return r.PemeriksaanID == 10 && r.PasienID == 7 && r.DokterID == 5 &&
+				r.Status == model.StatusResepMenunggu && len(r.Items) == 2 && r.Items[1].Urutan == 2 &&
+				len(r.Peringatan) == 0
+		})).Return(model.Resep{ID: 1, PemeriksaanID: 10, Status: model.StatusResepMenunggu, Items: []model.ResepItem{{ObatID: 1}, {ObatID: 2}}}, nil).Once()
+
+		result, err := resepService.CreateResep(context.Background(), 10, req, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.ID)
+		assert.Len(t, result.Items, 2)
+		mockRepo.AssertExpectations(t)
+		mockObatRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Obat inactive or missing", func(t *testing.T) {
+		mockPemeriksaanRepo.On("GetById", 10).Return(pemeriksaan, nil).Once()
+		mockObatRepo.On("GetByIDs", []int{1, 2}).Return([]model.Obat{obat[0], {ID: 2, Status: "nonaktif"}}, nil).Once()
+
+		_, err := resepService.CreateResep(context.Background(), 10, req, 5)
+
+		assert.ErrorIs(t, err, ErrObatInvalid)
+		mockRepo.AssertNumberOfCalls(t, "Create", 1)
+	})
+
+	t.Run("Fail: Same obat twice", func(t *testing.T) {
+		duplikat := req
+		duplikat.Items = []model.ResepItemRequest{req.Items[0], req.Items[0]}
+		mockPemeriksaanRepo.On("GetById", 10).Return(pemeriksaan, nil).Once()
+
+		_, err := resepService.CreateResep(context.Background(), 10, duplikat, 5)
+
+		assert.ErrorIs(t, err, ErrResepObatDuplikat)
+	})
+
+	t.Run("Fail: Pemeriksaan voided", func(t *testing.T) {
+		voided := pemeriksaan
+		voided.DibatalkanAt = sql.NullTime{Time: time.Now(), Valid: true}
+		mockPemeriksaanRepo.On("GetById", 10).Return(voided, nil).Once()
+
+		_, err := resepService.CreateResep(context.Background(), 10, req, 5)
+
+		assert.ErrorIs(t, err, ErrPemeriksaanVoided)
+	})
+}
+
+func TestResepService_CreateResep_Peringatan(t *testing.T) {
+	mockRepo := new(MockResepRepository)
+	mockObatRepo := new(MockObatRepository)
+	mockAlergiRepo := new(MockAlergiRepository)
+	mockInteraksiRepo := new(MockInteraksiObatRepository)
+	mockPemeriksaanRepo := new(MockPemeriksaanRepository)
+	resepService := NewResepService(mockRepo, mockObatRepo, nil, mockAlergiRepo, mockInteraksiRepo, mockPemeriksaanRepo, nil)
+
+	req := model.CreateResepRequest{
+		Items: []model.ResepItemRequest{
+			{ObatID: 1, Dosis: "500 mg", Frekuensi: "3x sehari", DurasiHari: 5, Rute: "oral", Jumlah: 15},
+			{ObatID: 3, Dosis: "2 mg", Frekuensi: "1x sehari", DurasiHari: 30, Rute: "oral", Jumlah: 30},
+		},
+	}
+	pemeriksaan := model.Pemeriksaan{ID: 10, Antrian: model.Antrian{PasienID: 7}}
+	obat := []model.Obat{
+		{ID: 1, NamaObat: "Amoxsan", ZatAktif: sql.NullString{String: "Amoksisilin", Valid: true}, Status: "aktif"},
+		{ID: 3, NamaObat: "Warfarin", Status: "aktif"},
+	}
+	alergi := []model.AlergiPasien{{ID: 4, PasienID: 7, Zat: "amoksisilin", Reaksi: sql.NullString{String: "ruam", Valid: true}, TingkatKeparahan: model.KeparahanAlergiBerat}}
+	interaksi := []model.InteraksiObat{{ID: 9, ZatA: "amoksisilin", ZatB: "warfarin", Tingkat: model.TingkatInteraksiModerat, Deskripsi: "meningkatkan INR"}}
+
+	setup := func() {
+		mockPemeriksaanRepo.On("GetById", 10).Return(pemeriksaan, nil).Once()
+		mockObatRepo.On("GetByIDs", []int{1, 3}).Return(obat, nil).Once()
+		mockAlergiRepo.On("GetAllByPasienID", 7).Return(alergi, nil).Once()
+		mockInteraksiRepo.On("GetByZat", []string{"amoksisilin", "warfarin"}).Return(interaksi, nil).Once()
+	}
+
+	t.Run("Fail: Allergy and interaction not acknowledged", func(t *testing.T) {
+		setup()
+
+		_, err := resepService.CreateResep(context.Background(), 10, req, 5)
+
+		assert.ErrorIs(t, err, ErrPeringatanBelumDikonfirmasi)
+		var peringatanErr *PeringatanResepError
+		if assert.ErrorAs(t, err, &peringatanErr) && assert.Len(t, peringatanErr.Peringatan, 2) {
+			assert.Equal(t, model.JenisPeringatanAlergi, peringatanErr.Peringatan[0].Jenis)
+			assert.Equal(t, "alergi:4:1", peringatanErr.Peringatan[0].Kode)
+			assert.Equal(t, model.KeparahanAlergiBerat, peringatanErr.Peringatan[0].Tingkat)
+			assert.Equal(t, model.JenisPeringatanInteraksi, peringatanErr.Peringatan[1].Jenis)
+			assert.Equal(t, "interaksi:9:1:3", peringatanErr.Peringatan[1].Kode)
+			assert.Equal(t, []int{1, 3}, peringatanErr.Peringatan[1].ObatIDs)
+		}
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Fail: Only some warnings acknowledged", func(t *testing.T) {
+		setup()
+		sebagian := req
+		sebagian.KonfirmasiPeringatan = []string{"alergi:4:1"}
+
+		_, err := resepService.CreateResep(context.Background(), 10, sebagian, 5)
+
+		var peringatanErr *PeringatanResepError
+		if assert.ErrorAs(t, err, &peringatanErr) && assert.Len(t, peringatanErr.Peringatan, 1) {
+			assert.Equal(t, "interaksi:9:1:3", peringatanErr.Peringatan[0].Kode)
+		}
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Success: Acknowledged warnings saved with resep", func(t *testing.T) {
+		setup()
+		dikonfirmasi := req
+		dikonfirmasi.KonfirmasiPeringatan = []string{"alergi:4:1", "interaksi:9:1:3"}
+		mockRepo.On("Create", mock.MatchedBy(func(r model.Resep) bool {
+			return len(r.Peringatan) == 2 && r.Peringatan[0].Kode == "alergi:4:1" && r.Peringatan[1].Kode == "interaksi:9:1:3"
+		})).Return(model.Resep{ID: 2, Peringatan: []model.ResepPeringatan{{Kode: "alergi:4:1"}, {Kode: "interaksi:9:1:3"}}}, nil).Once()
+
+		result, err := resepService.CreateResep(context.Background(), 10, dikonfirmasi, 5)
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Peringatan, 2)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestResepService_SerahkanResep(t *testing.T) {
+	mockRepo := new(MockResepRepository)
+	mockStokRepo := new(MockStokRepository)
+	resepService := NewResepService(mockRepo, nil, mockStokRepo, nil, nil, nil, nil)
+
+	paracetamol := model.Obat{ID: 5, NamaObat: "Paracetamol", Satuan: "tablet"}
+	amoxicillin := model.Obat{ID: 6, NamaObat: "Amoxicillin", Satuan: "kapsul"}
+	pending := func(id int) model.Resep {
+		return model.Resep{ID: id, Status: model.StatusResepMenunggu, Items: []model.ResepItem{
+			{ID: 11, ObatID: 5, Jumlah: 10, Obat: paracetamol},
+			{ID: 12, ObatID: 6, Jumlah: 15, Obat: amoxicillin},
+		}}
+	}
+
+	t.Run("Success: Stock taken from earliest expiry batch first", func(t *testing.T) {
+		mockRepo.On("GetByID", 1).Return(pending(1), nil).Once()
+		// urutan dari repository sudah FEFO
+		mockStokRepo.On("GetBatchTersedia", []int{5, 6}, mock.AnythingOfType("time.Time")).Return([]model.StokBatch{
+			{ID: 101, ObatID: 5, Sisa: 30},
+			{ID: 201, ObatID: 6, Sisa: 10},
+			{ID: 102, ObatID: 5, Sisa: 50},
+			{ID: 202, ObatID: 6, Sisa: 20},
+		}, nil).Once()
+
+		var mutasi []model.StokMutasi
+		mockRepo.On("Serahkan", 1, 9, mock.AnythingOfType("time.Time"), mock.Anything).
+			Run(func(args mock.Arguments) { mutasi = args.Get(3).([]model.StokMutasi) }).
+			Return(model.Resep{
+				ID: 1, Status: model.StatusResepDiserahkan,
+				DiserahkanAt:   sql.NullTime{Time: time.Now(), Valid: true},
+				DiserahkanOleh: sql.NullInt64{Int64: 9, Valid: true},
+				Penyerah:       model.Petugas{ID: 9, Nama: "Apoteker", Role: "Apotek"},
+			}, nil).Once()
+
+		result, err := resepService.SerahkanResep(context.Background(), 1, 9)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.StatusResepDiserahkan, result.Status)
+		assert.Equal(t, 9, result.DiserahkanOleh.ID)
+		if assert.Len(t, mutasi, 3) {
+			assert.Equal(t, 101, mutasi[0].StokBatchID)
+			assert.Equal(t, -10, mutasi[0].Jumlah)
+			// amoxicillin dipecah ke dua batch
+			assert.Equal(t, 201, mutasi[1].StokBatchID)
+			assert.Equal(t, -10, mutasi[1].Jumlah)
+			assert.Equal(t, 202, mutasi[2].StokBatchID)
+			assert.Equal(t, -5, mutasi[2].Jumlah)
+			for _, m := range mutasi {
+				assert.Equal(t, model.JenisMutasiKeluar, m.Jenis)
+				assert.Equal(t, int64(1), m.ResepID.Int64)
+				assert.Equal(t, int64(9), m.DibuatOleh.Int64)
+			}
+			assert.Equal(t, int64(12), mutasi[2].ResepItemID.Int64)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Insufficient stock", func(t *testing.T) {
+		mockRepo.On("GetByID", 4).Return(pending(4), nil).Once()
+		mockStokRepo.On("GetBatchTersedia", []int{5, 6}, mock.AnythingOfType("time.Time")).Return([]model.StokBatch{
+			{ID: 101, ObatID: 5, Sisa: 30},
+			{ID: 201, ObatID: 6, Sisa: 10},
+		}, nil).Once()
+
+		_, err := resepService.SerahkanResep(context.Background(), 4, 9)
+
+		assert.ErrorIs(t, err, ErrStokTidakCukup)
+		assert.Contains(t, err.Error(), "Amoxicillin")
+		mockRepo.AssertNumberOfCalls(t, "Serahkan", 1)
+	})
+
+	t.Run("Fail: Stock taken by concurrent request", func(t *testing.T) {
+		mockRepo.On("GetByID", 5).Return(pending(5), nil).Once()
+		mockStokRepo.On("GetBatchTersedia", []int{5, 6}, mock.AnythingOfType("time.Time")).Return([]model.StokBatch{
+			{ID: 101, ObatID: 5, Sisa: 30},
+			{ID: 202, ObatID: 6, Sisa: 20},
+		}, nil).Once()
+		mockRepo.On("Serahkan", 5, 9, mock.AnythingOfType("time.Time"), mock.Anything).Return(model.Resep{}, repository.ErrStokBerubah).Once()
+
+		_, err := resepService.SerahkanResep(context.Background(), 5, 9)
+
+		assert.ErrorIs(t, err, ErrStokTidakCukup)
+	})
+
+	t.Run("Fail: Already dispensed", func(t *testing.T) {
+		mockRepo.On("GetByID", 2).Return(model.Resep{ID: 2, Status: model.StatusResepDiserahkan}, nil).Once()
+
+		_, err := resepService.SerahkanResep(context.Background(), 2, 9)
+
+		assert.ErrorIs(t, err, ErrResepDiserahkan)
+	})
+
+	t.Run("Fail: Dispensed by concurrent request", func(t *testing.T) {
+		mockRepo.On("GetByID", 3).Return(pending(3), nil).Once()
+		mockStokRepo.On("GetBatchTersedia", []int{5, 6}, mock.AnythingOfType("time.Time")).Return([]model.StokBatch{
+			{ID: 101, ObatID: 5, Sisa: 30},
+			{ID: 202, ObatID: 6, Sisa: 20},
+		}, nil).Once()
+		mockRepo.On("Serahkan", 3, 9, mock.AnythingOfType("time.Time"), mock.Anything).Return(model.Resep{}, repository.ErrNotFound).Once()
+
+		_, err := resepService.SerahkanResep(context.Background(), 3, 9)
+
+		assert.ErrorIs(t, err, ErrResepDiserahkan)
+	})
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/franklindh/simedis-api/pkg/utils/pagination"
+)
+
+var (
+	ErrStokTidakCukup       = errors.New("insufficient stock")
+	ErrBatchKedaluwarsaBeda = errors.New("batch already recorded with a different expiry date")
+	ErrBatchKedaluwarsa     = errors.New("batch is already expired")
+)
+
+const defaultHariPeringatanKedaluwarsa = 90
+
+type StokService struct {
+	repo     StokRepository
+	obatRepo ObatRepository
+}
+
+func NewStokService(repo StokRepository, obatRepo ObatRepository) *StokService {
+	return &StokService{repo: repo, obatRepo: obatRepo}
+}
+
+// penerimaan batch baru atau tambahan untuk batch yang sudah ada
+func (s *StokService) StokMasuk(ctx context.Context, req model.StokMasukRequest, actorID int) (model.StokBatchResponse, error) {
+	today := time.Now()
+	obat, err := s.obatRepo.GetByID(req.ObatID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.StokBatchResponse{}, fmt.Errorf("%w: id %d", ErrObatInvalid, req.ObatID)
+		}
+		return model.StokBatchResponse{}, err
+	}
+	if obat.Status != statusKatalogAktif {
+		return model.StokBatchResponse{}, fmt.Errorf("%w: id %d", ErrObatInvalid, req.ObatID)
+	}
+
+	batch, mutasi := req.ToModel()
+	if batch.IsKedaluwarsa(today) {
+		return model.StokBatchResponse{}, ErrBatchKedaluwarsa
+	}
+	existing, err := s.repo.GetBatchByNomor(obat.ID, batch.NomorBatch)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return model.StokBatchResponse{}, err
+	}
+	if err == nil && !existing.TanggalKedaluwarsa.Equal(batch.TanggalKedaluwarsa) {
+		return model.StokBatchResponse{}, ErrBatchKedaluwarsaBeda
+	}
+	mutasi.DibuatOleh = sql.NullInt64{Int64: int64(actorID), Valid: actorID > 0}
+
+	saved, err := s.repo.StokMasuk(batch, mutasi)
+	if err != nil {
+		return model.StokBatchResponse{}, fmt.Errorf("failed to record stock in: %w", err)
+	}
+	return model.ToStokBatchResponse(saved, today), nil
+}
+
+func (s *StokService) Sesuaikan(ctx context.Context, req model.PenyesuaianStokRequest, actorID int) (model.StokBatchResponse, error) {
+	batch, err := s.repo.GetBatchByID(req.StokBatchID)
+	if err != nil {
+		return model.StokBatchResponse{}, err
+	}
+	if batch.Sisa+req.Jumlah < 0 {
+		return model.StokBatchResponse{}, fmt.Errorf("%w: sisa batch %s %d", ErrStokTidakCukup, batch.NomorBatch, batch.Sisa)
+	}
+
+	saved, err := s.repo.Sesuaikan(model.StokMutasi{
+		StokBatchID: batch.ID,
+		ObatID:      batch.ObatID,
+		Jenis:       model.JenisMutasiPenyesuaian,
+		Jumlah:      req.Jumlah,
+		Keterangan:  sql.NullString{String: req.Keterangan, Valid: true},
+		DibuatOleh:  sql.NullInt64{Int64: int64(actorID), Valid: actorID > 0},
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrStokBerubah) {
+			return model.StokBatchResponse{}, fmt.Errorf("%w: %v", ErrStokTidakCukup, err)
+		}
+		return model.StokBatchResponse{}, err
+	}
+	return model.ToStokBatchResponse(saved, time.Now()), nil
+}
+
+func (s *StokService) GetAllBatch(ctx context.Context, params repository.ParamsGetAllStokBatch) ([]model.StokBatchResponse, error) {
+	list, err := s.repo.GetAllBatch(params)
+	if err != nil {
+		return nil, err
+	}
+	return model.ToStokBatchResponseList(list, time.Now()), nil
+}
+
+func (s *StokService) GetAllMutasi(ctx context.Context, params repository.ParamsGetAllStokMutasi) ([]model.StokMutasiResponse, pagination.Metadata, error) {
+	if params.From != "" && params.To != "" && params.From > params.To {
+		return nil, pagination.Metadata{}, ErrInvalidDateRange
+	}
+	list, metadata, err := s.repo.GetAllMutasi(params)
+	if err != nil {
+		return nil, metadata, err
+	}
+	return model.ToStokMutasiResponseList(list), metadata, nil
+}
+
+// stok yang mencapai batas minimum serta batch yang kedaluwarsa dalam rentang hari tertentu
+// atau sudah kedaluwarsa tetapi masih bersisa
+func (s *StokService) GetPeringatan(ctx context.Context, params model.ParamsPeringatanStok) (model.PeringatanStokResponse, error) {
+	if params.Hari == 0 {
+		params.Hari = defaultHariPeringatanKedaluwarsa
+	}
+	today := time.Now()
+
+	menipis, err := s.repo.GetStokMenipis(today)
+	if err != nil {
+		return model.PeringatanStokResponse{}, err
+	}
+	batches, err := s.repo.GetBatchKedaluwarsa(today.AddDate(0, 0, params.Hari))
+	if err != nil {
+		return model.PeringatanStokResponse{}, err
+	}
+
+	resp := model.PeringatanStokResponse{
+		StokMenipis:          menipis,
+		MendekatiKedaluwarsa: []model.StokBatchResponse{},
+		SudahKedaluwarsa:     []model.StokBatchResponse{},
+		BatasHariKedaluwarsa: params.Hari,
+	}
+	if resp.StokMenipis == nil {
+		resp.StokMenipis = []model.StokObat{}
+	}
+	for _, b := range batches {
+		if b.IsKedaluwarsa(today) {
+			resp.SudahKedaluwarsa = append(resp.SudahKedaluwarsa, model.ToStokBatchResponse(b, today))
+			continue
+		}
+		resp.MendekatiKedaluwarsa = append(resp.MendekatiKedaluwarsa, model.ToStokBatchResponse(b, today))
+	}
+	return resp, nil
+}
+
+// memilih batch untuk setiap item resep dengan aturan FEFO. batches harus sudah terurut dari
+// kedaluwarsa paling dekat dan hanya berisi batch yang belum kedaluwarsa. Satu item bisa
+// diambil dari beberapa batch bila sisa batch pertama tidak cukup
+func alokasiFEFO(resep model.Resep, batches []model.StokBatch, actorID int) ([]model.StokMutasi, error) {
+	sisa := make(map[int]int, len(batches))
+	for _, b := range batches {
+		sisa[b.ID] = b.Sisa
+	}
+
+	var mutasi []model.StokMutasi
+	for _, item := range resep.Items {
+		kebutuhan := item.Jumlah
+		for _, b := range batches {
+			if kebutuhan == 0 {
+				break
+			}
+			if b.ObatID != item.ObatID || sisa[b.ID] == 0 {
+				continue
+			}
+			ambil := min(kebutuhan, sisa[b.ID])
+			sisa[b.ID] -= ambil
+			kebutuhan -= ambil
+			mutasi = append(mutasi, model.StokMutasi{
+				StokBatchID: b.ID,
+				ObatID:      item.ObatID,
+				Jenis:       model.JenisMutasiKeluar,
+				Jumlah:      -ambil,
+				ResepID:     sql.NullInt64{Int64: int64(resep.ID), Valid: true},
+				ResepItemID: sql.NullInt64{Int64: int64(item.ID), Valid: item.ID > 0},
+				DibuatOleh:  sql.NullInt64{Int64: int64(actorID), Valid: actorID > 0},
+			})
+		}
+		if kebutuhan > 0 {
+			return nil, fmt.Errorf("%w: %s kurang %d %s", ErrStokTidakCukup, item.Obat.NamaObat, kebutuhan, item.Obat.Satuan)
+		}
+	}
+	return mutasi, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/franklindh/simedis-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStokService_StokMasuk(t *testing.T) {
+	mockRepo := new(MockStokRepository)
+	mockObatRepo := new(MockObatRepository)
+	stokService := NewStokService(mockRepo, mockObatRepo)
+
+	kedaluwarsa := time.Now().AddDate(1, 0, 0)
+	req := model.StokMasukRequest{ObatID: 5, NomorBatch: "B2401", TanggalKedaluwarsa: kedaluwarsa.Format("2006-01-02"), Jumlah: 100}
+
+	t.Run("Success: New batch recorded with mutasi masuk", func(t *testing.T) {
+		mockObatRepo.On("GetByID", 5).Return(model.Obat{ID: 5, Status: "aktif"}, nil).Once()
+		mockRepo.On("GetBatchByNomor", 5, "B2401").Return(model.StokBatch{}, repository.ErrNotFound).Once()
+		mockRepo.On("StokMasuk", mock.AnythingOfType("model.StokBatch"), mock.MatchedBy(func(m model.StokMutasi) bool {
+			return m.Jenis == model.JenisMutasiMasuk && m.Jumlah == 100 && m.DibuatOleh.Int64 == 7
+		})).Return(model.StokBatch{ID: 1, ObatID: 5, NomorBatch: "B2401", TanggalKedaluwarsa: kedaluwarsa, Sisa: 100}, nil).Once()
+
+		result, err := stokService.StokMasuk(context.Background(), req, 7)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 100, result.Sisa)
+		assert.False(t, result.Kedaluwarsa)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Same batch number with different expiry", func(t *testing.T) {
+		mockObatRepo.On("GetByID", 5).Return(model.Obat{ID: 5, Status: "aktif"}, nil).Once()
+		mockRepo.On("GetBatchByNomor", 5, "B2401").Return(model.StokBatch{ID: 1, TanggalKedaluwarsa: kedaluwarsa.AddDate(0, 1, 0)}, nil).Once()
+
+		_, err := stokService.StokMasuk(context.Background(), req, 7)
+
+		assert.ErrorIs(t, err, ErrBatchKedaluwarsaBeda)
+	})
+
+	t.Run("Fail: Obat inactive", func(t *testing.T) {
+		mockObatRepo.On("GetByID", 5).Return(model.Obat{ID: 5, Status: "nonaktif"}, nil).Once()
+
+		_, err := stokService.StokMasuk(context.Background(), req, 7)
+
+		assert.ErrorIs(t, err, ErrObatInvalid)
+	})
+
+	t.Run("Fail: Batch already expired", func(t *testing.T) {
+		expired := req
+		expired.TanggalKedaluwarsa = time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+		mockObatRepo.On("GetByID", 5).Return(model.Obat{ID: 5, Status: "aktif"}, nil).Once()
+
+		_, err := stokService.StokMasuk(context.Background(), expired, 7)
+
+		assert.ErrorIs(t, err, ErrBatchKedaluwarsa)
+		mockRepo.AssertNumberOfCalls(t, "StokMasuk", 1)
+	})
+}
+
+func TestStokService_Sesuaikan(t *testing.T) {
+	mockRepo := new(MockStokRepository)
+	stokService := NewStokService(mockRepo, nil)
+
+	t.Run("Success: Damaged stock written off", func(t *testing.T) {
+		mockRepo.On("GetBatchByID", 1).Return(model.StokBatch{ID: 1, ObatID: 5, Sisa: 20}, nil).Once()
+		mockRepo.On("Sesuaikan", mock.MatchedBy(func(m model.StokMutasi) bool {
+			return m.Jenis == model.JenisMutasiPenyesuaian && m.Jumlah == -5 && m.ObatID == 5 && m.Keterangan.String == "kemasan rusak"
+		})).Return(model.StokBatch{ID: 1, ObatID: 5, Sisa: 15}, nil).Once()
+
+		result, err := stokService.Sesuaikan(context.Background(), model.PenyesuaianStokRequest{StokBatchID: 1, Jumlah: -5, Keterangan: "kemasan rusak"}, 7)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 15, result.Sisa)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Adjustment makes stock negative", func(t *testing.T) {
+		mockRepo.On("GetBatchByID", 2).Return(model.StokBatch{ID: 2, ObatID: 5, Sisa: 3}, nil).Once()
+
+		_, err := stokService.Sesuaikan(context.Background(), model.PenyesuaianStokRequest{StokBatchID: 2, Jumlah: -5, Keterangan: "stock opname"}, 7)
+
+		assert.ErrorIs(t, err, ErrStokTidakCukup)
+		mockRepo.AssertNumberOfCalls(t, "Sesuaikan", 1)
+	})
+}
+
+func TestStokService_GetPeringatan(t *testing.T) {
+	mockRepo := new(MockStokRepository)
+	stokService := NewStokService(mockRepo, nil)
+
+	t.Run("Success: Expired and near expiry batches separated", func(t *testing.T) {
+		today := time.Now()
+		mockRepo.On("GetStokMenipis", mock.AnythingOfType("time.Time")).Return([]model.StokObat{
+			{ObatID: 5, NamaObat: "Paracetamol", TotalStok: 4, StokMinimum: 20},
+		}, nil).Once()
+		mockRepo.On("GetBatchKedaluwarsa", mock.MatchedBy(func(batas time.Time) bool {
+			return batas.Format("2006-01-02") == today.AddDate(0, 0, 90).Format("2006-01-02")
+		})).Return([]model.StokBatch{
+			{ID: 1, ObatID: 5, Sisa: 4, TanggalKedaluwarsa: today.AddDate(0, 0, -3)},
+			{ID: 2, ObatID: 6, Sisa: 10, TanggalKedaluwarsa: today},
+			{ID: 3, ObatID: 6, Sisa: 10, TanggalKedaluwarsa: today.AddDate(0, 0, 30)},
+		}, nil).Once()
+
+		result, err := stokService.GetPeringatan(context.Background(), model.ParamsPeringatanStok{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 90, result.BatasHariKedaluwarsa)
+		assert.Len(t, result.StokMenipis, 1)
+		if assert.Len(t, result.SudahKedaluwarsa, 1) {
+			assert.Equal(t, 1, result.SudahKedaluwarsa[0].ID)
+			assert.True(t, result.SudahKedaluwarsa[0].Kedaluwarsa)
+		}
+		// batch yang kedaluwarsa hari ini masih boleh dipakai
+		assert.Len(t, result.MendekatiKedaluwarsa, 2)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/franklindh/simedis-api/internal/model"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTandaVitalLama(t *testing.T) {
+	type input struct{ nadi, tekananDarah, suhu, beratBadan string }
+	cases := []struct {
+		name  string
+		input input
+		want  model.TandaVital
+	}{
+		{
+			name:  "Success: Free text with units and spaces",
+			input: input{nadi: "88 x/mnt", tekananDarah: "120 / 80 mmHg", suhu: "36,5 C", beratBadan: "60kg"},
+			want: model.TandaVital{
+				Sistolik:   sql.NullInt64{Int64: 120, Valid: true},
+				Diastolik:  sql.NullInt64{Int64: 80, Valid: true},
+				Nadi:       sql.NullInt64{Int64: 88, Valid: true},
+				Suhu:       sql.NullFloat64{Float64: 36.5, Valid: true},
+				BeratBadan: sql.NullFloat64{Float64: 60, Valid: true},
+			},
+		},
+		{
+			name:  "Success: Compact blood pressure and decimal point",
+			input: input{tekananDarah: "110/70", suhu: "37.2", beratBadan: "3,4 kg"},
+			want: model.TandaVital{
+				Sistolik:   sql.NullInt64{Int64: 110, Valid: true},
+				Diastolik:  sql.NullInt64{Int64: 70, Valid: true},
+				Suhu:       sql.NullFloat64{Float64: 37.2, Valid: true},
+				BeratBadan: sql.NullFloat64{Float64: 3.4, Valid: true},
+			},
+		},
+		{
+			name:  "Fail: Unreadable text stays empty",
+			input: input{nadi: "abc", tekananDarah: "abc", suhu: "abc", beratBadan: "abc"},
+		},
+		{
+			name:  "Fail: Empty input",
+			input: input{},
+		},
+		{
+			name:  "Fail: Out of range values are dropped",
+			input: input{nadi: "400", tekananDarah: "400/80", suhu: "50", beratBadan: "900 kg"},
+		},
+		{
+			name:  "Fail: Systolic not above diastolic",
+			input: input{tekananDarah: "80/120"},
+		},
+		{
+			name:  "Fail: Fractional pulse is dropped",
+			input: input{nadi: "72,5", suhu: "29"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := model.ParseTandaVitalLama(tc.input.nadi, tc.input.tekananDarah, tc.input.suhu, tc.input.beratBadan)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestTandaVitalRequest_Binding(t *testing.T) {
+	angka := func(v int) *int { return &v }
+
+	cases := []struct {
+		name  string
+		req   model.TandaVitalRequest
+		valid bool
+	}{
+		{name: "Success: Systolic above diastolic", req: model.TandaVitalRequest{Sistolik: angka(120), Diastolik: angka(80)}, valid: true},
+		{name: "Success: No blood pressure", req: model.TandaVitalRequest{Nadi: angka(80)}, valid: true},
+		{name: "Fail: Diastolic without systolic", req: model.TandaVitalRequest{Diastolik: angka(80)}},
+		{name: "Fail: Systolic without diastolic", req: model.TandaVitalRequest{Sistolik: angka(120)}},
+		{name: "Fail: Systolic equal to diastolic", req: model.TandaVitalRequest{Sistolik: angka(80), Diastolik: angka(80)}},
+		{name: "Fail: Systolic below diastolic", req: model.TandaVitalRequest{Sistolik: angka(70), Diastolik: angka(90)}},
+		{name: "Fail: Pulse out of range", req: model.TandaVitalRequest{Nadi: angka(300)}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := binding.Validator.ValidateStruct(tc.req)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}